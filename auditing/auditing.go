@@ -68,6 +68,11 @@ func Previous(ctx context.Context, log *AuditLog) (*AuditLog, error) {
 // ProcessEventsFromAuditTrail inspects the audit table for logs which need to be
 // turned into events, updates their event_processed_at column, and then returns them.
 func ProcessEventsFromAuditTrail(ctx context.Context, schema *proto.Schema, traceId string) ([]*AuditLog, error) {
+	// User-defined events are not generated from the audit trail
+	if len(schema.ModelEvents()) == 0 {
+		return []*AuditLog{}, nil
+	}
+
 	database, err := db.GetDatabase(ctx)
 	if err != nil {
 		return nil, err
@@ -140,14 +145,15 @@ func processEventsSql(schema *proto.Schema, traceId string) (string, []any, erro
 		return "", nil, errors.New("traceId cannot be empty")
 	}
 
-	if len(schema.Events) == 0 {
+	modelEvents := schema.ModelEvents()
+	if len(modelEvents) == 0 {
 		return "", nil, errors.New("there are no events defined in this schema")
 	}
 
 	args := []any{}
 
 	conditions := []string{}
-	for _, e := range schema.Events {
+	for _, e := range modelEvents {
 		table := casing.ToSnake(e.ModelName)
 		op, err := opFromActionType(e.ActionType)
 		if err != nil {
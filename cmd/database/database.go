@@ -115,6 +115,39 @@ func Start(reset bool, projectDirectory string) (*db.ConnectionInfo, error) {
 	return projectConnectionInfo, nil
 }
 
+// StartScratch starts the database in the same way as Start and creates an empty scratch database for the
+// project alongside the project's database, such as for generating migrations. Any existing scratch database
+// is dropped first. The returned func drops the scratch database once all connections to it are closed.
+func StartScratch(projectDirectory string) (*db.ConnectionInfo, func() error, error) {
+	projectConnectionInfo, err := Start(false, projectDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scratchDbName := projectConnectionInfo.Database + "_scratch"
+
+	scratchDatabaseExists, err := doesDbExist(projectConnectionInfo, scratchDbName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if scratchDatabaseExists {
+		if err := dropDatabase(projectConnectionInfo, scratchDbName); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := createProjectDatabase(projectConnectionInfo, scratchDbName); err != nil {
+		return nil, nil, err
+	}
+
+	drop := func() error {
+		return dropDatabase(projectConnectionInfo, scratchDbName)
+	}
+
+	return projectConnectionInfo.WithDatabase(scratchDbName), drop, nil
+}
+
 // Stop stops the postgres container - having checked first
 // that such a container exists, and it is running.
 //
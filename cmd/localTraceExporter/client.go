@@ -16,9 +16,7 @@ package localTraceExporter
 
 import (
 	"context"
-	"encoding/hex"
 	"sync"
-	"time"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
@@ -31,21 +29,7 @@ type client struct {
 // Compile time check *client implements otlptrace.Client.
 var _ otlptrace.Client = (*client)(nil)
 
-var (
-	traces       = make(map[string][]*tracepb.Span)
-	traceSummary = make(map[string]*TraceSummary)
-)
-
-type TraceSummary struct {
-	StartTime time.Time
-	EndTime   time.Time
-	HasError  bool
-	Duration  time.Duration
-	RootName  string
-	Type      string
-}
-
-// NewClient creates a client that stores the spans in memory.
+// NewClient creates a client that adds the spans to the current store.
 func NewClient() otlptrace.Client {
 	return newClient()
 }
@@ -55,18 +39,6 @@ func newClient() *client {
 	return c
 }
 
-func GetTrace(traceID string) []*tracepb.Span {
-	return traces[traceID]
-}
-
-func AllTraces() map[string][]*tracepb.Span {
-	return traces
-}
-
-func Summary() map[string]*TraceSummary {
-	return traceSummary
-}
-
 func (c *client) Start(ctx context.Context) error {
 	return nil
 }
@@ -79,61 +51,17 @@ func (c *client) UploadTraces(ctx context.Context, protoSpans []*tracepb.Resourc
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Unpack all the spans and store in memory by trace ID
+	// Unpack all the spans and add them to the store.
 	// This is lossy as we're loosing the resource and service data so we may want to improve this later
+	spans := []*tracepb.Span{}
 	for _, ResourceSpan := range protoSpans {
 		scopedSpans := ResourceSpan.GetScopeSpans()
 		for _, scopedSpans := range scopedSpans {
-			for _, span := range scopedSpans.Spans {
-				traceID := hex.EncodeToString(span.TraceId)
-				traces[traceID] = append(traces[traceID], span)
-
-				c.updateTraceSummary(traceID, span)
-			}
+			spans = append(spans, scopedSpans.Spans...)
 		}
 	}
 
-	return nil
-}
-
-func (c *client) updateTraceSummary(traceID string, span *tracepb.Span) {
-	start := time.Unix(0, int64(span.StartTimeUnixNano))
-	end := time.Unix(0, int64(span.EndTimeUnixNano))
-
-	summary, has := traceSummary[traceID]
-	if !has {
-		summary = &TraceSummary{
-			StartTime: start,
-			EndTime:   end,
-			Duration:  end.Sub(start),
-			HasError:  false,
-		}
-	} else {
-		if start.Before(summary.StartTime) {
-			summary.StartTime = start
-		}
-		if end.After(summary.EndTime) {
-			summary.EndTime = end
-		}
-
-		summary.Duration = summary.EndTime.Sub(summary.StartTime)
-	}
-
-	if span.ParentSpanId == nil {
-		summary.RootName = span.Name
-
-		for _, attr := range span.Attributes {
-			if attr.Key == "type" {
-				summary.Type = attr.Value.GetStringValue()
-			}
-		}
-	}
-
-	if span.Status.Code == tracepb.Status_STATUS_CODE_ERROR {
-		summary.HasError = true
-	}
-
-	traceSummary[traceID] = summary
+	return getStore().AddSpans(ctx, spans)
 }
 
 // MarshalLog is the marshaling function used by the logging system to represent this Client.
@@ -0,0 +1,241 @@
+package localTraceExporter
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/teamkeel/keel/db"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+const (
+	TraceTableName     = "keel_trace"
+	TraceSpanTableName = "keel_trace_span"
+)
+
+var createTablesSql = fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %[1]s (
+	trace_id TEXT PRIMARY KEY,
+	root_name TEXT NOT NULL DEFAULT '',
+	type TEXT NOT NULL DEFAULT '',
+	start_time TIMESTAMPTZ NOT NULL,
+	end_time TIMESTAMPTZ NOT NULL,
+	has_error BOOLEAN NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS idx_%[1]s_start_time ON %[1]s (start_time);
+CREATE TABLE IF NOT EXISTS %[2]s (
+	trace_id TEXT NOT NULL REFERENCES %[1]s (trace_id) ON DELETE CASCADE,
+	span_id TEXT NOT NULL,
+	data BYTEA NOT NULL,
+	attributes JSONB NOT NULL DEFAULT '{}',
+	PRIMARY KEY (trace_id, span_id)
+);`, TraceTableName, TraceSpanTableName)
+
+type databaseStore struct {
+	// The connection is used directly, rather than through db.Database, as the
+	// statements would otherwise create spans and so more traces to store.
+	conn      *gorm.DB
+	retention Retention
+}
+
+var _ Store = &databaseStore{}
+
+// NewDatabaseStore creates a store which keeps traces in the database so that they are kept across restarts.
+// The tables are created if they do not already exist.
+func NewDatabaseStore(ctx context.Context, database db.Database, retention Retention) (Store, error) {
+	conn := database.GetDB()
+
+	err := conn.WithContext(ctx).Exec(createTablesSql).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &databaseStore{
+		conn:      conn,
+		retention: retention,
+	}, nil
+}
+
+func (s *databaseStore) AddSpans(ctx context.Context, spans []*tracepb.Span) error {
+	summaries := map[string]*TraceSummary{}
+	for _, span := range spans {
+		traceID := hex.EncodeToString(span.TraceId)
+		summaries[traceID] = summarise(summaries[traceID], span)
+	}
+
+	return s.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, summary := range summaries {
+			err := tx.Exec(
+				fmt.Sprintf(`INSERT INTO %[1]s (trace_id, root_name, type, start_time, end_time, has_error) VALUES (?, ?, ?, ?, ?, ?)
+				ON CONFLICT (trace_id) DO UPDATE SET
+					root_name = CASE WHEN EXCLUDED.root_name <> '' THEN EXCLUDED.root_name ELSE %[1]s.root_name END,
+					type = CASE WHEN EXCLUDED.root_name <> '' THEN EXCLUDED.type ELSE %[1]s.type END,
+					start_time = LEAST(%[1]s.start_time, EXCLUDED.start_time),
+					end_time = GREATEST(%[1]s.end_time, EXCLUDED.end_time),
+					has_error = %[1]s.has_error OR EXCLUDED.has_error`, TraceTableName),
+				summary.TraceID, summary.RootName, summary.Type, summary.StartTime, summary.EndTime, summary.HasError,
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, span := range spans {
+			data, err := proto.Marshal(span)
+			if err != nil {
+				return err
+			}
+
+			attributes := map[string]string{}
+			for _, attr := range span.Attributes {
+				if v, ok := attributeValue(attr.Value); ok {
+					attributes[attr.Key] = v
+				}
+			}
+
+			attributesJson, err := json.Marshal(attributes)
+			if err != nil {
+				return err
+			}
+
+			err = tx.Exec(
+				fmt.Sprintf("INSERT INTO %s (trace_id, span_id, data, attributes) VALUES (?, ?, ?, ?::jsonb) ON CONFLICT DO NOTHING", TraceSpanTableName),
+				hex.EncodeToString(span.TraceId), hex.EncodeToString(span.SpanId), data, string(attributesJson),
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.prune(tx)
+	})
+}
+
+// prune removes the traces which are beyond the retention settings. Their spans are removed by the cascading delete.
+func (s *databaseStore) prune(tx *gorm.DB) error {
+	if s.retention.MaxAge > 0 {
+		err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE start_time < ?", TraceTableName), time.Now().Add(-s.retention.MaxAge)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.retention.MaxTraces > 0 {
+		err := tx.Exec(
+			fmt.Sprintf("DELETE FROM %[1]s WHERE trace_id IN (SELECT trace_id FROM %[1]s ORDER BY start_time DESC OFFSET ?)", TraceTableName),
+			s.retention.MaxTraces,
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *databaseStore) GetTrace(ctx context.Context, traceID string) ([]*tracepb.Span, error) {
+	rows := []struct {
+		Data []byte
+	}{}
+
+	err := s.conn.WithContext(ctx).Raw(fmt.Sprintf("SELECT data FROM %s WHERE trace_id = ?", TraceSpanTableName), traceID).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	spans := make([]*tracepb.Span, 0, len(rows))
+	for _, row := range rows {
+		span := &tracepb.Span{}
+		err = proto.Unmarshal(row.Data, span)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartTimeUnixNano < spans[j].StartTimeUnixNano
+	})
+
+	return spans, nil
+}
+
+func (s *databaseStore) ListTraces(ctx context.Context, query *TraceQuery) ([]*TraceSummary, error) {
+	conditions := []string{"true"}
+	args := []any{}
+
+	if query.After != nil {
+		conditions = append(conditions, "start_time >= ?")
+		args = append(args, *query.After)
+	}
+	if query.Before != nil {
+		conditions = append(conditions, "start_time <= ?")
+		args = append(args, *query.Before)
+	}
+	if query.HasError != nil {
+		conditions = append(conditions, "has_error = ?")
+		args = append(args, *query.HasError)
+	}
+	if query.MinDuration != nil {
+		conditions = append(conditions, "end_time - start_time >= make_interval(secs => ?)")
+		args = append(args, query.MinDuration.Seconds())
+	}
+	if query.MaxDuration != nil {
+		conditions = append(conditions, "end_time - start_time <= make_interval(secs => ?)")
+		args = append(args, query.MaxDuration.Seconds())
+	}
+	if query.RequestsOnly {
+		conditions = append(conditions, "type = 'request' AND root_name <> 'GET /_health' AND root_name NOT LIKE '%openapi.json' AND root_name NOT LIKE 'OPTIONS%'")
+	}
+	for key, value := range query.Attributes {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM %s s WHERE s.trace_id = %s.trace_id AND s.attributes ->> ? = ?)", TraceSpanTableName, TraceTableName))
+		args = append(args, key, value)
+	}
+
+	sql := fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY start_time DESC OFFSET ?", TraceTableName, strings.Join(conditions, " AND "))
+	args = append(args, query.Offset)
+	if query.Limit > 0 {
+		sql += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	rows := []struct {
+		TraceId   string
+		RootName  string
+		Type      string
+		StartTime time.Time
+		EndTime   time.Time
+		HasError  bool
+	}{}
+
+	err := s.conn.WithContext(ctx).Raw(sql, args...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*TraceSummary, 0, len(rows))
+	for _, row := range rows {
+		summaries = append(summaries, &TraceSummary{
+			TraceID:   row.TraceId,
+			StartTime: row.StartTime,
+			EndTime:   row.EndTime,
+			HasError:  row.HasError,
+			Duration:  row.EndTime.Sub(row.StartTime),
+			RootName:  row.RootName,
+			Type:      row.Type,
+		})
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,101 @@
+package localTraceExporter
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+type memoryStore struct {
+	mu        sync.RWMutex
+	retention Retention
+	traces    map[string][]*tracepb.Span
+	summaries map[string]*TraceSummary
+}
+
+var _ Store = &memoryStore{}
+
+// NewMemoryStore creates a store which keeps traces in memory, and so only for the lifetime of the process.
+func NewMemoryStore(retention Retention) Store {
+	return &memoryStore{
+		retention: retention,
+		traces:    map[string][]*tracepb.Span{},
+		summaries: map[string]*TraceSummary{},
+	}
+}
+
+func (s *memoryStore) AddSpans(ctx context.Context, spans []*tracepb.Span) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, span := range spans {
+		traceID := hex.EncodeToString(span.TraceId)
+		s.traces[traceID] = append(s.traces[traceID], span)
+		s.summaries[traceID] = summarise(s.summaries[traceID], span)
+	}
+
+	s.prune()
+
+	return nil
+}
+
+// prune removes the traces which are beyond the retention settings.
+func (s *memoryStore) prune() {
+	if s.retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-s.retention.MaxAge)
+		for traceID, summary := range s.summaries {
+			if summary.StartTime.Before(cutoff) {
+				s.remove(traceID)
+			}
+		}
+	}
+
+	if s.retention.MaxTraces > 0 && len(s.summaries) > s.retention.MaxTraces {
+		oldest := make([]*TraceSummary, 0, len(s.summaries))
+		for _, summary := range s.summaries {
+			oldest = append(oldest, summary)
+		}
+
+		sort.Slice(oldest, func(i, j int) bool {
+			return oldest[i].StartTime.Before(oldest[j].StartTime)
+		})
+
+		for _, summary := range oldest[:len(oldest)-s.retention.MaxTraces] {
+			s.remove(summary.TraceID)
+		}
+	}
+}
+
+func (s *memoryStore) remove(traceID string) {
+	delete(s.traces, traceID)
+	delete(s.summaries, traceID)
+}
+
+func (s *memoryStore) GetTrace(ctx context.Context, traceID string) ([]*tracepb.Span, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.traces[traceID], nil
+}
+
+func (s *memoryStore) ListTraces(ctx context.Context, query *TraceQuery) ([]*TraceSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := []*TraceSummary{}
+	for traceID, summary := range s.summaries {
+		if !query.matches(summary, s.traces[traceID]) {
+			continue
+		}
+
+		// Copy the summary as it continues to be updated as spans are added
+		summaryCopy := *summary
+		summaries = append(summaries, &summaryCopy)
+	}
+
+	return query.paginate(summaries), nil
+}
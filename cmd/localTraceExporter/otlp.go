@@ -0,0 +1,77 @@
+package localTraceExporter
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// TracesData wraps the spans of a trace as OTLP traces data.
+// The resource and scope of the spans are not kept by the stores.
+func TracesData(spans []*tracepb.Span) *tracepb.TracesData {
+	return &tracepb.TracesData{
+		ResourceSpans: []*tracepb.ResourceSpans{
+			{
+				ScopeSpans: []*tracepb.ScopeSpans{
+					{Spans: spans},
+				},
+			},
+		},
+	}
+}
+
+// MarshalOTLPJSON encodes the spans of a trace with the OTLP JSON encoding, which differs from the
+// standard protobuf JSON mapping in that enums are integers and trace and span ids are hex encoded.
+func MarshalOTLPJSON(spans []*tracepb.Span) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(TracesData(spans))
+	if err != nil {
+		return nil, err
+	}
+
+	var data any
+	err = json.Unmarshal(b, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hexEncodeIds(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(data)
+}
+
+// hexEncodeIds re-encodes the trace and span ids, which the protobuf JSON mapping encodes with base64, as hex.
+func hexEncodeIds(data any) error {
+	switch v := data.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if s, ok := value.(string); ok && (key == "traceId" || key == "spanId" || key == "parentSpanId") {
+				id, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return err
+				}
+				v[key] = hex.EncodeToString(id)
+				continue
+			}
+
+			err := hexEncodeIds(value)
+			if err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, value := range v {
+			err := hexEncodeIds(value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
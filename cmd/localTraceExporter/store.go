@@ -0,0 +1,225 @@
+package localTraceExporter
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Store keeps the spans exported locally so that traces can be listed and inspected.
+type Store interface {
+	// AddSpans stores the spans and applies the retention settings of the store.
+	AddSpans(ctx context.Context, spans []*tracepb.Span) error
+	// GetTrace returns the spans of the trace, or nil if the trace is not in the store.
+	GetTrace(ctx context.Context, traceID string) ([]*tracepb.Span, error)
+	// ListTraces returns the summaries of the traces matching the query, most recent first.
+	ListTraces(ctx context.Context, query *TraceQuery) ([]*TraceSummary, error)
+}
+
+// Retention bounds how many traces a store keeps.
+type Retention struct {
+	// The maximum number of traces kept, after which the oldest are removed. Zero is unbounded.
+	MaxTraces int
+	// How long traces are kept for. Zero keeps them indefinitely.
+	MaxAge time.Duration
+}
+
+var DefaultRetention = Retention{
+	MaxTraces: 1000,
+	MaxAge:    7 * 24 * time.Hour,
+}
+
+type TraceSummary struct {
+	TraceID   string
+	StartTime time.Time
+	EndTime   time.Time
+	HasError  bool
+	Duration  time.Duration
+	RootName  string
+	Type      string
+}
+
+// TraceQuery filters the traces listed from a store.
+type TraceQuery struct {
+	After  *time.Time
+	Before *time.Time
+
+	// If set, only traces with (or without) an error.
+	HasError *bool
+
+	MinDuration *time.Duration
+	MaxDuration *time.Duration
+
+	// Only traces with a span for each attribute where the attribute has the value.
+	Attributes map[string]string
+
+	// Only traces of requests to the runtime, omitting health checks,
+	// OpenAPI requests and CORS preflight requests.
+	RequestsOnly bool
+
+	// A limit of zero lists all the traces.
+	Limit  int
+	Offset int
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore(DefaultRetention)
+)
+
+// SetStore sets the store where exported spans are kept.
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func getStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// GetTrace returns the spans of the trace from the current store.
+func GetTrace(ctx context.Context, traceID string) ([]*tracepb.Span, error) {
+	return getStore().GetTrace(ctx, traceID)
+}
+
+// ListTraces lists the traces matching the query from the current store.
+func ListTraces(ctx context.Context, query *TraceQuery) ([]*TraceSummary, error) {
+	return getStore().ListTraces(ctx, query)
+}
+
+// summarise updates the summary of a trace with a span belonging to it,
+// creating the summary if there is not one yet.
+func summarise(summary *TraceSummary, span *tracepb.Span) *TraceSummary {
+	start := time.Unix(0, int64(span.StartTimeUnixNano))
+	end := time.Unix(0, int64(span.EndTimeUnixNano))
+
+	if summary == nil {
+		summary = &TraceSummary{
+			TraceID:   hex.EncodeToString(span.TraceId),
+			StartTime: start,
+			EndTime:   end,
+			Duration:  end.Sub(start),
+			HasError:  false,
+		}
+	} else {
+		if start.Before(summary.StartTime) {
+			summary.StartTime = start
+		}
+		if end.After(summary.EndTime) {
+			summary.EndTime = end
+		}
+
+		summary.Duration = summary.EndTime.Sub(summary.StartTime)
+	}
+
+	if len(span.ParentSpanId) == 0 {
+		summary.RootName = span.Name
+
+		for _, attr := range span.Attributes {
+			if attr.Key == "type" {
+				summary.Type = attr.Value.GetStringValue()
+			}
+		}
+	}
+
+	if span.Status.GetCode() == tracepb.Status_STATUS_CODE_ERROR {
+		summary.HasError = true
+	}
+
+	return summary
+}
+
+// isSystemRequest determines if the root span is one of the requests which are omitted
+// from the traces listed unless verbose tracing is on.
+func isSystemRequest(summary *TraceSummary) bool {
+	return summary.Type != "request" ||
+		summary.RootName == "GET /_health" ||
+		strings.HasSuffix(summary.RootName, "openapi.json") ||
+		strings.HasPrefix(summary.RootName, "OPTIONS")
+}
+
+// attributeValue formats an attribute value as a string so that it can be compared with a filter.
+func attributeValue(value *commonpb.AnyValue) (string, bool) {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue, true
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue), true
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10), true
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+// matches determines if a trace satisfies the query.
+func (q *TraceQuery) matches(summary *TraceSummary, spans []*tracepb.Span) bool {
+	if q.After != nil && summary.StartTime.Before(*q.After) {
+		return false
+	}
+	if q.Before != nil && summary.StartTime.After(*q.Before) {
+		return false
+	}
+	if q.HasError != nil && summary.HasError != *q.HasError {
+		return false
+	}
+	if q.MinDuration != nil && summary.Duration < *q.MinDuration {
+		return false
+	}
+	if q.MaxDuration != nil && summary.Duration > *q.MaxDuration {
+		return false
+	}
+	if q.RequestsOnly && isSystemRequest(summary) {
+		return false
+	}
+
+	for key, value := range q.Attributes {
+		found := false
+		for _, span := range spans {
+			for _, attr := range span.Attributes {
+				if v, ok := attributeValue(attr.Value); ok && attr.Key == key && v == value {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// paginate sorts the summaries with the most recent first and applies the limit and offset.
+func (q *TraceQuery) paginate(summaries []*TraceSummary) []*TraceSummary {
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].StartTime.After(summaries[j].StartTime)
+	})
+
+	if q.Offset > len(summaries) {
+		return []*TraceSummary{}
+	}
+	summaries = summaries[q.Offset:]
+
+	if q.Limit > 0 && q.Limit < len(summaries) {
+		summaries = summaries[:q.Limit]
+	}
+
+	return summaries
+}
@@ -0,0 +1,156 @@
+package localTraceExporter
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func newSpan(traceID byte, spanID byte, parentID byte, name string, start time.Time, duration time.Duration, attributes map[string]string) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId:           []byte{traceID, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		SpanId:            []byte{spanID, 0, 0, 0, 0, 0, 0, 1},
+		Name:              name,
+		StartTimeUnixNano: uint64(start.UnixNano()),
+		EndTimeUnixNano:   uint64(start.Add(duration).UnixNano()),
+		Status:            &tracepb.Status{},
+	}
+
+	if parentID != 0 {
+		span.ParentSpanId = []byte{parentID, 0, 0, 0, 0, 0, 0, 1}
+	}
+
+	for k, v := range attributes {
+		span.Attributes = append(span.Attributes, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}},
+		})
+	}
+
+	return span
+}
+
+func traceIDs(summaries []*TraceSummary) []string {
+	ids := []string{}
+	for _, s := range summaries {
+		ids = append(ids, s.TraceID[:2])
+	}
+	return ids
+}
+
+func TestMemoryStoreListTraces(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(DefaultRetention)
+	now := time.Now()
+
+	failed := newSpan(2, 1, 0, "POST /api/json/createPost", now.Add(-time.Minute), 500*time.Millisecond, map[string]string{"type": "request"})
+	failed.Status.Code = tracepb.Status_STATUS_CODE_ERROR
+
+	err := store.AddSpans(ctx, []*tracepb.Span{
+		newSpan(1, 1, 0, "GET /api/json/getPost", now.Add(-2*time.Minute), 20*time.Millisecond, map[string]string{"type": "request"}),
+		newSpan(1, 2, 1, "getPost", now.Add(-2*time.Minute), 10*time.Millisecond, map[string]string{"function": "getPost"}),
+		failed,
+		newSpan(3, 1, 0, "GET /_health", now, time.Millisecond, map[string]string{"type": "request"}),
+	})
+	require.NoError(t, err)
+
+	all, err := store.ListTraces(ctx, &TraceQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"03", "02", "01"}, traceIDs(all))
+	assert.Equal(t, "GET /api/json/getPost", all[2].RootName)
+	assert.Equal(t, 20*time.Millisecond, all[2].Duration)
+
+	requests, err := store.ListTraces(ctx, &TraceQuery{RequestsOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"02", "01"}, traceIDs(requests))
+
+	hasError := true
+	errored, err := store.ListTraces(ctx, &TraceQuery{HasError: &hasError})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"02"}, traceIDs(errored))
+
+	minDuration := 100 * time.Millisecond
+	slow, err := store.ListTraces(ctx, &TraceQuery{MinDuration: &minDuration})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"02"}, traceIDs(slow))
+
+	maxDuration := 100 * time.Millisecond
+	fast, err := store.ListTraces(ctx, &TraceQuery{MaxDuration: &maxDuration})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"03", "01"}, traceIDs(fast))
+
+	withAttribute, err := store.ListTraces(ctx, &TraceQuery{Attributes: map[string]string{"function": "getPost"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01"}, traceIDs(withAttribute))
+
+	paged, err := store.ListTraces(ctx, &TraceQuery{Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"02"}, traceIDs(paged))
+}
+
+func TestMemoryStoreRetention(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(Retention{MaxTraces: 2, MaxAge: time.Hour})
+	now := time.Now()
+
+	err := store.AddSpans(ctx, []*tracepb.Span{
+		newSpan(1, 1, 0, "expired", now.Add(-2*time.Hour), time.Millisecond, nil),
+		newSpan(2, 1, 0, "oldest", now.Add(-3*time.Minute), time.Millisecond, nil),
+		newSpan(3, 1, 0, "older", now.Add(-2*time.Minute), time.Millisecond, nil),
+		newSpan(4, 1, 0, "newest", now.Add(-1*time.Minute), time.Millisecond, nil),
+	})
+	require.NoError(t, err)
+
+	summaries, err := store.ListTraces(ctx, &TraceQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"04", "03"}, traceIDs(summaries))
+
+	spans, err := store.GetTrace(ctx, summaries[0].TraceID)
+	require.NoError(t, err)
+	assert.Len(t, spans, 1)
+
+	spans, err = store.GetTrace(ctx, "02000000000000000000000000000001")
+	require.NoError(t, err)
+	assert.Nil(t, spans)
+}
+
+func TestMarshalOTLPJSON(t *testing.T) {
+	span := newSpan(1, 2, 1, "getPost", time.Unix(1700000000, 0), time.Second, map[string]string{"function": "getPost"})
+	span.Status.Code = tracepb.Status_STATUS_CODE_ERROR
+
+	b, err := MarshalOTLPJSON([]*tracepb.Span{span})
+	require.NoError(t, err)
+
+	var data struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceId           string `json:"traceId"`
+					SpanId            string `json:"spanId"`
+					ParentSpanId      string `json:"parentSpanId"`
+					Name              string `json:"name"`
+					StartTimeUnixNano string `json:"startTimeUnixNano"`
+					Status            struct {
+						Code int `json:"code"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(b, &data))
+
+	exported := data.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, hex.EncodeToString(span.TraceId), exported.TraceId)
+	assert.Equal(t, hex.EncodeToString(span.SpanId), exported.SpanId)
+	assert.Equal(t, hex.EncodeToString(span.ParentSpanId), exported.ParentSpanId)
+	assert.Equal(t, "getPost", exported.Name)
+	assert.Equal(t, "1700000000000000000", exported.StartTimeUnixNano)
+	assert.Equal(t, 2, exported.Status.Code)
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/cmd/database"
+	"github.com/teamkeel/keel/cmd/program"
+	"github.com/teamkeel/keel/colors"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/migrations"
+	"github.com/teamkeel/keel/schema"
+)
+
+var flagDbConn string
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage versioned migration files for your Keel App",
+	Long: `The migrate command allows you to generate, review and apply
+versioned migration files for your Keel App's database. Migration
+files are written to the migrations directory of your project and
+applied migrations are tracked in the keel_migrations table.
+
+The database used is the one given by --db-conn, or the KEEL_DB_CONN
+environment variable, otherwise the local development database is used.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// list subcommands
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateGenerateCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateApplyCmd)
+	migrateCmd.AddCommand(migrateRollbackCmd)
+	migrateCmd.PersistentFlags().StringVar(&flagDbConn, "db-conn", os.Getenv("KEEL_DB_CONN"), "connection string of the database to migrate")
+}
+
+var migrateGenerateCmd = &cobra.Command{
+	Use:   "generate <name>",
+	Short: "Generate a migration file from your schema changes",
+	Long: `The generate command compares your schema with the existing migration
+files and writes the SQL needed to migrate a database from them, along with
+the SQL to roll it back, to a new timestamped migration file. The migration
+files are replayed on a scratch database on the local development database
+server, so the database given by --db-conn isn't used.
+
+Models and fields which appear to have been renamed are confirmed before
+being renamed, otherwise they are dropped and added again.
+
+Note that rolling back a migration which removes a model or field cannot
+restore the data that was removed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		builder := schema.Builder{}
+		protoSchema, err := builder.MakeFromDirectory(flagProjectDir)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		files, err := migrations.ReadMigrationFiles(migrationsDir())
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		scratchConnInfo, dropScratch, err := database.StartScratch(flagProjectDir)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		scratch, err := db.New(context.Background(), scratchConnInfo.String())
+		if err != nil {
+			return program.RenderError(err)
+		}
+		defer func() {
+			_ = scratch.Close()
+			_ = dropScratch()
+		}()
+
+		file, changes, err := migrations.Generate(context.Background(), protoSchema, scratch, args[0], files, nil)
+
+		// Inferred renames are only applied once confirmed, otherwise they're a drop and an add
+		var renamesErr *migrations.UnconfirmedRenamesError
+		if errors.As(err, &renamesErr) {
+			renames, confirmErr := confirmRenames(renamesErr.Renames)
+			if confirmErr != nil {
+				return program.RenderError(confirmErr)
+			}
+
+			file, changes, err = migrations.Generate(context.Background(), protoSchema, scratch, args[0], files, renames)
+		}
+
+		if errors.Is(err, migrations.ErrNoChanges) {
+			program.RenderSuccess("No schema changes to migrate")
+			return nil
+		}
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		paths, err := migrations.WriteMigrationFile(migrationsDir(), file)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		program.RenderSuccess(fmt.Sprintf("Generated migration %s", file.ID()))
+		for _, ch := range changes {
+			fmt.Println(" -", renderChange(ch))
+		}
+		fmt.Println("")
+		for _, path := range paths {
+			fmt.Println(colors.Gray(path).String())
+		}
+
+		return nil
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which migration files have been applied",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, files, err := migrateSetup()
+		if err != nil {
+			return program.RenderError(err)
+		}
+		defer database.Close()
+
+		statuses, err := migrations.Status(context.Background(), database, files)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		if len(statuses) == 0 {
+			fmt.Println("No migrations found")
+			return nil
+		}
+
+		for _, s := range statuses {
+			id := fmt.Sprintf("%s_%s", s.Version, s.Name)
+			switch {
+			case s.Missing:
+				fmt.Println(colors.Red("missing").String(), " ", id, colors.Gray("(applied but no file found)").String())
+			case s.AppliedAt != nil:
+				fmt.Println(colors.Green("applied").String(), " ", id, colors.Gray(s.AppliedAt.Format("2006-01-02 15:04:05")).String())
+			default:
+				fmt.Println(colors.Yellow("pending").String(), " ", id)
+			}
+		}
+
+		return nil
+	},
+}
+
+var migrateApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the pending migration files in order",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, files, err := migrateSetup()
+		if err != nil {
+			return program.RenderError(err)
+		}
+		defer database.Close()
+
+		applied, err := migrations.ApplyFiles(context.Background(), database, files)
+		for _, file := range applied {
+			fmt.Println(" -", colors.Green("applied").String(), file.ID())
+		}
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		if len(applied) == 0 {
+			program.RenderSuccess("No pending migrations")
+			return nil
+		}
+
+		program.RenderSuccess(fmt.Sprintf("Applied %d migration(s)", len(applied)))
+
+		return nil
+	},
+}
+
+var migrateRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back the most recently applied migration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, files, err := migrateSetup()
+		if err != nil {
+			return program.RenderError(err)
+		}
+		defer database.Close()
+
+		file, err := migrations.Rollback(context.Background(), database, files)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		program.RenderSuccess(fmt.Sprintf("Rolled back migration %s", file.ID()))
+
+		return nil
+	},
+}
+
+func migrationsDir() string {
+	return filepath.Join(flagProjectDir, migrations.MigrationsDir)
+}
+
+// migrateSetup connects to the database and reads the project's migration files.
+func migrateSetup() (db.Database, []*migrations.MigrationFile, error) {
+	files, err := migrations.ReadMigrationFiles(migrationsDir())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := connectDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, files, nil
+}
+
+// connectDatabase connects to the database given by --db-conn, otherwise the local development database.
+func connectDatabase() (db.Database, error) {
+	connString := flagDbConn
+	if connString == "" {
+		connInfo, err := database.Start(false, flagProjectDir)
+		if err != nil {
+			return nil, err
+		}
+		connString = connInfo.String()
+	}
+
+	return db.New(context.Background(), connString)
+}
+
+// confirmRenames asks whether each of the inferred renames should be applied, or instead treated as a drop and an add.
+func confirmRenames(inferred []*migrations.DatabaseChange) (*migrations.Renames, error) {
+	renames := &migrations.Renames{}
+
+	for _, r := range inferred {
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Was %s renamed? Otherwise it is dropped, losing its data, and added again", renderChange(r)),
+			IsConfirm: true,
+		}
+
+		_, err := prompt.Run()
+		confirmed := !errors.Is(err, promptui.ErrAbort)
+		if err != nil && confirmed {
+			return nil, err
+		}
+
+		if confirmed {
+			renames.Confirmed = append(renames.Confirmed, r)
+		} else {
+			renames.Rejected = append(renames.Rejected, r)
+		}
+	}
+
+	return renames, nil
+}
+
+func renderChange(ch *migrations.DatabaseChange) string {
+	s := ""
+	switch ch.Type {
+	case migrations.ChangeTypeAdded:
+		s = colors.Green(ch.Type).String()
+	case migrations.ChangeTypeRemoved:
+		s = colors.Red(ch.Type).String()
+	case migrations.ChangeTypeModified:
+		s = colors.Black(ch.Type).String()
+	case migrations.ChangeTypeRenamed:
+		s = colors.Yellow(ch.Type).String()
+	}
+
+	s += " " + ch.Model
+	if ch.Field != "" {
+		s += "." + ch.Field
+	}
+	if ch.PreviousName != "" {
+		s += fmt.Sprintf(" (from %s)", ch.PreviousName)
+	}
+
+	return s
+}
@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
@@ -30,6 +28,8 @@ import (
 	"github.com/teamkeel/keel/migrations"
 	"github.com/teamkeel/keel/node"
 	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/scheduler"
 	"github.com/teamkeel/keel/schema"
 	"github.com/teamkeel/keel/schema/reader"
 	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
@@ -210,8 +210,8 @@ func CheckDependencies() tea.Cmd {
 }
 
 type ParsePrivateKeyMsg struct {
-	PrivateKey *rsa.PrivateKey
-	Err        error
+	PrivateKeys []*rsa.PrivateKey
+	Err         error
 }
 
 func ParsePrivateKey(path string) tea.Cmd {
@@ -237,14 +237,7 @@ func ParsePrivateKey(path string) tea.Cmd {
 			privateKeyPem = customPem
 		}
 
-		privateKeyBlock, _ := pem.Decode(privateKeyPem)
-		if privateKeyBlock == nil {
-			return ParsePrivateKeyMsg{
-				Err: errors.New("private key PEM either invalid or empty"),
-			}
-		}
-
-		privateKey, err := x509.ParsePKCS1PrivateKey(privateKeyBlock.Bytes)
+		privateKeys, err := oauth.ParsePrivateKeys(privateKeyPem)
 		if err != nil {
 			return ParsePrivateKeyMsg{
 				Err: err,
@@ -252,7 +245,7 @@ func ParsePrivateKey(path string) tea.Cmd {
 		}
 
 		return ParsePrivateKeyMsg{
-			PrivateKey: privateKey,
+			PrivateKeys: privateKeys,
 		}
 	}
 }
@@ -357,9 +350,9 @@ func (e *ApplyMigrationsError) Unwrap() error {
 	return e.Err
 }
 
-func RunMigrations(schema *proto.Schema, database db.Database) tea.Cmd {
+func RunMigrations(schema *proto.Schema, database db.Database, renames *migrations.Renames) tea.Cmd {
 	return func() tea.Msg {
-		m, err := migrations.New(context.Background(), schema, database)
+		m, err := migrations.NewWithRenames(context.Background(), schema, database, renames)
 		if err != nil {
 			return RunMigrationsMsg{
 				Err: &ApplyMigrationsError{
@@ -560,6 +553,59 @@ func (f *FunctionsOutputWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+const (
+	// How often failed event deliveries are retried
+	eventDeliveryInterval = 5 * time.Second
+	// The most event deliveries which are attempted at a time
+	eventDeliveryBatchSize = 50
+)
+
+type DeliverEventsMsg struct{}
+
+// DeliverEvents schedules the delivery of pending events, such as those which have previously failed
+func DeliverEvents() tea.Cmd {
+	return tea.Tick(eventDeliveryInterval, func(time.Time) tea.Msg {
+		return DeliverEventsMsg{}
+	})
+}
+
+// How often scheduled jobs are checked to see if they are due
+const scheduledJobsInterval = 15 * time.Second
+
+type RunScheduledJobsMsg struct{}
+
+// RunScheduledJobs schedules a check for jobs whose schedule is due
+func RunScheduledJobs() tea.Cmd {
+	return tea.Tick(scheduledJobsInterval, func(time.Time) tea.Msg {
+		return RunScheduledJobsMsg{}
+	})
+}
+
+type ScheduledJobsRunMsg struct {
+	Err  error
+	Runs []*scheduler.Run
+}
+
+// RunDueJobs runs the scheduled jobs which are due. The jobs can take a while, so are run outside of the update loop.
+func RunDueJobs(ctx context.Context, schema *proto.Schema, runner scheduler.JobRunner, envVars map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		for k, v := range envVars {
+			os.Setenv(k, v)
+		}
+		defer func() {
+			for k := range envVars {
+				os.Unsetenv(k)
+			}
+		}()
+
+		runs, err := scheduler.RunDue(ctx, schema, runner)
+		return ScheduledJobsRunMsg{
+			Err:  err,
+			Runs: runs,
+		}
+	}
+}
+
 type WatcherMsg struct {
 	Err   error
 	Path  string
@@ -3,6 +3,7 @@ package program
 import (
 	"context"
 	"crypto/rsa"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -21,6 +22,7 @@ import (
 	"github.com/teamkeel/keel/codegen"
 	"github.com/teamkeel/keel/config"
 	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/encryption"
 	"github.com/teamkeel/keel/events"
 	"github.com/teamkeel/keel/functions"
 	"github.com/teamkeel/keel/mail"
@@ -31,6 +33,7 @@ import (
 	rpcApi "github.com/teamkeel/keel/rpc/rpcApi"
 	"github.com/teamkeel/keel/runtime"
 	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/scheduler"
 	"github.com/teamkeel/keel/schema/reader"
 	"github.com/teamkeel/keel/storage"
 	"github.com/twitchtv/twirp"
@@ -144,11 +147,12 @@ type Model struct {
 	PackageManager string
 
 	// If set then runtime will be configured with private key
-	// located at this path in pem format.
+	// located at this path in pem format. The file may contain
+	// several keys, in which case the first signs tokens.
 	PrivateKeyPath string
 
-	// The private key to configure on runtime, or nil.
-	PrivateKey *rsa.PrivateKey
+	// The private keys to configure on runtime.
+	PrivateKeys []*rsa.PrivateKey
 
 	// Pattern to pass to vitest to isolate specific tests
 	TestPattern string
@@ -161,6 +165,9 @@ type Model struct {
 	// This will then show all system events in the local console.
 	VerboseTracing bool
 
+	// How many traces, and for how long, are kept in the local trace store. Only applies to ModeRun.
+	TraceRetention localTraceExporter.Retention
+
 	// A custom configured hostname, which may be necessary to change for SSO callback.
 	CustomHostname string
 
@@ -174,6 +181,8 @@ type Model struct {
 	DatabaseConnInfo  *db.ConnectionInfo
 	GeneratedFiles    codegen.GeneratedFiles
 	MigrationChanges  []*migrations.DatabaseChange
+	MigrationRenames  *migrations.Renames
+	PendingRenames    []*migrations.DatabaseChange
 	FunctionsServer   *node.DevelopmentServer
 	RuntimeHandler    http.Handler
 	JobHandler        runtime.JobHandler
@@ -182,6 +191,9 @@ type Model struct {
 	RuntimeRequests   []*RuntimeRequest
 	FunctionsLog      []*FunctionLog
 	Storage           storage.Storer
+	MailClient        mail.EmailClient
+	MailTemplates     *mail.Templates
+	Keyring           *encryption.Keyring
 	TestOutput        string
 	Secrets           map[string]string
 	Environment       string
@@ -224,11 +236,15 @@ func (m *Model) Init() tea.Cmd {
 	m.RpcPort = "34087"
 	m.TracePort = "4318"
 
+	m.MigrationRenames = &migrations.Renames{}
+
 	m.Status = StatusCheckingDependencies
 
 	cmds := []tea.Cmd{
 		FetchLatestVersion(),
 		CheckDependencies(),
+		DeliverEvents(),
+		RunScheduledJobs(),
 	}
 
 	return tea.Batch(cmds...)
@@ -241,6 +257,21 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c", "q":
 			m.Status = StatusQuitting
 			return m, tea.Quit
+		case "y", "n":
+			// Inferred renames are confirmed or rejected before the migrations are run again
+			if m.Status != StatusRunMigrations || len(m.PendingRenames) == 0 {
+				return m, nil
+			}
+
+			if msg.String() == "y" {
+				m.MigrationRenames.Confirmed = append(m.MigrationRenames.Confirmed, m.PendingRenames...)
+			} else {
+				m.MigrationRenames.Rejected = append(m.MigrationRenames.Rejected, m.PendingRenames...)
+			}
+
+			m.PendingRenames = nil
+			m.Err = nil
+			return m, RunMigrations(m.Schema, m.Database, m.MigrationRenames)
 		}
 	case tea.WindowSizeMsg:
 		// This msg is sent once on program start
@@ -280,6 +311,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.Database = database
+
+		// Keep traces in the database so that they survive restarts
+		if m.Mode == ModeRun && !m.CustomTracing {
+			store, err := localTraceExporter.NewDatabaseStore(context.Background(), database, m.TraceRetention)
+			if err != nil {
+				m.Err = err
+				return m, tea.Quit
+			}
+			localTraceExporter.SetStore(store)
+		}
+
 		m.Status = StatusParsePrivateKey
 		return m, ParsePrivateKey(m.PrivateKeyPath)
 	case ParsePrivateKeyMsg:
@@ -290,7 +332,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
-		m.PrivateKey = msg.PrivateKey
+		m.PrivateKeys = msg.PrivateKeys
 
 		m.Status = StatusSetupFunctions
 		return m, SetupFunctions(m.ProjectDir, m.NodePackagesPath, m.PackageManager)
@@ -355,19 +397,51 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.RuntimeHandler = cors.Handler(runtime.NewHttpHandler(m.Schema))
 		m.Status = StatusRunMigrations
-		return m, RunMigrations(m.Schema, m.Database)
+		return m, RunMigrations(m.Schema, m.Database, m.MigrationRenames)
 	case RunMigrationsMsg:
 		m.Err = msg.Err
 		m.MigrationChanges = msg.Changes
 
-		// we now set the file Storage using a dbstore
-		storer, err := storage.NewDbStore(context.Background(), m.Database)
+		renamesErr := &migrations.UnconfirmedRenamesError{}
+		if errors.As(m.Err, &renamesErr) {
+			m.PendingRenames = renamesErr.Renames
+			return m, nil
+		}
+
+		// we now set the file Storage, which is the database unless a bucket has been configured
+		storer, err := storage.NewStorer(context.Background(), m.Config, m.Secrets, m.Database)
 		if err != nil {
 			m.Err = err
 			return m, tea.Quit
 		}
 		m.Storage = storer
 
+		// the email client and templates are set from the email config
+		mailClient, err := mail.NewClient(m.Config, m.Secrets, m.ProjectDir)
+		if err != nil {
+			m.Err = err
+			return m, tea.Quit
+		}
+		m.MailClient = mailClient
+
+		mailTemplates, err := mail.LoadTemplates(m.Config.Email.TemplatesDir(m.ProjectDir))
+		if err != nil {
+			m.Err = err
+			return m, tea.Quit
+		}
+		m.MailTemplates = mailTemplates
+
+		// Secret fields are encrypted with the development key unless the project sets one
+		keyring, err := encryption.LoadKeyring(m.Secrets)
+		if err != nil {
+			m.Err = err
+			return m, tea.Quit
+		}
+		if keyring == nil {
+			keyring = encryption.DevelopmentKeyring()
+		}
+		m.Keyring = keyring
+
 		if m.Err != nil {
 			return m, nil
 		}
@@ -475,59 +549,90 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			attribute.String("http.path", request.Path),
 		)
 
-		if m.PrivateKey != nil {
-			ctx = runtimectx.WithPrivateKey(ctx, m.PrivateKey)
+		ctx = m.runtimeContext(ctx)
+
+		envVars := m.Config.GetEnvVars()
+		for k, v := range envVars {
+			os.Setenv(k, v)
 		}
 
-		ctx = db.WithDatabase(ctx, m.Database)
-		ctx = runtimectx.WithSecrets(ctx, m.Secrets)
-		ctx = runtimectx.WithOAuthConfig(ctx, &m.Config.Auth)
-		if m.Storage != nil {
-			ctx = runtimectx.WithStorage(ctx, m.Storage)
+		// Events are delivered straight after the request, and any failed deliveries are retried by DeliverEventsMsg
+		ctx, err := events.WithEventHandler(ctx, m.subscriberHandler())
+		if err != nil {
+			m.Err = err
+			return m, tea.Quit
 		}
 
-		mailClient := mail.NewSMTPClientFromEnv()
-		if mailClient != nil {
-			ctx = runtimectx.WithMailClient(ctx, mailClient)
-		} else {
-			ctx = runtimectx.WithMailClient(ctx, mail.NoOpClient())
+		r = msg.r.WithContext(ctx)
+		m.RuntimeHandler.ServeHTTP(msg.w, r)
+
+		for k := range envVars {
+			os.Unsetenv(k)
 		}
 
-		if m.FunctionsServer != nil {
-			ctx = functions.WithFunctionsTransport(
-				ctx,
-				functions.NewHttpTransport(m.FunctionsServer.URL),
-			)
+		msg.done <- true
+		return m, tea.Batch(cmds...)
+	case DeliverEventsMsg:
+		if m.Status != StatusRunning || m.Database == nil || m.Schema == nil || len(m.Schema.Events) == 0 {
+			return m, DeliverEvents()
 		}
 
+		ctx := m.runtimeContext(context.Background())
+
 		envVars := m.Config.GetEnvVars()
 		for k, v := range envVars {
 			os.Setenv(k, v)
 		}
 
-		// Synchronous event handling for keel run.
-		// TODO: make asynchronous
-		ctx, err := events.WithEventHandler(ctx, func(ctx context.Context, subscriber string, event *events.Event, traceparent string) error {
-			return runtime.NewSubscriberHandler(m.Schema).RunSubscriber(ctx, subscriber, event)
-		})
+		_, err := events.DeliverPending(ctx, m.subscriberHandler(), eventDeliveryBatchSize)
 		if err != nil {
 			m.Err = err
-			return m, tea.Quit
 		}
 
-		r = msg.r.WithContext(ctx)
-		m.RuntimeHandler.ServeHTTP(msg.w, r)
-
 		for k := range envVars {
 			os.Unsetenv(k)
 		}
 
-		msg.done <- true
+		return m, DeliverEvents()
+	case RunScheduledJobsMsg:
+		if m.Status != StatusRunning || m.Database == nil || m.Schema == nil || !scheduler.HasScheduledJobs(m.Schema) {
+			return m, RunScheduledJobs()
+		}
+
+		ctx := m.runtimeContext(context.Background())
+
+		// Events emitted by the jobs are delivered straight away, as they are for requests
+		ctx, err := events.WithEventHandler(ctx, m.subscriberHandler())
+		if err != nil {
+			m.Err = err
+			return m, tea.Quit
+		}
+
+		// Failed jobs are recorded against their runs
+		return m, RunDueJobs(ctx, m.Schema, func(ctx context.Context, job string) error {
+			return runtime.NewJobHandler(m.Schema).RunJob(ctx, job, map[string]any{}, functions.ScheduledTrigger)
+		}, m.Config.GetEnvVars())
+	case ScheduledJobsRunMsg:
+		if msg.Err != nil {
+			m.Err = msg.Err
+		}
+
+		cmds := []tea.Cmd{
+			RunScheduledJobs(),
+		}
+
+		if m.Mode == ModeRun {
+			for _, run := range msg.Runs {
+				cmds = append(cmds, tea.Println(renderScheduledJobLog(run)))
+			}
+		}
+
 		return m, tea.Batch(cmds...)
 	case RpcRequestMsg:
 		ctx := msg.r.Context()
 		ctx = db.WithDatabase(ctx, m.Database)
 		ctx = rpcApi.WithSchema(ctx, m.Schema)
+		ctx = rpcApi.WithTraceVerbosity(ctx, m.VerboseTracing)
 		r := msg.r.WithContext(ctx)
 		w := msg.w
 
@@ -576,6 +681,48 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// runtimeContext adds the services needed by the runtime to the context
+func (m *Model) runtimeContext(ctx context.Context) context.Context {
+	if len(m.PrivateKeys) > 0 {
+		ctx = runtimectx.WithPrivateKeys(ctx, m.PrivateKeys)
+	}
+
+	ctx = db.WithDatabase(ctx, m.Database)
+	ctx = runtimectx.WithSecrets(ctx, m.Secrets)
+	ctx = encryption.WithKeyring(ctx, m.Keyring)
+	ctx = runtimectx.WithOAuthConfig(ctx, &m.Config.Auth)
+	ctx = events.WithConfig(ctx, &m.Config.Events)
+	ctx = runtimectx.WithActionsConfig(ctx, &m.Config.Actions)
+	if m.Storage != nil {
+		ctx = runtimectx.WithStorage(ctx, m.Storage)
+	}
+
+	if m.MailClient != nil {
+		ctx = runtimectx.WithMailClient(ctx, m.MailClient)
+	} else {
+		ctx = runtimectx.WithMailClient(ctx, mail.NoOpClient())
+	}
+	if m.MailTemplates != nil {
+		ctx = runtimectx.WithMailTemplates(ctx, m.MailTemplates)
+	}
+
+	if m.FunctionsServer != nil {
+		ctx = functions.WithFunctionsTransport(
+			ctx,
+			functions.NewHttpTransport(m.FunctionsServer.URL),
+		)
+	}
+
+	return ctx
+}
+
+// subscriberHandler runs subscribers synchronously in this process
+func (m *Model) subscriberHandler() events.EventHandler {
+	return func(ctx context.Context, subscriber string, event *events.Event, traceparent string) error {
+		return runtime.NewSubscriberHandler(m.Schema).RunSubscriber(ctx, subscriber, event)
+	}
+}
+
 func (m *Model) View() string {
 	b := strings.Builder{}
 
@@ -15,6 +15,7 @@ import (
 	"github.com/teamkeel/keel/migrations"
 	"github.com/teamkeel/keel/node"
 	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/scheduler"
 	"github.com/teamkeel/keel/schema"
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
 )
@@ -102,12 +103,17 @@ func renderRun(m *Model) string {
 				b.WriteString(colors.Red(ch.Type).String())
 			case migrations.ChangeTypeModified:
 				b.WriteString(colors.Black(ch.Type).String())
+			case migrations.ChangeTypeRenamed:
+				b.WriteString(colors.Yellow(ch.Type).String())
 			}
 			b.WriteString(" ")
 			b.WriteString(ch.Model)
 			if ch.Field != "" {
 				b.WriteString(fmt.Sprintf(".%s", ch.Field))
 			}
+			if ch.PreviousName != "" {
+				b.WriteString(fmt.Sprintf(" (from %s)", ch.PreviousName))
+			}
 			b.WriteString("\n")
 		}
 	}
@@ -214,10 +220,37 @@ func renderError(m *Model) string {
 		}
 
 	case StatusRunMigrations:
-		b.WriteString("❌ There was an error updating your database schema:\n\n")
-
 		dbErr := &db.DbError{}
-		if errors.As(m.Err, &dbErr) {
+		typeChangeErr := &migrations.UnsafeTypeChangeError{}
+		renamesErr := &migrations.UnconfirmedRenamesError{}
+		if !errors.As(m.Err, &renamesErr) {
+			b.WriteString("❌ There was an error updating your database schema:\n\n")
+		}
+
+		if errors.As(m.Err, &renamesErr) {
+			b.WriteString("❓ These renames were inferred from your schema changes:\n\n")
+			for _, r := range renamesErr.Renames {
+				b.WriteString(" - ")
+				b.WriteString(colors.Yellow(r.Model).String())
+				if r.Field != "" {
+					b.WriteString(colors.Yellow(fmt.Sprintf(".%s", r.Field)).String())
+				}
+				b.WriteString(fmt.Sprintf(" (from %s)", r.PreviousName))
+				b.WriteString("\n")
+			}
+			b.WriteString("\nPress ")
+			b.WriteString(colors.Cyan("y").String())
+			b.WriteString(" to rename them and keep their data, or ")
+			b.WriteString(colors.Cyan("n").String())
+			b.WriteString(" to drop them and add them again, losing their data.\n")
+		} else if errors.As(m.Err, &typeChangeErr) {
+			b.WriteString("The type of these fields cannot be changed as their existing values may not convert safely. Instead add a new field and copy the values across:\n\n")
+			for _, c := range typeChangeErr.Changes {
+				b.WriteString(" - ")
+				b.WriteString(colors.Red(c.String()).String())
+				b.WriteString("\n")
+			}
+		} else if errors.As(m.Err, &dbErr) {
 			b.WriteString(colors.Red("Error: ").String())
 			b.WriteString(colors.Red(dbErr.Message).String())
 			b.WriteString(colors.Red(fmt.Sprintf(" (SQLSTATE Code: %s)", dbErr.PgErrCode)).String())
@@ -290,6 +323,23 @@ func renderRequestLog(request *RuntimeRequest) string {
 	return b.String()
 }
 
+func renderScheduledJobLog(run *scheduler.Run) string {
+	b := strings.Builder{}
+
+	b.WriteString(colors.Cyan("[Job]").String())
+	b.WriteString(" ")
+	b.WriteString(colors.White(run.Job).String())
+	b.WriteString(" ")
+
+	if run.Status == scheduler.RunFailed {
+		b.WriteString(colors.Red(run.Error).String())
+	} else {
+		b.WriteString(run.Status)
+	}
+
+	return b.String()
+}
+
 func RenderSecrets(secrets map[string]string) string {
 	var rows []table.Row
 	var keys []string
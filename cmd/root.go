@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
@@ -15,20 +16,22 @@ import (
 var enabledDebugFlags = "true"
 
 var (
-	flagProjectDir       string
-	flagReset            bool
-	flagPort             string
-	flagNodePackagesPath string
-	flagPrivateKeyPath   string
-	flagPattern          string
-	flagTracing          bool
-	flagVersion          bool
-	flagVerboseTracing   bool
-	flagEnvironment      string
-	flagHostname         string
-	flagJsonOutput       bool
-	flagSchema           string
-	flagConfig           string
+	flagProjectDir          string
+	flagReset               bool
+	flagPort                string
+	flagNodePackagesPath    string
+	flagPrivateKeyPath      string
+	flagPattern             string
+	flagTracing             bool
+	flagVersion             bool
+	flagVerboseTracing      bool
+	flagTraceRetentionCount int
+	flagTraceRetentionAge   time.Duration
+	flagEnvironment         string
+	flagHostname            string
+	flagJsonOutput          bool
+	flagSchema              string
+	flagConfig              string
 )
 
 var rootCmd = &cobra.Command{
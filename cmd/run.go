@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/cmd/localTraceExporter"
 	"github.com/teamkeel/keel/cmd/program"
 )
 
@@ -29,6 +30,11 @@ var runCmd = &cobra.Command{
 			NodePackagesPath: flagNodePackagesPath,
 			PackageManager:   packageManager,
 			PrivateKeyPath:   flagPrivateKeyPath,
+			VerboseTracing:   flagVerboseTracing,
+			TraceRetention: localTraceExporter.Retention{
+				MaxTraces: flagTraceRetentionCount,
+				MaxAge:    flagTraceRetentionAge,
+			},
 		})
 	},
 }
@@ -40,6 +46,8 @@ func init() {
 	runCmd.Flags().StringVar(&flagHostname, "hostname", "", "custom hostname to handle HTTP requests")
 	runCmd.Flags().StringVar(&flagPort, "port", "8000", "the local port to handle Keel HTTP requests")
 	runCmd.Flags().StringVar(&flagPrivateKeyPath, "private-key-path", "", "path to the private key .pem file")
+	runCmd.Flags().IntVar(&flagTraceRetentionCount, "trace-retention-count", localTraceExporter.DefaultRetention.MaxTraces, "the number of traces kept for the console, or 0 for no limit")
+	runCmd.Flags().DurationVar(&flagTraceRetentionAge, "trace-retention-age", localTraceExporter.DefaultRetention.MaxAge, "how long traces are kept for the console, or 0 to keep them indefinitely")
 
 	if enabledDebugFlags == "true" {
 		runCmd.Flags().StringVar(&flagNodePackagesPath, "node-packages-path", "", "path to local @teamkeel npm packages")
@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/teamkeel/keel/cmd/program"
+	"github.com/teamkeel/keel/encryption"
+	"github.com/teamkeel/keel/schema"
 )
 
 // secretsCmd represents the secrets command
@@ -27,6 +31,9 @@ func init() {
 	secretsCmd.AddCommand(secretsListCmd)
 	secretsCmd.AddCommand(secretsSetCmd)
 	secretsCmd.AddCommand(secretsRemoveCmd)
+	secretsCmd.AddCommand(secretsGenerateKeyCmd)
+	secretsCmd.AddCommand(secretsRotateCmd)
+	secretsRotateCmd.Flags().StringVar(&flagDbConn, "db-conn", os.Getenv("KEEL_DB_CONN"), "connection string of the database to rotate")
 	secretsCmd.PersistentFlags().StringVarP(&flagEnvironment, "env", "e", "development", "environment")
 }
 
@@ -91,3 +98,72 @@ var secretsRemoveCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var secretsGenerateKeyCmd = &cobra.Command{
+	Use:   "generate-key",
+	Short: "Generate a key for encrypting Secret fields",
+	Long: fmt.Sprintf(`The generate-key command prints a new random key which can be used to
+encrypt the values of Secret fields, by setting it as the %s secret.`, encryption.SecretKey),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := encryption.GenerateKey()
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		fmt.Println(key)
+
+		return nil
+	},
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Re-encrypt Secret fields with the current encryption key",
+	Long: fmt.Sprintf(`The rotate command re-encrypts the values of all Secret fields which are
+not encrypted with the current encryption key, including values written
+before encryption was enabled.
+
+To rotate keys, set the new key as the %s secret and add the old key
+to the comma separated %s secret, then run this command. Once
+it has completed the old key can be removed.
+
+The database used is the one given by --db-conn, or the KEEL_DB_CONN
+environment variable, otherwise the local development database is used.`, encryption.SecretKey, encryption.SecretPreviousKeys),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		builder := schema.Builder{}
+		protoSchema, err := builder.MakeFromDirectory(flagProjectDir)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		secrets, err := program.LoadSecrets(flagProjectDir, flagEnvironment)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		keyring, err := encryption.LoadKeyring(secrets)
+		if err != nil {
+			return program.RenderError(err)
+		}
+		if keyring == nil {
+			return program.RenderError(fmt.Errorf("no encryption key found, set one with the %s secret", encryption.SecretKey))
+		}
+
+		database, err := connectDatabase()
+		if err != nil {
+			return program.RenderError(err)
+		}
+		defer database.Close()
+
+		rotated, err := encryption.Rotate(context.Background(), database, protoSchema, keyring)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		program.RenderSuccess(fmt.Sprintf("Re-encrypted %d value(s) with key %s", rotated, keyring.CurrentKey().Id))
+
+		return nil
+	},
+}
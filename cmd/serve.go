@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/cmd/program"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var (
+	flagServePort       string
+	flagServeDbConn     string
+	flagFunctionsUrl    string
+	flagShutdownTimeout time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve your Keel App in production",
+	Long: `The serve command runs the Keel runtime for your project without the
+development tooling of the run command. It does not start a database, watch
+files or run migrations, which should be applied beforehand with
+'keel migrate apply'.
+
+Settings which are not given as flags are read from the environment:
+
+  PORT                    the port to serve requests on (default 8000)
+  KEEL_DB_CONN            the connection string of the database
+  KEEL_PRIVATE_KEY_PATH   path to the private key .pem file used to sign tokens, which
+                          may contain previous keys after the first during a rotation
+  KEEL_PRIVATE_KEY        the private key in pem format, if no path is given
+  KEEL_FUNCTIONS_URL      the url of the functions server, if the project has functions
+  KEEL_API_URL            the public url of the server
+  KEEL_SECRET_<NAME>      the value of each secret in the project config
+
+Traces are exported with OTLP over HTTP when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+
+The server responds on /healthz while it is running, and on /readyz when it
+can handle requests. On SIGINT or SIGTERM it stops accepting requests and
+waits for those in flight to complete.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		builder := schema.Builder{}
+		protoSchema, err := builder.MakeFromDirectory(flagProjectDir)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		shutdownTracing, err := serveTracing(ctx)
+		if err != nil {
+			return program.RenderError(err)
+		}
+		defer func() {
+			_ = shutdownTracing(context.Background())
+		}()
+
+		srv, err := server.New(ctx, protoSchema, builder.Config, server.Options{
+			Port:            flagServePort,
+			DbConn:          flagServeDbConn,
+			PrivateKeyPath:  flagPrivateKeyPath,
+			FunctionsUrl:    flagFunctionsUrl,
+			ApiUrl:          flagHostname,
+			ShutdownTimeout: flagShutdownTimeout,
+			ProjectDir:      flagProjectDir,
+		})
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		return srv.Run(ctx)
+	},
+}
+
+// serveTracing sets up the trace provider, which is needed for events to be sent even when traces are not exported.
+func serveTracing(ctx context.Context) (func(context.Context) error, error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(
+			resource.NewSchemaless(attribute.String("service.name", "runtime")),
+		),
+	}
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&flagServePort, "port", "", "the port to handle Keel HTTP requests, otherwise PORT")
+	serveCmd.Flags().StringVar(&flagServeDbConn, "db-conn", "", "connection string of the database, otherwise KEEL_DB_CONN")
+	serveCmd.Flags().StringVar(&flagPrivateKeyPath, "private-key-path", "", "path to the private key .pem file, otherwise KEEL_PRIVATE_KEY_PATH")
+	serveCmd.Flags().StringVar(&flagFunctionsUrl, "functions-url", "", "url of the functions server, otherwise KEEL_FUNCTIONS_URL")
+	serveCmd.Flags().StringVar(&flagHostname, "hostname", "", "the public url of the server, otherwise KEEL_API_URL")
+	serveCmd.Flags().DurationVar(&flagShutdownTimeout, "shutdown-timeout", 0, "how long to wait for requests to complete when shutting down, otherwise KEEL_SHUTDOWN_TIMEOUT or 30s")
+}
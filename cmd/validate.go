@@ -9,6 +9,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/teamkeel/keel/colors"
 	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/migrations"
+	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/schema"
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
 )
@@ -16,6 +18,7 @@ import (
 type JsonResponse struct {
 	ValidationErrors errorhandling.ValidationErrors `json:"validationErrors"`
 	ConfigErrors     config.ConfigErrors            `json:"configErrors"`
+	TypeChangeErrors []string                       `json:"typeChangeErrors,omitempty"`
 }
 
 var validateCmd = &cobra.Command{
@@ -41,7 +44,11 @@ var validateCmd = &cobra.Command{
 
 			_, err = b.MakeFromString(string(schema), string(config))
 		} else {
-			_, err = b.MakeFromDirectory(flagProjectDir)
+			var s *proto.Schema
+			s, err = b.MakeFromDirectory(flagProjectDir)
+			if err == nil {
+				err = checkMigrationTypeChanges(s)
+			}
 		}
 
 		if err == nil && !flagJsonOutput {
@@ -58,6 +65,8 @@ var validateCmd = &cobra.Command{
 			Errors: []*config.ConfigError{},
 		}
 
+		var typeChangeErrors *migrations.UnsafeTypeChangeError
+
 		if flagJsonOutput {
 			resp := JsonResponse{
 				ValidationErrors: *validationErrors,
@@ -69,6 +78,10 @@ var validateCmd = &cobra.Command{
 				resp.ValidationErrors = *validationErrors
 			case errors.As(err, &configErrors):
 				resp.ConfigErrors = *configErrors
+			case errors.As(err, &typeChangeErrors):
+				for _, c := range typeChangeErrors.Changes {
+					resp.TypeChangeErrors = append(resp.TypeChangeErrors, c.String())
+				}
 			default:
 				if err != nil {
 					return err
@@ -98,6 +111,12 @@ var validateCmd = &cobra.Command{
 			for _, v := range configErrors.Errors {
 				fmt.Println(" -", colors.Red(v.Message).String())
 			}
+		case errors.As(err, &typeChangeErrors):
+			fmt.Println("❌ The type of these fields cannot be changed from the latest migration, as their existing values may not convert safely:")
+			fmt.Println("")
+			for _, c := range typeChangeErrors.Changes {
+				fmt.Println(" -", colors.Red(c.String()).String())
+			}
 		default:
 			return err
 		}
@@ -106,6 +125,23 @@ var validateCmd = &cobra.Command{
 	},
 }
 
+// checkMigrationTypeChanges checks that the type of each field can be safely changed from the schema
+// of the latest migration file, if the project has any.
+func checkMigrationTypeChanges(s *proto.Schema) error {
+	files, err := migrations.ReadMigrationFiles(migrationsDir())
+	if err != nil {
+		return err
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		if files[i].Schema != nil {
+			return migrations.CheckTypeChanges(files[i].Schema, s)
+		}
+	}
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
 	validateCmd.Flags().BoolVar(&flagJsonOutput, "json", false, "output validation and config errors as json")
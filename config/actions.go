@@ -0,0 +1,20 @@
+package config
+
+const (
+	// The default maximum number of records which a createMany action can create in one request
+	DefaultActionsMaxBatchSize = 1000
+)
+
+// ActionsConfig is the configuration for how the runtime performs actions
+type ActionsConfig struct {
+	MaxBatchSize *int `yaml:"maxBatchSize,omitempty"`
+}
+
+// BatchSizeLimit retrieves the configured or default maximum number of records which a createMany action can create
+func (c *ActionsConfig) BatchSizeLimit() int {
+	if c.MaxBatchSize != nil {
+		return *c.MaxBatchSize
+	} else {
+		return DefaultActionsMaxBatchSize
+	}
+}
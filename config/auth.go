@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"regexp"
@@ -17,6 +18,14 @@ const (
 	DefaultAccessTokenExpiry time.Duration = time.Hour * 24
 	// 3 months is the default refresh token expiry period
 	DefaultRefreshTokenExpiry time.Duration = time.Hour * 24 * 90
+	// 10 failed sign-ins or password reset requests for the same identity are allowed in the rate limit window
+	DefaultRateLimitMaxAttemptsPerIdentity = 10
+	// 100 failed sign-ins, password reset or passwordless code requests from the same IP address are allowed in the rate limit window
+	DefaultRateLimitMaxAttemptsPerIp = 100
+	// 15 minutes is the default window in which attempts are counted
+	DefaultRateLimitWindow time.Duration = time.Minute * 15
+	// 15 minutes is the default lockout once the attempts are exceeded
+	DefaultRateLimitLockout time.Duration = time.Minute * 15
 )
 
 const ProviderSecretPrefix = "AUTH_PROVIDER_SECRET_"
@@ -57,11 +66,13 @@ var (
 )
 
 type AuthConfig struct {
-	Tokens      TokensConfig    `yaml:"tokens"`
-	RedirectUrl *string         `yaml:"redirectUrl,omitempty"`
-	Providers   []Provider      `yaml:"providers"`
-	Claims      []IdentityClaim `yaml:"claims"`
-	Hooks       []FunctionHook  `yaml:"hooks"`
+	Tokens                   TokensConfig    `yaml:"tokens"`
+	RedirectUrl              *string         `yaml:"redirectUrl,omitempty"`
+	RequireEmailVerification *bool           `yaml:"requireEmailVerification,omitempty"`
+	RateLimits               RateLimits      `yaml:"rateLimits"`
+	Providers                []Provider      `yaml:"providers"`
+	Claims                   []IdentityClaim `yaml:"claims"`
+	Hooks                    []FunctionHook  `yaml:"hooks"`
 }
 
 type TokensConfig struct {
@@ -70,6 +81,21 @@ type TokensConfig struct {
 	RefreshTokenRotationEnabled *bool `yaml:"refreshTokenRotationEnabled,omitempty"`
 }
 
+// RateLimits protects the password, passwordless and MFA grants, as well as password reset and passwordless code requests,
+// from brute-force attacks by locking out an identity or IP address once it has exceeded the number of attempts allowed
+// in the window.
+//
+// The IP address is the address which connected to the runtime, unless that is one of the trusted proxies, in which
+// case the address the proxy added to X-Forwarded-For is used.
+type RateLimits struct {
+	Enabled                *bool    `yaml:"enabled,omitempty"`
+	MaxAttemptsPerIdentity *int     `yaml:"maxAttemptsPerIdentity,omitempty"`
+	MaxAttemptsPerIp       *int     `yaml:"maxAttemptsPerIp,omitempty"`
+	Window                 *int     `yaml:"window,omitempty"`
+	Lockout                *int     `yaml:"lockout,omitempty"`
+	TrustedProxies         []string `yaml:"trustedProxies,omitempty"`
+}
+
 type Provider struct {
 	Type             string `yaml:"type"`
 	Name             string `yaml:"name"`
@@ -116,6 +142,93 @@ func (c *AuthConfig) RefreshTokenRotationEnabled() bool {
 	}
 }
 
+// EmailVerificationRequired retrieves the configured or default requirement for identities
+// which sign in with a password to have verified their email address
+func (c *AuthConfig) EmailVerificationRequired() bool {
+	if c.RequireEmailVerification != nil {
+		return *c.RequireEmailVerification
+	} else {
+		return false
+	}
+}
+
+// RateLimitsEnabled retrieves the configured or default enabling of rate limits
+func (c *AuthConfig) RateLimitsEnabled() bool {
+	if c.RateLimits.Enabled != nil {
+		return *c.RateLimits.Enabled
+	} else {
+		return true
+	}
+}
+
+// RateLimitMaxAttemptsPerIdentity retrieves the configured or default number of attempts allowed for an identity in the window
+func (c *AuthConfig) RateLimitMaxAttemptsPerIdentity() int {
+	if c.RateLimits.MaxAttemptsPerIdentity != nil {
+		return *c.RateLimits.MaxAttemptsPerIdentity
+	} else {
+		return DefaultRateLimitMaxAttemptsPerIdentity
+	}
+}
+
+// RateLimitMaxAttemptsPerIp retrieves the configured or default number of attempts allowed from an IP address in the window
+func (c *AuthConfig) RateLimitMaxAttemptsPerIp() int {
+	if c.RateLimits.MaxAttemptsPerIp != nil {
+		return *c.RateLimits.MaxAttemptsPerIp
+	} else {
+		return DefaultRateLimitMaxAttemptsPerIp
+	}
+}
+
+// RateLimitWindow retrieves the configured or default window in which attempts are counted
+func (c *AuthConfig) RateLimitWindow() time.Duration {
+	if c.RateLimits.Window != nil {
+		return time.Duration(*c.RateLimits.Window) * time.Second
+	} else {
+		return DefaultRateLimitWindow
+	}
+}
+
+// RateLimitLockout retrieves the configured or default lockout once the attempts are exceeded
+func (c *AuthConfig) RateLimitLockout() time.Duration {
+	if c.RateLimits.Lockout != nil {
+		return time.Duration(*c.RateLimits.Lockout) * time.Second
+	} else {
+		return DefaultRateLimitLockout
+	}
+}
+
+// RateLimitTrustedProxies retrieves the IP addresses and CIDR ranges of the proxies which are trusted to set
+// X-Forwarded-For, ignoring any which are invalid
+func (c *AuthConfig) RateLimitTrustedProxies() []*net.IPNet {
+	proxies := []*net.IPNet{}
+	for _, p := range c.RateLimits.TrustedProxies {
+		if proxy := parseTrustedProxy(p); proxy != nil {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// parseTrustedProxy parses either a CIDR range or a single IP address, returning nil if it is neither
+func parseTrustedProxy(proxy string) *net.IPNet {
+	if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+		return ipNet
+	}
+
+	ip := net.ParseIP(proxy)
+	if ip == nil {
+		return nil
+	}
+
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
+
 // AddOidcProvider adds an OpenID Connect provider to the list of supported authentication providers
 func (c *AuthConfig) AddOidcProvider(name string, issuerUrl string, clientId string) error {
 	if invalidName(name) {
@@ -19,11 +19,15 @@ const Empty = ""
 
 // ProjectConfig is the configuration for a keel project
 type ProjectConfig struct {
-	Environment   []Input    `yaml:"environment"`
-	UseDefaultApi *bool      `yaml:"useDefaultApi,omitempty"`
-	Secrets       []Input    `yaml:"secrets"`
-	Auth          AuthConfig `yaml:"auth"`
-	DisableAuth   bool       `yaml:"disableKeelAuth"`
+	Environment   []Input       `yaml:"environment"`
+	UseDefaultApi *bool         `yaml:"useDefaultApi,omitempty"`
+	Secrets       []Input       `yaml:"secrets"`
+	Auth          AuthConfig    `yaml:"auth"`
+	DisableAuth   bool          `yaml:"disableKeelAuth"`
+	Storage       StorageConfig `yaml:"storage"`
+	Email         EmailConfig   `yaml:"email"`
+	Events        EventsConfig  `yaml:"events"`
+	Actions       ActionsConfig `yaml:"actions"`
 }
 
 func (p *ProjectConfig) GetEnvVars() map[string]string {
@@ -106,6 +110,26 @@ const (
 	ConfigAuthProviderInvalidHttpUrlErrorString      = "auth provider '%s' has missing or invalid https url for field: %s"
 	ConfigAuthInvalidRedirectUrlErrorString          = "auth redirectUrl '%s' is not a valid url"
 	ConfigAuthInvalidHook                            = "%s is not a recognised hook"
+	ConfigAuthRateLimitMustBePositive                = "auth rateLimits %s must be at least 1"
+	ConfigAuthRateLimitInvalidTrustedProxy           = "auth rateLimits trustedProxies '%s' must be an IP address or CIDR range"
+	ConfigStorageInvalidProviderErrorString          = "storage provider '%s' is not valid and must be one of: %s"
+	ConfigStorageMissingFieldErrorString             = "%s storage is missing field: %s"
+	ConfigStorageInvalidEndpointErrorString          = "storage endpoint '%s' is not a valid url"
+	ConfigStorageUrlExpiryMustBePositive             = "storage urlExpiry cannot be negative or zero"
+	ConfigStorageMaxUploadSizeMustBePositive         = "storage maxUploadSize cannot be negative or zero"
+	ConfigEmailInvalidProviderErrorString            = "email provider '%s' is not valid and must be one of: %s"
+	ConfigEmailMissingFieldErrorString               = "%s email is missing field: %s"
+	ConfigEmailInvalidEndpointErrorString            = "email endpoint '%s' is not a valid url"
+	ConfigEmailInvalidAddressErrorString             = "email %s '%s' is not a valid email address"
+	ConfigEventsMaxAttemptsMustBePositive            = "events maxAttempts must be at least 1"
+	ConfigEventsSubscriberMaxAttemptsMustBePositive  = "events maxAttempts for subscriber '%s' must be at least 1"
+	ConfigWebhookInvalidName                         = "webhook name '%s' must only include alphanumeric characters and underscores, and cannot start with a number"
+	ConfigWebhookDuplicateErrorString                = "webhook name '%s' has been defined more than once, but must be unique"
+	ConfigWebhookInvalidUrlErrorString               = "webhook '%s' has missing or invalid url '%s'"
+	ConfigWebhookMissingEventsErrorString            = "webhook '%s' must have at least one event"
+	ConfigWebhookInvalidEventErrorString             = "webhook '%s' has invalid event '%s' which must be in the form model_name.created"
+	ConfigWebhookMaxAttemptsMustBePositive           = "events maxAttempts for webhook '%s' must be at least 1"
+	ConfigActionsMaxBatchSizeMustBePositive          = "actions maxBatchSize must be at least 1"
 )
 
 type ConfigErrors struct {
@@ -219,6 +243,30 @@ func Validate(config *ProjectConfig) *ConfigErrors {
 		})
 	}
 
+	rateLimits := map[string]int{
+		"maxAttemptsPerIdentity": config.Auth.RateLimitMaxAttemptsPerIdentity(),
+		"maxAttemptsPerIp":       config.Auth.RateLimitMaxAttemptsPerIp(),
+		"window":                 int(config.Auth.RateLimitWindow().Seconds()),
+		"lockout":                int(config.Auth.RateLimitLockout().Seconds()),
+	}
+	for _, field := range []string{"maxAttemptsPerIdentity", "maxAttemptsPerIp", "window", "lockout"} {
+		if rateLimits[field] < 1 {
+			errors = append(errors, &ConfigError{
+				Type:    "invalid",
+				Message: fmt.Sprintf(ConfigAuthRateLimitMustBePositive, field),
+			})
+		}
+	}
+
+	for _, proxy := range config.Auth.RateLimits.TrustedProxies {
+		if parseTrustedProxy(proxy) == nil {
+			errors = append(errors, &ConfigError{
+				Type:    "invalid",
+				Message: fmt.Sprintf(ConfigAuthRateLimitInvalidTrustedProxy, proxy),
+			})
+		}
+	}
+
 	invalidProviderNames := findAuthProviderInvalidName(config.Auth.Providers)
 	for _, p := range invalidProviderNames {
 		errors = append(errors, &ConfigError{
@@ -333,6 +381,160 @@ func Validate(config *ProjectConfig) *ConfigErrors {
 		}
 	}
 
+	if config.Storage.Provider != "" && !slices.Contains(SupportedStorageProviders, config.Storage.Provider) {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: fmt.Sprintf(ConfigStorageInvalidProviderErrorString, config.Storage.Provider, strings.Join(SupportedStorageProviders, ", ")),
+		})
+	}
+
+	if config.Storage.UsesS3() && config.Storage.Bucket == "" {
+		errors = append(errors, &ConfigError{
+			Type:    "missing",
+			Message: fmt.Sprintf(ConfigStorageMissingFieldErrorString, config.Storage.Provider, "bucket"),
+		})
+	}
+
+	if config.Storage.Endpoint != "" && invalidEndpoint(config.Storage.Endpoint) {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: fmt.Sprintf(ConfigStorageInvalidEndpointErrorString, config.Storage.Endpoint),
+		})
+	}
+
+	if config.Storage.PresignedUrlExpiry() <= 0 {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: ConfigStorageUrlExpiryMustBePositive,
+		})
+	}
+
+	if config.Storage.UploadSizeLimit() <= 0 {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: ConfigStorageMaxUploadSizeMustBePositive,
+		})
+	}
+
+	if config.Email.Provider != "" && !slices.Contains(SupportedEmailProviders, config.Email.Provider) {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: fmt.Sprintf(ConfigEmailInvalidProviderErrorString, config.Email.Provider, strings.Join(SupportedEmailProviders, ", ")),
+		})
+	}
+
+	if config.Email.Provider == SmtpEmail {
+		if config.Email.Host == "" {
+			errors = append(errors, &ConfigError{
+				Type:    "missing",
+				Message: fmt.Sprintf(ConfigEmailMissingFieldErrorString, config.Email.Provider, "host"),
+			})
+		}
+		if config.Email.Port == "" {
+			errors = append(errors, &ConfigError{
+				Type:    "missing",
+				Message: fmt.Sprintf(ConfigEmailMissingFieldErrorString, config.Email.Provider, "port"),
+			})
+		}
+	}
+
+	if config.Email.Provider == HttpEmail && config.Email.Endpoint == "" {
+		errors = append(errors, &ConfigError{
+			Type:    "missing",
+			Message: fmt.Sprintf(ConfigEmailMissingFieldErrorString, config.Email.Provider, "endpoint"),
+		})
+	}
+
+	if config.Email.Endpoint != "" && invalidEndpoint(config.Email.Endpoint) {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: fmt.Sprintf(ConfigEmailInvalidEndpointErrorString, config.Email.Endpoint),
+		})
+	}
+
+	if config.Email.From != "" && invalidEmailAddress(config.Email.From) {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: fmt.Sprintf(ConfigEmailInvalidAddressErrorString, "from", config.Email.From),
+		})
+	}
+
+	if config.Email.ReplyTo != "" && invalidEmailAddress(config.Email.ReplyTo) {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: fmt.Sprintf(ConfigEmailInvalidAddressErrorString, "replyTo", config.Email.ReplyTo),
+		})
+	}
+
+	if config.Events.MaxAttempts != nil && *config.Events.MaxAttempts < 1 {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: ConfigEventsMaxAttemptsMustBePositive,
+		})
+	}
+
+	for _, s := range config.Events.Subscribers {
+		if s.MaxAttempts != nil && *s.MaxAttempts < 1 {
+			errors = append(errors, &ConfigError{
+				Type:    "invalid",
+				Message: fmt.Sprintf(ConfigEventsSubscriberMaxAttemptsMustBePositive, s.Name),
+			})
+		}
+	}
+
+	webhookNames := map[string]bool{}
+	for _, w := range config.Events.Webhooks {
+		if invalidName(w.Name) {
+			errors = append(errors, &ConfigError{
+				Type:    "invalid",
+				Message: fmt.Sprintf(ConfigWebhookInvalidName, w.Name),
+			})
+		} else if webhookNames[w.Name] {
+			errors = append(errors, &ConfigError{
+				Type:    "duplicate",
+				Message: fmt.Sprintf(ConfigWebhookDuplicateErrorString, w.Name),
+			})
+		}
+		webhookNames[w.Name] = true
+
+		if invalidEndpoint(w.Url) {
+			errors = append(errors, &ConfigError{
+				Type:    "invalid",
+				Message: fmt.Sprintf(ConfigWebhookInvalidUrlErrorString, w.Name, w.Url),
+			})
+		}
+
+		if len(w.Events) == 0 {
+			errors = append(errors, &ConfigError{
+				Type:    "missing",
+				Message: fmt.Sprintf(ConfigWebhookMissingEventsErrorString, w.Name),
+			})
+		}
+
+		for _, e := range w.Events {
+			if invalidWebhookEvent(e) {
+				errors = append(errors, &ConfigError{
+					Type:    "invalid",
+					Message: fmt.Sprintf(ConfigWebhookInvalidEventErrorString, w.Name, e),
+				})
+			}
+		}
+
+		if w.MaxAttempts != nil && *w.MaxAttempts < 1 {
+			errors = append(errors, &ConfigError{
+				Type:    "invalid",
+				Message: fmt.Sprintf(ConfigWebhookMaxAttemptsMustBePositive, w.Name),
+			})
+		}
+	}
+
+	if config.Actions.BatchSizeLimit() < 1 {
+		errors = append(errors, &ConfigError{
+			Type:    "invalid",
+			Message: ConfigActionsMaxBatchSizeMustBePositive,
+		})
+	}
+
 	if len(errors) == 0 {
 		return nil
 	}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -97,6 +98,7 @@ func TestAuthTokens(t *testing.T) {
 	assert.Equal(t, time.Duration(3600)*time.Second, config.Auth.AccessTokenExpiry())
 	assert.Equal(t, time.Duration(604800)*time.Second, config.Auth.RefreshTokenExpiry())
 	assert.Equal(t, false, config.Auth.RefreshTokenRotationEnabled())
+	assert.Equal(t, true, config.Auth.EmailVerificationRequired())
 }
 
 func TestAuthInvalidRedirectUrl(t *testing.T) {
@@ -118,6 +120,50 @@ func TestAuthDefaults(t *testing.T) {
 	assert.Equal(t, time.Duration(24)*time.Hour, config.Auth.AccessTokenExpiry())
 	assert.Equal(t, time.Duration(24)*time.Hour*90, config.Auth.RefreshTokenExpiry())
 	assert.Equal(t, true, config.Auth.RefreshTokenRotationEnabled())
+	assert.Nil(t, config.Auth.RequireEmailVerification)
+	assert.Equal(t, false, config.Auth.EmailVerificationRequired())
+}
+
+func TestAuthRateLimits(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_auth_rate_limits.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, true, config.Auth.RateLimitsEnabled())
+	assert.Equal(t, 5, config.Auth.RateLimitMaxAttemptsPerIdentity())
+	assert.Equal(t, 50, config.Auth.RateLimitMaxAttemptsPerIp())
+	assert.Equal(t, time.Duration(600)*time.Second, config.Auth.RateLimitWindow())
+	assert.Equal(t, time.Duration(3600)*time.Second, config.Auth.RateLimitLockout())
+
+	proxies := config.Auth.RateLimitTrustedProxies()
+	assert.Len(t, proxies, 2)
+	assert.Equal(t, "10.0.0.0/8", proxies[0].String())
+	assert.Equal(t, "192.168.1.1/32", proxies[1].String())
+}
+
+func TestAuthRateLimitsDefaults(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_empty_config.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, true, config.Auth.RateLimitsEnabled())
+	assert.Equal(t, 10, config.Auth.RateLimitMaxAttemptsPerIdentity())
+	assert.Equal(t, 100, config.Auth.RateLimitMaxAttemptsPerIp())
+	assert.Equal(t, time.Duration(15)*time.Minute, config.Auth.RateLimitWindow())
+	assert.Equal(t, time.Duration(15)*time.Minute, config.Auth.RateLimitLockout())
+	assert.Empty(t, config.Auth.RateLimitTrustedProxies())
+}
+
+func TestAuthInvalidRateLimits(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_auth_invalid_rate_limits.yaml")
+
+	assert.Contains(t, err.Error(), "auth rateLimits maxAttemptsPerIdentity must be at least 1\n")
+	assert.Contains(t, err.Error(), "auth rateLimits maxAttemptsPerIp must be at least 1\n")
+	assert.Contains(t, err.Error(), "auth rateLimits window must be at least 1\n")
+	assert.Contains(t, err.Error(), "auth rateLimits lockout must be at least 1\n")
+	assert.Contains(t, err.Error(), "auth rateLimits trustedProxies 'load-balancer' must be an IP address or CIDR range\n")
+	assert.NotContains(t, err.Error(), "10.0.0.0/8")
 }
 
 func TestAuthNegativeTokenLifespan(t *testing.T) {
@@ -365,3 +411,154 @@ func TestAuthHooksAsArray(t *testing.T) {
 	assert.Equal(t, HookAfterAuthentication, config.Auth.Hooks[0])
 	assert.Equal(t, HookAfterIdentityCreated, config.Auth.Hooks[1])
 }
+
+func TestStorageDefaults(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_empty_config.yaml")
+	assert.NoError(t, err)
+
+	assert.False(t, config.Storage.UsesS3())
+	assert.Equal(t, time.Duration(15)*time.Minute, config.Storage.PresignedUrlExpiry())
+	assert.Equal(t, int64(25*1024*1024), config.Storage.UploadSizeLimit())
+}
+
+func TestStorageS3(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_storage_s3.yaml")
+	assert.NoError(t, err)
+
+	assert.True(t, config.Storage.UsesS3())
+	assert.Equal(t, "my-app-files", config.Storage.Bucket)
+	assert.Equal(t, "eu-west-2", config.Storage.Region)
+	assert.Equal(t, "http://localhost:9000", config.Storage.Endpoint)
+	assert.True(t, config.Storage.UsePathStyle)
+	assert.Equal(t, time.Duration(300)*time.Second, config.Storage.PresignedUrlExpiry())
+	assert.Equal(t, int64(1048576), config.Storage.UploadSizeLimit())
+}
+
+func TestStorageInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_storage_invalid.yaml")
+
+	assert.Contains(t, err.Error(), "s3 storage is missing field: bucket\n")
+	assert.Contains(t, err.Error(), "storage endpoint 'not a url' is not a valid url\n")
+	assert.Contains(t, err.Error(), "storage urlExpiry cannot be negative or zero\n")
+	assert.Contains(t, err.Error(), "storage maxUploadSize cannot be negative or zero\n")
+}
+
+func TestStorageInvalidProvider(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_storage_invalid_provider.yaml")
+
+	assert.Contains(t, err.Error(), "storage provider 'gcs' is not valid and must be one of: database, s3\n")
+}
+
+func TestEmailDefaults(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_empty_config.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", config.Email.Provider)
+	assert.Equal(t, "hi@keel.xyz", config.Email.FromAddress())
+	assert.Equal(t, filepath.Join("project", "emails"), config.Email.TemplatesDir("project"))
+	assert.Equal(t, filepath.Join("project", ".keel", "outbox"), config.Email.OutboxDir("project"))
+}
+
+func TestEmailHttp(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_email_http.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, HttpEmail, config.Email.Provider)
+	assert.Equal(t, "https://api.mailprovider.com/v1/send", config.Email.Endpoint)
+	assert.Equal(t, "My App <noreply@myapp.com>", config.Email.FromAddress())
+	assert.Equal(t, "support@myapp.com", config.Email.ReplyTo)
+	assert.Equal(t, filepath.Join("project", "templates", "email"), config.Email.TemplatesDir("project"))
+}
+
+func TestEmailInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_email_invalid.yaml")
+
+	assert.Contains(t, err.Error(), "smtp email is missing field: host\n")
+	assert.Contains(t, err.Error(), "smtp email is missing field: port\n")
+	assert.Contains(t, err.Error(), "email endpoint 'not a url' is not a valid url\n")
+	assert.Contains(t, err.Error(), "email from 'not an address' is not a valid email address\n")
+}
+
+func TestEmailInvalidProvider(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_email_invalid_provider.yaml")
+
+	assert.Contains(t, err.Error(), "email provider 'sendgrid' is not valid and must be one of: smtp, http, outbox\n")
+}
+
+func TestEventsMaxAttempts(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_events.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 12, config.Events.SubscriberMaxAttempts("sendWelcomeEmail"))
+	assert.Equal(t, 8, config.Events.SubscriberMaxAttempts("syncBilling"))
+	assert.Equal(t, 8, config.Events.SubscriberMaxAttempts("other"))
+
+	config, err = Load("fixtures/test_empty_config.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, config.Events.SubscriberMaxAttempts("sendWelcomeEmail"))
+}
+
+func TestEventsInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_events_invalid.yaml")
+
+	assert.Contains(t, err.Error(), "events maxAttempts must be at least 1\n")
+	assert.Contains(t, err.Error(), "events maxAttempts for subscriber 'sendWelcomeEmail' must be at least 1\n")
+}
+
+func TestActions(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_actions.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, 250, config.Actions.BatchSizeLimit())
+
+	config, err = Load("fixtures/test_empty_config.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, config.Actions.BatchSizeLimit())
+}
+
+func TestActionsInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_actions_invalid.yaml")
+
+	assert.Contains(t, err.Error(), "actions maxBatchSize must be at least 1\n")
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+	config, err := Load("fixtures/test_webhooks.yaml")
+	assert.NoError(t, err)
+
+	webhooks := config.Events.EventWebhooks("order.created")
+	assert.Len(t, webhooks, 2)
+	assert.Equal(t, "orders", webhooks[0].Name)
+	assert.Equal(t, "WEBHOOK_SECRET_ORDERS", webhooks[0].GetSecretName())
+	assert.Equal(t, "audit", webhooks[1].Name)
+
+	assert.Len(t, config.Events.EventWebhooks("order.updated"), 1)
+	assert.Empty(t, config.Events.EventWebhooks("order.deleted"))
+
+	assert.Equal(t, 3, config.Events.WebhookMaxAttempts("orders"))
+	assert.Equal(t, 8, config.Events.WebhookMaxAttempts("audit"))
+}
+
+func TestWebhooksInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := Load("fixtures/test_webhooks_invalid.yaml")
+
+	assert.Contains(t, err.Error(), "webhook name '1orders' must only include alphanumeric characters and underscores, and cannot start with a number\n")
+	assert.Contains(t, err.Error(), "webhook 'audit' has missing or invalid url 'ftp://example.com'\n")
+	assert.Contains(t, err.Error(), "webhook 'audit' has invalid event 'Order.Created' which must be in the form model_name.created\n")
+	assert.Contains(t, err.Error(), "events maxAttempts for webhook 'audit' must be at least 1\n")
+	assert.Contains(t, err.Error(), "webhook name 'audit' has been defined more than once, but must be unique\n")
+	assert.Contains(t, err.Error(), "webhook 'audit' must have at least one event\n")
+}
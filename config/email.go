@@ -0,0 +1,79 @@
+package config
+
+import (
+	"net/mail"
+	"path/filepath"
+)
+
+const (
+	SmtpEmail   = "smtp"
+	HttpEmail   = "http"
+	OutboxEmail = "outbox"
+)
+
+var (
+	SupportedEmailProviders = []string{
+		SmtpEmail,
+		HttpEmail,
+		OutboxEmail,
+	}
+)
+
+const (
+	// The address emails are sent from if one has not been configured
+	DefaultEmailFrom = "hi@keel.xyz"
+	// The directory within the project where email templates are kept
+	DefaultEmailTemplatesDir = "emails"
+	// The directory within the project where the outbox provider writes emails
+	DefaultEmailOutboxDir = ".keel/outbox"
+)
+
+// The secrets which hold the credentials used to send emails
+const (
+	EmailSmtpUsernameSecret = "SMTP_USERNAME"
+	EmailSmtpPasswordSecret = "SMTP_PASSWORD"
+	EmailApiKeySecret       = "EMAIL_API_KEY"
+)
+
+// EmailConfig is the configuration for how emails are sent, such as the password reset email. If no
+// provider is configured then the KEEL_SMTP_* environment variables are used if they are set.
+type EmailConfig struct {
+	Provider  string `yaml:"provider,omitempty"`
+	From      string `yaml:"from,omitempty"`
+	ReplyTo   string `yaml:"replyTo,omitempty"`
+	Host      string `yaml:"host,omitempty"`
+	Port      string `yaml:"port,omitempty"`
+	Endpoint  string `yaml:"endpoint,omitempty"`
+	Outbox    string `yaml:"outbox,omitempty"`
+	Templates string `yaml:"templates,omitempty"`
+}
+
+// FromAddress retrieves the configured or default address emails are sent from
+func (c *EmailConfig) FromAddress() string {
+	if c.From != "" {
+		return c.From
+	}
+	return DefaultEmailFrom
+}
+
+// TemplatesDir retrieves the configured or default directory of email templates, relative to the project directory
+func (c *EmailConfig) TemplatesDir(projectDir string) string {
+	if c.Templates != "" {
+		return filepath.Join(projectDir, c.Templates)
+	}
+	return filepath.Join(projectDir, DefaultEmailTemplatesDir)
+}
+
+// OutboxDir retrieves the configured or default directory of the outbox provider, relative to the project directory
+func (c *EmailConfig) OutboxDir(projectDir string) string {
+	if c.Outbox != "" {
+		return filepath.Join(projectDir, c.Outbox)
+	}
+	return filepath.Join(projectDir, DefaultEmailOutboxDir)
+}
+
+// invalidEmailAddress checks the address can be parsed, such as "Keel <hi@keel.xyz>"
+func invalidEmailAddress(address string) bool {
+	_, err := mail.ParseAddress(address)
+	return err != nil
+}
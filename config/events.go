@@ -0,0 +1,34 @@
+package config
+
+const (
+	// The number of times delivering an event to a subscriber is attempted before it is dead-lettered
+	DefaultEventMaxAttempts = 5
+)
+
+// EventsConfig is the configuration for how events are delivered to subscribers
+type EventsConfig struct {
+	MaxAttempts *int               `yaml:"maxAttempts,omitempty"`
+	Subscribers []SubscriberConfig `yaml:"subscribers,omitempty"`
+	Webhooks    []WebhookConfig    `yaml:"webhooks,omitempty"`
+}
+
+// SubscriberConfig overrides the delivery of events for a single subscriber
+type SubscriberConfig struct {
+	Name        string `yaml:"name"`
+	MaxAttempts *int   `yaml:"maxAttempts,omitempty"`
+}
+
+// SubscriberMaxAttempts retrieves the configured or default number of delivery attempts for the subscriber
+func (c *EventsConfig) SubscriberMaxAttempts(subscriber string) int {
+	for _, s := range c.Subscribers {
+		if s.Name == subscriber && s.MaxAttempts != nil {
+			return *s.MaxAttempts
+		}
+	}
+
+	if c.MaxAttempts != nil {
+		return *c.MaxAttempts
+	}
+
+	return DefaultEventMaxAttempts
+}
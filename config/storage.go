@@ -0,0 +1,73 @@
+package config
+
+import (
+	"net/url"
+	"time"
+)
+
+const (
+	DatabaseStorage = "database"
+	S3Storage       = "s3"
+)
+
+var (
+	SupportedStorageProviders = []string{
+		DatabaseStorage,
+		S3Storage,
+	}
+)
+
+const (
+	// 15 minutes is the default expiry period of presigned file URLs
+	DefaultStorageUrlExpiry time.Duration = time.Minute * 15
+	// 25MB is the default maximum size of a file which is uploaded directly
+	DefaultStorageMaxUploadSize int64 = 25 * 1024 * 1024
+)
+
+// The secrets which hold the credentials used to access the storage bucket
+const (
+	StorageAccessKeyIdSecret     = "STORAGE_ACCESS_KEY_ID"
+	StorageSecretAccessKeySecret = "STORAGE_SECRET_ACCESS_KEY"
+)
+
+// StorageConfig is the configuration for where files are stored. If no provider is
+// configured then files are stored in the database.
+type StorageConfig struct {
+	Provider      string `yaml:"provider,omitempty"`
+	Bucket        string `yaml:"bucket,omitempty"`
+	Region        string `yaml:"region,omitempty"`
+	Endpoint      string `yaml:"endpoint,omitempty"`
+	UsePathStyle  bool   `yaml:"usePathStyle,omitempty"`
+	UrlExpiry     *int   `yaml:"urlExpiry,omitempty"`
+	MaxUploadSize *int64 `yaml:"maxUploadSize,omitempty"`
+}
+
+// UsesS3 returns true if files are stored in an S3-compatible bucket
+func (c *StorageConfig) UsesS3() bool {
+	return c.Provider == S3Storage
+}
+
+// PresignedUrlExpiry retrieves the configured or default expiry of presigned file URLs
+func (c *StorageConfig) PresignedUrlExpiry() time.Duration {
+	if c.UrlExpiry != nil {
+		return time.Duration(*c.UrlExpiry) * time.Second
+	} else {
+		return DefaultStorageUrlExpiry
+	}
+}
+
+// UploadSizeLimit retrieves the configured or default maximum size in bytes of a file uploaded directly
+func (c *StorageConfig) UploadSizeLimit() int64 {
+	if c.MaxUploadSize != nil {
+		return *c.MaxUploadSize
+	} else {
+		return DefaultStorageMaxUploadSize
+	}
+}
+
+// invalidEndpoint checks the endpoint is a http(s) url. Plain http is allowed
+// so that a local service, such as MinIO, can be used.
+func invalidEndpoint(endpoint string) bool {
+	parsed, err := url.ParseRequestURI(endpoint)
+	return err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == ""
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WebhookSecretPrefix is the prefix of the secret used to sign the requests made to a webhook
+const WebhookSecretPrefix = "WEBHOOK_SECRET_"
+
+var webhookEventRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*\.[a-z][a-z0-9_]*$`)
+
+// WebhookConfig is an HTTP endpoint which is sent events, as an alternative to a subscriber function
+type WebhookConfig struct {
+	Name        string   `yaml:"name"`
+	Url         string   `yaml:"url"`
+	Events      []string `yaml:"events"`
+	MaxAttempts *int     `yaml:"maxAttempts,omitempty"`
+}
+
+// GetSecretName generates the name of the secret used to sign requests to this webhook
+func (w *WebhookConfig) GetSecretName() string {
+	return fmt.Sprintf("%s%s", WebhookSecretPrefix, strings.ToUpper(w.Name))
+}
+
+// FindWebhook retrieves the webhook with the given name, or nil
+func (c *EventsConfig) FindWebhook(name string) *WebhookConfig {
+	for i, w := range c.Webhooks {
+		if w.Name == name {
+			return &c.Webhooks[i]
+		}
+	}
+	return nil
+}
+
+// EventWebhooks retrieves the webhooks which are sent the event
+func (c *EventsConfig) EventWebhooks(eventName string) []*WebhookConfig {
+	webhooks := []*WebhookConfig{}
+	for i, w := range c.Webhooks {
+		for _, e := range w.Events {
+			if e == eventName {
+				webhooks = append(webhooks, &c.Webhooks[i])
+				break
+			}
+		}
+	}
+	return webhooks
+}
+
+// WebhookMaxAttempts retrieves the configured or default number of delivery attempts for the webhook
+func (c *EventsConfig) WebhookMaxAttempts(name string) int {
+	if w := c.FindWebhook(name); w != nil && w.MaxAttempts != nil {
+		return *w.MaxAttempts
+	}
+
+	if c.MaxAttempts != nil {
+		return *c.MaxAttempts
+	}
+
+	return DefaultEventMaxAttempts
+}
+
+func invalidWebhookEvent(event string) bool {
+	return !webhookEventRegex.MatchString(event)
+}
@@ -0,0 +1,191 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// How far ahead Next looks for a matching time before giving up, which is only
+// reached by expressions which never fire, such as the 31st of February.
+const maxLookahead = 5 * 366 * 24 * time.Hour
+
+// ParseExpression parses an expression in the format returned by CronExpression.String,
+// such as the schedule stored against a job in the proto.
+func ParseExpression(src string) (*CronExpression, error) {
+	fields := strings.Fields(src)
+	if len(fields) == 6 {
+		// The year field is always '*'
+		fields = fields[:5]
+	}
+
+	if len(fields) != 5 {
+		return nil, Error{Message: fmt.Sprintf("invalid cron expression '%s'", src)}
+	}
+
+	c := &CronExpression{
+		Minutes:    fields[0],
+		Hours:      fields[1],
+		DayOfMonth: fields[2],
+		Month:      fields[3],
+		DayOfWeek:  fields[4],
+	}
+
+	// Check that each field can be expanded
+	_, err := c.schedule()
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Next returns the first time after t at which the expression fires. Expressions
+// are evaluated in UTC, and so the returned time is in UTC.
+func (c *CronExpression) Next(t time.Time) (time.Time, error) {
+	s, err := c.schedule()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Start from the next whole minute
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxLookahead)
+
+	for t.Before(limit) {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.daysOfMonth[t.Day()] || !s.daysOfWeek[int(t.Weekday())] {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t, nil
+	}
+
+	return time.Time{}, Error{Message: fmt.Sprintf("cron expression '%s' does not fire within the next five years", c.String())}
+}
+
+// schedule is a cron expression expanded into the values at which each field matches.
+type schedule struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+}
+
+func (c *CronExpression) schedule() (*schedule, error) {
+	s := &schedule{}
+
+	var err error
+	s.minutes, err = expandField(c.Minutes, "minutes", 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hours, err = expandField(c.Hours, "hours", 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.daysOfMonth, err = expandField(c.DayOfMonth, "day-of-month", 1, 31, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.months, err = expandField(c.Month, "month", 1, 12, cronFieldConfigs[3].altValues)
+	if err != nil {
+		return nil, err
+	}
+
+	s.daysOfWeek, err = expandField(c.DayOfWeek, "day-of-week", 0, 6, cronFieldConfigs[4].altValues)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// expandField returns the values from low to high which match the field, where the field is a comma
+// separated list of '*', '?', step values such as '*/10', and values or ranges such as '9-17' or 'MON-FRI'.
+func expandField(field string, label string, low int, high int, names []string) (map[int]bool, error) {
+	values := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+		switch {
+		case part == "*" || part == "?":
+			for v := low; v <= high; v++ {
+				values[v] = true
+			}
+		case strings.HasPrefix(part, "*/"):
+			step, err := strconv.Atoi(strings.TrimPrefix(part, "*/"))
+			if err != nil || step < 1 {
+				return nil, Error{Message: fmt.Sprintf("invalid step value '%s' for %s field", part, label)}
+			}
+			for v := low; v <= high; v += step {
+				values[v] = true
+			}
+		default:
+			bounds := strings.SplitN(part, "-", 2)
+			from, err := fieldValue(bounds[0], label, low, high, names)
+			if err != nil {
+				return nil, err
+			}
+
+			to := from
+			if len(bounds) == 2 {
+				to, err = fieldValue(bounds[1], label, low, high, names)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if to < from {
+				return nil, Error{Message: fmt.Sprintf("invalid range '%s' for %s field", part, label)}
+			}
+
+			for v := from; v <= to; v++ {
+				values[v] = true
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// fieldValue parses a single integer or named value of a field, such as '9' or 'MON'.
+func fieldValue(value string, label string, low int, high int, names []string) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		_, idx, found := lo.FindIndexOf(names, func(name string) bool {
+			return name != "" && name == strings.ToUpper(value)
+		})
+		if !found {
+			return 0, Error{Message: fmt.Sprintf("invalid value '%s' for %s field", value, label)}
+		}
+		v = idx
+	}
+
+	if v < low || v > high {
+		return 0, Error{Message: fmt.Sprintf("invalid value '%s' for %s field - must be integer between %d and %d", value, label, low, high)}
+	}
+
+	return v, nil
+}
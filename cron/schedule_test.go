@@ -0,0 +1,143 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/cron"
+)
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+
+	// A Wednesday
+	from := time.Date(2024, 1, 31, 10, 17, 30, 0, time.UTC)
+
+	fixtures := []struct {
+		input    string
+		expected []time.Time
+	}{
+		{
+			"every 10 minutes",
+			[]time.Time{
+				time.Date(2024, 1, 31, 10, 20, 0, 0, time.UTC),
+				time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC),
+				time.Date(2024, 1, 31, 10, 40, 0, 0, time.UTC),
+			},
+		},
+		{
+			"every 30 minutes from 9am to 11am",
+			[]time.Time{
+				time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC),
+				time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 31, 11, 30, 0, 0, time.UTC),
+				time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"every 2 hours",
+			[]time.Time{
+				time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 31, 14, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"every monday at 9am",
+			[]time.Time{
+				time.Date(2024, 2, 5, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"every weekday at 9am and 5pm",
+			[]time.Time{
+				time.Date(2024, 1, 31, 17, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 1, 17, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 2, 17, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 5, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 0 29 2 *",
+			[]time.Time{
+				time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"15 6 * JAN-MAR 0",
+			[]time.Time{
+				time.Date(2024, 2, 4, 6, 15, 0, 0, time.UTC),
+				time.Date(2024, 2, 11, 6, 15, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 12 1 */4 *",
+			[]time.Time{
+				time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, fixture := range fixtures {
+		fixture := fixture
+		t.Run(fixture.input, func(t *testing.T) {
+			t.Parallel()
+			c, err := cron.Parse(fixture.input)
+			require.NoError(t, err)
+
+			// The expression stored in the proto gives the same times
+			stored, err := cron.ParseExpression(c.String())
+			require.NoError(t, err)
+			assert.Equal(t, c, stored)
+
+			next := from
+			for _, expected := range fixture.expected {
+				next, err = stored.Next(next)
+				require.NoError(t, err)
+				assert.Equal(t, expected, next)
+			}
+		})
+	}
+}
+
+func TestCronNextInUTC(t *testing.T) {
+	t.Parallel()
+
+	c, err := cron.Parse("every day at 9am")
+	require.NoError(t, err)
+
+	location := time.FixedZone("UTC+10", 10*60*60)
+	next, err := c.Next(time.Date(2024, 1, 31, 10, 0, 0, 0, location))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC), next)
+}
+
+func TestCronNextNeverFires(t *testing.T) {
+	t.Parallel()
+
+	c, err := cron.Parse("0 0 31 2 *")
+	require.NoError(t, err)
+
+	_, err = c.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.Error(t, err)
+}
+
+func TestParseExpressionError(t *testing.T) {
+	t.Parallel()
+
+	_, err := cron.ParseExpression("* * *")
+	assert.Equal(t, "invalid cron expression '* * *'", err.Error())
+
+	_, err = cron.ParseExpression("0 9 ? * FUN *")
+	assert.Equal(t, "invalid value 'FUN' for day-of-week field", err.Error())
+
+	_, err = cron.ParseExpression("0 25 ? * * *")
+	assert.Equal(t, "invalid value '25' for hours field - must be integer between 0 and 23", err.Error())
+}
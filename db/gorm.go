@@ -95,12 +95,30 @@ func (db *GormDB) Transaction(ctx context.Context, fn func(context.Context) erro
 	ctx, span := tracer.Start(ctx, "Database Transaction")
 	defer span.End()
 
-	return db.db.Transaction(func(tx *gorm.DB) (err error) {
+	conn := db.db.WithContext(ctx)
+
+	// If there is already a transaction then this becomes a nested
+	// transaction, which is implemented with a savepoint.
+	if v, ok := ctx.Value(transactionCtxKey).(*gorm.DB); ok {
+		conn = v
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) (err error) {
 		ctx = context.WithValue(ctx, transactionCtxKey, tx)
 		return fn(ctx)
 	})
 }
 
+// Conn returns the connection to use for the given context, which is the transaction
+// if one has been started on the context by Database.Transaction.
+func Conn(ctx context.Context, database Database) *gorm.DB {
+	if v, ok := ctx.Value(transactionCtxKey).(*gorm.DB); ok {
+		return v
+	}
+
+	return database.GetDB().WithContext(ctx)
+}
+
 func (db *GormDB) Close() error {
 	conn, err := db.db.DB()
 	if err != nil {
@@ -0,0 +1,284 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Encrypted values are stored with this prefix, followed by the id of the key which encrypted the data key,
+// the encrypted data key and the encrypted value, for example keel:enc:v1:3f2a9c1d:<data key>:<value>
+const Prefix = "keel:enc:v1:"
+
+// The project secrets, and otherwise the environment variables, which the encryption keys are read from.
+// Previous keys are a comma separated list of keys which values may still be encrypted with.
+const (
+	SecretKey          = "ENCRYPTION_KEY"
+	SecretPreviousKeys = "ENCRYPTION_PREVIOUS_KEYS"
+	EnvKey             = "KEEL_ENCRYPTION_KEY"
+	EnvPreviousKeys    = "KEEL_ENCRYPTION_PREVIOUS_KEYS"
+)
+
+// The length in bytes of the keys, which are used for AES-256-GCM
+const keyLength = 32
+
+// The key used by keel run when the project does not set one, so that values are encrypted in the same
+// way locally. It must never be used for data which needs to be kept secret.
+var developmentKey = sha256.Sum256([]byte("keel local development encryption key"))
+
+var ErrUnknownKey = errors.New("the value was encrypted with a key which is not the current or a previous encryption key")
+
+type keyringContextKey string
+
+var keyringContext keyringContextKey = "encryptionKeyring"
+
+// Key is a key which encrypts the data keys of values.
+type Key struct {
+	// The id is derived from the key, and is stored with each value so that the key which encrypted it can be found
+	Id       string
+	material []byte
+}
+
+// ParseKey parses a base64 encoded 32 byte key.
+func ParseKey(encoded string) (*Key, error) {
+	material, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("an encryption key must be base64 encoded")
+	}
+
+	if len(material) != keyLength {
+		return nil, fmt.Errorf("an encryption key must be %d bytes, but it is %d bytes", keyLength, len(material))
+	}
+
+	return newKey(material), nil
+}
+
+// GenerateKey returns a new random key, base64 encoded.
+func GenerateKey() (string, error) {
+	material := make([]byte, keyLength)
+	if _, err := rand.Read(material); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(material), nil
+}
+
+func newKey(material []byte) *Key {
+	sum := sha256.Sum256(material)
+	return &Key{
+		Id:       hex.EncodeToString(sum[:4]),
+		material: material,
+	}
+}
+
+// Keyring holds the current key, which encrypts new values, and any previous keys which existing
+// values can still be decrypted with until they have been rotated to the current key.
+type Keyring struct {
+	keys []*Key
+}
+
+// NewKeyring creates a keyring from the base64 encoded current key and previous keys.
+func NewKeyring(current string, previous ...string) (*Keyring, error) {
+	keyring := &Keyring{}
+
+	for _, encoded := range append([]string{current}, previous...) {
+		if strings.TrimSpace(encoded) == "" {
+			continue
+		}
+
+		key, err := ParseKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys = append(keyring.keys, key)
+	}
+
+	if len(keyring.keys) == 0 {
+		return nil, errors.New("an encryption key is required")
+	}
+
+	return keyring, nil
+}
+
+// LoadKeyring creates a keyring from the project secrets or, if they are not set, from the environment.
+// If no key is set then nil is returned.
+func LoadKeyring(secrets map[string]string) (*Keyring, error) {
+	current, previous := secrets[SecretKey], secrets[SecretPreviousKeys]
+	if current == "" {
+		current, previous = os.Getenv(EnvKey), os.Getenv(EnvPreviousKeys)
+	}
+
+	if current == "" {
+		return nil, nil
+	}
+
+	keyring, err := NewKeyring(current, strings.Split(previous, ",")...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	return keyring, nil
+}
+
+// DevelopmentKeyring is the keyring used locally when the project does not set an encryption key.
+func DevelopmentKeyring() *Keyring {
+	return &Keyring{keys: []*Key{newKey(developmentKey[:])}}
+}
+
+// CurrentKey is the key which encrypts new values.
+func (k *Keyring) CurrentKey() *Key {
+	return k.keys[0]
+}
+
+// EncodedKeys returns the base64 encoded keys, with the current key first, so that they can be given
+// to the functions runtime which reads and writes Secret fields in the same way.
+func (k *Keyring) EncodedKeys() []string {
+	encoded := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		encoded[i] = base64.StdEncoding.EncodeToString(key.material)
+	}
+	return encoded
+}
+
+// Encrypt encrypts the value with a new data key, which is itself encrypted with the current key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, keyLength)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	key := k.CurrentKey()
+
+	wrapped, err := seal(key.material, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s:%s:%s", Prefix, key.Id, base64.RawStdEncoding.EncodeToString(wrapped), base64.RawStdEncoding.EncodeToString(ciphertext)), nil
+}
+
+// Decrypt decrypts a value encrypted with any of the keys. Values which are not encrypted, such as those
+// written before encryption was enabled, are returned unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("the encrypted value is malformed")
+	}
+
+	key := k.findKey(parts[0])
+	if key == nil {
+		return "", ErrUnknownKey
+	}
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.New("the encrypted value is malformed")
+	}
+
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.New("the encrypted value is malformed")
+	}
+
+	dataKey, err := open(key.material, wrapped)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRotation is true if the value is not encrypted with the current key.
+func (k *Keyring) NeedsRotation(value string) bool {
+	return !strings.HasPrefix(value, fmt.Sprintf("%s%s:", Prefix, k.CurrentKey().Id))
+}
+
+func (k *Keyring) findKey(id string) *Key {
+	for _, key := range k.keys {
+		if key.Id == id {
+			return key
+		}
+	}
+	return nil
+}
+
+// IsEncrypted is true if the value has been encrypted by a keyring.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// seal encrypts the data with AES-256-GCM, prepending the random nonce.
+func seal(key []byte, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts data encrypted by seal.
+func open(key []byte, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("the encrypted value is malformed")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("the encrypted value could not be decrypted")
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// WithKeyring sets the keyring which encrypts and decrypts Secret fields.
+func WithKeyring(ctx context.Context, keyring *Keyring) context.Context {
+	return context.WithValue(ctx, keyringContext, keyring)
+}
+
+// GetKeyring returns the keyring in the context, or nil if there is none.
+func GetKeyring(ctx context.Context) *Keyring {
+	keyring, _ := ctx.Value(keyringContext).(*Keyring)
+	return keyring
+}
@@ -0,0 +1,162 @@
+package encryption_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/encryption"
+)
+
+func newKeyring(t *testing.T, previous ...string) (*encryption.Keyring, string) {
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+
+	keyring, err := encryption.NewKeyring(key, previous...)
+	require.NoError(t, err)
+
+	return keyring, key
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+	keyring, _ := newKeyring(t)
+
+	encrypted, err := keyring.Encrypt("sk_live_123")
+	require.NoError(t, err)
+	assert.True(t, encryption.IsEncrypted(encrypted))
+	assert.True(t, strings.HasPrefix(encrypted, encryption.Prefix+keyring.CurrentKey().Id+":"))
+	assert.NotContains(t, encrypted, "sk_live_123")
+
+	decrypted, err := keyring.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "sk_live_123", decrypted)
+
+	// Each value is encrypted with a new data key
+	again, err := keyring.Encrypt("sk_live_123")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again)
+}
+
+func TestDecryptPlaintext(t *testing.T) {
+	t.Parallel()
+	keyring, _ := newKeyring(t)
+
+	decrypted, err := keyring.Decrypt("written before encryption")
+	require.NoError(t, err)
+	assert.Equal(t, "written before encryption", decrypted)
+}
+
+func TestDecryptPreviousKey(t *testing.T) {
+	t.Parallel()
+	oldKeyring, oldKey := newKeyring(t)
+
+	encrypted, err := oldKeyring.Encrypt("hello")
+	require.NoError(t, err)
+
+	keyring, _ := newKeyring(t, oldKey)
+	assert.True(t, keyring.NeedsRotation(encrypted))
+
+	decrypted, err := keyring.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", decrypted)
+
+	rotated, err := keyring.Encrypt(decrypted)
+	require.NoError(t, err)
+	assert.False(t, keyring.NeedsRotation(rotated))
+}
+
+func TestDecryptUnknownKey(t *testing.T) {
+	t.Parallel()
+	oldKeyring, _ := newKeyring(t)
+
+	encrypted, err := oldKeyring.Encrypt("hello")
+	require.NoError(t, err)
+
+	keyring, _ := newKeyring(t)
+	_, err = keyring.Decrypt(encrypted)
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+}
+
+func TestDecryptTampered(t *testing.T) {
+	t.Parallel()
+	keyring, _ := newKeyring(t)
+
+	encrypted, err := keyring.Encrypt("hello")
+	require.NoError(t, err)
+
+	// Change a character of the encrypted value, which is authenticated
+	tampered := []byte(encrypted)
+	i := len(tampered) - 5
+	if tampered[i] == 'A' {
+		tampered[i] = 'B'
+	} else {
+		tampered[i] = 'A'
+	}
+
+	_, err = keyring.Decrypt(string(tampered))
+	assert.EqualError(t, err, "the encrypted value could not be decrypted")
+}
+
+func TestNeedsRotationPlaintext(t *testing.T) {
+	t.Parallel()
+	keyring, _ := newKeyring(t)
+	assert.True(t, keyring.NeedsRotation("written before encryption"))
+}
+
+func TestParseKeyInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := encryption.ParseKey("not base64!")
+	assert.EqualError(t, err, "an encryption key must be base64 encoded")
+
+	_, err = encryption.ParseKey("c2hvcnQ=")
+	assert.EqualError(t, err, "an encryption key must be 32 bytes, but it is 5 bytes")
+}
+
+func TestLoadKeyring(t *testing.T) {
+	oldKeyring, oldKey := newKeyring(t)
+	_, key := newKeyring(t)
+
+	keyring, err := encryption.LoadKeyring(map[string]string{})
+	require.NoError(t, err)
+	assert.Nil(t, keyring)
+
+	keyring, err = encryption.LoadKeyring(map[string]string{
+		encryption.SecretKey:          key,
+		encryption.SecretPreviousKeys: oldKey,
+	})
+	require.NoError(t, err)
+
+	encrypted, err := oldKeyring.Encrypt("hello")
+	require.NoError(t, err)
+	decrypted, err := keyring.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", decrypted)
+
+	t.Setenv(encryption.EnvKey, key)
+	keyring, err = encryption.LoadKeyring(map[string]string{})
+	require.NoError(t, err)
+	require.NotNil(t, keyring)
+	assert.True(t, keyring.NeedsRotation(encrypted))
+}
+
+func TestEncodedKeys(t *testing.T) {
+	_, oldKey := newKeyring(t)
+	_, key := newKeyring(t)
+
+	keyring, err := encryption.NewKeyring(key, oldKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{key, oldKey}, keyring.EncodedKeys())
+
+	// The encoded keys create the same keyring
+	encrypted, err := keyring.Encrypt("hello")
+	require.NoError(t, err)
+
+	other, err := encryption.NewKeyring(keyring.EncodedKeys()[0], keyring.EncodedKeys()[1:]...)
+	require.NoError(t, err)
+	decrypted, err := other.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", decrypted)
+}
@@ -0,0 +1,95 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+)
+
+// The number of rows which are read and re-encrypted in each transaction
+const rotateBatchSize = 500
+
+// Rotate re-encrypts the values of every Secret field which are not encrypted with the current key of the
+// keyring, including values written before encryption was enabled, and returns how many were re-encrypted.
+// Values encrypted with a key which is not in the keyring cannot be rotated and so fail the rotation.
+func Rotate(ctx context.Context, database db.Database, schema *proto.Schema, keyring *Keyring) (int, error) {
+	rotated := 0
+
+	for _, model := range schema.Models {
+		for _, field := range model.SecretFields() {
+			n, err := rotateField(ctx, database, model, field, keyring)
+			rotated += n
+			if err != nil {
+				return rotated, fmt.Errorf("rotating %s.%s: %w", model.Name, field.Name, err)
+			}
+		}
+	}
+
+	return rotated, nil
+}
+
+func rotateField(ctx context.Context, database db.Database, model *proto.Model, field *proto.Field, keyring *Keyring) (int, error) {
+	table := db.QuoteIdentifier(casing.ToSnake(model.Name))
+	column := db.QuoteIdentifier(casing.ToSnake(field.Name))
+
+	rotated := 0
+	lastId := ""
+
+	for {
+		done := false
+		batchRotated := 0
+
+		err := database.Transaction(ctx, func(ctx context.Context) error {
+			sql := fmt.Sprintf("SELECT id, %s AS value FROM %s WHERE %s IS NOT NULL AND id > ? ORDER BY id LIMIT ?", column, table, column)
+			result, err := database.ExecuteQuery(ctx, sql, lastId, rotateBatchSize)
+			if err != nil {
+				return err
+			}
+
+			done = len(result.Rows) < rotateBatchSize
+
+			for _, row := range result.Rows {
+				id, _ := row["id"].(string)
+				value, _ := row["value"].(string)
+				lastId = id
+
+				if !keyring.NeedsRotation(value) {
+					continue
+				}
+
+				plaintext, err := keyring.Decrypt(value)
+				if err != nil {
+					return fmt.Errorf("decrypting row %s: %w", id, err)
+				}
+
+				encrypted, err := keyring.Encrypt(plaintext)
+				if err != nil {
+					return err
+				}
+
+				// Only replace the value if it has not been changed since it was read
+				sql = fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ? AND %s = ?", table, column, column)
+				_, err = database.ExecuteStatement(ctx, sql, encrypted, id, value)
+				if err != nil {
+					return err
+				}
+
+				batchRotated++
+			}
+
+			return nil
+		})
+		if err != nil {
+			return rotated, err
+		}
+
+		rotated += batchRotated
+
+		if done {
+			return rotated, nil
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/karlseguin/typed"
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// The table where events are kept until they have been delivered to each subscriber
+const DeliveryTableName = "keel_event_delivery"
+
+// Delivery statuses
+const (
+	// The event is yet to be delivered, or has failed and will be retried
+	DeliveryPending = "pending"
+	// The subscriber handled the event successfully
+	DeliveryDelivered = "delivered"
+	// Every attempt to deliver the event failed, and it will not be retried
+	DeliveryDead = "dead"
+)
+
+const (
+	// How long a delivery is leased to the process attempting it. If the process dies during the
+	// attempt, then the delivery will be retried once the lease has expired.
+	deliveryLease = 5 * time.Minute
+	// The delay before the first retry, which doubles with each failed attempt
+	initialBackoff = 10 * time.Second
+	// The longest delay between attempts
+	maxBackoff = time.Hour
+)
+
+// Delivery is an event which is to be delivered to a single subscriber
+type Delivery struct {
+	Id          string
+	Subscriber  string
+	Event       *Event
+	Traceparent string
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+}
+
+type configContextKey string
+
+var eventsConfigKey configContextKey = "eventsConfig"
+
+// WithConfig sets the config used when delivering events, such as the number of attempts
+func WithConfig(ctx context.Context, cfg *config.EventsConfig) context.Context {
+	return context.WithValue(ctx, eventsConfigKey, cfg)
+}
+
+func getConfig(ctx context.Context) *config.EventsConfig {
+	cfg, ok := ctx.Value(eventsConfigKey).(*config.EventsConfig)
+	if !ok || cfg == nil {
+		return &config.EventsConfig{}
+	}
+	return cfg
+}
+
+// backoff is the delay before the next attempt, given the number of attempts already made
+func backoff(attempts int) time.Duration {
+	delay := float64(initialBackoff) * math.Pow(2, float64(attempts-1))
+	if delay > float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// enqueue persists the deliveries, leased to this process as they are attempted straight away.
+func enqueue(ctx context.Context, database db.Database, deliveries []*Delivery) error {
+	nextAttemptAt := time.Now().UTC().Add(deliveryLease)
+
+	for _, d := range deliveries {
+		d.Id = ksuid.New().String()
+		d.Status = DeliveryPending
+		d.Attempts = 1
+
+		event, err := json.Marshal(d.Event)
+		if err != nil {
+			return err
+		}
+
+		sql := fmt.Sprintf(`INSERT INTO %s (id, subscriber, event, traceparent, status, attempts, max_attempts, next_attempt_at) VALUES (?, ?, ?::jsonb, ?, ?, ?, ?, ?)`, DeliveryTableName)
+
+		_, err = database.ExecuteStatement(ctx, sql, d.Id, d.Subscriber, string(event), d.Traceparent, d.Status, d.Attempts, d.MaxAttempts, nextAttemptAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver calls the handler or webhook for the delivery and records the outcome. If the handler fails then the
+// delivery is retried with exponential backoff, until there are no attempts remaining, at which point
+// the delivery is dead-lettered.
+func deliver(ctx context.Context, database db.Database, handler EventHandler, d *Delivery) error {
+	ctx, span := tracer.Start(ctx, "Deliver event")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event.delivery.id", d.Id),
+		attribute.String("event.name", d.Event.EventName),
+		attribute.String("event.subscriber", d.Subscriber),
+		attribute.Int("event.delivery.attempt", d.Attempts),
+	)
+
+	handlerErr := attemptDelivery(ctx, database, handler, d)
+
+	var err error
+	switch {
+	case handlerErr == nil:
+		d.Status = DeliveryDelivered
+		d.LastError = ""
+		sql := fmt.Sprintf("UPDATE %s SET status = ?, last_error = NULL, delivered_at = now(), updated_at = now() WHERE id = ?", DeliveryTableName)
+		_, err = database.ExecuteStatement(ctx, sql, d.Status, d.Id)
+	case d.Attempts >= d.MaxAttempts:
+		d.Status = DeliveryDead
+		d.LastError = handlerErr.Error()
+		err = deadLetter(ctx, database, d.Id, handlerErr)
+		span.AddEvent("dead-lettered")
+	default:
+		d.Status = DeliveryPending
+		d.LastError = handlerErr.Error()
+		sql := fmt.Sprintf("UPDATE %s SET status = ?, last_error = ?, next_attempt_at = ?, updated_at = now() WHERE id = ?", DeliveryTableName)
+		_, err = database.ExecuteStatement(ctx, sql, d.Status, d.LastError, time.Now().UTC().Add(backoff(d.Attempts)), d.Id)
+	}
+
+	if handlerErr != nil {
+		span.RecordError(handlerErr)
+		span.SetStatus(codes.Error, handlerErr.Error())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return handlerErr
+}
+
+// DeliverPending attempts the deliveries which are due, up to the limit, and returns how many were attempted.
+// Failures of the handler are recorded against each delivery rather than returned. Deliveries are leased
+// whilst they are attempted so that multiple processes can deliver events concurrently.
+func DeliverPending(ctx context.Context, handler EventHandler, limit int) (int, error) {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+
+	sql := fmt.Sprintf(`
+		UPDATE %[1]s SET attempts = attempts + 1, next_attempt_at = ?, updated_at = now()
+		WHERE id IN (
+			SELECT id FROM %[1]s
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, DeliveryTableName)
+
+	result, err := database.ExecuteQuery(ctx, sql, now.Add(deliveryLease), DeliveryPending, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range result.Rows {
+		d, err := deliveryFromRow(row)
+		if err != nil {
+			// A delivery which can't be parsed will never succeed, so it shouldn't hold up the rest of the batch
+			err = deadLetter(ctx, database, typed.New(row).String("id"), err)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		// Failures are recorded on the delivery to be retried
+		_ = deliver(ctx, database, handler, d)
+	}
+
+	return len(result.Rows), nil
+}
+
+// deadLetter marks the delivery as dead so that it is not attempted again.
+func deadLetter(ctx context.Context, database db.Database, id string, cause error) error {
+	sql := fmt.Sprintf("UPDATE %s SET status = ?, last_error = ?, updated_at = now() WHERE id = ?", DeliveryTableName)
+	_, err := database.ExecuteStatement(ctx, sql, DeliveryDead, cause.Error(), id)
+	return err
+}
+
+func deliveryFromRow(row map[string]any) (*Delivery, error) {
+	r := typed.New(row)
+
+	var event []byte
+	switch v := row["event"].(type) {
+	case string:
+		event = []byte(v)
+	case []byte:
+		event = v
+	default:
+		var err error
+		event, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d := &Delivery{
+		Id:          r.String("id"),
+		Subscriber:  r.String("subscriber"),
+		Traceparent: r.String("traceparent"),
+		Status:      r.String("status"),
+		Attempts:    r.Int("attempts"),
+		MaxAttempts: r.Int("max_attempts"),
+		LastError:   r.String("last_error"),
+	}
+
+	err := json.Unmarshal(event, &d.Event)
+	if err != nil {
+		return nil, fmt.Errorf("parsing event of delivery %s: %w", d.Id, err)
+	}
+
+	return d, nil
+}
+
+// Worker delivers pending events to subscribers, including the retries of failed deliveries.
+type Worker struct {
+	handler EventHandler
+	// How often to check for pending deliveries
+	Interval time.Duration
+	// The most deliveries which are attempted at a time
+	BatchSize int
+}
+
+func NewWorker(handler EventHandler) *Worker {
+	return &Worker{
+		handler:   handler,
+		Interval:  5 * time.Second,
+		BatchSize: 50,
+	}
+}
+
+// Run drains pending deliveries until the context is cancelled. The context must have a database.
+func (w *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// Keep going while there are full batches
+			for {
+				n, err := DeliverPending(ctx, w.handler, w.BatchSize)
+				if err != nil {
+					return err
+				}
+				if n < w.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/karlseguin/typed"
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/schema/parser"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The table where user-defined events are kept from when they are emitted until they are sent
+const EventTableName = "keel_event"
+
+// Emit records a user-defined event, such as order.shipped, to be sent to its subscribers once the
+// action has completed. The event is written with the trace id of the action so that, like model events,
+// it is only sent if the action's transaction is committed.
+func Emit(ctx context.Context, eventName string, data map[string]any) error {
+	// If no event handler has been configured, then the event could never be sent.
+	if !HasEventHandler(ctx) {
+		return nil
+	}
+
+	spanContext := trace.SpanFromContext(ctx).SpanContext()
+	if !spanContext.IsValid() {
+		return errors.New("valid spanContext expected")
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	var identityId *string
+	if auth.IsAuthenticated(ctx) {
+		identity, err := auth.GetIdentity(ctx)
+		if err != nil {
+			return err
+		}
+
+		id := identity[parser.FieldNameId].(string)
+		identityId = &id
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (id, name, data, identity_id, trace_id) VALUES (?, ?, ?::jsonb, ?, ?)", EventTableName)
+	_, err = database.ExecuteStatement(ctx, sql, ksuid.New().String(), eventName, string(payload), identityId, spanContext.TraceID().String())
+
+	return err
+}
+
+// processEmittedEvents marks the events emitted within the trace as processed, and then returns them.
+func processEmittedEvents(ctx context.Context, database db.Database, traceId string) ([]*Event, error) {
+	sql := fmt.Sprintf("UPDATE %s SET processed_at = now() WHERE trace_id = ? AND processed_at IS NULL RETURNING *", EventTableName)
+
+	result, err := database.ExecuteQuery(ctx, sql, traceId)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*Event{}
+	for _, row := range result.Rows {
+		event, err := eventFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func eventFromRow(row map[string]any) (*Event, error) {
+	r := typed.New(row)
+
+	name := r.String("name")
+	if name == "" {
+		return nil, errors.New("emitted event has no name")
+	}
+
+	createdAt, ok := r.TimeIf("created_at")
+	if !ok {
+		return nil, fmt.Errorf("emitted event '%s' has no created_at", name)
+	}
+
+	var raw []byte
+	switch v := row["data"].(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		var err error
+		raw, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := map[string]any{}
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		EventName:  name,
+		OccurredAt: createdAt.UTC(),
+		IdentityId: r.String("identity_id"),
+		Data:       data,
+	}, nil
+}
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/iancoleman/strcase"
+	"github.com/sirupsen/logrus"
 	"github.com/teamkeel/keel/auditing"
 	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/db"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/auth"
 	"github.com/teamkeel/keel/schema/parser"
@@ -18,6 +20,8 @@ import (
 	"go.opentelemetry.io/otel/trace/noop"
 )
 
+var tracer = otel.Tracer("github.com/teamkeel/keel/events")
+
 // Event names
 const (
 	Created = "created"
@@ -32,8 +36,10 @@ type Event struct {
 	OccurredAt time.Time `json:"occurredAt"`
 	// The identity that resulted in the triggered events.
 	IdentityId string `json:"identityId,omitempty"`
-	// The target impacted by this event.
-	Target *EventTarget `json:"target"`
+	// The target impacted by this event, for events generated by model actions.
+	Target *EventTarget `json:"target,omitempty"`
+	// The payload of a user-defined event.
+	Data map[string]any `json:"data,omitempty"`
 }
 
 type EventTarget struct {
@@ -77,8 +83,10 @@ func GetEventHandler(ctx context.Context) (EventHandler, error) {
 }
 
 // SendEvents will gather, create and send events which have occurred within the scope of this context.
-// It achieves this by inspecting the keel_audit table for rows which must be generated into events,
-// updates the event_processed_at field on these rows, and then calls the event handler for each event.
+// It achieves this by inspecting the keel_audit table for rows which must be generated into events and the
+// keel_event table for user-defined events which have been emitted, marks these rows as processed, and
+// persists a delivery for each subscriber of each event in the same transaction. The event handler is then
+// called for each delivery straight away, and any which fail are retried later by the Worker.
 func SendEvents(ctx context.Context, schema *proto.Schema) error {
 	// If no event handler has been configured, then no events can be sent.
 	if !HasEventHandler(ctx) {
@@ -105,8 +113,14 @@ func SendEvents(ctx context.Context, schema *proto.Schema) error {
 		return err
 	}
 
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
 	traceparent := util.GetTraceparent(spanContext)
 	traceId := spanContext.TraceID().String()
+	cfg := getConfig(ctx)
 
 	identityId := ""
 	if auth.IsAuthenticated(ctx) {
@@ -118,42 +132,34 @@ func SendEvents(ctx context.Context, schema *proto.Schema) error {
 		identityId = identity[parser.FieldNameId].(string)
 	}
 
-	auditLogs, err := auditing.ProcessEventsFromAuditTrail(ctx, schema, traceId)
-	if err != nil {
-		return err
-	}
+	deliveries := []*Delivery{}
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		events := []*Event{}
 
-	var handlerErrors error
-	for _, log := range auditLogs {
-		eventName, err := eventNameFromAudit(log.TableName, log.Op)
+		auditLogs, err := auditing.ProcessEventsFromAuditTrail(ctx, schema, traceId)
 		if err != nil {
 			return err
 		}
 
-		protoEvent := proto.FindEvent(schema.Events, eventName)
-		if protoEvent == nil {
-			return fmt.Errorf("event '%s' does not exist", eventName)
-		}
-
-		subscribers := schema.FindEventSubscribers(protoEvent)
-		if len(subscribers) == 0 {
-			return fmt.Errorf("event '%s' must have at least one subscriber", eventName)
-		}
-
-		var previous map[string]any
-		if log.Op != auditing.Insert {
-			p, err := auditing.Previous(ctx, log)
+		for _, log := range auditLogs {
+			eventName, err := eventNameFromAudit(log.TableName, log.Op)
 			if err != nil {
 				return err
 			}
 
-			if p != nil {
-				previous = p.Data
+			var previous map[string]any
+			if log.Op != auditing.Insert {
+				p, err := auditing.Previous(ctx, log)
+				if err != nil {
+					return err
+				}
+
+				if p != nil {
+					previous = p.Data
+				}
 			}
-		}
 
-		for _, subscriber := range subscribers {
-			event := &Event{
+			events = append(events, &Event{
 				EventName:  eventName,
 				OccurredAt: time.Now().UTC(),
 				IdentityId: identityId,
@@ -163,22 +169,79 @@ func SendEvents(ctx context.Context, schema *proto.Schema) error {
 					Data:         toLowerCamelMap(log.Data),
 					PreviousData: toLowerCamelMap(previous),
 				},
-			}
+			})
+		}
 
-			err = handler(ctx, subscriber.Name, event, traceparent)
+		if len(schema.UserDefinedEvents()) > 0 {
+			emitted, err := processEmittedEvents(ctx, database, traceId)
 			if err != nil {
-				// We do not error yet when the event handler fails
-				handlerErrors = errors.Join(handlerErrors, err)
-			} else {
-				// For successfully fired events
-				span.AddEvent(eventName)
+				return err
 			}
+			events = append(events, emitted...)
+		}
+
+		for _, event := range events {
+			// An event which can't be delivered, such as one emitted from a function with a name which isn't
+			// in the schema, is skipped so that it doesn't prevent the other events from being sent.
+			protoEvent := proto.FindEvent(schema.Events, event.EventName)
+			if protoEvent == nil {
+				skipEvent(span, event, fmt.Errorf("event '%s' does not exist", event.EventName))
+				continue
+			}
+
+			subscribers := schema.FindEventSubscribers(protoEvent)
+			webhooks := cfg.EventWebhooks(event.EventName)
+			if len(subscribers) == 0 && len(webhooks) == 0 {
+				skipEvent(span, event, fmt.Errorf("event '%s' has no subscribers or webhooks", event.EventName))
+				continue
+			}
+
+			for _, subscriber := range subscribers {
+				deliveries = append(deliveries, &Delivery{
+					Subscriber:  subscriber.Name,
+					Traceparent: traceparent,
+					MaxAttempts: cfg.SubscriberMaxAttempts(subscriber.Name),
+					Event:       event,
+				})
+			}
+
+			for _, webhook := range webhooks {
+				deliveries = append(deliveries, &Delivery{
+					Subscriber:  webhookSubscriberName(webhook),
+					Traceparent: traceparent,
+					MaxAttempts: cfg.WebhookMaxAttempts(webhook.Name),
+					Event:       event,
+				})
+			}
+		}
+
+		return enqueue(ctx, database, deliveries)
+	})
+	if err != nil {
+		return err
+	}
+
+	var handlerErrors error
+	for _, d := range deliveries {
+		err = deliver(ctx, database, handler, d)
+		if err != nil {
+			// We do not error yet when the event handler fails
+			handlerErrors = errors.Join(handlerErrors, err)
+		} else {
+			// For successfully fired events
+			span.AddEvent(d.Event.EventName)
 		}
 	}
 
 	return handlerErrors
 }
 
+// skipEvent records why an event was not sent to any subscribers.
+func skipEvent(span trace.Span, event *Event, err error) {
+	span.RecordError(err)
+	logrus.WithField("event", event.EventName).Warnf("skipping event: %s", err.Error())
+}
+
 // eventNameFromAudit generates an event name from audit table columns.
 func eventNameFromAudit(tableName string, op string) (string, error) {
 	var action string
@@ -1,10 +1,14 @@
 package events
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/teamkeel/keel/auditing"
+	"github.com/teamkeel/keel/db"
 )
 
 func TestEventNameFromInsertAudit(t *testing.T) {
@@ -30,3 +34,126 @@ func TestEventNameFromUnknown(t *testing.T) {
 	require.Empty(t, eventName)
 	require.Error(t, err)
 }
+
+func TestDeliveryBackoff(t *testing.T) {
+	require.Equal(t, 10*time.Second, backoff(1))
+	require.Equal(t, 20*time.Second, backoff(2))
+	require.Equal(t, 40*time.Second, backoff(3))
+	require.Equal(t, time.Hour, backoff(20))
+}
+
+func TestDeliveryFromRow(t *testing.T) {
+	d, err := deliveryFromRow(map[string]any{
+		"id":           "2a9Wx7ydNlfmtcvPe1lQ2NFmJh0",
+		"subscriber":   "sendWelcomeEmail",
+		"event":        `{"eventName":"member.created","occurredAt":"2024-01-01T00:00:00Z","identityId":"","target":{"id":"123","type":"Member","data":{"id":"123"}}}`,
+		"traceparent":  "00-71f835dc7ac2750bed2135c7b30dc7fe-b4c9e2a6a0d84702-01",
+		"status":       DeliveryPending,
+		"attempts":     int64(2),
+		"max_attempts": int32(5),
+		"last_error":   nil,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "sendWelcomeEmail", d.Subscriber)
+	require.Equal(t, 2, d.Attempts)
+	require.Equal(t, 5, d.MaxAttempts)
+	require.Equal(t, "member.created", d.Event.EventName)
+	require.Equal(t, "Member", d.Event.Target.Type)
+}
+
+func TestDeliverRetriesUntilDeadLettered(t *testing.T) {
+	database := &recordingDatabase{}
+	handler := func(ctx context.Context, subscriber string, event *Event, traceparent string) error {
+		return errors.New("something went wrong")
+	}
+
+	d := &Delivery{
+		Id:          "2a9Wx7ydNlfmtcvPe1lQ2NFmJh0",
+		Subscriber:  "sendWelcomeEmail",
+		Event:       &Event{EventName: "member.created"},
+		MaxAttempts: 3,
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		d.Attempts = attempt
+		err := deliver(context.Background(), database, handler, d)
+		require.EqualError(t, err, "something went wrong")
+	}
+
+	require.Len(t, database.statements, 3)
+
+	// The first attempts are retried after the backoff
+	for _, args := range database.statements[:2] {
+		require.Equal(t, DeliveryPending, args[0])
+		require.Equal(t, "something went wrong", args[1])
+		require.IsType(t, time.Time{}, args[2])
+		require.Equal(t, d.Id, args[3])
+	}
+
+	// ... and the last attempt is dead-lettered
+	require.Equal(t, []any{DeliveryDead, "something went wrong", d.Id}, database.statements[2])
+	require.Equal(t, DeliveryDead, d.Status)
+	require.Equal(t, "something went wrong", d.LastError)
+}
+
+func TestDeliverPendingDeadLettersUnparseableDeliveries(t *testing.T) {
+	database := &recordingDatabase{
+		rows: []map[string]any{
+			{
+				"id":           "2a9Wx7ydNlfmtcvPe1lQ2NFmJh0",
+				"subscriber":   "sendWelcomeEmail",
+				"event":        `not an event`,
+				"status":       DeliveryPending,
+				"attempts":     int64(1),
+				"max_attempts": int32(5),
+			},
+			{
+				"id":           "2a9Wx8B0bG3S5m9TLb7i4cQJYhZ",
+				"subscriber":   "sendWelcomeEmail",
+				"event":        `{"eventName":"member.created","occurredAt":"2024-01-01T00:00:00Z","target":{"id":"123","type":"Member","data":{"id":"123"}}}`,
+				"status":       DeliveryPending,
+				"attempts":     int64(1),
+				"max_attempts": int32(5),
+			},
+		},
+	}
+
+	handled := []string{}
+	handler := func(ctx context.Context, subscriber string, event *Event, traceparent string) error {
+		handled = append(handled, event.Target.Id)
+		return nil
+	}
+
+	n, err := DeliverPending(db.WithDatabase(context.Background(), database), handler, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	// The delivery after the unparseable one is still delivered
+	require.Equal(t, []string{"123"}, handled)
+
+	require.Len(t, database.statements, 2)
+	require.Equal(t, DeliveryDead, database.statements[0][0])
+	require.Contains(t, database.statements[0][1], "parsing event of delivery 2a9Wx7ydNlfmtcvPe1lQ2NFmJh0")
+	require.Equal(t, "2a9Wx7ydNlfmtcvPe1lQ2NFmJh0", database.statements[0][2])
+	require.Equal(t, []any{DeliveryDelivered, "2a9Wx8B0bG3S5m9TLb7i4cQJYhZ"}, database.statements[1])
+}
+
+func TestEmittedEventFromRow(t *testing.T) {
+	occurredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	e, err := eventFromRow(map[string]any{
+		"id":           "2a9Wx7ydNlfmtcvPe1lQ2NFmJh0",
+		"name":         "order.shipped",
+		"data":         `{"reference":"ABC123"}`,
+		"identity_id":  "2a9Wx7ydNlfmtcvPe1lQ2NFmJh1",
+		"trace_id":     "71f835dc7ac2750bed2135c7b30dc7fe",
+		"created_at":   occurredAt,
+		"processed_at": nil,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "order.shipped", e.EventName)
+	require.Equal(t, occurredAt, e.OccurredAt)
+	require.Equal(t, "2a9Wx7ydNlfmtcvPe1lQ2NFmJh1", e.IdentityId)
+	require.Equal(t, map[string]any{"reference": "ABC123"}, e.Data)
+	require.Nil(t, e.Target)
+}
@@ -0,0 +1,145 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// The table where the result of each request to a webhook is recorded
+const WebhookAttemptTableName = "keel_webhook_attempt"
+
+// Deliveries to webhooks have a subscriber name with this prefix followed by the webhook name
+const WebhookSubscriberPrefix = "webhook:"
+
+// Headers sent with each request to a webhook
+const (
+	// The HMAC-SHA256 signature of the timestamp and body, in the form sha256=<hex>
+	WebhookSignatureHeader = "Keel-Signature"
+	// The unix time in seconds at which the request was signed
+	WebhookTimestampHeader = "Keel-Timestamp"
+	// The same for every attempt of a delivery, so that receivers can ignore repeated events
+	WebhookIdempotencyKeyHeader = "Idempotency-Key"
+	// The name of the event, such as order.created
+	WebhookEventHeader = "Keel-Event"
+)
+
+const (
+	webhookTimeout = 10 * time.Second
+	// The most of a response body which is recorded
+	webhookMaxResponseBody = 1024
+)
+
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// SignWebhook generates the signature for a webhook request. Receivers should compute the same
+// signature with their copy of the secret and compare it to the Keel-Signature header.
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookSubscriberName(webhook *config.WebhookConfig) string {
+	return WebhookSubscriberPrefix + webhook.Name
+}
+
+// sendWebhook posts the event to the webhook and records the result of the attempt.
+func sendWebhook(ctx context.Context, database db.Database, d *Delivery) error {
+	name := strings.TrimPrefix(d.Subscriber, WebhookSubscriberPrefix)
+
+	webhook := getConfig(ctx).FindWebhook(name)
+	if webhook == nil {
+		return fmt.Errorf("webhook '%s' is not configured", name)
+	}
+
+	secret, err := runtimectx.GetSecret(ctx, webhook.GetSecretName())
+	if err != nil || secret == "" {
+		return fmt.Errorf("secret %s has not been set for webhook '%s'", webhook.GetSecretName(), name)
+	}
+
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, timestamp, body))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookIdempotencyKeyHeader, d.Id)
+	req.Header.Set(WebhookEventHeader, d.Event.EventName)
+	if d.Traceparent != "" {
+		req.Header.Set("traceparent", d.Traceparent)
+	}
+
+	start := time.Now()
+	res, sendErr := webhookClient.Do(req)
+	duration := time.Since(start)
+
+	var statusCode *int
+	var responseBody *string
+	if sendErr == nil {
+		defer res.Body.Close()
+
+		statusCode = &res.StatusCode
+		b, _ := io.ReadAll(io.LimitReader(res.Body, webhookMaxResponseBody))
+		s := string(b)
+		responseBody = &s
+
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			sendErr = fmt.Errorf("webhook '%s' responded with status %d", name, res.StatusCode)
+		}
+	}
+
+	var errorMessage *string
+	if sendErr != nil {
+		s := sendErr.Error()
+		errorMessage = &s
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (id, delivery_id, webhook, url, status_code, response_body, error, duration_ms) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", WebhookAttemptTableName)
+	_, err = database.ExecuteStatement(ctx, sql, ksuid.New().String(), d.Id, name, webhook.Url, statusCode, responseBody, errorMessage, duration.Milliseconds())
+	if err != nil {
+		return err
+	}
+
+	return sendErr
+}
+
+// attemptDelivery hands the event to the webhook or subscriber function of the delivery.
+func attemptDelivery(ctx context.Context, database db.Database, handler EventHandler, d *Delivery) error {
+	if strings.HasPrefix(d.Subscriber, WebhookSubscriberPrefix) {
+		ctx, span := tracer.Start(ctx, "Send webhook")
+		defer span.End()
+
+		span.SetAttributes(attribute.String("webhook.name", strings.TrimPrefix(d.Subscriber, WebhookSubscriberPrefix)))
+
+		return sendWebhook(ctx, database, d)
+	}
+
+	return handler(ctx, d.Subscriber, d.Event, d.Traceparent)
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"gorm.io/gorm"
+)
+
+// recordingDatabase captures the arguments of each statement rather than executing them, and
+// returns the given rows from every query
+type recordingDatabase struct {
+	statements [][]any
+	rows       []map[string]any
+}
+
+func (r *recordingDatabase) ExecuteQuery(ctx context.Context, sql string, args ...any) (*db.ExecuteQueryResult, error) {
+	return &db.ExecuteQueryResult{Rows: r.rows}, nil
+}
+
+func (r *recordingDatabase) ExecuteStatement(ctx context.Context, sql string, args ...any) (*db.ExecuteStatementResult, error) {
+	r.statements = append(r.statements, args)
+	return &db.ExecuteStatementResult{RowsAffected: 1}, nil
+}
+
+func (r *recordingDatabase) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *recordingDatabase) Close() error { return nil }
+
+func (r *recordingDatabase) GetDB() *gorm.DB { return nil }
+
+func webhookContext(url string) context.Context {
+	ctx := context.Background()
+	ctx = runtimectx.WithSecrets(ctx, map[string]string{"WEBHOOK_SECRET_ORDERS": "shhh"})
+	ctx = WithConfig(ctx, &config.EventsConfig{
+		Webhooks: []config.WebhookConfig{
+			{Name: "orders", Url: url, Events: []string{"order.created"}},
+		},
+	})
+	return ctx
+}
+
+func TestSignWebhook(t *testing.T) {
+	require.Equal(t, "sha256=75f9e988deeac03f98f65f0dee3cbed1e0f8cedb83b09ac17f14a5bd26f31241", SignWebhook("shhh", "1700000000", []byte(`{}`)))
+}
+
+func TestSendWebhook(t *testing.T) {
+	var headers http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	database := &recordingDatabase{}
+	d := &Delivery{
+		Id:          "2a9Wx7ydNlfmtcvPe1lQ2NFmJh0",
+		Subscriber:  "webhook:orders",
+		Traceparent: "00-71f835dc7ac2750bed2135c7b30dc7fe-b4c9e2a6a0d84702-01",
+		Event:       &Event{EventName: "order.created", Target: &EventTarget{Id: "123", Type: "Order"}},
+	}
+
+	err := attemptDelivery(webhookContext(server.URL), database, nil, d)
+	require.NoError(t, err)
+
+	require.Equal(t, "application/json", headers.Get("Content-Type"))
+	require.Equal(t, d.Id, headers.Get(WebhookIdempotencyKeyHeader))
+	require.Equal(t, "order.created", headers.Get(WebhookEventHeader))
+	require.Equal(t, d.Traceparent, headers.Get("traceparent"))
+	require.Equal(t, SignWebhook("shhh", headers.Get(WebhookTimestampHeader), body), headers.Get(WebhookSignatureHeader))
+
+	var event Event
+	require.NoError(t, json.Unmarshal(body, &event))
+	require.Equal(t, "order.created", event.EventName)
+	require.Equal(t, "123", event.Target.Id)
+
+	// The result of the attempt is recorded
+	require.Len(t, database.statements, 1)
+	require.Equal(t, d.Id, database.statements[0][1])
+	require.Equal(t, "orders", database.statements[0][2])
+	require.Equal(t, http.StatusNoContent, *database.statements[0][4].(*int))
+	require.Nil(t, database.statements[0][6])
+}
+
+func TestSendWebhookFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("oops"))
+	}))
+	defer server.Close()
+
+	database := &recordingDatabase{}
+	d := &Delivery{
+		Id:         "2a9Wx7ydNlfmtcvPe1lQ2NFmJh0",
+		Subscriber: "webhook:orders",
+		Event:      &Event{EventName: "order.created"},
+	}
+
+	err := attemptDelivery(webhookContext(server.URL), database, nil, d)
+	require.EqualError(t, err, "webhook 'orders' responded with status 500")
+
+	require.Len(t, database.statements, 1)
+	require.Equal(t, http.StatusInternalServerError, *database.statements[0][4].(*int))
+	require.Equal(t, "oops", *database.statements[0][5].(*string))
+	require.Equal(t, "webhook 'orders' responded with status 500", *database.statements[0][6].(*string))
+}
+
+func TestSendWebhookMissingSecret(t *testing.T) {
+	ctx := WithConfig(runtimectx.WithSecrets(context.Background(), map[string]string{}), &config.EventsConfig{
+		Webhooks: []config.WebhookConfig{{Name: "orders", Url: "http://localhost", Events: []string{"order.created"}}},
+	})
+
+	err := attemptDelivery(ctx, &recordingDatabase{}, nil, &Delivery{Subscriber: "webhook:orders", Event: &Event{}})
+	require.EqualError(t, err, "secret WEBHOOK_SECRET_ORDERS has not been set for webhook 'orders'")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/iancoleman/strcase"
 	"github.com/segmentio/ksuid"
 	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/encryption"
 	"github.com/teamkeel/keel/events"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/auth"
@@ -152,6 +153,7 @@ func CallFunction(ctx context.Context, actionName string, body any, permissionSt
 		"headers":         requestHeaders,
 		"identity":        identity,
 		"secrets":         secrets,
+		"encryptionKeys":  encryptionKeys(ctx),
 		"tracing":         tracingContext,
 		"permissionState": permissionState,
 	}
@@ -211,6 +213,7 @@ func CallJob(ctx context.Context, job *proto.Job, inputs map[string]any, permiss
 	meta := map[string]any{
 		"identity":        identity,
 		"secrets":         secrets,
+		"encryptionKeys":  encryptionKeys(ctx),
 		"tracing":         tracingContext,
 		"permissionState": permissionState,
 		"triggerType":     trigger,
@@ -260,8 +263,9 @@ func CallSubscriber(ctx context.Context, subscriber *proto.Subscriber, event *ev
 	otel.GetTextMapPropagator().Inject(ctx, tracingContext)
 
 	meta := map[string]any{
-		"secrets": secrets,
-		"tracing": tracingContext,
+		"secrets":        secrets,
+		"encryptionKeys": encryptionKeys(ctx),
+		"tracing":        tracingContext,
 	}
 
 	req := &FunctionsRuntimeRequest{
@@ -337,3 +341,12 @@ func toRuntimeError(errorResponse *FunctionsRuntimeError) error {
 		}
 	}
 }
+
+// encryptionKeys returns the keys which the functions runtime uses to encrypt and decrypt Secret fields.
+func encryptionKeys(ctx context.Context) []string {
+	keyring := encryption.GetKeyring(ctx)
+	if keyring == nil {
+		return nil
+	}
+	return keyring.EncodedKeys()
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"fmt"
+
+	"github.com/teamkeel/keel/config"
+)
+
+// NewClient returns the EmailClient for the project's email config. If no provider has been configured
+// then emails are sent by SMTP if the KEEL_SMTP_* environment variables are set, otherwise they are not sent.
+func NewClient(cfg *config.ProjectConfig, secrets map[string]string, projectDir string) (EmailClient, error) {
+	var emailConfig config.EmailConfig
+	if cfg != nil {
+		emailConfig = cfg.Email
+	}
+
+	var client EmailClient
+	switch emailConfig.Provider {
+	case config.SmtpEmail:
+		username := secrets[config.EmailSmtpUsernameSecret]
+		password := secrets[config.EmailSmtpPasswordSecret]
+		if username == "" || password == "" {
+			return nil, fmt.Errorf("the %s and %s secrets must be set to send emails by smtp", config.EmailSmtpUsernameSecret, config.EmailSmtpPasswordSecret)
+		}
+		client = NewSMTPClient(emailConfig.Host, emailConfig.Port, username, password)
+	case config.HttpEmail:
+		apiKey := secrets[config.EmailApiKeySecret]
+		if apiKey == "" {
+			return nil, fmt.Errorf("the %s secret must be set to send emails by http", config.EmailApiKeySecret)
+		}
+		client = NewHTTPClient(emailConfig.Endpoint, apiKey)
+	case config.OutboxEmail:
+		client = NewOutboxClient(emailConfig.OutboxDir(projectDir))
+	case "":
+		client = NewSMTPClientFromEnv()
+		if client == nil {
+			client = NoOpClient()
+		}
+	default:
+		return nil, fmt.Errorf("unsupported email provider: %s", emailConfig.Provider)
+	}
+
+	return &defaultsClient{
+		client:  client,
+		from:    emailConfig.FromAddress(),
+		replyTo: emailConfig.ReplyTo,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type httpClient struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewHTTPClient returns a client which sends emails by posting them as JSON to the endpoint of an email API, with
+// the API key as a bearer token. The body of the request is an httpEmail.
+func NewHTTPClient(endpoint string, apiKey string) EmailClient {
+	return &httpClient{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client:   http.DefaultClient,
+	}
+}
+
+type httpEmail struct {
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	ReplyTo     string            `json:"replyTo,omitempty"`
+	Subject     string            `json:"subject"`
+	Text        string            `json:"text,omitempty"`
+	HTML        string            `json:"html,omitempty"`
+	Attachments []*httpAttachment `json:"attachments,omitempty"`
+}
+
+type httpAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	// Content is the base64 encoded data of the file
+	Content string `json:"content"`
+}
+
+func (c *httpClient) Send(ctx context.Context, req *SendEmailRequest) error {
+	if req.To == "" || req.From == "" {
+		return errors.New("email must have a sender and recipient")
+	}
+
+	email := &httpEmail{
+		From:    req.From,
+		To:      req.To,
+		ReplyTo: req.ReplyTo,
+		Subject: req.Subject,
+		Text:    req.PlainText,
+		HTML:    req.HTML,
+	}
+
+	for _, a := range req.Attachments {
+		email.Attachments = append(email.Attachments, &httpAttachment{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Content:     base64.StdEncoding.EncodeToString(a.Data),
+		})
+	}
+
+	body, err := json.Marshal(email)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("sending email failed with status %d: %s", response.StatusCode, string(b))
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package mail_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/mail"
+)
+
+func TestBuildMessagePlainText(t *testing.T) {
+	msg, err := mail.BuildMessage(&mail.SendEmailRequest{
+		To:        "jane@example.com",
+		From:      "Keel <hi@keel.xyz>",
+		Subject:   "Hello",
+		PlainText: "Hello there",
+	})
+	require.NoError(t, err)
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(msg)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.0", parsed.Header.Get("MIME-Version"))
+	assert.Equal(t, `"Keel" <hi@keel.xyz>`, parsed.Header.Get("From"))
+	assert.Equal(t, "<jane@example.com>", parsed.Header.Get("To"))
+	assert.Equal(t, "Hello", parsed.Header.Get("Subject"))
+	assert.Empty(t, parsed.Header.Get("Reply-To"))
+	assert.True(t, strings.HasSuffix(parsed.Header.Get("Message-ID"), "@keel.xyz>"))
+	assert.Equal(t, "text/plain; charset=utf-8", parsed.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(parsed.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello there", string(body))
+}
+
+func TestBuildMessageHtmlWithAttachment(t *testing.T) {
+	msg, err := mail.BuildMessage(&mail.SendEmailRequest{
+		To:        "jane@example.com",
+		From:      "hi@keel.xyz",
+		ReplyTo:   "support@keel.xyz",
+		Subject:   "Your invoice – March",
+		PlainText: "Your invoice is attached",
+		HTML:      "<p>Your invoice is attached</p>",
+		Attachments: []*mail.Attachment{
+			{
+				Filename:    "invoice.pdf",
+				ContentType: "application/pdf",
+				Data:        []byte("%PDF-1.4"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(msg)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "<support@keel.xyz>", parsed.Header.Get("Reply-To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Your invoice – March", subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mixed := multipart.NewReader(parsed.Body, params["boundary"])
+
+	// The first part is the alternative plain text and HTML bodies
+	part, err := mixed.NextPart()
+	require.NoError(t, err)
+
+	mediaType, params, err = mime.ParseMediaType(part.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	alternative := multipart.NewReader(part, params["boundary"])
+
+	text, err := alternative.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", text.Header.Get("Content-Type"))
+	b, err := io.ReadAll(text)
+	require.NoError(t, err)
+	assert.Equal(t, "Your invoice is attached", string(b))
+
+	html, err := alternative.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", html.Header.Get("Content-Type"))
+	b, err = io.ReadAll(html)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Your invoice is attached</p>", string(b))
+
+	_, err = alternative.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// The second part is the attachment
+	attachment, err := mixed.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", attachment.Header.Get("Content-Type"))
+	assert.Equal(t, "invoice.pdf", attachment.FileName())
+	assert.Equal(t, "base64", attachment.Header.Get("Content-Transfer-Encoding"))
+
+	_, err = mixed.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestBuildMessageInvalid(t *testing.T) {
+	_, err := mail.BuildMessage(&mail.SendEmailRequest{
+		To:        "not an address",
+		From:      "hi@keel.xyz",
+		PlainText: "Hello",
+	})
+	assert.ErrorContains(t, err, "invalid recipient address")
+
+	_, err = mail.BuildMessage(&mail.SendEmailRequest{
+		To:   "jane@example.com",
+		From: "hi@keel.xyz",
+	})
+	assert.ErrorContains(t, err, "email has no content")
+}
+
+func TestHTTPClient(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("invalid api key"))
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	req := &mail.SendEmailRequest{
+		To:        "jane@example.com",
+		From:      "hi@keel.xyz",
+		Subject:   "Hello",
+		PlainText: "Hello there",
+		HTML:      "<p>Hello there</p>",
+		Attachments: []*mail.Attachment{
+			{Filename: "notes.txt", ContentType: "text/plain", Data: []byte("hello")},
+		},
+	}
+
+	err := mail.NewHTTPClient(server.URL, "secret-key").Send(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "jane@example.com", received["to"])
+	assert.Equal(t, "hi@keel.xyz", received["from"])
+	assert.Equal(t, "Hello", received["subject"])
+	assert.Equal(t, "Hello there", received["text"])
+	assert.Equal(t, "<p>Hello there</p>", received["html"])
+	assert.Equal(t, []any{map[string]any{"filename": "notes.txt", "contentType": "text/plain", "content": "aGVsbG8="}}, received["attachments"])
+
+	err = mail.NewHTTPClient(server.URL, "wrong-key").Send(context.Background(), req)
+	assert.ErrorContains(t, err, "sending email failed with status 401: invalid api key")
+}
+
+func TestOutboxClient(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+
+	err := mail.NewOutboxClient(dir).Send(context.Background(), &mail.SendEmailRequest{
+		To:        "jane@example.com",
+		From:      "hi@keel.xyz",
+		Subject:   "Hello",
+		PlainText: "Hello there",
+	})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+
+	f, err := os.Open(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	defer f.Close()
+
+	parsed, err := netmail.ReadMessage(f)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", parsed.Header.Get("Subject"))
+}
+
+func TestNewClientSender(t *testing.T) {
+	dir := t.TempDir()
+
+	client, err := mail.NewClient(&config.ProjectConfig{
+		Email: config.EmailConfig{
+			Provider: config.OutboxEmail,
+			From:     "My App <noreply@myapp.com>",
+			ReplyTo:  "support@myapp.com",
+		},
+	}, nil, dir)
+	require.NoError(t, err)
+
+	err = client.Send(context.Background(), &mail.SendEmailRequest{
+		To:        "jane@example.com",
+		Subject:   "Hello",
+		PlainText: "Hello there",
+	})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(filepath.Join(dir, ".keel", "outbox"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	b, err := os.ReadFile(filepath.Join(dir, ".keel", "outbox", files[0].Name()))
+	require.NoError(t, err)
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(b)))
+	require.NoError(t, err)
+	assert.Equal(t, `"My App" <noreply@myapp.com>`, parsed.Header.Get("From"))
+	assert.Equal(t, "<support@myapp.com>", parsed.Header.Get("Reply-To"))
+}
+
+func TestNewClientMissingSecrets(t *testing.T) {
+	_, err := mail.NewClient(&config.ProjectConfig{
+		Email: config.EmailConfig{
+			Provider: config.HttpEmail,
+			Endpoint: "https://api.mailprovider.com/v1/send",
+		},
+	}, map[string]string{}, "")
+	assert.ErrorContains(t, err, "the EMAIL_API_KEY secret must be set to send emails by http")
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	email, err := mail.DefaultTemplates().Render(mail.TemplatePasswordReset, &mail.PasswordResetData{
+		Identity: mail.TemplateIdentity{Email: "jane@example.com", Name: "Jane"},
+		ResetUrl: "https://myapp.com/reset?token=abc&x=<y>",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Reset your password", email.Subject)
+	assert.Contains(t, email.PlainText, "Hi Jane,")
+	assert.Contains(t, email.PlainText, "https://myapp.com/reset?token=abc&x=<y>")
+	assert.Contains(t, email.HTML, "Hi Jane,")
+	assert.Contains(t, email.HTML, `href="https://myapp.com/reset?token=abc&amp;x=%3cy%3e"`)
+}
+
+func TestDefaultTemplatesPasswordlessSignIn(t *testing.T) {
+	email, err := mail.DefaultTemplates().Render(mail.TemplatePasswordlessSignIn, &mail.PasswordlessSignInData{
+		Identity:  mail.TemplateIdentity{Email: "jane@example.com"},
+		Code:      "012345",
+		SignInUrl: "https://myapp.com/sign-in?code=012345&email=jane%40example.com",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Your sign-in code is 012345", email.Subject)
+	assert.Contains(t, email.PlainText, "Hi,")
+	assert.Contains(t, email.PlainText, "012345")
+	assert.Contains(t, email.PlainText, "https://myapp.com/sign-in?code=012345&email=jane%40example.com")
+	assert.Contains(t, email.HTML, `href="https://myapp.com/sign-in?code=012345&amp;email=jane%40example.com"`)
+
+	// Without a link, only the code is sent
+	email, err = mail.DefaultTemplates().Render(mail.TemplatePasswordlessSignIn, &mail.PasswordlessSignInData{
+		Identity: mail.TemplateIdentity{Email: "jane@example.com", Name: "Jane"},
+		Code:     "012345",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, email.PlainText, "Hi Jane,")
+	assert.NotContains(t, email.PlainText, "follow this link")
+	assert.NotContains(t, email.HTML, "href=")
+}
+
+func TestDefaultTemplatesEmailVerification(t *testing.T) {
+	email, err := mail.DefaultTemplates().Render(mail.TemplateEmailVerification, &mail.EmailVerificationData{
+		Identity:  mail.TemplateIdentity{Email: "jane@example.com", Name: "Jane"},
+		VerifyUrl: "https://myapp.com/verify?token=abc&x=<y>",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Verify your email address", email.Subject)
+	assert.Contains(t, email.PlainText, "Hi Jane,")
+	assert.Contains(t, email.PlainText, "jane@example.com")
+	assert.Contains(t, email.PlainText, "https://myapp.com/verify?token=abc&x=<y>")
+	assert.Contains(t, email.HTML, `href="https://myapp.com/verify?token=abc&amp;x=%3cy%3e"`)
+}
+
+func TestProjectTemplates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password_reset.subject.txt"), []byte("{{ .Identity.Name }}, reset your My App password\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password_reset.txt"), []byte("Reset at {{ .ResetUrl }}"), 0644))
+
+	templates, err := mail.LoadTemplates(dir)
+	require.NoError(t, err)
+
+	email, err := templates.Render(mail.TemplatePasswordReset, &mail.PasswordResetData{
+		Identity: mail.TemplateIdentity{Email: "jane@example.com", Name: "Jane"},
+		ResetUrl: "https://myapp.com/reset?token=abc",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Jane, reset your My App password", email.Subject)
+	assert.Equal(t, "Reset at https://myapp.com/reset?token=abc", email.PlainText)
+	// The built-in HTML body is not used when the project provides its own body
+	assert.Empty(t, email.HTML)
+}
+
+func TestProjectTemplatesInvalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password_reset.html"), []byte("<p>{{ .ResetUrl </p>"), 0644))
+
+	_, err := mail.LoadTemplates(dir)
+	assert.ErrorContains(t, err, "parsing email template")
+}
+
+func TestTemplatesMissingDirectory(t *testing.T) {
+	templates, err := mail.LoadTemplates(filepath.Join(t.TempDir(), "emails"))
+	require.NoError(t, err)
+
+	email, err := templates.Render(mail.TemplatePasswordReset, &mail.PasswordResetData{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, email.HTML)
+}
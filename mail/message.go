@@ -0,0 +1,216 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// BuildMessage builds the MIME message for the email. An email with both plain text and HTML is sent as
+// multipart/alternative so that mail clients can choose which to display, and any attachments are added
+// alongside it in a multipart/mixed message.
+func BuildMessage(req *SendEmailRequest) ([]byte, error) {
+	if req.To == "" {
+		return nil, errors.New("email has no recipient")
+	}
+	if req.From == "" {
+		return nil, errors.New("email has no sender")
+	}
+	if req.PlainText == "" && req.HTML == "" {
+		return nil, errors.New("email has no content")
+	}
+
+	from, err := mail.ParseAddress(req.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	to, err := mail.ParseAddress(req.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Date", time.Now().UTC().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", fmt.Sprintf("<%s@%s>", ksuid.New().String(), domain(from.Address)))
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", to.String())
+	if req.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(req.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply-to address: %w", err)
+		}
+		writeHeader(&buf, "Reply-To", replyTo.String())
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", req.Subject))
+
+	if len(req.Attachments) == 0 {
+		err = writeBody(&buf, req)
+		return buf.Bytes(), err
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mixed.Boundary()}))
+	buf.WriteString("\r\n")
+
+	var body bytes.Buffer
+	err = writeBody(&body, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The body is written as its own part with the headers it was written with
+	header, content, _ := bytes.Cut(body.Bytes(), []byte("\r\n\r\n"))
+	part, err := mixed.CreatePart(parseHeader(header))
+	if err != nil {
+		return nil, err
+	}
+	_, err = part.Write(content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range req.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeBase64(part, attachment.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mixed.Close()
+	return buf.Bytes(), err
+}
+
+// writeBody writes the content headers and body of the email, which is either plain text, HTML or both.
+func writeBody(buf *bytes.Buffer, req *SendEmailRequest) error {
+	if req.HTML == "" || req.PlainText == "" {
+		contentType := "text/plain"
+		content := req.PlainText
+		if req.HTML != "" {
+			contentType = "text/html"
+			content = req.HTML
+		}
+
+		writeHeader(buf, "Content-Type", mime.FormatMediaType(contentType, map[string]string{"charset": "utf-8"}))
+		writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		return writeQuotedPrintable(buf, content)
+	}
+
+	alternative := multipart.NewWriter(buf)
+	writeHeader(buf, "Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": alternative.Boundary()}))
+	buf.WriteString("\r\n")
+
+	// The preferred format is last, as per RFC 2046
+	for _, p := range []struct{ contentType, content string }{
+		{"text/plain", req.PlainText},
+		{"text/html", req.HTML},
+	} {
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(p.contentType, map[string]string{"charset": "utf-8"})},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+
+		var content bytes.Buffer
+		err = writeQuotedPrintable(&content, p.content)
+		if err != nil {
+			return err
+		}
+
+		_, err = part.Write(content.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	return alternative.Close()
+}
+
+func writeHeader(buf *bytes.Buffer, key string, value string) {
+	buf.WriteString(key)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+func parseHeader(header []byte) textproto.MIMEHeader {
+	h := textproto.MIMEHeader{}
+	for _, line := range strings.Split(string(header), "\r\n") {
+		key, value, found := strings.Cut(line, ": ")
+		if found {
+			h.Add(key, value)
+		}
+	}
+	return h
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, content string) error {
+	w := quotedprintable.NewWriter(buf)
+	_, err := w.Write([]byte(content))
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// writeBase64 writes the data in lines of 76 characters, as per RFC 2045
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := 76
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+		_, err := w.Write([]byte(encoded[:n] + "\r\n"))
+		if err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+func domain(address string) string {
+	_, d, found := strings.Cut(address, "@")
+	if !found {
+		return "localhost"
+	}
+	return d
+}
+
+// addressOnly returns the email address without the display name, such as hi@keel.xyz from "Keel <hi@keel.xyz>"
+func addressOnly(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Address, nil
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+type outboxClient struct {
+	dir string
+}
+
+// NewOutboxClient returns a client which writes each email to a .eml file in the directory rather than
+// sending it, which is useful during development and in tests. The files can be opened by most mail clients.
+func NewOutboxClient(dir string) EmailClient {
+	return &outboxClient{
+		dir: dir,
+	}
+}
+
+func (c *outboxClient) Send(ctx context.Context, req *SendEmailRequest) error {
+	msg, err := BuildMessage(req)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(c.dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("creating outbox directory: %w", err)
+	}
+
+	// Files are named so that they are ordered by when they were sent
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), ksuid.New().String())
+
+	return os.WriteFile(filepath.Join(c.dir, name), msg, 0644)
+}
@@ -12,10 +12,20 @@ type EmailClient interface {
 }
 
 type SendEmailRequest struct {
-	To        string
-	From      string
-	Subject   string
-	PlainText string
+	To          string
+	From        string
+	ReplyTo     string
+	Subject     string
+	PlainText   string
+	HTML        string
+	Attachments []*Attachment
+}
+
+// Attachment is a file which is attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
 }
 
 type smtpClient struct {
@@ -58,12 +68,25 @@ func NewSMTPClientFromEnv() EmailClient {
 }
 
 func (c *smtpClient) Send(ctx context.Context, req *SendEmailRequest) error {
+	msg, err := BuildMessage(req)
+	if err != nil {
+		return err
+	}
+
+	from, err := addressOnly(req.From)
+	if err != nil {
+		return err
+	}
+
+	to, err := addressOnly(req.To)
+	if err != nil {
+		return err
+	}
+
 	host := fmt.Sprintf("%s:%s", c.host, c.port)
 	auth := smtp.PlainAuth("", c.username, c.password, c.host)
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n%s.\r\n",
-		req.From, req.To, req.Subject, req.PlainText)
 
-	return smtp.SendMail(host, auth, req.From, []string{req.To}, []byte(msg))
+	return smtp.SendMail(host, auth, from, []string{to}, msg)
 }
 
 type noOpClient struct {
@@ -77,3 +100,21 @@ func NoOpClient() EmailClient {
 func (c *noOpClient) Send(context.Context, *SendEmailRequest) error {
 	return nil
 }
+
+// defaultsClient fills in the sender of the email from the project's config if one is not given.
+type defaultsClient struct {
+	client  EmailClient
+	from    string
+	replyTo string
+}
+
+func (c *defaultsClient) Send(ctx context.Context, req *SendEmailRequest) error {
+	r := *req
+	if r.From == "" {
+		r.From = c.from
+	}
+	if r.ReplyTo == "" {
+		r.ReplyTo = c.replyTo
+	}
+	return c.client.Send(ctx, &r)
+}
@@ -0,0 +1,214 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	texttemplate "text/template"
+)
+
+// The templates of the emails sent by Keel. A project can override any of these by adding files to its
+// email templates directory, named after the template:
+//   - <name>.subject.txt for the subject
+//   - <name>.txt for the plain text body
+//   - <name>.html for the HTML body
+//
+// If a project provides either of the bodies then the built-in bodies are not used, so that the plain text
+// and HTML versions of the email don't differ.
+const (
+	TemplatePasswordReset      = "password_reset"
+	TemplatePasswordlessSignIn = "passwordless_sign_in"
+	TemplateEmailVerification  = "email_verification"
+)
+
+var builtInTemplates = []string{
+	TemplatePasswordReset,
+	TemplatePasswordlessSignIn,
+	TemplateEmailVerification,
+}
+
+//go:embed templates/*
+var defaultTemplates embed.FS
+
+// TemplateIdentity is the identity which an email is being sent to
+type TemplateIdentity struct {
+	Id    string
+	Email string
+	Name  string
+}
+
+// PasswordResetData are the variables available in the password reset template
+type PasswordResetData struct {
+	Identity TemplateIdentity
+	ResetUrl string
+}
+
+// PasswordlessSignInData are the variables available in the passwordless sign-in template. The identity
+// only has an email if it will be created on first sign-in, and the link is only set if the app provided one.
+type PasswordlessSignInData struct {
+	Identity  TemplateIdentity
+	Code      string
+	SignInUrl string
+}
+
+// EmailVerificationData are the variables available in the email verification template
+type EmailVerificationData struct {
+	Identity  TemplateIdentity
+	VerifyUrl string
+}
+
+// Email is the rendered content of an email template
+type Email struct {
+	Subject   string
+	PlainText string
+	HTML      string
+}
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// Templates are the email templates available to the runtime
+type Templates struct {
+	templates map[string]*emailTemplate
+}
+
+// DefaultTemplates returns the built-in templates
+func DefaultTemplates() *Templates {
+	t, err := loadTemplates(defaultTemplates, nil)
+	if err != nil {
+		// The built-in templates are tested, so this cannot happen
+		panic(err)
+	}
+	return t
+}
+
+// LoadTemplates loads the built-in templates along with any templates in the project's email templates directory
+// which override them. The directory is optional.
+func LoadTemplates(dir string) (*Templates, error) {
+	var project fs.FS
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		project = os.DirFS(dir)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return loadTemplates(defaultTemplates, project)
+}
+
+func loadTemplates(defaults fs.FS, project fs.FS) (*Templates, error) {
+	templates := &Templates{
+		templates: map[string]*emailTemplate{},
+	}
+
+	defaults, err := fs.Sub(defaults, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range builtInTemplates {
+		source := defaults
+		if project != nil && (exists(project, name+".txt") || exists(project, name+".html")) {
+			source = project
+		}
+
+		t := &emailTemplate{}
+
+		subjectSource := defaults
+		if project != nil && exists(project, name+".subject.txt") {
+			subjectSource = project
+		}
+
+		subject, err := readTemplate(subjectSource, name+".subject.txt")
+		if err != nil {
+			return nil, err
+		}
+		t.subject, err = texttemplate.New(name + ".subject.txt").Parse(strings.TrimSpace(subject))
+		if err != nil {
+			return nil, fmt.Errorf("parsing email template: %w", err)
+		}
+
+		if exists(source, name+".txt") {
+			text, err := readTemplate(source, name+".txt")
+			if err != nil {
+				return nil, err
+			}
+			t.text, err = texttemplate.New(name + ".txt").Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("parsing email template: %w", err)
+			}
+		}
+
+		if exists(source, name+".html") {
+			html, err := readTemplate(source, name+".html")
+			if err != nil {
+				return nil, err
+			}
+			t.html, err = htmltemplate.New(name + ".html").Parse(html)
+			if err != nil {
+				return nil, fmt.Errorf("parsing email template: %w", err)
+			}
+		}
+
+		templates.templates[name] = t
+	}
+
+	return templates, nil
+}
+
+// Render renders the email template with the data, such as PasswordResetData
+func (t *Templates) Render(name string, data any) (*Email, error) {
+	template, ok := t.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("email template %s does not exist", name)
+	}
+
+	email := &Email{}
+
+	var buf bytes.Buffer
+	err := template.subject.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("rendering email subject: %w", err)
+	}
+	email.Subject = strings.TrimSpace(buf.String())
+
+	if template.text != nil {
+		buf.Reset()
+		err = template.text.Execute(&buf, data)
+		if err != nil {
+			return nil, fmt.Errorf("rendering email text: %w", err)
+		}
+		email.PlainText = buf.String()
+	}
+
+	if template.html != nil {
+		buf.Reset()
+		err = template.html.Execute(&buf, data)
+		if err != nil {
+			return nil, fmt.Errorf("rendering email html: %w", err)
+		}
+		email.HTML = buf.String()
+	}
+
+	return email, nil
+}
+
+func exists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+func readTemplate(fsys fs.FS, name string) (string, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("reading email template: %w", err)
+	}
+	return string(b), nil
+}
@@ -16,7 +16,7 @@ LEFT JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
 LEFT JOIN pg_catalog.pg_index i on i.indexrelid = a.attrelid
 WHERE
 	n.nspname = 'public'
-	AND c.relname not in ('keel_schema', 'keel_refresh_token', 'keel_storage', 'keel_auth_code', 'pg_stat_statements_info', 'pg_stat_statements')
+	AND c.relname not in ('keel_schema', 'keel_migrations', 'keel_refresh_token', 'keel_storage', 'keel_storage_upload', 'keel_auth_code', 'keel_event_delivery', 'keel_webhook_attempt', 'keel_event', 'keel_trace', 'keel_trace_span', 'keel_mfa', 'keel_mfa_recovery_code', 'keel_mfa_challenge', 'keel_passwordless_code', 'keel_email_verification', 'keel_rate_limit', 'keel_job_run', 'pg_stat_statements_info', 'pg_stat_statements')
 	AND a.attnum > 0
 	AND NOT a.attisdropped
 	AND i.indexrelid is null; -- no indexes
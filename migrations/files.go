@@ -0,0 +1,520 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Migration files are an alternative to applying migrations directly to the database with Migrations.Apply.
+// The SQL generated by New is written to versioned files in the project so that it can be reviewed
+// before being applied. Each migration consists of:
+//
+//   - <version>_<name>.up.sql - the SQL which applies the schema changes
+//   - <version>_<name>.down.sql - the SQL which reverts the schema changes
+//   - <version>_<name>.schema.json - the Keel schema the database matches once the migration is applied
+//
+// Applied migrations are recorded in the keel_migrations table.
+
+// MigrationsDir is the directory in a Keel project which contains the migration files.
+const MigrationsDir = "migrations"
+
+const versionFormat = "20060102150405"
+
+const migrationsTableSQL = "CREATE TABLE IF NOT EXISTS keel_migrations (version TEXT NOT NULL PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL);\n"
+
+var (
+	ErrNoChanges         = errors.New("the migration files are already up to date with the schema")
+	ErrNothingToRollback = errors.New("there are no applied migrations to roll back")
+)
+
+var migrationFileRegex = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(up\.sql|down\.sql|schema\.json)$`)
+
+var errRollbackGenerate = errors.New("rollback generate transaction")
+
+type MigrationFile struct {
+	// The version of the migration, which is the UTC time it was generated at
+	Version string
+
+	// The name given to the migration
+	Name string
+
+	// The SQL to apply the migration
+	Up string
+
+	// The SQL to revert the migration (might be empty)
+	Down string
+
+	// The Keel schema the database matches once the migration is applied (might be nil)
+	Schema *proto.Schema
+}
+
+// ID returns the identifier of the migration which prefixes each of its files.
+func (f *MigrationFile) ID() string {
+	return fmt.Sprintf("%s_%s", f.Version, f.Name)
+}
+
+type AppliedMigration struct {
+	Version   string
+	Name      string
+	AppliedAt time.Time
+}
+
+// MigrationStatus describes whether a migration file has been applied to the database.
+type MigrationStatus struct {
+	Version string
+	Name    string
+
+	// When the migration was applied, or nil if it is pending
+	AppliedAt *time.Time
+
+	// True if the migration has been applied to the database but there is no file for it
+	Missing bool
+}
+
+// Generate creates a new migration file from the changes required to migrate a database, on which the
+// existing migration files have been replayed, to the schema. The scratch database must be empty. It is
+// used instead of the database being migrated, which might have been changed outside of the migration
+// files, such as by keel run.
+//
+// The down migration is generated by applying the changes in a transaction which is then rolled back.
+// ErrNoChanges is returned if the migration files are already up to date with the schema, and an
+// UnconfirmedRenamesError if any inferred renames have been neither confirmed nor rejected.
+func Generate(ctx context.Context, schema *proto.Schema, scratch db.Database, name string, files []*MigrationFile, renames *Renames) (*MigrationFile, []*DatabaseChange, error) {
+	ctx, span := tracer.Start(ctx, "Generate Migration File")
+	defer span.End()
+
+	_, err := ApplyFiles(ctx, scratch, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := NewWithRenames(ctx, schema, scratch, renames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !m.HasModelFieldChanges() {
+		return nil, nil, ErrNoChanges
+	}
+
+	previousSchema, err := GetCurrentSchema(ctx, scratch)
+	if err != nil && !errors.Is(err, ErrNoStoredSchema) {
+		return nil, nil, err
+	}
+	if previousSchema == nil {
+		previousSchema = &proto.Schema{}
+	}
+
+	var down string
+	err = scratch.Transaction(ctx, func(ctx context.Context) error {
+		schemaSQL, err := storeSchemaSQL(schema)
+		if err != nil {
+			return err
+		}
+
+		_, err = scratch.ExecuteStatement(ctx, setupSQL()+schemaSQL+m.SQL)
+		if err != nil {
+			return err
+		}
+
+		// Changes which cannot be reversed safely leave the migration without a down migration
+		reverse, err := NewWithRenames(ctx, previousSchema, scratch, renames.reversed())
+		var unsafe *UnsafeTypeChangeError
+		var unconfirmed *UnconfirmedRenamesError
+		if errors.As(err, &unsafe) || errors.As(err, &unconfirmed) {
+			return errRollbackGenerate
+		}
+		if err != nil {
+			return err
+		}
+		down = reverse.SQL
+
+		return errRollbackGenerate
+	})
+	if err != nil && !errors.Is(err, errRollbackGenerate) {
+		return nil, nil, err
+	}
+
+	file := &MigrationFile{
+		Version: time.Now().UTC().Format(versionFormat),
+		Name:    migrationName(name),
+		Up:      m.SQL,
+		Down:    down,
+		Schema:  schema,
+	}
+
+	span.SetAttributes(attribute.String("migration", file.ID()))
+
+	return file, m.Changes, nil
+}
+
+// migrationName converts the name given to a migration into one which is safe to use in file names.
+func migrationName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "migration"
+	}
+	return name
+}
+
+// ReadMigrationFiles reads all the migration files from the directory, ordered by version.
+// A missing directory is treated as having no migrations.
+func ReadMigrationFiles(dir string) ([]*MigrationFile, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*MigrationFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := map[string]*MigrationFile{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, name, kind := matches[1], matches[2], matches[3]
+
+		file, ok := migrations[version]
+		if !ok {
+			file = &MigrationFile{Version: version, Name: name}
+			migrations[version] = file
+		}
+		if file.Name != name {
+			return nil, fmt.Errorf("more than one migration found with version %s", version)
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case "up.sql":
+			file.Up = string(b)
+		case "down.sql":
+			file.Down = string(b)
+		case "schema.json":
+			var schema proto.Schema
+			err = protojson.Unmarshal(b, &schema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema file for migration %s: %w", file.ID(), err)
+			}
+			file.Schema = &schema
+		}
+	}
+
+	files := []*MigrationFile{}
+	for _, file := range migrations {
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Version < files[j].Version
+	})
+
+	return files, nil
+}
+
+// WriteMigrationFile writes the files for the migration to the directory, creating it if necessary.
+func WriteMigrationFile(dir string, file *MigrationFile) ([]string, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := map[string]string{
+		"up.sql":   file.Up + "\n",
+		"down.sql": file.Down + "\n",
+	}
+
+	if file.Schema != nil {
+		b, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(file.Schema)
+		if err != nil {
+			return nil, err
+		}
+		contents["schema.json"] = string(b) + "\n"
+	}
+
+	paths := []string{}
+	for _, kind := range []string{"up.sql", "down.sql", "schema.json"} {
+		content, ok := contents[kind]
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s", file.ID(), kind))
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// Status returns the status of each migration file, along with any migrations which have been applied
+// to the database but no longer have a file.
+func Status(ctx context.Context, database db.Database, files []*MigrationFile) ([]*MigrationStatus, error) {
+	applied, err := GetAppliedMigrations(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedAt := map[string]time.Time{}
+	for _, a := range applied {
+		appliedAt[a.Version] = a.AppliedAt
+	}
+
+	statuses := []*MigrationStatus{}
+	versions := map[string]bool{}
+
+	for _, file := range files {
+		versions[file.Version] = true
+		status := &MigrationStatus{
+			Version: file.Version,
+			Name:    file.Name,
+		}
+		if t, ok := appliedAt[file.Version]; ok {
+			status.AppliedAt = &t
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, a := range applied {
+		if versions[a.Version] {
+			continue
+		}
+		t := a.AppliedAt
+		statuses = append(statuses, &MigrationStatus{
+			Version:   a.Version,
+			Name:      a.Name,
+			AppliedAt: &t,
+			Missing:   true,
+		})
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// ApplyFiles applies each of the pending migration files in order, each in its own transaction,
+// and returns the migrations which were applied.
+func ApplyFiles(ctx context.Context, database db.Database, files []*MigrationFile) ([]*MigrationFile, error) {
+	ctx, span := tracer.Start(ctx, "Apply Migration Files")
+	defer span.End()
+
+	pending, err := pendingMigrations(ctx, database, files)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) == 0 {
+		return pending, nil
+	}
+
+	_, err = database.ExecuteStatement(ctx, setupSQL()+migrationsTableSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []*MigrationFile{}
+	for _, file := range pending {
+		err = database.Transaction(ctx, func(ctx context.Context) error {
+			sql := strings.Builder{}
+			sql.WriteString(fmt.Sprintf("SELECT set_trace_id('%s');\n", span.SpanContext().TraceID().String()))
+			sql.WriteString(file.Up)
+			sql.WriteString("\n")
+
+			if file.Schema != nil {
+				schemaSQL, err := storeSchemaSQL(file.Schema)
+				if err != nil {
+					return err
+				}
+				sql.WriteString(schemaSQL)
+			}
+
+			_, err := database.ExecuteStatement(ctx, sql.String())
+			if err != nil {
+				return err
+			}
+
+			_, err = database.ExecuteStatement(ctx, "INSERT INTO keel_migrations (version, name, applied_at) VALUES (?, ?, now())", file.Version, file.Name)
+			return err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("applying migration %s: %w", file.ID(), err)
+		}
+
+		applied = append(applied, file)
+	}
+
+	_, err = database.ExecuteStatement(ctx, postMigrationSQL())
+	if err != nil {
+		return applied, err
+	}
+
+	span.SetAttributes(attribute.Int("migrations.applied", len(applied)))
+
+	return applied, nil
+}
+
+// Rollback reverts the most recently applied migration using its down migration. The schema
+// stored in the database is restored to that of the previously applied migration.
+func Rollback(ctx context.Context, database db.Database, files []*MigrationFile) (*MigrationFile, error) {
+	ctx, span := tracer.Start(ctx, "Rollback Migration File")
+	defer span.End()
+
+	applied, err := GetAppliedMigrations(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(applied) == 0 {
+		return nil, ErrNothingToRollback
+	}
+
+	last := applied[len(applied)-1]
+
+	file := findMigrationFile(files, last.Version)
+	if file == nil {
+		return nil, fmt.Errorf("no migration file found for applied migration %s_%s", last.Version, last.Name)
+	}
+
+	if strings.TrimSpace(file.Down) == "" {
+		return nil, fmt.Errorf("migration %s has no down migration", file.ID())
+	}
+
+	var previous *MigrationFile
+	if len(applied) > 1 {
+		previous = findMigrationFile(files, applied[len(applied)-2].Version)
+	}
+
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		sql := strings.Builder{}
+		sql.WriteString(fmt.Sprintf("SELECT set_trace_id('%s');\n", span.SpanContext().TraceID().String()))
+		sql.WriteString(file.Down)
+		sql.WriteString("\n")
+
+		if previous != nil && previous.Schema != nil {
+			schemaSQL, err := storeSchemaSQL(previous.Schema)
+			if err != nil {
+				return err
+			}
+			sql.WriteString(schemaSQL)
+		} else {
+			sql.WriteString("DELETE FROM keel_schema;\n")
+		}
+
+		_, err := database.ExecuteStatement(ctx, sql.String())
+		if err != nil {
+			return err
+		}
+
+		_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_migrations WHERE version = ?", file.Version)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rolling back migration %s: %w", file.ID(), err)
+	}
+
+	span.SetAttributes(attribute.String("migration", file.ID()))
+
+	return file, nil
+}
+
+// GetAppliedMigrations returns the migrations recorded as applied in the database, ordered by version.
+func GetAppliedMigrations(ctx context.Context, database db.Database) ([]*AppliedMigration, error) {
+	result, err := database.ExecuteQuery(ctx, "SELECT to_regclass('keel_migrations') AS name")
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []*AppliedMigration{}
+	if result.Rows[0]["name"] == nil {
+		return applied, nil
+	}
+
+	result, err = database.ExecuteQuery(ctx, "SELECT version, name, applied_at FROM keel_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range result.Rows {
+		a := &AppliedMigration{
+			Version: row["version"].(string),
+			Name:    row["name"].(string),
+		}
+		if t, ok := row["applied_at"].(time.Time); ok {
+			a.AppliedAt = t
+		}
+		applied = append(applied, a)
+	}
+
+	return applied, nil
+}
+
+// pendingMigrations returns the migration files which have not been applied to the database.
+// Migration files must be applied in order, so a file older than an applied migration is an error.
+func pendingMigrations(ctx context.Context, database db.Database, files []*MigrationFile) ([]*MigrationFile, error) {
+	applied, err := GetAppliedMigrations(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions := map[string]bool{}
+	latest := ""
+	for _, a := range applied {
+		appliedVersions[a.Version] = true
+		if a.Version > latest {
+			latest = a.Version
+		}
+	}
+
+	pending := []*MigrationFile{}
+	for _, file := range files {
+		if appliedVersions[file.Version] {
+			continue
+		}
+		if file.Version < latest {
+			return nil, fmt.Errorf("migration %s is older than the latest applied migration %s", file.ID(), latest)
+		}
+		pending = append(pending, file)
+	}
+
+	return pending, nil
+}
+
+func findMigrationFile(files []*MigrationFile, version string) *MigrationFile {
+	for _, f := range files {
+		if f.Version == version {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/migrations"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/testhelpers"
+)
+
+func TestWriteAndReadMigrationFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), migrations.MigrationsDir)
+
+	second := &migrations.MigrationFile{
+		Version: "20230102000000",
+		Name:    "add_post",
+		Up:      `CREATE TABLE "post" ("id" TEXT NOT NULL);`,
+		Down:    `DROP TABLE "post" CASCADE;`,
+		Schema: &proto.Schema{
+			Models: []*proto.Model{{Name: "Post"}},
+		},
+	}
+
+	first := &migrations.MigrationFile{
+		Version: "20230101000000",
+		Name:    "initial",
+		Up:      `CREATE TABLE "author" ("id" TEXT NOT NULL);`,
+	}
+
+	paths, err := migrations.WriteMigrationFile(dir, second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "20230102000000_add_post.up.sql"),
+		filepath.Join(dir, "20230102000000_add_post.down.sql"),
+		filepath.Join(dir, "20230102000000_add_post.schema.json"),
+	}, paths)
+
+	_, err = migrations.WriteMigrationFile(dir, first)
+	require.NoError(t, err)
+
+	// Files which aren't migrations are ignored
+	err = os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Migrations"), 0644)
+	require.NoError(t, err)
+
+	files, err := migrations.ReadMigrationFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	assert.Equal(t, "20230101000000_initial", files[0].ID())
+	assert.Equal(t, first.Up+"\n", files[0].Up)
+	assert.Equal(t, "\n", files[0].Down)
+	assert.Nil(t, files[0].Schema)
+
+	assert.Equal(t, "20230102000000_add_post", files[1].ID())
+	assert.Equal(t, second.Up+"\n", files[1].Up)
+	assert.Equal(t, second.Down+"\n", files[1].Down)
+	require.NotNil(t, files[1].Schema)
+	assert.Equal(t, "Post", files[1].Schema.Models[0].Name)
+}
+
+func TestReadMigrationFilesMissingDirectory(t *testing.T) {
+	files, err := migrations.ReadMigrationFiles(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestReadMigrationFilesDuplicateVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "20230101000000_one.up.sql"), []byte(""), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "20230101000000_two.up.sql"), []byte(""), 0644)
+	require.NoError(t, err)
+
+	_, err = migrations.ReadMigrationFiles(dir)
+	assert.ErrorContains(t, err, "more than one migration found with version 20230101000000")
+}
+
+// newFilesTestDatabase creates an empty database with the given name, which is dropped at the end of the test.
+func newFilesTestDatabase(t *testing.T, ctx context.Context, dbName string) db.Database {
+	dbConnInfo := &db.ConnectionInfo{
+		Host:     "localhost",
+		Port:     "8001",
+		Username: "postgres",
+		Password: "postgres",
+		Database: "keel",
+	}
+
+	mainDB, err := sql.Open("pgx/v5", dbConnInfo.String())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		mainDB.Close()
+	})
+
+	_, err = mainDB.Exec("DROP DATABASE if exists " + dbName)
+	require.NoError(t, err)
+	_, err = mainDB.Exec("CREATE DATABASE " + dbName)
+	require.NoError(t, err)
+
+	database, err := db.New(ctx, dbConnInfo.WithDatabase(dbName).String())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		database.Close()
+		_, _ = mainDB.Exec("DROP DATABASE if exists " + dbName)
+	})
+
+	return database
+}
+
+func makeFilesTestSchema(t *testing.T, s string) *proto.Schema {
+	builder := &schema.Builder{}
+	protoSchema, err := builder.MakeFromString(s, config.Empty)
+	require.NoError(t, err)
+	return protoSchema
+}
+
+var filesTestSchemaV1 = `
+	model Post {
+		fields {
+			title Text
+		}
+	}`
+
+var filesTestSchemaV2 = `
+	model Post {
+		fields {
+			title Text
+			views Number?
+		}
+	}`
+
+// generateFilesTestMigrations generates a migration for each schema in turn, each against a new scratch database.
+func generateFilesTestMigrations(t *testing.T, ctx context.Context, schemas ...string) []*migrations.MigrationFile {
+	files := []*migrations.MigrationFile{}
+
+	for i, s := range schemas {
+		scratch := newFilesTestDatabase(t, ctx, fmt.Sprintf("testmigrationfilesscratch%d", i))
+
+		file, _, err := migrations.Generate(ctx, makeFilesTestSchema(t, s), scratch, fmt.Sprintf("migration %d", i), files, nil)
+		require.NoError(t, err)
+
+		// Migrations generated within the same second would otherwise have the same version
+		file.Version = fmt.Sprintf("2023010100000%d", i)
+		files = append(files, file)
+	}
+
+	return files
+}
+
+func TestGenerate(t *testing.T) {
+	ctx, err := testhelpers.WithTracing(context.Background())
+	require.NoError(t, err)
+
+	// The database being migrated has already been changed outside of the migration files, such as by keel run,
+	// and is not used to generate the migrations
+	database := newFilesTestDatabase(t, ctx, "testgenerate")
+	m, err := migrations.New(ctx, makeFilesTestSchema(t, filesTestSchemaV2), database)
+	require.NoError(t, err)
+	require.NoError(t, m.Apply(ctx, false))
+
+	files := generateFilesTestMigrations(t, ctx, filesTestSchemaV1, filesTestSchemaV2)
+
+	assert.Equal(t, "migration_0", files[0].Name)
+	assert.Contains(t, files[0].Up, `CREATE TABLE "post" (`)
+	assert.NotContains(t, files[0].Up, `"views"`)
+	assert.Contains(t, files[0].Down, `DROP TABLE "post" CASCADE;`)
+	require.NotNil(t, files[0].Schema)
+
+	// The second migration is generated from the first migration file replayed on the scratch database
+	assert.Equal(t, "migration_1", files[1].Name)
+	assert.Equal(t, `ALTER TABLE "post" ADD COLUMN "views" INTEGER;`, files[1].Up)
+	assert.Equal(t, `ALTER TABLE "post" DROP COLUMN "views";`, files[1].Down)
+
+	// There's nothing to generate once the migration files are up to date with the schema
+	scratch := newFilesTestDatabase(t, ctx, "testgeneratescratch")
+	_, _, err = migrations.Generate(ctx, makeFilesTestSchema(t, filesTestSchemaV2), scratch, "nothing", files, nil)
+	assert.ErrorIs(t, err, migrations.ErrNoChanges)
+}
+
+func TestApplyFilesAndRollback(t *testing.T) {
+	ctx, err := testhelpers.WithTracing(context.Background())
+	require.NoError(t, err)
+
+	files := generateFilesTestMigrations(t, ctx, filesTestSchemaV1, filesTestSchemaV2)
+	database := newFilesTestDatabase(t, ctx, "testapplyfilesandrollback")
+
+	applied, err := migrations.ApplyFiles(ctx, database, files)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+
+	statuses, err := migrations.Status(ctx, database, files)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.NotNil(t, statuses[1].AppliedAt)
+
+	_, err = database.ExecuteStatement(ctx, `INSERT INTO "post" (id, title, views) VALUES ('1', 'Hello', 5)`)
+	require.NoError(t, err)
+
+	current, err := migrations.GetCurrentSchema(ctx, database)
+	require.NoError(t, err)
+	assert.NotNil(t, proto.FindField(current.Models, "Post", "views"))
+
+	// Applied migrations are not applied again
+	applied, err = migrations.ApplyFiles(ctx, database, files)
+	require.NoError(t, err)
+	assert.Len(t, applied, 0)
+
+	// Rolling back reverts the latest migration and restores the schema of the previous one
+	rolledBack, err := migrations.Rollback(ctx, database, files)
+	require.NoError(t, err)
+	assert.Equal(t, files[1].ID(), rolledBack.ID())
+
+	result, err := database.ExecuteQuery(ctx, `SELECT * FROM "post"`)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "Hello", result.Rows[0]["title"])
+	assert.NotContains(t, result.Rows[0], "views")
+
+	current, err = migrations.GetCurrentSchema(ctx, database)
+	require.NoError(t, err)
+	assert.Nil(t, proto.FindField(current.Models, "Post", "views"))
+
+	statuses, err = migrations.Status(ctx, database, files)
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	rolledBack, err = migrations.Rollback(ctx, database, files)
+	require.NoError(t, err)
+	assert.Equal(t, files[0].ID(), rolledBack.ID())
+
+	_, err = migrations.GetCurrentSchema(ctx, database)
+	assert.ErrorIs(t, err, migrations.ErrNoStoredSchema)
+
+	_, err = migrations.Rollback(ctx, database, files)
+	assert.ErrorIs(t, err, migrations.ErrNothingToRollback)
+
+	// The rolled back migrations can be applied again
+	applied, err = migrations.ApplyFiles(ctx, database, files)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+}
@@ -0,0 +1,253 @@
+package migrations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+var indexMethods = map[proto.IndexMethod]string{
+	proto.IndexMethod_INDEX_METHOD_BTREE: "btree",
+	proto.IndexMethod_INDEX_METHOD_GIN:   "gin",
+	proto.IndexMethod_INDEX_METHOD_HNSW:  "hnsw",
+}
+
+// The pgvector operator classes used by hnsw indexes for each distance metric
+var vectorOperatorClasses = map[proto.DistanceMetric]string{
+	proto.DistanceMetric_DISTANCE_METRIC_COSINE:        "vector_cosine_ops",
+	proto.DistanceMetric_DISTANCE_METRIC_L2:            "vector_l2_ops",
+	proto.DistanceMetric_DISTANCE_METRIC_INNER_PRODUCT: "vector_ip_ops",
+}
+
+// Keel records the definition of each index it creates as a comment on the index, which is how the indexes
+// generated by Keel are told apart from any indexes created on the tables by other means.
+var generatedIndexDefinition = regexp.MustCompile(fmt.Sprintf(`^(%s) \(`, strings.Join(lo.Values(indexMethods), "|")))
+
+// generatedByKeel determines if the index was created by Keel for an index defined in the schema.
+func (i *IndexRow) generatedByKeel() bool {
+	return generatedIndexDefinition.MatchString(i.Definition)
+}
+
+// modelIndexes generates the statements for creating or dropping the indexes of a model, so that
+// the indexes in the database match those defined in the schema. An index is recreated if its
+// definition has changed. Only the indexes generated by Keel are dropped.
+func modelIndexes(model *proto.Model, existing []*IndexRow) (statements []string, err error) {
+	tableName := casing.ToSnake(model.Name)
+
+	tableIndexes := lo.Filter(existing, func(i *IndexRow, _ int) bool {
+		return i.TableName == tableName && i.generatedByKeel()
+	})
+
+	for _, row := range tableIndexes {
+		index, found := lo.Find(model.Indexes, func(i *proto.Index) bool {
+			return i.Name == row.IndexName
+		})
+		if !found || indexDefinition(index) != row.Definition {
+			statements = append(statements, dropIndexStmt(row.IndexName))
+		}
+	}
+
+	for _, index := range model.Indexes {
+		row, found := lo.Find(tableIndexes, func(i *IndexRow) bool {
+			return i.IndexName == index.Name
+		})
+		if found && indexDefinition(index) == row.Definition {
+			continue
+		}
+
+		stmt, err := createIndexStmt(model, index)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, stmt)
+	}
+
+	return statements, nil
+}
+
+// indexDefinition describes the index as defined in the schema e.g. btree (title, created_at). The operator
+// class of an hnsw index is included unless it measures the cosine distance e.g. hnsw (embedding vector_l2_ops)
+func indexDefinition(index *proto.Index) string {
+	fields := strings.Join(index.FieldNames, ", ")
+	if opclass := vectorOperatorClass(index); opclass != "" && opclass != vectorOperatorClasses[proto.DistanceMetric_DISTANCE_METRIC_COSINE] {
+		fields = fmt.Sprintf("%s %s", fields, opclass)
+	}
+
+	definition := fmt.Sprintf("%s (%s)", indexMethods[index.Method], fields)
+	if index.Where != nil {
+		definition += fmt.Sprintf(" WHERE %s", index.Where.Source)
+	}
+	return definition
+}
+
+func createIndexStmt(model *proto.Model, index *proto.Index) (string, error) {
+	method, ok := indexMethods[index.Method]
+	if !ok {
+		return "", fmt.Errorf("unsupported index method %s on index %s", index.Method, index.Name)
+	}
+
+	columns := lo.Map(index.FieldNames, func(f string, _ int) string {
+		if opclass := vectorOperatorClass(index); opclass != "" {
+			return fmt.Sprintf("%s %s", Identifier(f), opclass)
+		}
+		return Identifier(f)
+	})
+
+	stmt := fmt.Sprintf("CREATE INDEX %s ON %s USING %s (%s)", db.QuoteIdentifier(index.Name), Identifier(model.Name), method, strings.Join(columns, ", "))
+
+	if index.Where != nil {
+		predicate, err := indexPredicate(model, index.Where.Source)
+		if err != nil {
+			return "", err
+		}
+		stmt += fmt.Sprintf(" WHERE %s", predicate)
+	}
+
+	stmt += ";\n"
+	stmt += fmt.Sprintf("COMMENT ON INDEX %s IS %s;", db.QuoteIdentifier(index.Name), db.QuoteLiteral(indexDefinition(index)))
+
+	return stmt, nil
+}
+
+// vectorOperatorClass returns the operator class for the distance metric of an hnsw index, which is
+// empty for other index methods. An hnsw index measures the cosine distance unless another metric is given.
+func vectorOperatorClass(index *proto.Index) string {
+	if index.Method != proto.IndexMethod_INDEX_METHOD_HNSW {
+		return ""
+	}
+
+	if opclass, ok := vectorOperatorClasses[index.Metric]; ok {
+		return opclass
+	}
+
+	return vectorOperatorClasses[proto.DistanceMetric_DISTANCE_METRIC_COSINE]
+}
+
+func dropIndexStmt(name string) string {
+	// The index may have already been dropped along with a column that it indexes.
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;", db.QuoteIdentifier(name))
+}
+
+// indexPredicate converts the where expression of a partial index into SQL. The expression has been
+// validated to only compare fields of the model with literal values.
+func indexPredicate(model *proto.Model, source string) (string, error) {
+	expr, err := parser.ParseExpression(source)
+	if err != nil {
+		return "", err
+	}
+
+	return expressionSql(model, expr)
+}
+
+func expressionSql(model *proto.Model, expr *parser.Expression) (string, error) {
+	ors := []string{}
+	for _, or := range expr.Or {
+		ands := []string{}
+		for _, and := range or.And {
+			if and.Expression != nil {
+				sql, err := expressionSql(model, and.Expression)
+				if err != nil {
+					return "", err
+				}
+				ands = append(ands, fmt.Sprintf("(%s)", sql))
+				continue
+			}
+
+			sql, err := conditionSql(model, and.Condition)
+			if err != nil {
+				return "", err
+			}
+			ands = append(ands, sql)
+		}
+		ors = append(ors, strings.Join(ands, " AND "))
+	}
+
+	if len(ors) == 1 {
+		return ors[0], nil
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(ors, " OR ")), nil
+}
+
+func conditionSql(model *proto.Model, condition *parser.Condition) (string, error) {
+	lhs, err := operandSql(model, condition.LHS)
+	if err != nil {
+		return "", err
+	}
+
+	if condition.Operator == nil {
+		return lhs, nil
+	}
+
+	rhs, err := operandSql(model, condition.RHS)
+	if err != nil {
+		return "", err
+	}
+
+	switch condition.Operator.Symbol {
+	case parser.OperatorEquals:
+		if condition.RHS.Null {
+			return fmt.Sprintf("%s IS NULL", lhs), nil
+		}
+		return fmt.Sprintf("%s = %s", lhs, rhs), nil
+	case parser.OperatorNotEquals:
+		if condition.RHS.Null {
+			return fmt.Sprintf("%s IS NOT NULL", lhs), nil
+		}
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", lhs, rhs), nil
+	case parser.OperatorGreaterThan, parser.OperatorGreaterThanOrEqualTo, parser.OperatorLessThan, parser.OperatorLessThanOrEqualTo:
+		return fmt.Sprintf("%s %s %s", lhs, condition.Operator.Symbol, rhs), nil
+	case parser.OperatorIn:
+		if condition.RHS.Array != nil && len(condition.RHS.Array.Values) == 0 {
+			return "false", nil
+		}
+		return fmt.Sprintf("%s IN %s", lhs, rhs), nil
+	case parser.OperatorNotIn:
+		if condition.RHS.Array != nil && len(condition.RHS.Array.Values) == 0 {
+			return "true", nil
+		}
+		return fmt.Sprintf("%s NOT IN %s", lhs, rhs), nil
+	default:
+		return "", fmt.Errorf("operator %s is not supported in index expressions", condition.Operator.Symbol)
+	}
+}
+
+func operandSql(model *proto.Model, operand *parser.Operand) (string, error) {
+	switch {
+	case operand.Null:
+		return "NULL", nil
+	case operand.Array != nil:
+		values := []string{}
+		for _, v := range operand.Array.Values {
+			sql, err := operandSql(model, v)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, sql)
+		}
+		return fmt.Sprintf("(%s)", strings.Join(values, ", ")), nil
+	case operand.Ident != nil:
+		fragments := operand.Ident.Fragments
+		if len(fragments) != 2 {
+			return "", fmt.Errorf("unsupported operand %s in index expression", operand.ToString())
+		}
+
+		// A field on this model
+		if fragments[0].Fragment == casing.ToLowerCamel(model.Name) {
+			return Identifier(fragments[1].Fragment), nil
+		}
+
+		// Otherwise an enum value
+		return db.QuoteLiteral(fragments[1].Fragment), nil
+	case operand.String != nil, operand.Decimal != nil, operand.Number != nil, operand.True, operand.False:
+		return toSqlLiteral(operand, nil)
+	default:
+		return "", fmt.Errorf("unsupported operand %s in index expression", operand.ToString())
+	}
+}
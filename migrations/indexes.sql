@@ -0,0 +1,14 @@
+SELECT
+	t.relname::text "table_name",
+	i.relname::text "index_name",
+	COALESCE(obj_description(i.oid, 'pg_class'), '') "definition"
+FROM pg_catalog.pg_index ix
+JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+WHERE
+	n.nspname = 'public'
+	AND NOT ix.indisprimary
+	AND NOT ix.indisunique
+	AND i.relname LIKE '%\_idx'
+ORDER BY t.relname, i.relname;
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/proto"
+)
+
+func TestCreateIndexStmtVectorOperatorClass(t *testing.T) {
+	model := &proto.Model{Name: "Document"}
+
+	for metric, expected := range map[proto.DistanceMetric]string{
+		proto.DistanceMetric_DISTANCE_METRIC_UNKNOWN:       `CREATE INDEX "document_embedding_idx" ON "document" USING hnsw ("embedding" vector_cosine_ops);` + "\n" + `COMMENT ON INDEX "document_embedding_idx" IS 'hnsw (embedding)';`,
+		proto.DistanceMetric_DISTANCE_METRIC_COSINE:        `CREATE INDEX "document_embedding_idx" ON "document" USING hnsw ("embedding" vector_cosine_ops);` + "\n" + `COMMENT ON INDEX "document_embedding_idx" IS 'hnsw (embedding)';`,
+		proto.DistanceMetric_DISTANCE_METRIC_L2:            `CREATE INDEX "document_embedding_idx" ON "document" USING hnsw ("embedding" vector_l2_ops);` + "\n" + `COMMENT ON INDEX "document_embedding_idx" IS 'hnsw (embedding vector_l2_ops)';`,
+		proto.DistanceMetric_DISTANCE_METRIC_INNER_PRODUCT: `CREATE INDEX "document_embedding_idx" ON "document" USING hnsw ("embedding" vector_ip_ops);` + "\n" + `COMMENT ON INDEX "document_embedding_idx" IS 'hnsw (embedding vector_ip_ops)';`,
+	} {
+		stmt, err := createIndexStmt(model, &proto.Index{
+			Name:       "document_embedding_idx",
+			FieldNames: []string{"embedding"},
+			Method:     proto.IndexMethod_INDEX_METHOD_HNSW,
+			Metric:     metric,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expected, stmt, metric.String())
+	}
+}
+
+func TestModelIndexesOnlyDropsGeneratedIndexes(t *testing.T) {
+	model := &proto.Model{
+		Name: "Post",
+		Indexes: []*proto.Index{
+			{Name: "post_title_idx", FieldNames: []string{"title"}, Method: proto.IndexMethod_INDEX_METHOD_BTREE},
+		},
+	}
+
+	stmts, err := modelIndexes(model, []*IndexRow{
+		{TableName: "post", IndexName: "post_title_idx", Definition: "btree (title)"},
+		{TableName: "post", IndexName: "post_score_idx", Definition: "btree (score)"},
+		{TableName: "post", IndexName: "post_body_trgm_idx", Definition: ""},
+		{TableName: "post", IndexName: "post_slug_idx", Definition: "Created for the blog importer"},
+	})
+	require.NoError(t, err)
+
+	// Indexes which weren't generated by Keel are kept
+	assert.Equal(t, []string{`DROP INDEX IF EXISTS "post_score_idx";`}, stmts)
+}
@@ -1,25 +1,31 @@
 package migrations
 
 import (
+	"context"
 	_ "embed"
 
 	"github.com/lib/pq"
 	"github.com/teamkeel/keel/db"
 )
 
-func getConstraints(database db.Database) ([]*ConstraintRow, error) {
+func getConstraints(ctx context.Context, database db.Database) ([]*ConstraintRow, error) {
 	rows := []*ConstraintRow{}
-	return rows, database.GetDB().Raw(constraintsQuery).Scan(&rows).Error
+	return rows, db.Conn(ctx, database).Raw(constraintsQuery).Scan(&rows).Error
 }
 
-func getTriggers(database db.Database) ([]*TriggerRow, error) {
+func getTriggers(ctx context.Context, database db.Database) ([]*TriggerRow, error) {
 	rows := []*TriggerRow{}
-	return rows, database.GetDB().Raw(triggersQuery).Scan(&rows).Error
+	return rows, db.Conn(ctx, database).Raw(triggersQuery).Scan(&rows).Error
 }
 
-func getColumns(database db.Database) ([]*ColumnRow, error) {
+func getColumns(ctx context.Context, database db.Database) ([]*ColumnRow, error) {
 	rows := []*ColumnRow{}
-	return rows, database.GetDB().Raw(columnsQuery).Scan(&rows).Error
+	return rows, db.Conn(ctx, database).Raw(columnsQuery).Scan(&rows).Error
+}
+
+func getIndexes(ctx context.Context, database db.Database) ([]*IndexRow, error) {
+	rows := []*IndexRow{}
+	return rows, db.Conn(ctx, database).Raw(indexesQuery).Scan(&rows).Error
 }
 
 var (
@@ -31,6 +37,9 @@ var (
 
 	//go:embed triggers.sql
 	triggersQuery string
+
+	//go:embed indexes.sql
+	indexesQuery string
 )
 
 type ColumnRow struct {
@@ -80,3 +89,13 @@ type TriggerRow struct {
 	// e.g. AFTER
 	ActionTiming string `json:"action_timing"`
 }
+
+type IndexRow struct {
+	// e.g. post_title_idx
+	IndexName string `json:"index_name"`
+	// e.g. post
+	TableName string `json:"table_name"`
+	// The definition of the index from the Keel schema, which is stored as a comment on the
+	// index so that changes to the index can be detected e.g. btree (title)
+	Definition string `json:"definition"`
+}
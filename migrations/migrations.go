@@ -24,6 +24,7 @@ const (
 	ChangeTypeAdded    = "ADDED"
 	ChangeTypeRemoved  = "REMOVED"
 	ChangeTypeModified = "MODIFIED"
+	ChangeTypeRenamed  = "RENAMED"
 )
 
 var ErrNoStoredSchema = errors.New("no schema stored in keel_schema table")
@@ -55,9 +56,15 @@ type DatabaseChange struct {
 
 	// The type of change
 	Type string
+
+	// The previous name of the model or field if it has been renamed
+	PreviousName string `json:"PreviousName,omitempty"`
 }
 
 func (c DatabaseChange) String() string {
+	if c.Type == ChangeTypeRenamed {
+		return fmt.Sprintf("Model: %s, Field: %s, Type: %s, PreviousName: %s", c.Model, c.Field, c.Type, c.PreviousName)
+	}
 	return fmt.Sprintf("Model: %s, Field: %s, Type: %s", c.Model, c.Field, c.Type)
 }
 
@@ -93,6 +100,44 @@ func (m *Migrations) Apply(ctx context.Context, dryRun bool) error {
 		sql.WriteString("BEGIN TRANSACTION;\n")
 	}
 
+	sql.WriteString(setupSQL())
+
+	schemaSQL, err := storeSchemaSQL(m.Schema)
+	if err != nil {
+		return err
+	}
+	sql.WriteString(schemaSQL)
+
+	sql.WriteString(fmt.Sprintf("SELECT set_trace_id('%s');\n", span.SpanContext().TraceID().String()))
+
+	sql.WriteString(m.SQL)
+	sql.WriteString("\n")
+
+	sql.WriteString(postMigrationSQL())
+
+	if dryRun {
+		sql.WriteString("ROLLBACK TRANSACTION;\n")
+	}
+
+	_, err = m.database.ExecuteStatement(ctx, sql.String())
+	if err != nil {
+		// Rollback the transaction if we're doing a dry run. This needs to be a separate exec
+		// because when a SQL migration error happens, then the rollback command won't be executed and
+		// then the transaction will be left open.
+		if dryRun {
+			_, _ = m.database.ExecuteStatement(ctx, "ROLLBACK TRANSACTION;")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// setupSQL returns the statements which create the extensions, functions and internal
+// tables that the migrations and runtime depend on. These are all safe to run repeatedly.
+func setupSQL() string {
+	sql := strings.Builder{}
+
 	// Enable extensions
 	sql.WriteString("CREATE EXTENSION IF NOT EXISTS pg_stat_statements;\n")
 	sql.WriteString("CREATE EXTENSION IF NOT EXISTS vector;\n")
@@ -110,28 +155,63 @@ func (m *Migrations) Apply(ctx context.Context, dryRun bool) error {
 	sql.WriteString("\n")
 
 	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_schema (schema TEXT NOT NULL);\n")
-	sql.WriteString("DELETE FROM keel_schema;\n")
 
-	b, err := protojson.Marshal(m.Schema)
-	if err != nil {
-		return err
-	}
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_refresh_token (token TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, created_at TIMESTAMP, expires_at TIMESTAMP);\n")
+	sql.WriteString("\n")
 
-	escapedJSON := db.QuoteLiteral(string(b))
-	sql.WriteString(fmt.Sprintf("INSERT INTO keel_schema (schema) VALUES (%s);", escapedJSON))
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_auth_code (code TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, created_at TIMESTAMP, expires_at TIMESTAMP);\n")
 	sql.WriteString("\n")
 
-	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_refresh_token (token TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, created_at TIMESTAMP, expires_at TIMESTAMP);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_storage_upload (id TEXT NOT NULL PRIMARY KEY, model_name TEXT NOT NULL, field_name TEXT NOT NULL, identity_id TEXT, filename TEXT NOT NULL, content_type TEXT NOT NULL, size BIGINT NOT NULL, expires_at TIMESTAMPTZ NOT NULL, uploaded_at TIMESTAMPTZ, consumed_at TIMESTAMPTZ, created_at TIMESTAMPTZ NOT NULL DEFAULT now());\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_storage_upload_expires_at ON keel_storage_upload (expires_at);\n")
 	sql.WriteString("\n")
 
-	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_auth_code (code TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, created_at TIMESTAMP, expires_at TIMESTAMP);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_event_delivery (id TEXT NOT NULL PRIMARY KEY, subscriber TEXT NOT NULL, event JSONB NOT NULL, traceparent TEXT, status TEXT NOT NULL, attempts INTEGER NOT NULL DEFAULT 0, max_attempts INTEGER NOT NULL, next_attempt_at TIMESTAMPTZ NOT NULL, last_error TEXT, delivered_at TIMESTAMPTZ, created_at TIMESTAMPTZ NOT NULL DEFAULT now(), updated_at TIMESTAMPTZ NOT NULL DEFAULT now());\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_event_delivery_pending ON keel_event_delivery (next_attempt_at) WHERE status = 'pending';\n")
 	sql.WriteString("\n")
 
-	sql.WriteString(fmt.Sprintf("SELECT set_trace_id('%s');\n", span.SpanContext().TraceID().String()))
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_webhook_attempt (id TEXT NOT NULL PRIMARY KEY, delivery_id TEXT NOT NULL, webhook TEXT NOT NULL, url TEXT NOT NULL, status_code INTEGER, response_body TEXT, error TEXT, duration_ms INTEGER NOT NULL, created_at TIMESTAMPTZ NOT NULL DEFAULT now());\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_webhook_attempt_delivery_id ON keel_webhook_attempt (delivery_id);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_event (id TEXT NOT NULL PRIMARY KEY, name TEXT NOT NULL, data JSONB NOT NULL, identity_id TEXT, trace_id TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL DEFAULT now(), processed_at TIMESTAMPTZ);\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_event_trace_id ON keel_event (trace_id) WHERE processed_at IS NULL;\n")
+	sql.WriteString("\n")
 
-	sql.WriteString(m.SQL)
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_mfa (identity_id TEXT NOT NULL PRIMARY KEY, secret TEXT NOT NULL, last_used_step BIGINT, enabled_at TIMESTAMPTZ, created_at TIMESTAMPTZ NOT NULL);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_mfa_recovery_code (code TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL);\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_mfa_recovery_code_identity_id ON keel_mfa_recovery_code (identity_id);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_mfa_challenge (token TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, attempts INTEGER NOT NULL DEFAULT 0, created_at TIMESTAMPTZ, expires_at TIMESTAMPTZ);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_passwordless_code (email TEXT NOT NULL PRIMARY KEY, code TEXT NOT NULL, attempts INTEGER NOT NULL DEFAULT 0, created_at TIMESTAMPTZ, expires_at TIMESTAMPTZ);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_email_verification (identity_id TEXT NOT NULL PRIMARY KEY, token TEXT NOT NULL UNIQUE, email TEXT NOT NULL, created_at TIMESTAMPTZ, expires_at TIMESTAMPTZ);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_rate_limit (key TEXT NOT NULL PRIMARY KEY, attempts INTEGER NOT NULL DEFAULT 0, window_started_at TIMESTAMPTZ, locked_until TIMESTAMPTZ);\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_job_run (id TEXT NOT NULL PRIMARY KEY, job TEXT NOT NULL, schedule TEXT NOT NULL, status TEXT NOT NULL, scheduled_for TIMESTAMPTZ NOT NULL, started_at TIMESTAMPTZ, finished_at TIMESTAMPTZ, lease_expires_at TIMESTAMPTZ, error TEXT, created_at TIMESTAMPTZ NOT NULL DEFAULT now());\n")
+	sql.WriteString("CREATE UNIQUE INDEX IF NOT EXISTS idx_keel_job_run_job_scheduled_for ON keel_job_run (job, scheduled_for);\n")
+	sql.WriteString("CREATE UNIQUE INDEX IF NOT EXISTS idx_keel_job_run_running ON keel_job_run (job) WHERE status = 'running';\n")
 	sql.WriteString("\n")
 
+	return sql.String()
+}
+
+// storeSchemaSQL returns the statements which replace the schema stored in keel_schema.
+func storeSchemaSQL(schema *proto.Schema) (string, error) {
+	b, err := protojson.Marshal(schema)
+	if err != nil {
+		return "", err
+	}
+
+	sql := strings.Builder{}
+	sql.WriteString("DELETE FROM keel_schema;\n")
+	sql.WriteString(fmt.Sprintf("INSERT INTO keel_schema (schema) VALUES (%s);", db.QuoteLiteral(string(b))))
+	sql.WriteString("\n")
+
+	return sql.String(), nil
+}
+
+// postMigrationSQL returns the statements which are run once the schema changes have been applied.
+func postMigrationSQL() string {
+	sql := strings.Builder{}
+
 	// For now, we do this here but this could belong in our proto once we start on the database indexing work.
 	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_audit_trace_id ON keel_audit USING HASH(trace_id);\n")
 	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_audit_table_name_data_id_created_at ON keel_audit (table_name, (data->>'id'), created_at);\n")
@@ -139,50 +219,55 @@ func (m *Migrations) Apply(ctx context.Context, dryRun bool) error {
 	// Data migration when migrating to new authentication methods.
 	sql.WriteString("UPDATE identity SET issuer = 'https://keel.so' WHERE issuer = 'keel';\n")
 
-	if dryRun {
-		sql.WriteString("ROLLBACK TRANSACTION;\n")
-	}
-
-	_, err = m.database.ExecuteStatement(ctx, sql.String())
-	if err != nil {
-		// Rollback the transaction if we're doing a dry run. This needs to be a separate exec
-		// because when a SQL migration error happens, then the rollback command won't be executed and
-		// then the transaction will be left open.
-		if dryRun {
-			_, _ = m.database.ExecuteStatement(ctx, "ROLLBACK TRANSACTION;")
-		}
-		return err
-	}
-
-	return nil
+	return sql.String()
 }
 
 // New creates a new Migrations instance for the given schema and database.
 // Introspection is performed on the database to work out what schema changes
 // need to be applied to result in the database schema matching the Keel schema
 func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migrations, error) {
+	return NewWithRenames(ctx, schema, database, nil)
+}
+
+// NewWithRenames creates a new Migrations instance in the same way as New, applying the inferred
+// renames which have been confirmed. An UnconfirmedRenamesError is returned if any inferred renames
+// have been neither confirmed nor rejected.
+func NewWithRenames(ctx context.Context, schema *proto.Schema, database db.Database, renames *Renames) (*Migrations, error) {
 	_, span := tracer.Start(ctx, "Generate Migrations")
 	defer span.End()
 
-	columns, err := getColumns(database)
+	columns, err := getColumns(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints, err := getConstraints(ctx, database)
 	if err != nil {
 		return nil, err
 	}
 
-	constraints, err := getConstraints(database)
+	triggers, err := getTriggers(ctx, database)
 	if err != nil {
 		return nil, err
 	}
 
-	triggers, err := getTriggers(database)
+	indexes, err := getIndexes(ctx, database)
 	if err != nil {
 		return nil, err
 	}
 
+	// The schema last applied to the database is used to detect renamed models and fields.
+	previousSchema, err := GetCurrentSchema(ctx, database)
+	if err != nil && !errors.Is(err, ErrNoStoredSchema) {
+		return nil, err
+	}
+
 	statements := []string{}
 	changes := []*DatabaseChange{}
 	modelsAdded := []*proto.Model{}
 	existingModels := []*proto.Model{}
+	unsafeTypeChanges := []*UnsafeTypeChange{}
+	unconfirmedRenames := []*DatabaseChange{}
 
 	// We're going to analyse the database changes required using a temporarily mutated schema.
 	// Specifically we're going to inject a fake, hard-coded KeelAudit model into it.
@@ -198,6 +283,50 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 	pushAuditModel(schema)
 	defer popAuditModel(schema)
 
+	// Rename any models, so that they are then treated as existing tables. Renames are
+	// applied before any other statements.
+	modelRenames := detectModelRenames(previousSchema, schema, columns)
+	for _, oldName := range lo.Keys(modelRenames) {
+		newName := modelRenames[oldName]
+		oldTable, newTable := casing.ToSnake(oldName), casing.ToSnake(newName)
+
+		rename := &DatabaseChange{
+			Model:        newName,
+			Type:         ChangeTypeRenamed,
+			PreviousName: oldName,
+		}
+
+		if confirmed, decided := renames.decision(rename); !confirmed {
+			if !decided {
+				unconfirmedRenames = append(unconfirmedRenames, rename)
+			}
+			delete(modelRenames, oldName)
+			continue
+		}
+
+		statements = append(statements, renameTableStmt(oldName, newName))
+		statements = append(statements, renameTriggerStmts(triggers, oldName, newName)...)
+		statements = append(statements, renameConstraintStmts(constraints, oldName, newName)...)
+		statements = append(statements, renameIndexStmts(indexes, oldName, newName)...)
+		changes = append(changes, rename)
+
+		for _, c := range columns {
+			if c.TableName == oldTable {
+				c.TableName = newTable
+			}
+		}
+		for _, c := range constraints {
+			if c.TableName == oldTable {
+				c.TableName = newTable
+			}
+		}
+		for _, i := range indexes {
+			if i.TableName == oldTable {
+				i.TableName = newTable
+			}
+		}
+	}
+
 	modelNames := schema.ModelNames()
 
 	// Add any new models
@@ -212,6 +341,13 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 				return nil, err
 			}
 			statements = append(statements, stmt)
+
+			// Passing an empty slice of indexes here as this is a new table so no existing indexes
+			stmts, err := modelIndexes(model, []*IndexRow{})
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, stmts...)
 			changes = append(changes, &DatabaseChange{
 				Model: model.Name,
 				Type:  ChangeTypeAdded,
@@ -267,6 +403,37 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 			return c.TableName == tableName
 		})
 
+		// Rename a field, so that it is then treated as an existing column.
+		previousModelName, ok := lo.FindKey(modelRenames, model.Name)
+		if !ok {
+			previousModelName = model.Name
+		}
+		previousField, renamedField := detectFieldRename(previousSchema, previousModelName, model, tableColumns, modelRenames)
+		if renamedField != nil {
+			rename := &DatabaseChange{
+				Model:        model.Name,
+				Field:        renamedField.Name,
+				Type:         ChangeTypeRenamed,
+				PreviousName: previousField.Name,
+			}
+
+			if confirmed, decided := renames.decision(rename); confirmed {
+				column, _ := lo.Find(tableColumns, func(c *ColumnRow) bool {
+					return c.ColumnName == casing.ToSnake(previousField.Name)
+				})
+
+				statements = append(statements, renameColumnStmt(model.Name, previousField.Name, renamedField.Name))
+				changes = append(changes, rename)
+
+				column.ColumnName = casing.ToSnake(renamedField.Name)
+			} else {
+				if !decided {
+					unconfirmedRenames = append(unconfirmedRenames, rename)
+				}
+				previousField, renamedField = nil, nil
+			}
+		}
+
 		for _, field := range model.Fields {
 			if field.Type.Type == proto.Type_TYPE_MODEL {
 				continue
@@ -298,6 +465,73 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 			// Column already exists - see if any changes need to be applied
 			hasChanged := false
 
+			// The field as it was when the schema was last applied
+			var priorField *proto.Field
+			switch {
+			case renamedField == field:
+				priorField = previousField
+			case previousSchema != nil:
+				priorField = proto.FindField(previousSchema.Models, previousModelName, field.Name)
+			}
+
+			from, known := introspectedColumnType(column)
+			to := columnType(field)
+			if known && from != to {
+				if !canCast(from, to) {
+					change := &UnsafeTypeChange{
+						Model: model.Name,
+						Field: field.Name,
+						From:  column.DataType,
+						To:    describeType(field),
+					}
+					if priorField != nil {
+						change.From = describeType(priorField)
+					}
+					unsafeTypeChanges = append(unsafeTypeChanges, change)
+					continue
+				}
+
+				statements = append(statements, alterColumnTypeStmt(model.Name, field, column, from))
+				hasChanged = true
+
+				// The default was dropped to change the type, and so is set again if the field has one
+				column.HasDefault = false
+				column.DefaultValue = ""
+			}
+
+			if changedSecret(priorField, field) {
+				unsafeTypeChanges = append(unsafeTypeChanges, &UnsafeTypeChange{
+					Model:  model.Name,
+					Field:  field.Name,
+					From:   describeType(priorField),
+					To:     describeType(field),
+					Reason: secretTypeChangeReason,
+				})
+				continue
+			}
+
+			// Values of a column which is now an enum must all be values of the enum
+			if changedToEnum(priorField, field) {
+				enum := proto.FindEnum(schema.Enums, field.Type.EnumName.Value)
+				invalid, err := invalidEnumValues(ctx, database, model.Name, column, enum)
+				if err != nil {
+					return nil, err
+				}
+
+				if len(invalid) > 0 {
+					unsafeTypeChanges = append(unsafeTypeChanges, &UnsafeTypeChange{
+						Model: model.Name,
+						Field: field.Name,
+						From:  describeType(priorField),
+						To:    describeType(field),
+						Reason: fmt.Sprintf("as it has values which are not in the enum: %s", strings.Join(lo.Map(invalid, func(v string, _ int) string {
+							return db.QuoteLiteral(v)
+						}), ", ")),
+					})
+					continue
+				}
+			}
+
 			alterSQL, err := alterColumnStmt(model.Name, field, column)
 			if err != nil {
 				return nil, err
@@ -325,6 +559,19 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 				hasChanged = true
 			}
 
+			// Foreign key constraints cannot be altered, so are replaced when the onDelete action changes
+			if field.ForeignKeyInfo != nil {
+				fkConstraintRow, hasFkConstraint := lo.Find(constraints, func(c *ConstraintRow) bool {
+					return c.TableName == tableName && c.ConstraintType == "f" && len(c.ConstrainedColumns) == 1 && c.ConstrainedColumns[0] == int64(column.ColumnNum)
+				})
+
+				if hasFkConstraint && fkConstraintRow.OnDelete != onDeleteActionCodes[onDeleteAction(field)] {
+					statements = append(statements, dropConstraintStmt(fkConstraintRow.TableName, fkConstraintRow.ConstraintName))
+					statements = append(statements, fkConstraint(field, model))
+					hasChanged = true
+				}
+			}
+
 			if hasChanged {
 				changes = append(changes, &DatabaseChange{
 					Model: model.Name,
@@ -352,6 +599,12 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 			return nil, err
 		}
 
+		indexStmts, err := modelIndexes(model, indexes)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, indexStmts...)
+
 		if len(stmts) > 0 {
 			statements = append(statements, stmts...)
 			changes = append(changes, &DatabaseChange{
@@ -361,6 +614,16 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 		}
 	}
 
+	// Nothing is applied until each of the inferred renames has been confirmed or rejected
+	if len(unconfirmedRenames) > 0 {
+		return nil, &UnconfirmedRenamesError{Renames: unconfirmedRenames}
+	}
+
+	// Nothing is applied if any of the fields cannot be converted to their new type
+	if len(unsafeTypeChanges) > 0 {
+		return nil, &UnsafeTypeChangeError{Changes: unsafeTypeChanges}
+	}
+
 	stringChanges := lo.Map(changes, func(c *DatabaseChange, _ int) string { return c.String() })
 	span.SetAttributes(attribute.StringSlice("migration", stringChanges))
 
@@ -478,13 +741,38 @@ func fkConstraintsForModel(model *proto.Model) (fkStatements []string) {
 // fkConstraint generates a foreign key constraint statement for the given foreign key field.
 func fkConstraint(field *proto.Field, thisModel *proto.Model) (fkStatement string) {
 	fki := field.ForeignKeyInfo
-	onDelete := lo.Ternary(field.Optional, "SET NULL", "CASCADE")
 	stmt := addForeignKeyConstraintStmt(
 		Identifier(thisModel.Name),
 		Identifier(field.Name),
 		Identifier(fki.RelatedModelName),
 		Identifier(fki.RelatedModelField),
-		onDelete,
+		onDeleteAction(field),
 	)
 	return stmt
 }
+
+// The codes Postgres uses for the referential actions of foreign key constraints when introspected.
+var onDeleteActionCodes = map[string]string{
+	"NO ACTION": "a",
+	"RESTRICT":  "r",
+	"CASCADE":   "c",
+	"SET NULL":  "n",
+}
+
+// onDeleteAction is the referential action taken on the rows with the foreign key when the related row is
+// deleted. Unless defined with @relation(onDelete: ...), optional foreign keys are set to null and rows
+// with required foreign keys are deleted.
+func onDeleteAction(field *proto.Field) string {
+	switch field.OnDelete {
+	case proto.OnDelete_ON_DELETE_CASCADE:
+		return "CASCADE"
+	case proto.OnDelete_ON_DELETE_RESTRICT:
+		return "RESTRICT"
+	case proto.OnDelete_ON_DELETE_SET_NULL:
+		return "SET NULL"
+	case proto.OnDelete_ON_DELETE_NO_ACTION:
+		return "NO ACTION"
+	default:
+		return lo.Ternary(field.Optional, "SET NULL", "CASCADE")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -105,6 +106,12 @@ func TestMigrations(t *testing.T) {
 				schema,
 				database,
 			)
+
+			// Inferred renames must be confirmed before they're applied
+			var renamesErr *migrations.UnconfirmedRenamesError
+			if errors.As(err, &renamesErr) {
+				m, err = migrations.NewWithRenames(ctx, schema, database, &migrations.Renames{Confirmed: renamesErr.Renames})
+			}
 			require.NoError(t, err)
 
 			// Assert correct SQL generated
@@ -157,3 +164,223 @@ func assertJSON(t *testing.T, expected []byte, actual []byte) {
 		assert.Fail(t, "expected changes JSON is invalid")
 	}
 }
+
+func TestMigrationsUnsafeTypeChange(t *testing.T) {
+	dbConnInfo := &db.ConnectionInfo{
+		Host:     "localhost",
+		Port:     "8001",
+		Username: "postgres",
+		Password: "postgres",
+		Database: "keel",
+	}
+
+	mainDB, err := sql.Open("pgx/v5", dbConnInfo.String())
+	require.NoError(t, err)
+	defer mainDB.Close()
+
+	dbName := "testmigrationsunsafetypechange"
+	_, err = mainDB.Exec("DROP DATABASE if exists " + dbName)
+	require.NoError(t, err)
+	_, err = mainDB.Exec("CREATE DATABASE " + dbName)
+	require.NoError(t, err)
+
+	ctx, err := testhelpers.WithTracing(context.Background())
+	require.NoError(t, err)
+
+	database, err := db.New(ctx, dbConnInfo.WithDatabase(dbName).String())
+	require.NoError(t, err)
+	defer database.Close()
+
+	builder := &schema.Builder{}
+	currProto, err := builder.MakeFromString(`
+		model Post {
+			fields {
+				views Decimal
+				tags Text[]
+				status Text
+				apiKey Text
+				token Secret
+			}
+		}`, config.Empty)
+	require.NoError(t, err)
+
+	m, err := migrations.New(ctx, currProto, database)
+	require.NoError(t, err)
+	require.NoError(t, m.Apply(ctx, false))
+
+	_, err = database.ExecuteStatement(ctx, `INSERT INTO "post" (id, views, tags, status, api_key, token) VALUES ('1', 1.5, '{a}', 'Draft', 'abc', 'encrypted'), ('2', 2, '{}', 'Archived', 'def', 'encrypted')`)
+	require.NoError(t, err)
+
+	builder = &schema.Builder{}
+	newProto, err := builder.MakeFromString(`
+		enum Status {
+			Draft
+			Published
+		}
+		model Post {
+			fields {
+				views Number
+				tags Text
+				status Status
+				apiKey Secret
+				token Text
+			}
+		}`, config.Empty)
+	require.NoError(t, err)
+
+	_, err = migrations.New(ctx, newProto, database)
+	require.Error(t, err)
+
+	var typeChangeErr *migrations.UnsafeTypeChangeError
+	require.ErrorAs(t, err, &typeChangeErr)
+	require.Len(t, typeChangeErr.Changes, 5)
+	require.Equal(t, "Post.views from Decimal to Number", typeChangeErr.Changes[0].String())
+	require.Equal(t, "Post.tags from Text[] to Text", typeChangeErr.Changes[1].String())
+	require.Equal(t, "Post.status from Text to Status, as it has values which are not in the enum: 'Archived'", typeChangeErr.Changes[2].String())
+	require.Equal(t, "Post.apiKey from Text to Secret, as Secret values are stored encrypted", typeChangeErr.Changes[3].String())
+	require.Equal(t, "Post.token from Secret to Text, as Secret values are stored encrypted", typeChangeErr.Changes[4].String())
+
+	// Nothing has been applied
+	result, err := database.ExecuteQuery(ctx, `SELECT views::TEXT AS views FROM "post" WHERE id = '1'`)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	require.Equal(t, "1.5", result.Rows[0]["views"])
+
+	result, err = database.ExecuteQuery(ctx, `SELECT api_key, token FROM "post" WHERE id = '1'`)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	require.Equal(t, "abc", result.Rows[0]["api_key"])
+	require.Equal(t, "encrypted", result.Rows[0]["token"])
+
+	result, err = database.ExecuteQuery(ctx, `SELECT column_name::TEXT AS column_name, data_type::TEXT AS data_type FROM information_schema.columns WHERE table_name = 'post' AND column_name IN ('views', 'tags', 'status') ORDER BY column_name`)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{
+		{"column_name": "status", "data_type": "text"},
+		{"column_name": "tags", "data_type": "ARRAY"},
+		{"column_name": "views", "data_type": "numeric"},
+	}, result.Rows)
+}
+
+func TestMigrationsInferredRenames(t *testing.T) {
+	dbConnInfo := &db.ConnectionInfo{
+		Host:     "localhost",
+		Port:     "8001",
+		Username: "postgres",
+		Password: "postgres",
+		Database: "keel",
+	}
+
+	mainDB, err := sql.Open("pgx/v5", dbConnInfo.String())
+	require.NoError(t, err)
+	defer mainDB.Close()
+
+	dbName := "testmigrationsinferredrenames"
+	_, err = mainDB.Exec("DROP DATABASE if exists " + dbName)
+	require.NoError(t, err)
+	_, err = mainDB.Exec("CREATE DATABASE " + dbName)
+	require.NoError(t, err)
+
+	ctx, err := testhelpers.WithTracing(context.Background())
+	require.NoError(t, err)
+
+	database, err := db.New(ctx, dbConnInfo.WithDatabase(dbName).String())
+	require.NoError(t, err)
+	defer database.Close()
+
+	builder := &schema.Builder{}
+	currProto, err := builder.MakeFromString(`
+		model Person {
+			fields {
+				age Number
+			}
+		}`, config.Empty)
+	require.NoError(t, err)
+
+	m, err := migrations.New(ctx, currProto, database)
+	require.NoError(t, err)
+	require.NoError(t, m.Apply(ctx, false))
+
+	builder = &schema.Builder{}
+	newProto, err := builder.MakeFromString(`
+		model Person {
+			fields {
+				yearsOld Number
+			}
+		}`, config.Empty)
+	require.NoError(t, err)
+
+	_, err = migrations.New(ctx, newProto, database)
+	require.Error(t, err)
+
+	var renamesErr *migrations.UnconfirmedRenamesError
+	require.ErrorAs(t, err, &renamesErr)
+	require.Len(t, renamesErr.Renames, 1)
+	require.Equal(t, "Person", renamesErr.Renames[0].Model)
+	require.Equal(t, "yearsOld", renamesErr.Renames[0].Field)
+	require.Equal(t, "age", renamesErr.Renames[0].PreviousName)
+
+	// A rejected rename is a drop and an add
+	m, err = migrations.NewWithRenames(ctx, newProto, database, &migrations.Renames{Rejected: renamesErr.Renames})
+	require.NoError(t, err)
+	require.Contains(t, m.SQL, `ALTER TABLE "person" DROP COLUMN "age";`)
+	require.NotContains(t, m.SQL, "RENAME")
+
+	// A confirmed rename keeps the data
+	m, err = migrations.NewWithRenames(ctx, newProto, database, &migrations.Renames{Confirmed: renamesErr.Renames})
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "person" RENAME COLUMN "age" TO "years_old";`, m.SQL)
+}
+
+func TestCheckTypeChanges(t *testing.T) {
+	builder := &schema.Builder{}
+	previous, err := builder.MakeFromString(`
+		model Post {
+			fields {
+				views Number
+				score Decimal
+				tags Text[]
+				published Timestamp
+				status Text
+				apiKey Text
+				token Secret
+				notes Text
+			}
+		}`, config.Empty)
+	require.NoError(t, err)
+
+	builder = &schema.Builder{}
+	current, err := builder.MakeFromString(`
+		enum Status {
+			Draft
+			Published
+		}
+		model Post {
+			fields {
+				views Decimal
+				score Number
+				tags Text
+				published Date
+				status Status
+				apiKey Secret
+				token Text
+				notes Markdown
+			}
+		}`, config.Empty)
+	require.NoError(t, err)
+
+	err = migrations.CheckTypeChanges(previous, current)
+
+	var typeChangeErr *migrations.UnsafeTypeChangeError
+	require.ErrorAs(t, err, &typeChangeErr)
+	require.Equal(t, []string{
+		"Post.score from Decimal to Number",
+		"Post.tags from Text[] to Text",
+		"Post.published from Timestamp to Date",
+		"Post.apiKey from Text to Secret, as Secret values are stored encrypted",
+		"Post.token from Secret to Text, as Secret values are stored encrypted",
+	}, lo.Map(typeChangeErr.Changes, func(c *migrations.UnsafeTypeChange, _ int) string {
+		return c.String()
+	}))
+
+	require.NoError(t, migrations.CheckTypeChanges(previous, previous))
+}
@@ -0,0 +1,292 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/proto"
+)
+
+// Renames are detected by comparing the schema last applied to the database (from GetCurrentSchema)
+// with the new schema. Without this, a renamed model or field would be dropped and then added again,
+// losing all of its data.
+//
+// The detection is deliberately conservative. Within a model, a field is only considered renamed if it is
+// the only field removed and the only field added, and both fields are of the same type. Likewise, a model is
+// only considered renamed if it is the only model removed and the only model added, and both models have
+// identical fields. Anything else is treated as a drop and an add.
+//
+// As a removed field and an unrelated new field of the same type are indistinguishable from a rename, an
+// inferred rename is never applied without being confirmed. Until every inferred rename has either been
+// confirmed or rejected, an UnconfirmedRenamesError is returned listing them. A rejected rename is treated
+// as a drop and an add.
+
+// Renames are the decisions made about the renames inferred from the previous schema.
+type Renames struct {
+	// The inferred renames which are applied
+	Confirmed []*DatabaseChange
+
+	// The inferred renames which are instead treated as a drop and an add
+	Rejected []*DatabaseChange
+}
+
+// decision returns whether the inferred rename has been confirmed, and whether a decision has been made at all.
+func (r *Renames) decision(rename *DatabaseChange) (confirmed bool, decided bool) {
+	if r == nil {
+		return false, false
+	}
+
+	matches := func(c *DatabaseChange) bool {
+		return c.Model == rename.Model && c.Field == rename.Field && c.PreviousName == rename.PreviousName
+	}
+
+	if lo.ContainsBy(r.Confirmed, matches) {
+		return true, true
+	}
+
+	return false, lo.ContainsBy(r.Rejected, matches)
+}
+
+// reversed returns the decisions for the renames which revert these renames, such as when generating a down migration.
+func (r *Renames) reversed() *Renames {
+	if r == nil {
+		return nil
+	}
+
+	// The model a renamed field belongs to is also reverted if it has been renamed
+	modelNames := map[string]string{}
+	for _, c := range append(append([]*DatabaseChange{}, r.Confirmed...), r.Rejected...) {
+		if c.Field == "" {
+			modelNames[c.Model] = c.PreviousName
+		}
+	}
+
+	reverse := func(changes []*DatabaseChange) []*DatabaseChange {
+		return lo.Map(changes, func(c *DatabaseChange, _ int) *DatabaseChange {
+			if c.Field == "" {
+				return &DatabaseChange{Model: c.PreviousName, Type: ChangeTypeRenamed, PreviousName: c.Model}
+			}
+
+			model := c.Model
+			if previous, ok := modelNames[model]; ok {
+				model = previous
+			}
+			return &DatabaseChange{Model: model, Field: c.PreviousName, Type: ChangeTypeRenamed, PreviousName: c.Field}
+		})
+	}
+
+	return &Renames{
+		Confirmed: reverse(r.Confirmed),
+		Rejected:  reverse(r.Rejected),
+	}
+}
+
+// UnconfirmedRenamesError is returned when renames have been inferred which have been neither confirmed nor rejected.
+type UnconfirmedRenamesError struct {
+	Renames []*DatabaseChange
+}
+
+func (e *UnconfirmedRenamesError) Error() string {
+	lines := []string{"these renames were inferred from the schema changes and must be confirmed, otherwise they are treated as a drop and an add:"}
+	for _, r := range e.Renames {
+		name := r.Model
+		if r.Field != "" {
+			name = fmt.Sprintf("%s.%s", r.Model, r.Field)
+		}
+		lines = append(lines, fmt.Sprintf("  - %s (from %s)", name, r.PreviousName))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detectModelRenames returns a map of old model names to new model names for those models
+// which have been renamed since the previous schema.
+func detectModelRenames(previous *proto.Schema, schema *proto.Schema, columns []*ColumnRow) map[string]string {
+	renames := map[string]string{}
+	if previous == nil {
+		return renames
+	}
+
+	tables := lo.Uniq(lo.Map(columns, func(c *ColumnRow, _ int) string {
+		return c.TableName
+	}))
+
+	removed := lo.Filter(tables, func(table string, _ int) bool {
+		return schema.FindModel(casing.ToCamel(table)) == nil
+	})
+
+	added := lo.Filter(schema.Models, func(model *proto.Model, _ int) bool {
+		return !lo.Contains(tables, casing.ToSnake(model.Name))
+	})
+
+	if len(removed) != 1 || len(added) != 1 {
+		return renames
+	}
+
+	previousModel := previous.FindModel(casing.ToCamel(removed[0]))
+	if previousModel == nil {
+		return renames
+	}
+
+	if !sameColumns(previousModel, added[0]) {
+		return renames
+	}
+
+	renames[previousModel.Name] = added[0].Name
+
+	return renames
+}
+
+// detectFieldRename returns the previous field of the model which has been renamed to the new field, if any.
+func detectFieldRename(previous *proto.Schema, previousModelName string, model *proto.Model, tableColumns []*ColumnRow, modelRenames map[string]string) (*proto.Field, *proto.Field) {
+	if previous == nil {
+		return nil, nil
+	}
+
+	removed := lo.Filter(tableColumns, func(c *ColumnRow, _ int) bool {
+		return proto.FindField([]*proto.Model{model}, model.Name, casing.ToLowerCamel(c.ColumnName)) == nil
+	})
+
+	added := lo.Filter(model.Fields, func(f *proto.Field, _ int) bool {
+		if f.Type.Type == proto.Type_TYPE_MODEL {
+			return false
+		}
+		_, exists := lo.Find(tableColumns, func(c *ColumnRow) bool {
+			return c.ColumnName == casing.ToSnake(f.Name)
+		})
+		return !exists
+	})
+
+	if len(removed) != 1 || len(added) != 1 {
+		return nil, nil
+	}
+
+	previousField := proto.FindField(previous.Models, previousModelName, casing.ToLowerCamel(removed[0].ColumnName))
+	if previousField == nil {
+		return nil, nil
+	}
+
+	if !sameColumnType(previousField, added[0]) {
+		return nil, nil
+	}
+
+	// A renamed foreign key must still reference the same model.
+	if previousField.ForeignKeyInfo != nil {
+		relatedModel := previousField.ForeignKeyInfo.RelatedModelName
+		if renamed, ok := modelRenames[relatedModel]; ok {
+			relatedModel = renamed
+		}
+		if relatedModel != added[0].ForeignKeyInfo.RelatedModelName {
+			return nil, nil
+		}
+	}
+
+	return previousField, added[0]
+}
+
+// sameColumns determines if two models result in the same set of columns with the same types.
+func sameColumns(a *proto.Model, b *proto.Model) bool {
+	signatures := func(model *proto.Model) []string {
+		s := []string{}
+		for _, f := range model.Fields {
+			if f.Type.Type == proto.Type_TYPE_MODEL {
+				continue
+			}
+			s = append(s, fmt.Sprintf("%s:%s", f.Name, columnTypeSignature(f)))
+		}
+		sort.Strings(s)
+		return s
+	}
+
+	return strings.Join(signatures(a), ",") == strings.Join(signatures(b), ",")
+}
+
+// sameColumnType determines if two fields are stored in columns of the same type.
+func sameColumnType(a *proto.Field, b *proto.Field) bool {
+	if (a.ForeignKeyInfo == nil) != (b.ForeignKeyInfo == nil) {
+		return false
+	}
+
+	return columnTypeSignature(a) == columnTypeSignature(b)
+}
+
+func columnTypeSignature(field *proto.Field) string {
+	return fmt.Sprintf("%s|%t|%s", field.Type.Type, field.Type.Repeated, field.Type.EnumName.GetValue())
+}
+
+// renameTriggerStmts generates the statements to rename the triggers on a renamed table, as the trigger
+// names are derived from the table name.
+func renameTriggerStmts(triggers []*TriggerRow, oldModelName string, newModelName string) []string {
+	oldTable := casing.ToSnake(oldModelName)
+	newTable := casing.ToSnake(newModelName)
+
+	tableTriggers := lo.Filter(triggers, func(t *TriggerRow, _ int) bool {
+		return t.TableName == oldTable && strings.HasPrefix(t.TriggerName, oldTable+"_")
+	})
+	sort.Slice(tableTriggers, func(i, j int) bool {
+		return tableTriggers[i].TriggerName < tableTriggers[j].TriggerName
+	})
+
+	statements := []string{}
+	for _, trigger := range tableTriggers {
+		newName := newTable + strings.TrimPrefix(trigger.TriggerName, oldTable)
+		statements = append(statements, renameTriggerStmt(trigger.TriggerName, newModelName, newName))
+
+		trigger.TriggerName = newName
+		trigger.TableName = newTable
+	}
+
+	return statements
+}
+
+// renameConstraintStmts generates the statements to rename the constraints on a renamed table whose names
+// are prefixed with the table name, such as its primary key and unique constraints.
+func renameConstraintStmts(constraints []*ConstraintRow, oldModelName string, newModelName string) []string {
+	oldTable := casing.ToSnake(oldModelName)
+	newTable := casing.ToSnake(newModelName)
+
+	tableConstraints := lo.Filter(constraints, func(c *ConstraintRow, _ int) bool {
+		return c.TableName == oldTable && strings.HasPrefix(c.ConstraintName, oldTable+"_")
+	})
+	sort.Slice(tableConstraints, func(i, j int) bool {
+		return tableConstraints[i].ConstraintName < tableConstraints[j].ConstraintName
+	})
+
+	statements := []string{}
+	for _, constraint := range tableConstraints {
+		newName := newTable + strings.TrimPrefix(constraint.ConstraintName, oldTable)
+		statements = append(statements, renameConstraintStmt(newModelName, constraint.ConstraintName, newName))
+
+		constraint.ConstraintName = newName
+		constraint.TableName = newTable
+	}
+
+	return statements
+}
+
+// renameIndexStmts generates the statements to rename the indexes on a renamed table whose names are
+// prefixed with the table name, so that they still match the names of the indexes in the schema.
+func renameIndexStmts(indexes []*IndexRow, oldModelName string, newModelName string) []string {
+	oldTable := casing.ToSnake(oldModelName)
+	newTable := casing.ToSnake(newModelName)
+
+	tableIndexes := lo.Filter(indexes, func(i *IndexRow, _ int) bool {
+		return i.TableName == oldTable && strings.HasPrefix(i.IndexName, oldTable+"_")
+	})
+	sort.Slice(tableIndexes, func(i, j int) bool {
+		return tableIndexes[i].IndexName < tableIndexes[j].IndexName
+	})
+
+	statements := []string{}
+	for _, index := range tableIndexes {
+		newName := newTable + strings.TrimPrefix(index.IndexName, oldTable)
+		statements = append(statements, renameIndexStmt(index.IndexName, newName))
+
+		index.IndexName = newName
+		index.TableName = newTable
+	}
+
+	return statements
+}
@@ -113,6 +113,26 @@ func dropTableStmt(name string) string {
 	return fmt.Sprintf("DROP TABLE %s CASCADE;", Identifier(name))
 }
 
+func renameTableStmt(oldName string, newName string) string {
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", Identifier(oldName), Identifier(newName))
+}
+
+func renameColumnStmt(modelName string, oldName string, newName string) string {
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", Identifier(modelName), Identifier(oldName), Identifier(newName))
+}
+
+func renameTriggerStmt(triggerName string, modelName string, newTriggerName string) string {
+	return fmt.Sprintf("ALTER TRIGGER %s ON %s RENAME TO %s;", triggerName, Identifier(modelName), newTriggerName)
+}
+
+func renameConstraintStmt(modelName string, constraintName string, newConstraintName string) string {
+	return fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s;", Identifier(modelName), constraintName, newConstraintName)
+}
+
+func renameIndexStmt(indexName string, newIndexName string) string {
+	return fmt.Sprintf("ALTER INDEX %s RENAME TO %s;", db.QuoteIdentifier(indexName), db.QuoteIdentifier(newIndexName))
+}
+
 func addUniqueConstraintStmt(schema *proto.Schema, modelName string, fieldNames []string) (string, error) {
 	slices.Sort(fieldNames)
 
@@ -227,17 +247,43 @@ func alterColumnStmt(modelName string, field *proto.Field, column *ColumnRow) (s
 	return strings.Join(stmts, "\n"), nil
 }
 
-func fieldDefinition(field *proto.Field) (string, error) {
-	columnName := Identifier(field.Name)
+// We don't yet support Postgres JSON field types in Keel schemas.
+// But we need one for the special case of the keel_audit table.
+// So we hard code the JSON field type for now, for that special case.
+func isAuditDataColumn(field *proto.Field) bool {
+	return (field.ModelName == strcase.ToCamel(auditing.TableName)) && (field.Name == auditing.ColumnData)
+}
+
+// alterColumnTypeStmt changes the type of the column to the type of the field, casting the existing values.
+// A scalar column which becomes repeated has each value wrapped in an array. The default is dropped
+// first in case it cannot be cast, and so must then be set again.
+func alterColumnTypeStmt(modelName string, field *proto.Field, column *ColumnRow, from string) string {
+	stmts := []string{}
+
+	alterColumnStmtPrefix := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", Identifier(modelName), Identifier(column.ColumnName))
 
-	// We don't yet support Postgres JSON field types in Keel schemas.
-	// But we need one for the special case of the keel_audit table.
-	// So we hard code the JSON field type for now, for that special case.
+	if column.HasDefault {
+		stmts = append(stmts, fmt.Sprintf("%s DROP DEFAULT;", alterColumnStmtPrefix))
+	}
+
+	to := columnType(field)
+	value := Identifier(column.ColumnName)
+
+	using := fmt.Sprintf("%s::%s", value, to)
+	if !strings.HasSuffix(from, "[]") && strings.HasSuffix(to, "[]") {
+		using = fmt.Sprintf("CASE WHEN %s IS NULL THEN NULL ELSE ARRAY[%s::%s] END", value, value, strings.TrimSuffix(to, "[]"))
+	}
+
+	stmts = append(stmts, fmt.Sprintf("%s TYPE %s USING %s;", alterColumnStmtPrefix, to, using))
 
-	isAuditDataColumn := (field.ModelName == strcase.ToCamel(auditing.TableName)) && (field.Name == auditing.ColumnData)
+	return strings.Join(stmts, "\n")
+}
+
+func fieldDefinition(field *proto.Field) (string, error) {
+	columnName := Identifier(field.Name)
 
 	fieldType := lo.Ternary(
-		isAuditDataColumn,
+		isAuditDataColumn(field),
 		"jsonb",
 		PostgresFieldTypes[field.Type.Type])
 
@@ -359,27 +405,48 @@ func dropColumnStmt(modelName string, fieldName string) string {
 }
 
 // createAuditTriggerStmts generates the CREATE TRIGGER statements for auditing.
-// Only creates a trigger if the trigger does not already exist in the database.
+// Only creates a trigger if the trigger does not already exist in the database, or replaces it
+// if the columns which are excluded from the audit data have changed.
 func createAuditTriggerStmts(triggers []*TriggerRow, model *proto.Model) string {
 	modelLower := casing.ToSnake(model.Name)
 	statements := []string{}
 
-	create := fmt.Sprintf("%s_create", modelLower)
-	if _, found := lo.Find(triggers, func(t *TriggerRow) bool { return t.TriggerName == create && t.TableName == modelLower }); !found {
-		statements = append(statements, fmt.Sprintf(
-			`CREATE TRIGGER %s AFTER INSERT ON %s REFERENCING NEW TABLE AS new_table FOR EACH STATEMENT EXECUTE PROCEDURE process_audit();`, create, Identifier(model.Name)))
+	// The values of Secret fields are never recorded in the audit data
+	excluded := lo.Map(model.SecretFields(), func(f *proto.Field, _ int) string {
+		return db.QuoteLiteral(casing.ToSnake(f.Name))
+	})
+	procedure := fmt.Sprintf("process_audit(%s)", strings.Join(excluded, ", "))
+
+	auditTriggers := []struct {
+		name      string
+		statement string
+	}{
+		{
+			name:      fmt.Sprintf("%s_create", modelLower),
+			statement: "AFTER INSERT ON %s REFERENCING NEW TABLE AS new_table",
+		},
+		{
+			name:      fmt.Sprintf("%s_update", modelLower),
+			statement: "AFTER UPDATE ON %s REFERENCING NEW TABLE AS new_table OLD TABLE AS old_table",
+		},
+		{
+			name:      fmt.Sprintf("%s_delete", modelLower),
+			statement: "AFTER DELETE ON %s REFERENCING OLD TABLE AS old_table",
+		},
 	}
 
-	update := fmt.Sprintf("%s_update", modelLower)
-	if _, found := lo.Find(triggers, func(t *TriggerRow) bool { return t.TriggerName == update && t.TableName == modelLower }); !found {
-		statements = append(statements, fmt.Sprintf(
-			`CREATE TRIGGER %s AFTER UPDATE ON %s REFERENCING NEW TABLE AS new_table OLD TABLE AS old_table FOR EACH STATEMENT EXECUTE PROCEDURE process_audit();`, update, Identifier(model.Name)))
-	}
+	for _, trigger := range auditTriggers {
+		existing, found := lo.Find(triggers, func(t *TriggerRow) bool { return t.TriggerName == trigger.name && t.TableName == modelLower })
+		if found && strings.HasSuffix(existing.ActionStatement, procedure) {
+			continue
+		}
+
+		if found {
+			statements = append(statements, fmt.Sprintf("DROP TRIGGER %s ON %s;", trigger.name, Identifier(model.Name)))
+		}
 
-	delete := fmt.Sprintf("%s_delete", modelLower)
-	if _, found := lo.Find(triggers, func(t *TriggerRow) bool { return t.TriggerName == delete && t.TableName == modelLower }); !found {
 		statements = append(statements, fmt.Sprintf(
-			`CREATE TRIGGER %s AFTER DELETE ON %s REFERENCING OLD TABLE AS old_table FOR EACH STATEMENT EXECUTE PROCEDURE process_audit();`, delete, Identifier(model.Name)))
+			`CREATE TRIGGER %s %s FOR EACH STATEMENT EXECUTE PROCEDURE %s;`, trigger.name, fmt.Sprintf(trigger.statement, Identifier(model.Name)), procedure))
 	}
 
 	return strings.Join(statements, "\n")
@@ -0,0 +1,245 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// The column types as named by Postgres when introspecting the columns, mapped to the
+// types in PostgresFieldTypes. Columns of any other type are never changed.
+var introspectedTypes = map[string]string{
+	"text":                     "TEXT",
+	"integer":                  "INTEGER",
+	"numeric":                  "NUMERIC",
+	"boolean":                  "BOOL",
+	"timestamp with time zone": "TIMESTAMPTZ",
+	"date":                     "DATE",
+	"vector":                   "VECTOR",
+	"jsonb":                    "JSONB",
+}
+
+// The column types which the values of a column can be cast to without any loss or the cast failing.
+var safeCasts = map[string][]string{
+	"INTEGER":     {"NUMERIC", "TEXT"},
+	"NUMERIC":     {"TEXT"},
+	"BOOL":        {"TEXT"},
+	"DATE":        {"TIMESTAMPTZ", "TEXT"},
+	"TIMESTAMPTZ": {"TEXT"},
+}
+
+// The names of the field types in the schema, used to describe type changes.
+var fieldTypeNames = map[proto.Type]string{
+	proto.Type_TYPE_ID:        parser.FieldTypeID,
+	proto.Type_TYPE_STRING:    parser.FieldTypeText,
+	proto.Type_TYPE_INT:       parser.FieldTypeNumber,
+	proto.Type_TYPE_DECIMAL:   parser.FieldTypeDecimal,
+	proto.Type_TYPE_BOOL:      parser.FieldTypeBoolean,
+	proto.Type_TYPE_TIMESTAMP: parser.FieldTypeDatetime,
+	proto.Type_TYPE_DATETIME:  parser.FieldTypeDatetime,
+	proto.Type_TYPE_DATE:      parser.FieldTypeDate,
+	proto.Type_TYPE_SECRET:    parser.FieldTypeSecret,
+	proto.Type_TYPE_PASSWORD:  parser.FieldTypePassword,
+	proto.Type_TYPE_MARKDOWN:  parser.FieldTypeMarkdown,
+	proto.Type_TYPE_VECTOR:    parser.FieldTypeVector,
+	proto.Type_TYPE_FILE:      parser.FieldTypeFile,
+}
+
+// UnsafeTypeChange is a field whose type has changed to one which its existing values cannot safely be converted to.
+type UnsafeTypeChange struct {
+	Model  string
+	Field  string
+	From   string
+	To     string
+	Reason string
+}
+
+func (c *UnsafeTypeChange) String() string {
+	s := fmt.Sprintf("%s.%s from %s to %s", c.Model, c.Field, c.From, c.To)
+	if c.Reason != "" {
+		s += ", " + c.Reason
+	}
+	return s
+}
+
+const secretTypeChangeReason = "as Secret values are stored encrypted"
+
+// UnsafeTypeChangeError is returned when generating migrations, before anything is applied, if the type of any
+// fields has changed in a way which could lose or fail to convert the existing values of their columns.
+type UnsafeTypeChangeError struct {
+	Changes []*UnsafeTypeChange
+}
+
+func (e *UnsafeTypeChangeError) Error() string {
+	lines := []string{"the type of these fields cannot be changed as their existing values may not convert safely, so instead add a new field and copy the values across:"}
+	for _, c := range e.Changes {
+		lines = append(lines, fmt.Sprintf("  - %s", c.String()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckTypeChanges compares the fields of the schema with the same fields in the previous schema, such as the
+// one stored with the latest migration file, and returns an UnsafeTypeChangeError if any have changed to a
+// type which cannot be safely converted to. Changes which depend on the existing values, such as from Text
+// to an enum, are only checked when the migrations are generated against the database.
+func CheckTypeChanges(previous *proto.Schema, schema *proto.Schema) error {
+	unsafe := []*UnsafeTypeChange{}
+
+	for _, model := range schema.Models {
+		for _, field := range model.Fields {
+			if field.Type.Type == proto.Type_TYPE_MODEL {
+				continue
+			}
+
+			previousField := proto.FindField(previous.Models, model.Name, field.Name)
+			if previousField == nil || previousField.Type.Type == proto.Type_TYPE_MODEL {
+				continue
+			}
+
+			from, to := columnType(previousField), columnType(field)
+			if from != to && !canCast(from, to) {
+				unsafe = append(unsafe, &UnsafeTypeChange{
+					Model: model.Name,
+					Field: field.Name,
+					From:  describeType(previousField),
+					To:    describeType(field),
+				})
+				continue
+			}
+
+			if changedSecret(previousField, field) {
+				unsafe = append(unsafe, &UnsafeTypeChange{
+					Model:  model.Name,
+					Field:  field.Name,
+					From:   describeType(previousField),
+					To:     describeType(field),
+					Reason: secretTypeChangeReason,
+				})
+			}
+		}
+	}
+
+	if len(unsafe) > 0 {
+		return &UnsafeTypeChangeError{Changes: unsafe}
+	}
+
+	return nil
+}
+
+// columnType is the type of the column for the field, e.g. NUMERIC or TEXT[].
+func columnType(field *proto.Field) string {
+	fieldType := PostgresFieldTypes[field.Type.Type]
+	if isAuditDataColumn(field) {
+		fieldType = "JSONB"
+	}
+
+	if field.Type.Repeated {
+		fieldType += "[]"
+	}
+
+	return fieldType
+}
+
+// introspectedColumnType is the type of the existing column in the same form as columnType, or false if
+// the column's type is not one which Keel creates.
+func introspectedColumnType(column *ColumnRow) (string, bool) {
+	dataType := strings.TrimSuffix(column.DataType, "[]")
+	columnType, ok := introspectedTypes[dataType]
+	if !ok {
+		return "", false
+	}
+
+	if dataType != column.DataType {
+		columnType += "[]"
+	}
+
+	return columnType, true
+}
+
+// canCast is true if the values of a column can always be converted from one type to the other, including
+// from a scalar type to an array. An array cannot be converted to a scalar type.
+func canCast(from string, to string) bool {
+	fromArray, toArray := strings.HasSuffix(from, "[]"), strings.HasSuffix(to, "[]")
+	if fromArray && !toArray {
+		return false
+	}
+
+	from, to = strings.TrimSuffix(from, "[]"), strings.TrimSuffix(to, "[]")
+
+	return from == to || lo.Contains(safeCasts[from], to)
+}
+
+// describeType names the type of the field as it appears in the schema, e.g. Decimal or Text[].
+func describeType(field *proto.Field) string {
+	name, ok := fieldTypeNames[field.Type.Type]
+	if field.Type.Type == proto.Type_TYPE_ENUM && field.Type.EnumName != nil {
+		name, ok = field.Type.EnumName.Value, true
+	}
+	if !ok {
+		name = strings.ToLower(strings.TrimPrefix(field.Type.Type.String(), "TYPE_"))
+	}
+
+	if field.Type.Repeated {
+		name += "[]"
+	}
+
+	return name
+}
+
+// invalidEnumValues returns some of the values in the column which are not values of the enum.
+func invalidEnumValues(ctx context.Context, database db.Database, modelName string, column *ColumnRow, enum *proto.Enum) ([]string, error) {
+	values := lo.Map(enum.Values, func(v *proto.EnumValue, _ int) string {
+		return db.QuoteLiteral(v.Name)
+	})
+
+	columnName := db.QuoteIdentifier(column.ColumnName)
+	from := Identifier(modelName)
+	if strings.HasSuffix(column.DataType, "[]") {
+		from = fmt.Sprintf("%s, unnest(%s) AS value", from, columnName)
+		columnName = "value"
+	}
+
+	sql := fmt.Sprintf("SELECT DISTINCT %s::text AS value FROM %s WHERE %s IS NOT NULL", columnName, from, columnName)
+	if len(values) > 0 {
+		sql += fmt.Sprintf(" AND %s NOT IN (%s)", columnName, strings.Join(values, ", "))
+	}
+	sql += " ORDER BY 1 LIMIT 5"
+
+	result, err := database.ExecuteQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := []string{}
+	for _, row := range result.Rows {
+		if v, ok := row["value"].(string); ok {
+			invalid = append(invalid, v)
+		}
+	}
+
+	return invalid, nil
+}
+
+// changedToEnum is true if the field is an enum and was previously not the same enum.
+// changedSecret is true if the field has changed to or from a Secret. Secret values are stored encrypted in
+// a TEXT column just like Text, and so the change would leave the existing values unreadable or unencrypted.
+func changedSecret(previousField *proto.Field, field *proto.Field) bool {
+	if previousField == nil || previousField.Type.Type == field.Type.Type {
+		return false
+	}
+
+	return previousField.Type.Type == proto.Type_TYPE_SECRET || field.Type.Type == proto.Type_TYPE_SECRET
+}
+
+func changedToEnum(previousField *proto.Field, field *proto.Field) bool {
+	if field.Type.Type != proto.Type_TYPE_ENUM || previousField == nil {
+		return false
+	}
+
+	return previousField.Type.Type != proto.Type_TYPE_ENUM || previousField.Type.EnumName.GetValue() != field.Type.EnumName.GetValue()
+}
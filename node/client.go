@@ -272,6 +272,8 @@ func toClientActionReturnType(model *proto.Model, op *proto.Action) string {
 		return "{results: " + respName + "[], pageInfo: PageInfo}"
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		return "string"
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY, proto.ActionType_ACTION_TYPE_UPDATE_MANY, proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		return "{ids: string[], count: number}"
 	case proto.ActionType_ACTION_TYPE_READ, proto.ActionType_ACTION_TYPE_WRITE:
 		if op.ResponseMessageName == parser.MessageFieldTypeAny {
 			return "any"
@@ -80,11 +80,23 @@ func generateSdkPackage(schema *proto.Schema, cfg *config.ProjectConfig) codegen
 	writeFunctionHookTypes(sdkTypes)
 
 	writeTableConfig(sdk, schema.Models)
+	if schema.HasSecretFields() {
+		writeSecretFieldsConfig(sdk, schema.Models)
+	}
 	writeAPIFactory(sdk, schema)
 
 	sdk.Writeln("module.exports.useDatabase = runtime.useDatabase;")
 	sdk.Writeln("module.exports.errors = runtime.ErrorPresets;")
 
+	// Functions can emit any of the events declared in the schema
+	for _, event := range schema.UserDefinedEvents() {
+		sdkTypes.Writef("export declare function emit(eventName: %q, data: %s): Promise<void>;", event.Name, event.MessageName)
+		sdkTypes.Writeln("")
+	}
+	if len(schema.UserDefinedEvents()) > 0 {
+		sdk.Writeln("module.exports.emit = runtime.emit;")
+	}
+
 	for _, model := range schema.Models {
 		writeTableInterface(sdkTypes, model)
 		writeModelInterface(sdkTypes, model, false)
@@ -1018,7 +1030,12 @@ func writeAPIFactory(w *codegen.Writer, schema *proto.Schema) {
 		// default values are now set in the database so this is no longer needed.
 		// Passing a no-op function here for backwards compatibility with older versions of the
 		// functions-runtime package.
-		w.Writef(`new runtime.ModelAPI("%s", () => ({}), tableConfigMap)`, casing.ToSnake(model.Name))
+		// Secret fields are encrypted by the model API, so it is given their columns for each table
+		if schema.HasSecretFields() {
+			w.Writef(`new runtime.ModelAPI("%s", () => ({}), tableConfigMap, secretFieldsMap)`, casing.ToSnake(model.Name))
+		} else {
+			w.Writef(`new runtime.ModelAPI("%s", () => ({}), tableConfigMap)`, casing.ToSnake(model.Name))
+		}
 
 		w.Writeln(",")
 	}
@@ -1086,6 +1103,23 @@ func writeTableConfig(w *codegen.Writer, models []*proto.Model) {
 	w.Writeln(";")
 }
 
+// writeSecretFieldsConfig writes the columns of the Secret fields of each model, which the model API encrypts
+// when writing and decrypts when reading.
+func writeSecretFieldsConfig(w *codegen.Writer, models []*proto.Model) {
+	w.Write("const secretFieldsMap = ")
+
+	secretFieldsMap := map[string][]string{}
+	for _, model := range models {
+		for _, field := range model.SecretFields() {
+			secretFieldsMap[casing.ToSnake(model.Name)] = append(secretFieldsMap[casing.ToSnake(model.Name)], casing.ToSnake(field.Name))
+		}
+	}
+
+	b, _ := json.MarshalIndent(secretFieldsMap, "", "    ")
+	w.Write(string(b))
+	w.Writeln(";")
+}
+
 var (
 	//go:embed templates/**/*
 	templates embed.FS
@@ -1322,6 +1356,8 @@ func toActionReturnType(model *proto.Model, op *proto.Action) string {
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		// todo: create ID type
 		returnType += "string"
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY, proto.ActionType_ACTION_TYPE_UPDATE_MANY, proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		returnType += "{ids: string[], count: number}"
 	case proto.ActionType_ACTION_TYPE_READ, proto.ActionType_ACTION_TYPE_WRITE:
 		returnType += op.ResponseMessageName
 	}
@@ -1514,7 +1550,7 @@ func generateTestingPackage(schema *proto.Schema) codegen.GeneratedFiles {
 	js.Indent()
 	js.Writeln("const db = useDatabase();")
 	js.Write("await sql`TRUNCATE TABLE ")
-	tableNames := []string{"keel_audit", "keel_storage"}
+	tableNames := []string{"keel_audit", "keel_storage", "keel_rate_limit"}
 	for _, model := range schema.Models {
 		tableNames = append(tableNames, fmt.Sprintf("\"%s\"", casing.ToSnake(model.Name)))
 	}
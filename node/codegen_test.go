@@ -1778,6 +1778,43 @@ export interface SendWelcomeEmailMemberCreatedEventTarget {
 	})
 }
 
+func TestWriteSubscriberMessagesUserDefinedEvent(t *testing.T) {
+	t.Parallel()
+	schema := `
+model Order {
+	fields {
+		reference Text
+	}
+	actions {
+		update ship(id) with (reference) {
+			@emit(order.shipped)
+		}
+	}
+}
+event order.shipped {
+	fields {
+		reference Text
+	}
+	@on(notifyCustomer)
+}`
+
+	expected := `
+export interface OrderShippedEventPayload {
+	reference: string;
+}
+export type NotifyCustomerEvent = (NotifyCustomerOrderShippedEvent);
+export interface NotifyCustomerOrderShippedEvent {
+	eventName: "order.shipped";
+	occurredAt: Date;
+	identityId?: string;
+	data: OrderShippedEventPayload;
+}`
+
+	runWriterTest(t, schema, expected, func(s *proto.Schema, w *codegen.Writer) {
+		writeMessages(w, s, false, false)
+	})
+}
+
 func TestWriteSubscriberFunctionWrapperType(t *testing.T) {
 	t.Parallel()
 	schema := `
@@ -2109,6 +2146,58 @@ const tableConfigMap = {
 	})
 }
 
+func TestWriteSecretFieldsConfig(t *testing.T) {
+	t.Parallel()
+	schema := `
+model Integration {
+	fields {
+		name Text
+		apiKey Secret
+		webhookSecret Secret?
+	}
+}
+model Customer {
+	fields {
+		stripeToken Secret
+	}
+}`
+	expected := `
+const secretFieldsMap = {
+	"customer": [
+		"stripe_token"
+	],
+	"integration": [
+		"api_key",
+		"webhook_secret"
+	]
+};`
+
+	runWriterTest(t, schema, expected, func(s *proto.Schema, w *codegen.Writer) {
+		writeSecretFieldsConfig(w, s.Models)
+	})
+}
+
+func TestWriteModelAPIWithSecretFields(t *testing.T) {
+	t.Parallel()
+	schema := `
+model Integration {
+	fields {
+		apiKey Secret
+	}
+}`
+	expected := `
+function createModelAPI() {
+	return {
+		integration: new runtime.ModelAPI("integration", () => ({}), tableConfigMap, secretFieldsMap),
+		identity: new runtime.ModelAPI("identity", () => ({}), tableConfigMap, secretFieldsMap),
+	};
+};`
+
+	runWriterTest(t, schema, expected, func(s *proto.Schema, w *codegen.Writer) {
+		writeAPIFactory(w, s)
+	})
+}
+
 func TestWriteTestingTypesEnums(t *testing.T) {
 	t.Parallel()
 	schema := `
@@ -130,6 +130,100 @@ export class Core {
     },
   };
 
+  files = {
+    /**
+     * Uploads a file directly to storage for the given model and File field and, if successful, returns data field set to the file key.
+     * The key can then be used as the input for the File field in a create or update action, instead of a data URL.
+     * Returns error field if an error occurred.
+     */
+    upload: async (input: FileUploadInput): Promise<APIResult<string>> => {
+      try {
+        // If necessary, refresh the expired session before requesting the ticket
+        const isAuth = await this.auth.isAuthenticated();
+        if (isAuth.error) {
+          return { error: isAuth.error };
+        }
+
+        const token = this.auth.accessToken.get();
+
+        const url = new URL(this.config.baseUrl);
+        const result = await globalThis.fetch(url.origin + "/files/ticket", {
+          method: "POST",
+          cache: "no-cache",
+          headers: {
+            accept: "application/json",
+            "content-type": "application/json",
+            ...this.config.headers,
+            ...(token != null
+              ? {
+                  Authorization: "Bearer " + token,
+                }
+              : {}),
+          },
+          body: JSON.stringify({
+            model: input.model,
+            field: input.field,
+            filename: input.filename || (input.file as File).name,
+            contentType: input.file.type,
+            size: input.file.size,
+          }),
+        });
+
+        if (!result.ok) {
+          let errorMessage = "unknown error";
+
+          try {
+            const errorData: {
+              message: string;
+            } = await result.json();
+            errorMessage = errorData.message;
+          } catch (error) {}
+
+          return {
+            error: {
+              type:
+                result.status == 400
+                  ? "bad_request"
+                  : result.status == 403
+                  ? "forbidden"
+                  : "unknown",
+              message: errorMessage,
+            },
+          };
+        }
+
+        const ticket: UploadTicket = await result.json();
+
+        const upload = await globalThis.fetch(ticket.url, {
+          method: ticket.method,
+          headers: ticket.headers,
+          body: input.file,
+        });
+
+        if (!upload.ok) {
+          return {
+            error: {
+              type: "unknown",
+              message:
+                "unexpected status code response when uploading file: " +
+                upload.status,
+            },
+          };
+        }
+
+        return { data: ticket.key };
+      } catch (error) {
+        return {
+          error: {
+            type: "unknown",
+            message: "unknown error",
+            error,
+          },
+        };
+      }
+    },
+  };
+
   auth = {
     /**
      * Get or set the access token from the configured token store.
@@ -140,6 +140,23 @@ export type TokenRequest =
   | AuthorizationCodeGrant
   | RefreshGrant;
 
+// Files
+
+export interface FileUploadInput {
+  model: string;
+  field: string;
+  file: Blob;
+  filename?: string;
+}
+
+type UploadTicket = {
+  key: string;
+  url: string;
+  method: string;
+  headers: globalThis.Record<string, string>;
+  expiresAt: string;
+};
+
 export type SortDirection = "asc" | "desc" | "ASC" | "DESC";
 
 type PageInfo = {
@@ -23,6 +23,10 @@ const {
 } = require("./casing");
 const tracing = require("./tracing");
 const { DatabaseError } = require("./errors");
+const {
+  encryptSecretFields,
+  decryptSecretFields,
+} = require("./encryption");
 
 /**
  * RelationshipConfig is a simple representation of a model field that
@@ -41,6 +45,10 @@ const { DatabaseError } = require("./errors");
  *
  * TableConfigMap is mapping of database table names to TableConfig objects
  * @typedef {Object.<string, TableConfig>} TableConfigMap
+ *
+ * SecretFieldsMap is a mapping of database table names to the columns of
+ * Secret fields, which are encrypted when written and decrypted when read
+ * @typedef {Object.<string, string[]>} SecretFieldsMap
  */
 
 class ModelAPI {
@@ -48,10 +56,12 @@ class ModelAPI {
    * @param {string} tableName The name of the table this API is for
    * @param {Function} _ Used to be a function that returns the default values for a row in this table. No longer used.
    * @param {TableConfigMap} tableConfigMap
+   * @param {SecretFieldsMap} secretFieldsMap
    */
-  constructor(tableName, _, tableConfigMap = {}) {
+  constructor(tableName, _, tableConfigMap = {}, secretFieldsMap = {}) {
     this._tableName = tableName;
     this._tableConfigMap = tableConfigMap;
+    this._secretFieldsMap = secretFieldsMap;
     this._modelName = upperCamelCase(this._tableName);
   }
 
@@ -64,6 +74,7 @@ class ModelAPI {
         db,
         this._tableName,
         this._tableConfigMap,
+        this._secretFieldsMap,
         snakeCaseObject(values)
       );
     });
@@ -90,7 +101,9 @@ class ModelAPI {
         return null;
       }
 
-      return transformRichDataTypes(camelCaseObject(row));
+      return transformRichDataTypes(
+        camelCaseObject(decryptSecretFields(row, this._secretColumns()))
+      );
     });
   }
 
@@ -146,7 +159,11 @@ class ModelAPI {
 
       span.setAttribute("sql", query.compile().sql);
       const rows = await builder.execute();
-      return rows.map((x) => transformRichDataTypes(camelCaseObject(x)));
+      return rows.map((x) =>
+        transformRichDataTypes(
+          camelCaseObject(decryptSecretFields(x, this._secretColumns()))
+        )
+      );
     });
   }
 
@@ -174,7 +191,9 @@ class ModelAPI {
         }
       }
 
-      builder = builder.set(snakeCaseObject(row));
+      builder = builder.set(
+        encryptSecretFields(snakeCaseObject(row), this._secretColumns())
+      );
 
       const context = new QueryContext([this._tableName], this._tableConfigMap);
 
@@ -185,7 +204,9 @@ class ModelAPI {
 
       try {
         const row = await builder.executeTakeFirstOrThrow();
-        return transformRichDataTypes(camelCaseObject(row));
+        return transformRichDataTypes(
+          camelCaseObject(decryptSecretFields(row, this._secretColumns()))
+        );
       } catch (e) {
         throw new DatabaseError(e);
       }
@@ -227,11 +248,20 @@ class ModelAPI {
     builder = applyJoins(context, builder, where);
     builder = applyWhereConditions(context, builder, where);
 
-    return new QueryBuilder(this._tableName, context, builder);
+    return new QueryBuilder(
+      this._tableName,
+      context,
+      builder,
+      this._secretColumns()
+    );
+  }
+
+  _secretColumns() {
+    return this._secretFieldsMap[this._tableName] || [];
   }
 }
 
-async function create(conn, tableName, tableConfigs, values) {
+async function create(conn, tableName, tableConfigs, secretFields, values) {
   try {
     let query = conn.insertInto(tableName);
 
@@ -277,6 +307,7 @@ async function create(conn, tableName, tableConfigs, values) {
               conn,
               columnConfig.referencesTable,
               tableConfigs,
+              secretFields,
               value
             );
             row[columnConfig.foreignKey] = created.id;
@@ -303,7 +334,9 @@ async function create(conn, tableName, tableConfigs, values) {
         }
       }
 
-      query = query.values(row);
+      query = query.values(
+        encryptSecretFields(row, secretFields[tableName])
+      );
     }
 
     const created = await query.returningAll().executeTakeFirstOrThrow();
@@ -322,14 +355,22 @@ async function create(conn, tableName, tableConfigs, values) {
           );
         }
 
-        return create(conn, columnConfig.referencesTable, tableConfigs, {
-          ...value,
-          [columnConfig.foreignKey]: created.id,
-        });
+        return create(
+          conn,
+          columnConfig.referencesTable,
+          tableConfigs,
+          secretFields,
+          {
+            ...value,
+            [columnConfig.foreignKey]: created.id,
+          }
+        );
       })
     );
 
-    return transformRichDataTypes(created);
+    return transformRichDataTypes(
+      decryptSecretFields(created, secretFields[tableName])
+    );
   } catch (e) {
     throw new DatabaseError(e);
   }
@@ -3,6 +3,7 @@ const { InlineFile, File } = require("./File");
 const { ModelAPI } = require("./ModelAPI");
 const { sql } = require("kysely");
 const { useDatabase } = require("./database");
+const { withEncryptionKeys } = require("./encryption");
 const KSUID = require("ksuid");
 
 let personAPI;
@@ -1467,3 +1468,70 @@ describe("QueryBuilder", () => {
     expect(rows.map((x) => x.id).sort()).toEqual([p1.id, p2.id].sort());
   });
 });
+
+describe("Secret fields", () => {
+  // base64 encoding of "0123456789abcdef0123456789abcdef"
+  const request = {
+    meta: { encryptionKeys: ["MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="] },
+  };
+
+  let integrationAPI;
+
+  beforeEach(async () => {
+    const db = useDatabase();
+
+    await sql`
+    DROP TABLE IF EXISTS integration;
+    CREATE TABLE integration(
+      id               text PRIMARY KEY,
+      name             text,
+      api_key          text
+    );`.execute(db);
+
+    integrationAPI = new ModelAPI(
+      "integration",
+      undefined,
+      {},
+      { integration: ["api_key"] }
+    );
+  });
+
+  test("values are encrypted when written and decrypted when read", async () => {
+    await withEncryptionKeys(request, async () => {
+      const created = await integrationAPI.create({
+        id: KSUID.randomSync().string,
+        name: "Stripe",
+        apiKey: "sk_live_123",
+      });
+      expect(created.apiKey).toEqual("sk_live_123");
+
+      const stored = await sql`SELECT api_key FROM integration`.execute(
+        useDatabase()
+      );
+      expect(stored.rows[0].api_key.startsWith("keel:enc:v1:")).toBe(true);
+
+      expect(
+        (await integrationAPI.findOne({ id: created.id })).apiKey
+      ).toEqual("sk_live_123");
+      expect((await integrationAPI.findMany())[0].apiKey).toEqual(
+        "sk_live_123"
+      );
+      expect(
+        (await integrationAPI.where({ name: "Stripe" }).findOne()).apiKey
+      ).toEqual("sk_live_123");
+
+      const updated = await integrationAPI.update(
+        { id: created.id },
+        { apiKey: "sk_live_456" }
+      );
+      expect(updated.apiKey).toEqual("sk_live_456");
+
+      const updatedStored =
+        await sql`SELECT api_key FROM integration`.execute(useDatabase());
+      expect(updatedStored.rows[0].api_key).not.toContain("sk_live_456");
+      expect(
+        (await integrationAPI.findOne({ id: created.id })).apiKey
+      ).toEqual("sk_live_456");
+    });
+  });
+});
@@ -15,17 +15,23 @@ const { transformRichDataTypes } = require("./parsing");
 const { QueryContext } = require("./QueryContext");
 const tracing = require("./tracing");
 const { DatabaseError } = require("./errors");
+const {
+  encryptSecretFields,
+  decryptSecretFields,
+} = require("./encryption");
 
 class QueryBuilder {
   /**
    * @param {string} tableName
    * @param {import("./QueryContext").QueryContext} context
    * @param {import("kysely").Kysely} db
+   * @param {string[]} secretColumns The columns of the model's Secret fields
    */
-  constructor(tableName, context, db) {
+  constructor(tableName, context, db, secretColumns = []) {
     this._tableName = tableName;
     this._context = context;
     this._db = db;
+    this._secretColumns = secretColumns;
     this._modelName = upperCamelCase(this._tableName);
   }
 
@@ -35,7 +41,12 @@ class QueryBuilder {
     let builder = applyJoins(context, this._db, where);
     builder = applyWhereConditions(context, builder, where);
 
-    return new QueryBuilder(this._tableName, context, builder);
+    return new QueryBuilder(
+      this._tableName,
+      context,
+      builder,
+      this._secretColumns
+    );
   }
 
   orWhere(where) {
@@ -47,7 +58,12 @@ class QueryBuilder {
       return applyWhereConditions(context, qb, where);
     });
 
-    return new QueryBuilder(this._tableName, context, builder);
+    return new QueryBuilder(
+      this._tableName,
+      context,
+      builder,
+      this._secretColumns
+    );
   }
 
   sql() {
@@ -65,7 +81,9 @@ class QueryBuilder {
 
       const query = db
         .updateTable(this._tableName)
-        .set(snakeCaseObject(values))
+        .set(
+          encryptSecretFields(snakeCaseObject(values), this._secretColumns)
+        )
         .returningAll()
         .where("id", "in", sub);
 
@@ -86,7 +104,9 @@ class QueryBuilder {
           );
         }
 
-        return transformRichDataTypes(camelCaseObject(result[0]));
+        return transformRichDataTypes(
+          camelCaseObject(decryptSecretFields(result[0], this._secretColumns))
+        );
       } catch (e) {
         throw new DatabaseError(e);
       }
@@ -140,7 +160,9 @@ class QueryBuilder {
         return null;
       }
 
-      return transformRichDataTypes(camelCaseObject(row));
+      return transformRichDataTypes(
+        camelCaseObject(decryptSecretFields(row, this._secretColumns))
+      );
     });
   }
 
@@ -215,7 +237,11 @@ class QueryBuilder {
 
       span.setAttribute("sql", query.compile().sql);
       const rows = await builder.execute();
-      return rows.map((x) => transformRichDataTypes(camelCaseObject(x)));
+      return rows.map((x) =>
+        transformRichDataTypes(
+          camelCaseObject(decryptSecretFields(x, this._secretColumns))
+        )
+      );
     });
   }
 }
@@ -0,0 +1,142 @@
+const { AsyncLocalStorage } = require("async_hooks");
+const crypto = require("crypto");
+
+// Encrypted values are stored with this prefix, followed by the id of the key which encrypted the data key,
+// the encrypted data key and the encrypted value. This must match the format used by the Go runtime.
+const ENCRYPTED_PREFIX = "keel:enc:v1:";
+
+const NONCE_LENGTH = 12;
+const TAG_LENGTH = 16;
+const KEY_LENGTH = 32;
+
+const encryptionKeysStorage = new AsyncLocalStorage();
+
+// withEncryptionKeys sets the encryption keys from the runtime request body in
+// AsyncLocalStorage so that the ModelAPI can encrypt and decrypt Secret fields
+// during the execution of actions, jobs and subscribers. The first key is the
+// current key and any others are previous keys.
+async function withEncryptionKeys(request, cb) {
+  const keys = (request.meta?.encryptionKeys || []).map(parseKey);
+
+  return await encryptionKeysStorage.run(keys, () => {
+    return cb();
+  });
+}
+
+function parseKey(encoded) {
+  const material = Buffer.from(encoded, "base64");
+  const id = crypto
+    .createHash("sha256")
+    .update(material)
+    .digest("hex")
+    .substring(0, 8);
+
+  return { id, material };
+}
+
+function getEncryptionKeys() {
+  const keys = encryptionKeysStorage.getStore();
+  if (!keys || keys.length === 0) {
+    throw new Error(
+      "an encryption key is required to read and write Secret fields"
+    );
+  }
+  return keys;
+}
+
+// encryptSecret encrypts the value with a new data key, which is itself encrypted with the current key.
+function encryptSecret(plaintext) {
+  const key = getEncryptionKeys()[0];
+  const dataKey = crypto.randomBytes(KEY_LENGTH);
+
+  const wrapped = seal(key.material, dataKey);
+  const ciphertext = seal(dataKey, Buffer.from(plaintext, "utf8"));
+
+  return `${ENCRYPTED_PREFIX}${key.id}:${toBase64(wrapped)}:${toBase64(
+    ciphertext
+  )}`;
+}
+
+// decryptSecret decrypts a value encrypted with any of the keys. Values which are not
+// encrypted, such as those written before encryption was enabled, are returned unchanged.
+function decryptSecret(value) {
+  if (typeof value !== "string" || !value.startsWith(ENCRYPTED_PREFIX)) {
+    return value;
+  }
+
+  const parts = value.substring(ENCRYPTED_PREFIX.length).split(":");
+  if (parts.length !== 3) {
+    throw new Error("the encrypted value is malformed");
+  }
+
+  const key = getEncryptionKeys().find((k) => k.id === parts[0]);
+  if (!key) {
+    throw new Error(
+      "the value was encrypted with a key which is not the current or a previous encryption key"
+    );
+  }
+
+  const dataKey = open(key.material, Buffer.from(parts[1], "base64"));
+  return open(dataKey, Buffer.from(parts[2], "base64")).toString("utf8");
+}
+
+// encryptSecretFields encrypts the values of the given columns in a row which is about to be written.
+function encryptSecretFields(row, columns = []) {
+  for (const column of columns) {
+    if (typeof row[column] === "string") {
+      row[column] = encryptSecret(row[column]);
+    }
+  }
+  return row;
+}
+
+// decryptSecretFields decrypts the values of the given columns in a row read from the database.
+function decryptSecretFields(row, columns = []) {
+  for (const column of columns) {
+    if (row && column in row) {
+      row[column] = decryptSecret(row[column]);
+    }
+  }
+  return row;
+}
+
+// seal encrypts the data with AES-256-GCM, prepending the random nonce and appending the auth tag.
+function seal(key, data) {
+  const nonce = crypto.randomBytes(NONCE_LENGTH);
+  const cipher = crypto.createCipheriv("aes-256-gcm", key, nonce);
+  const ciphertext = Buffer.concat([cipher.update(data), cipher.final()]);
+
+  return Buffer.concat([nonce, ciphertext, cipher.getAuthTag()]);
+}
+
+// open decrypts data encrypted by seal.
+function open(key, data) {
+  if (data.length < NONCE_LENGTH + TAG_LENGTH) {
+    throw new Error("the encrypted value is malformed");
+  }
+
+  const nonce = data.subarray(0, NONCE_LENGTH);
+  const tag = data.subarray(data.length - TAG_LENGTH);
+  const ciphertext = data.subarray(NONCE_LENGTH, data.length - TAG_LENGTH);
+
+  try {
+    const decipher = crypto.createDecipheriv("aes-256-gcm", key, nonce);
+    decipher.setAuthTag(tag);
+    return Buffer.concat([decipher.update(ciphertext), decipher.final()]);
+  } catch (e) {
+    throw new Error("the encrypted value could not be decrypted");
+  }
+}
+
+// The Go runtime encodes without padding
+function toBase64(data) {
+  return data.toString("base64").replace(/=+$/, "");
+}
+
+module.exports = {
+  withEncryptionKeys,
+  encryptSecret,
+  decryptSecret,
+  encryptSecretFields,
+  decryptSecretFields,
+};
@@ -0,0 +1,85 @@
+import { test, expect } from "vitest";
+const {
+  withEncryptionKeys,
+  encryptSecret,
+  decryptSecret,
+  encryptSecretFields,
+  decryptSecretFields,
+} = require("./encryption");
+
+// base64 encoding of "0123456789abcdef0123456789abcdef"
+const key = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=";
+const otherKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=";
+
+// Encrypted by the Go runtime with the key above
+const encryptedByRuntime =
+  "keel:enc:v1:3eb1bd43:CrmzKnrriHOQLOw3heJ+81XEK3qPDUlOstYB9vPwYL8XtfJnxlz5gRDW2RPkIojbdADZfdkBBljWBkZg:uJRF+qlajMONr2NlVHINZpW6WJc60/FdI+VmY4orKbbkLMmh3cb6";
+
+function withKeys(keys, cb) {
+  return withEncryptionKeys({ meta: { encryptionKeys: keys } }, cb);
+}
+
+test("encryptSecret - round trip", async () => {
+  await withKeys([key], () => {
+    const encrypted = encryptSecret("sk_live_123");
+    expect(encrypted.startsWith("keel:enc:v1:3eb1bd43:")).toBe(true);
+    expect(encrypted).not.toContain("sk_live_123");
+    expect(encryptSecret("sk_live_123")).not.toEqual(encrypted);
+    expect(decryptSecret(encrypted)).toEqual("sk_live_123");
+  });
+});
+
+test("decryptSecret - value encrypted by the runtime", async () => {
+  await withKeys([key], () => {
+    expect(decryptSecret(encryptedByRuntime)).toEqual("sk_live_123");
+  });
+});
+
+test("decryptSecret - previous key", async () => {
+  await withKeys([otherKey, key], () => {
+    expect(decryptSecret(encryptedByRuntime)).toEqual("sk_live_123");
+  });
+});
+
+test("decryptSecret - unknown key", async () => {
+  await withKeys([otherKey], () => {
+    expect(() => decryptSecret(encryptedByRuntime)).toThrow(
+      "the value was encrypted with a key which is not the current or a previous encryption key"
+    );
+  });
+});
+
+test("decryptSecret - unencrypted and null values", async () => {
+  await withKeys([key], () => {
+    expect(decryptSecret("plaintext")).toEqual("plaintext");
+    expect(decryptSecret(null)).toBeNull();
+  });
+});
+
+test("encryptSecret - no keys", async () => {
+  await withKeys([], () => {
+    expect(() => encryptSecret("sk_live_123")).toThrow(
+      "an encryption key is required to read and write Secret fields"
+    );
+  });
+});
+
+test("encryptSecretFields - only given columns", async () => {
+  await withKeys([key], () => {
+    const row = encryptSecretFields(
+      { name: "Stripe", api_token: "sk_live_123", webhook_secret: null },
+      ["api_token", "webhook_secret"]
+    );
+    expect(row.name).toEqual("Stripe");
+    expect(row.api_token.startsWith("keel:enc:v1:")).toBe(true);
+    expect(row.webhook_secret).toBeNull();
+
+    expect(
+      decryptSecretFields(row, ["api_token", "webhook_secret"])
+    ).toEqual({
+      name: "Stripe",
+      api_token: "sk_live_123",
+      webhook_secret: null,
+    });
+  });
+});
@@ -0,0 +1,24 @@
+const { sql } = require("kysely");
+const KSUID = require("ksuid");
+const { useDatabase } = require("./database");
+const { getAuditContext } = require("./auditing");
+
+// emit records a user-defined event, such as order.shipped, which is sent to its
+// subscribers once the function has completed. The event is written with the trace id
+// of the request so that it is picked up by the runtime along with any model events.
+async function emit(eventName, data) {
+  const audit = getAuditContext();
+  if (!audit.traceId) {
+    throw new Error("events can only be emitted within a traced request");
+  }
+
+  const db = useDatabase();
+
+  await sql`INSERT INTO keel_event (id, name, data, identity_id, trace_id) VALUES (${
+    KSUID.randomSync().string
+  }, ${eventName}, ${JSON.stringify(data || {})}::jsonb, ${
+    audit.identityId || null
+  }, ${audit.traceId})`.execute(db);
+}
+
+module.exports.emit = emit;
@@ -203,6 +203,12 @@ export declare class Permissions {
   deny(): never;
 }
 
+// emit() records a user-defined event, which is sent to its subscribers once the function has completed
+export declare function emit(
+  eventName: string,
+  data?: Record<string, any>
+): Promise<void>;
+
 declare class NotFoundError extends Error {}
 declare class BadRequestError extends Error {}
 declare class UnknownError extends Error {}
@@ -13,6 +13,7 @@ const {
 const tracing = require("./tracing");
 const { InlineFile, File } = require("./File");
 const { ErrorPresets } = require("./errors");
+const { emit } = require("./events");
 
 module.exports = {
   ModelAPI,
@@ -28,6 +29,7 @@ module.exports = {
   checkBuiltInPermissions,
   tracing,
   ErrorPresets,
+  emit,
   ksuid() {
     return KSUID.randomSync().string;
   },
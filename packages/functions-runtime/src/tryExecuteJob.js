@@ -1,5 +1,6 @@
 const { withDatabase } = require("./database");
 const { withAuditContext } = require("./auditing");
+const { withEncryptionKeys } = require("./encryption");
 const { withPermissions, PERMISSION_STATE } = require("./permissions");
 const { PermissionError } = require("./errors");
 
@@ -9,7 +10,7 @@ function tryExecuteJob({ db, permitted, actionType, request }, cb) {
   return withPermissions(permitted, async ({ getPermissionState }) => {
     return withDatabase(db, actionType, async () => {
       await withAuditContext(request, async () => {
-        return cb();
+        return withEncryptionKeys(request, cb);
       });
 
       // api.permissions maintains an internal state of whether the current operation has been *explicitly* permitted/denied by the user in the course of their custom function, or if execution has already been permitted by a role based permission (evaluated in the main runtime).
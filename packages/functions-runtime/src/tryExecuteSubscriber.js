@@ -1,11 +1,12 @@
 const { withDatabase } = require("./database");
 const { withAuditContext } = require("./auditing");
+const { withEncryptionKeys } = require("./encryption");
 
 // tryExecuteSubscriber will create a new database connection and execute the function call.
 function tryExecuteSubscriber({ request, db, actionType }, cb) {
   return withDatabase(db, actionType, async () => {
     await withAuditContext(request, async () => {
-      return cb();
+      return withEncryptionKeys(request, cb);
     });
   });
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/teamkeel/keel/casing"
 	"github.com/teamkeel/keel/db"
 	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/expressions/sqlgen"
 	"github.com/teamkeel/keel/schema/parser"
 )
 
@@ -148,6 +149,13 @@ func handleExpression(s *proto.Schema, m *proto.Model, expr *parser.Expression,
 
 func handleOperand(s *proto.Schema, model *proto.Model, o *parser.Operand, stmt *statement) (err error) {
 	switch {
+	case o.Arithmetic != nil:
+		return handleArithmetic(s, model, o, stmt)
+	case o.Function != nil:
+		return handleFunction(s, model, o.Function, stmt)
+	case o.Duration != nil:
+		stmt.expression += fmt.Sprintf("INTERVAL '%s'", o.Duration.ToString())
+		return nil
 	case o.True:
 		stmt.expression += "true"
 		return nil
@@ -190,6 +198,156 @@ func handleOperand(s *proto.Schema, model *proto.Model, o *parser.Operand, stmt
 	return fmt.Errorf("unsupported operand: %s", o.ToString())
 }
 
+// handleArithmetic writes out the arithmetic operation, applying the operators in order of precedence.
+func handleArithmetic(s *proto.Schema, model *proto.Model, o *parser.Operand, stmt *statement) error {
+	operands, operators := o.ArithmeticTerms()
+
+	terms := make([]*term, len(operands))
+	for i, operand := range operands {
+		var err error
+		terms[i], err = handleTerm(s, model, operand, stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := parser.FoldArithmetic(terms, operators, func(lhs *term, operator string, rhs *term) (*term, error) {
+		t := sqlgen.ArithmeticType(lhs.typ, rhs.typ)
+		return &term{expression: fmt.Sprintf(sqlgen.Arithmetic(operator, t), lhs.expression, rhs.expression), typ: t}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	stmt.expression += result.expression
+	return nil
+}
+
+func handleFunction(s *proto.Schema, model *proto.Model, f *parser.Function, stmt *statement) error {
+	template, err := sqlgen.Function(f.Name.Fragment, len(f.Arguments))
+	if err != nil {
+		return err
+	}
+
+	args := make([]any, len(f.Arguments))
+	for i, arg := range f.Arguments {
+		t, err := handleTerm(s, model, arg, stmt)
+		if err != nil {
+			return err
+		}
+		args[i] = t.expression
+	}
+
+	stmt.expression += fmt.Sprintf(template, args...)
+	return nil
+}
+
+// An operand within an arithmetic operation or function along with the type it resolves to.
+type term struct {
+	expression string
+	typ        proto.Type
+}
+
+// handleTerm writes the operand into its own expression so that it can be combined by an arithmetic operation
+// or function, adding any joins and values to stmt. As the terms are combined in the order they are written,
+// the values remain in the same order as their placeholders.
+func handleTerm(s *proto.Schema, model *proto.Model, o *parser.Operand, stmt *statement) (*term, error) {
+	inner := &statement{}
+	err := handleOperand(s, model, o, inner)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.joins = append(stmt.joins, inner.joins...)
+	stmt.values = append(stmt.values, inner.values...)
+
+	return &term{expression: inner.expression, typ: operandType(s, model, o)}, nil
+}
+
+// operandType determines the type of a literal, field, context value or the value computed by an arithmetic
+// operation or function.
+func operandType(s *proto.Schema, model *proto.Model, o *parser.Operand) proto.Type {
+	switch {
+	case o.String != nil:
+		return proto.Type_TYPE_STRING
+	case o.Number != nil:
+		return proto.Type_TYPE_INT
+	case o.Decimal != nil:
+		return proto.Type_TYPE_DECIMAL
+	case o.True || o.False:
+		return proto.Type_TYPE_BOOL
+	case o.Arithmetic != nil:
+		operands, operators := o.ArithmeticTerms()
+		types := lo.Map(operands, func(operand *parser.Operand, _ int) proto.Type {
+			return operandType(s, model, operand)
+		})
+		t, _ := parser.FoldArithmetic(types, operators, func(lhs proto.Type, _ string, rhs proto.Type) (proto.Type, error) {
+			return sqlgen.ArithmeticType(lhs, rhs), nil
+		})
+		return t
+	case o.Function != nil:
+		argType := proto.Type_TYPE_UNKNOWN
+		if len(o.Function.Arguments) > 0 {
+			argType = operandType(s, model, o.Function.Arguments[0])
+		}
+		t, err := sqlgen.FunctionType(o.Function.Name.Fragment, argType)
+		if err != nil {
+			return proto.Type_TYPE_UNKNOWN
+		}
+		return t
+	case o.Ident == nil:
+		return proto.Type_TYPE_UNKNOWN
+	}
+
+	fragments := o.Ident.Fragments
+	switch {
+	case fragments[0].Fragment == "ctx":
+		if len(fragments) < 2 {
+			return proto.Type_TYPE_UNKNOWN
+		}
+		switch fragments[1].Fragment {
+		case "identity":
+			if len(fragments) == 2 {
+				return proto.Type_TYPE_ID
+			}
+			model = s.FindModel("Identity")
+			fragments = fragments[2:]
+		case "headers", "secrets":
+			return proto.Type_TYPE_STRING
+		case "now":
+			return proto.Type_TYPE_TIMESTAMP
+		case "isAuthenticated":
+			return proto.Type_TYPE_BOOL
+		default:
+			return proto.Type_TYPE_UNKNOWN
+		}
+	case fragments[0].Fragment == casing.ToLowerCamel(model.Name):
+		fragments = fragments[1:]
+	default:
+		return proto.Type_TYPE_UNKNOWN
+	}
+
+	var field *proto.Field
+	for _, f := range fragments {
+		if model == nil {
+			return proto.Type_TYPE_UNKNOWN
+		}
+		field = proto.FindField(s.Models, model.Name, f.Fragment)
+		if field == nil {
+			return proto.Type_TYPE_UNKNOWN
+		}
+		if field.Type.Type == proto.Type_TYPE_MODEL {
+			model = s.FindModel(field.Type.ModelName.Value)
+		}
+	}
+
+	if field == nil {
+		return proto.Type_TYPE_UNKNOWN
+	}
+
+	return field.Type.Type
+}
+
 func handleContext(s *proto.Schema, o *parser.Operand, stmt *statement) error {
 	if len(o.Ident.Fragments) < 2 {
 		return errors.New("ctx used in expression with no properties")
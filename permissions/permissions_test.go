@@ -808,6 +808,101 @@ func TestToSQL(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "arithmetic_and_functions",
+			schema: `
+				model Post {
+					fields {
+						title Text
+						viewCount Number
+						likeCount Number
+					}
+					actions {
+						get getPost(id)
+					}
+					@permission(
+						expression: post.viewCount + post.likeCount * 2 > 10 and lower(post.title) == "foo" + "bar",
+						actions: [get]
+					)
+				}
+			`,
+			action: "getPost",
+			sql: `
+				SELECT DISTINCT "post"."id" 
+				FROM "post" 
+				WHERE (("post"."view_count" + ("post"."like_count" * ?)) > ? and lower("post"."title") IS NOT DISTINCT FROM (? || ?)) AND "post"."id" IN (?)
+			`,
+			values: []permissions.Value{
+				{
+					Type:        permissions.ValueNumber,
+					NumberValue: 2,
+				},
+				{
+					Type:        permissions.ValueNumber,
+					NumberValue: 10,
+				},
+				{
+					Type:        permissions.ValueString,
+					StringValue: `"foo"`,
+				},
+				{
+					Type:        permissions.ValueString,
+					StringValue: `"bar"`,
+				},
+				{
+					Type: permissions.ValueRecordIDs,
+				},
+			},
+		},
+		{
+			name: "dates_modulo_and_trim",
+			schema: `
+				model Post {
+					fields {
+						title Text
+						viewCount Number
+						publishDate Date
+					}
+					actions {
+						get getPost(id)
+					}
+					@permission(
+						expression: post.publishDate + 7 days > ctx.now and post.viewCount % 2 == 0 and year(post.publishDate) == 2024 and trim(post.title) != "",
+						actions: [get]
+					)
+				}
+			`,
+			action: "getPost",
+			sql: `
+				SELECT DISTINCT "post"."id" 
+				FROM "post" 
+				WHERE (("post"."publish_date" + INTERVAL '7 days')::DATE > ? and mod("post"."view_count", ?) IS NOT DISTINCT FROM ? and EXTRACT(YEAR FROM "post"."publish_date")::INTEGER IS NOT DISTINCT FROM ? and trim("post"."title") IS DISTINCT FROM ?) AND "post"."id" IN (?)
+			`,
+			values: []permissions.Value{
+				{
+					Type: permissions.ValueNow,
+				},
+				{
+					Type:        permissions.ValueNumber,
+					NumberValue: 2,
+				},
+				{
+					Type:        permissions.ValueNumber,
+					NumberValue: 0,
+				},
+				{
+					Type:        permissions.ValueNumber,
+					NumberValue: 2024,
+				},
+				{
+					Type:        permissions.ValueString,
+					StringValue: `""`,
+				},
+				{
+					Type: permissions.ValueRecordIDs,
+				},
+			},
+		},
 	}
 
 	for _, fixture := range fixtures {
@@ -10,7 +10,8 @@ func (a *Action) IsArbitraryFunction() bool {
 
 func (a *Action) IsWriteAction() bool {
 	switch a.Type {
-	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE:
+	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE,
+		ActionType_ACTION_TYPE_CREATE_MANY, ActionType_ACTION_TYPE_UPDATE_MANY, ActionType_ACTION_TYPE_DELETE_MANY:
 		return true
 	default:
 		return false
@@ -37,3 +38,28 @@ func (a *Action) IsList() bool {
 func (a *Action) IsGet() bool {
 	return a.Type == ActionType_ACTION_TYPE_GET
 }
+
+// IsBulkAction is true for actions which create, update or delete many records at once.
+func (a *Action) IsBulkAction() bool {
+	switch a.Type {
+	case ActionType_ACTION_TYPE_CREATE_MANY, ActionType_ACTION_TYPE_UPDATE_MANY, ActionType_ACTION_TYPE_DELETE_MANY:
+		return true
+	default:
+		return false
+	}
+}
+
+// SingleActionType returns the action type which a bulk action type performs on each record, e.g.
+// create for createMany. Other action types are returned unchanged.
+func SingleActionType(actionType ActionType) ActionType {
+	switch actionType {
+	case ActionType_ACTION_TYPE_CREATE_MANY:
+		return ActionType_ACTION_TYPE_CREATE
+	case ActionType_ACTION_TYPE_UPDATE_MANY:
+		return ActionType_ACTION_TYPE_UPDATE
+	case ActionType_ACTION_TYPE_DELETE_MANY:
+		return ActionType_ACTION_TYPE_DELETE
+	default:
+		return actionType
+	}
+}
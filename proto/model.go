@@ -18,6 +18,13 @@ func (m *Model) HasFiles() bool {
 	return len(m.FileFields()) > 0
 }
 
+// SecretFields will return a slice of fields for the model that are of type secret
+func (m *Model) SecretFields() []*Field {
+	return lo.Filter(m.Fields, func(f *Field, _ int) bool {
+		return f.Type.Type == Type_TYPE_SECRET
+	})
+}
+
 // FieldNames provides a (sorted) list of the fields in the model of the given name.
 func (m *Model) FieldNames() []string {
 	names := lo.Map(m.Fields, func(x *Field, _ int) string {
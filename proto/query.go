@@ -221,7 +221,8 @@ func ActionIsArbitraryFunction(action *Action) bool {
 // Deprecated: Use Action.IsWriteAction() instead
 func IsWriteAction(action *Action) bool {
 	switch action.Type {
-	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE:
+	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE,
+		ActionType_ACTION_TYPE_CREATE_MANY, ActionType_ACTION_TYPE_UPDATE_MANY, ActionType_ACTION_TYPE_DELETE_MANY:
 		return true
 	default:
 		return false
@@ -340,8 +341,8 @@ func PermissionsForAction(schema *Schema, action *Action, filters ...PermissionF
 	}
 
 	// if there are no action level permissions, then we fallback to model level permissions
-	// that match the type of the action
-	opTypePermissions := PermissionsForActionType(schema, action.ModelName, action.Type)
+	// that match the type of the action, where bulk actions use the permissions of their single record counterpart
+	opTypePermissions := PermissionsForActionType(schema, action.ModelName, SingleActionType(action.Type))
 	permissions = append(permissions, opTypePermissions...)
 
 	if len(filters) == 0 {
@@ -425,7 +426,9 @@ func FindValuesInputMessage(schema *Schema, actionName string) *Message {
 	switch action.Type {
 	case ActionType_ACTION_TYPE_CREATE:
 		return message
-	case ActionType_ACTION_TYPE_UPDATE:
+	case ActionType_ACTION_TYPE_UPDATE,
+		ActionType_ACTION_TYPE_CREATE_MANY,
+		ActionType_ACTION_TYPE_UPDATE_MANY:
 		for _, v := range message.Fields {
 			if v.Name == "values" && v.Type.Type == Type_TYPE_MESSAGE {
 				return schema.FindMessage(v.Type.MessageName.Value)
@@ -446,7 +449,9 @@ func FindWhereInputMessage(schema *Schema, actionName string) *Message {
 		ActionType_ACTION_TYPE_DELETE:
 		return message
 	case ActionType_ACTION_TYPE_LIST,
-		ActionType_ACTION_TYPE_UPDATE:
+		ActionType_ACTION_TYPE_UPDATE,
+		ActionType_ACTION_TYPE_UPDATE_MANY,
+		ActionType_ACTION_TYPE_DELETE_MANY:
 		for _, v := range message.Fields {
 			if v.Name == "where" && v.Type.Type == Type_TYPE_MESSAGE {
 				return schema.FindMessage(v.Type.MessageName.Value)
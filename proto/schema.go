@@ -23,6 +23,17 @@ func (s *Schema) HasFiles() bool {
 	return false
 }
 
+// HasSecretFields checks if any models in the schema have fields that are secrets
+func (s *Schema) HasSecretFields() bool {
+	for _, model := range s.Models {
+		if len(model.SecretFields()) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // FindModel finds within the schema the model that has the given name. Returns nil if model not found.
 func (s *Schema) FindModel(modelName string) *Model {
 	for _, m := range s.GetModels() {
@@ -104,6 +115,25 @@ func (s *Schema) FindEventSubscribers(event *Event) []*Subscriber {
 	return subscribers
 }
 
+// ModelEvents returns the events which are generated by the actions of models, such as order.created.
+func (s *Schema) ModelEvents() []*Event {
+	return lo.Filter(s.Events, func(e *Event, _ int) bool {
+		return !e.IsUserDefined()
+	})
+}
+
+// UserDefinedEvents returns the events declared in the schema with the event keyword.
+func (s *Schema) UserDefinedEvents() []*Event {
+	return lo.Filter(s.Events, func(e *Event, _ int) bool {
+		return e.IsUserDefined()
+	})
+}
+
+// IsUserDefined returns true if the event is declared in the schema, rather than generated by a model action.
+func (e *Event) IsUserDefined() bool {
+	return e.MessageName != ""
+}
+
 // FindApiName finds the api name for the given model and action name.
 func (s *Schema) FindApiName(modelName, actionName string) string {
 	for _, api := range s.Apis {
@@ -40,6 +40,8 @@ type API interface {
 
 	ListTraces(context.Context, *ListTracesRequest) (*ListTracesResponse, error)
 
+	ExportTrace(context.Context, *ExportTraceRequest) (*ExportTraceResponse, error)
+
 	// Return a list of default generated tools config for interacting with the API
 	ListTools(context.Context, *ListToolsRequest) (*ListToolsResponse, error)
 }
@@ -50,7 +52,7 @@ type API interface {
 
 type aPIProtobufClient struct {
 	client      HTTPClient
-	urls        [5]string
+	urls        [6]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -78,11 +80,12 @@ func NewAPIProtobufClient(baseURL string, client HTTPClient, opts ...twirp.Clien
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "rpc", "API")
-	urls := [5]string{
+	urls := [6]string{
 		serviceURL + "GetActiveSchema",
 		serviceURL + "RunSQLQuery",
 		serviceURL + "GetTrace",
 		serviceURL + "ListTraces",
+		serviceURL + "ExportTrace",
 		serviceURL + "ListTools",
 	}
 
@@ -278,6 +281,52 @@ func (c *aPIProtobufClient) callListTraces(ctx context.Context, in *ListTracesRe
 	return out, nil
 }
 
+func (c *aPIProtobufClient) ExportTrace(ctx context.Context, in *ExportTraceRequest) (*ExportTraceResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "rpc")
+	ctx = ctxsetters.WithServiceName(ctx, "API")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTrace")
+	caller := c.callExportTrace
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportTraceRequest) (*ExportTraceResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportTraceRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTraceRequest) when calling interceptor")
+					}
+					return c.callExportTrace(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportTraceResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTraceResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *aPIProtobufClient) callExportTrace(ctx context.Context, in *ExportTraceRequest) (*ExportTraceResponse, error) {
+	out := new(ExportTraceResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 func (c *aPIProtobufClient) ListTools(ctx context.Context, in *ListToolsRequest) (*ListToolsResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "rpc")
 	ctx = ctxsetters.WithServiceName(ctx, "API")
@@ -309,7 +358,7 @@ func (c *aPIProtobufClient) ListTools(ctx context.Context, in *ListToolsRequest)
 
 func (c *aPIProtobufClient) callListTools(ctx context.Context, in *ListToolsRequest) (*ListToolsResponse, error) {
 	out := new(ListToolsResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -330,7 +379,7 @@ func (c *aPIProtobufClient) callListTools(ctx context.Context, in *ListToolsRequ
 
 type aPIJSONClient struct {
 	client      HTTPClient
-	urls        [5]string
+	urls        [6]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -358,11 +407,12 @@ func NewAPIJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOpt
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "rpc", "API")
-	urls := [5]string{
+	urls := [6]string{
 		serviceURL + "GetActiveSchema",
 		serviceURL + "RunSQLQuery",
 		serviceURL + "GetTrace",
 		serviceURL + "ListTraces",
+		serviceURL + "ExportTrace",
 		serviceURL + "ListTools",
 	}
 
@@ -558,6 +608,52 @@ func (c *aPIJSONClient) callListTraces(ctx context.Context, in *ListTracesReques
 	return out, nil
 }
 
+func (c *aPIJSONClient) ExportTrace(ctx context.Context, in *ExportTraceRequest) (*ExportTraceResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "rpc")
+	ctx = ctxsetters.WithServiceName(ctx, "API")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTrace")
+	caller := c.callExportTrace
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportTraceRequest) (*ExportTraceResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportTraceRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTraceRequest) when calling interceptor")
+					}
+					return c.callExportTrace(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportTraceResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTraceResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *aPIJSONClient) callExportTrace(ctx context.Context, in *ExportTraceRequest) (*ExportTraceResponse, error) {
+	out := new(ExportTraceResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 func (c *aPIJSONClient) ListTools(ctx context.Context, in *ListToolsRequest) (*ListToolsResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "rpc")
 	ctx = ctxsetters.WithServiceName(ctx, "API")
@@ -589,7 +685,7 @@ func (c *aPIJSONClient) ListTools(ctx context.Context, in *ListToolsRequest) (*L
 
 func (c *aPIJSONClient) callListTools(ctx context.Context, in *ListToolsRequest) (*ListToolsResponse, error) {
 	out := new(ListToolsResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -713,6 +809,9 @@ func (s *aPIServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	case "ListTraces":
 		s.serveListTraces(ctx, resp, req)
 		return
+	case "ExportTrace":
+		s.serveExportTrace(ctx, resp, req)
+		return
 	case "ListTools":
 		s.serveListTools(ctx, resp, req)
 		return
@@ -1443,6 +1542,186 @@ func (s *aPIServer) serveListTracesProtobuf(ctx context.Context, resp http.Respo
 	callResponseSent(ctx, s.hooks)
 }
 
+func (s *aPIServer) serveExportTrace(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveExportTraceJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveExportTraceProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *aPIServer) serveExportTraceJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTrace")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ExportTraceRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.API.ExportTrace
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ExportTraceRequest) (*ExportTraceResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportTraceRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTraceRequest) when calling interceptor")
+					}
+					return s.API.ExportTrace(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportTraceResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTraceResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ExportTraceResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportTraceResponse and nil error while calling ExportTrace. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *aPIServer) serveExportTraceProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTrace")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ExportTraceRequest)
+	if err = proto1.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.API.ExportTrace
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ExportTraceRequest) (*ExportTraceResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportTraceRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTraceRequest) when calling interceptor")
+					}
+					return s.API.ExportTrace(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportTraceResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTraceResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ExportTraceResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportTraceResponse and nil error while calling ExportTrace. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto1.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
 func (s *aPIServer) serveListTools(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
@@ -2204,131 +2483,134 @@ func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error)
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 2006 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x58, 0x5f, 0x73, 0x1b, 0xb7,
-	0x11, 0x17, 0xff, 0x1f, 0x97, 0x14, 0x45, 0xc1, 0x8a, 0x7c, 0xa1, 0xdb, 0xc6, 0x39, 0x27, 0xb5,
-	0xa2, 0x66, 0xa8, 0x46, 0xb5, 0xa7, 0xb1, 0x1b, 0x67, 0x6c, 0x59, 0x8e, 0xa9, 0x8c, 0x9d, 0x28,
-	0x27, 0x4d, 0x5e, 0x6f, 0x20, 0x1e, 0x48, 0xa1, 0x3a, 0x1e, 0x2e, 0x38, 0x50, 0x16, 0xd3, 0x97,
-	0x3e, 0xb4, 0x33, 0x79, 0xec, 0xf4, 0xb1, 0x9f, 0xa3, 0xfd, 0x04, 0xfd, 0x46, 0x7d, 0xee, 0x74,
-	0x3a, 0x58, 0xe0, 0x8e, 0x47, 0x8a, 0x56, 0xd3, 0x87, 0x3e, 0xf5, 0x89, 0xdc, 0xdd, 0xdf, 0x02,
-	0x8b, 0xdd, 0xc5, 0xee, 0xe2, 0xa0, 0x29, 0x93, 0x61, 0x3f, 0x91, 0x42, 0x09, 0x52, 0x91, 0xc9,
-	0xb0, 0xd7, 0x4e, 0x87, 0xe7, 0x6c, 0x42, 0x0d, 0xab, 0xb7, 0x23, 0x12, 0x16, 0x2b, 0x16, 0xb1,
-	0x09, 0x53, 0x72, 0xb6, 0x87, 0xcc, 0x3d, 0x25, 0xe9, 0x90, 0xed, 0x5d, 0x7e, 0x62, 0xfe, 0x58,
-	0xe4, 0x7b, 0x63, 0x21, 0xc6, 0x11, 0x33, 0x90, 0xb3, 0xe9, 0x68, 0x4f, 0xf1, 0x09, 0x4b, 0x15,
-	0x9d, 0x24, 0x06, 0xe0, 0x3d, 0x82, 0xee, 0x4b, 0xa6, 0x4e, 0x70, 0x75, 0x9f, 0x7d, 0x37, 0x65,
-	0xa9, 0x22, 0x1f, 0x42, 0x87, 0xc5, 0x97, 0x5c, 0x8a, 0x78, 0xc2, 0x62, 0x15, 0xf0, 0xd0, 0x2d,
-	0xdd, 0x2d, 0xed, 0x34, 0xfd, 0xf5, 0x02, 0xf7, 0x28, 0xf4, 0x1e, 0xc3, 0x66, 0x41, 0x35, 0x4d,
-	0x44, 0x9c, 0x32, 0xf2, 0x21, 0xd4, 0x8d, 0xa9, 0xa8, 0xd3, 0xda, 0x5f, 0x37, 0xfb, 0xf4, 0x2d,
-	0xcc, 0x0a, 0xbd, 0xbf, 0x94, 0x60, 0xfd, 0xe4, 0x9b, 0x57, 0xdf, 0x4c, 0x99, 0x9c, 0x1d, 0xc5,
-	0xc9, 0x54, 0x91, 0x9f, 0x40, 0x33, 0x91, 0xe2, 0xb7, 0x6c, 0xa8, 0x8e, 0x0e, 0xed, 0x7e, 0x73,
-	0x06, 0xf9, 0x00, 0x16, 0x36, 0x3f, 0x74, 0xcb, 0xd7, 0x2d, 0x3a, 0x24, 0x5b, 0x50, 0xfb, 0x4e,
-	0xaf, 0xe8, 0x56, 0x50, 0x6a, 0x08, 0xf2, 0x3e, 0x34, 0xdf, 0x48, 0xae, 0xd8, 0x6b, 0x11, 0x32,
-	0xb7, 0x7a, 0xb7, 0xb4, 0xe3, 0x0c, 0xd6, 0xfc, 0x39, 0xeb, 0x87, 0x52, 0xe9, 0xa0, 0x0d, 0x10,
-	0xe4, 0x0c, 0xef, 0xef, 0x25, 0xe8, 0x66, 0xc6, 0xe5, 0x07, 0xfb, 0x05, 0xd4, 0x53, 0x45, 0xd5,
-	0x34, 0x45, 0xe3, 0x3a, 0xfb, 0xb7, 0xfa, 0x3a, 0x44, 0x19, 0xec, 0x04, 0x45, 0xbe, 0x85, 0x90,
-	0x8f, 0x61, 0x93, 0x5d, 0xb1, 0xe1, 0x54, 0x71, 0x11, 0x1f, 0x4e, 0x25, 0xd5, 0xbf, 0x68, 0x72,
-	0xcd, 0xbf, 0x2e, 0x20, 0x77, 0xa1, 0x25, 0x59, 0x3a, 0x8d, 0x54, 0xfa, 0xe5, 0xc9, 0xd7, 0x5f,
-	0x59, 0xe3, 0x8b, 0x2c, 0xed, 0x1c, 0x25, 0x14, 0x8d, 0x7c, 0xf1, 0x26, 0xc5, 0x23, 0xd4, 0xfc,
-	0x39, 0x43, 0x1f, 0x9b, 0x49, 0x29, 0xa4, 0x5b, 0x33, 0xc7, 0x46, 0xc2, 0xfb, 0x18, 0x36, 0x5e,
-	0x32, 0x75, 0xaa, 0x93, 0x21, 0x0b, 0xec, 0xbb, 0xe0, 0x60, 0x72, 0xcc, 0x43, 0xda, 0x40, 0xfa,
-	0x28, 0xf4, 0x7c, 0xcc, 0x03, 0x8b, 0xb6, 0x47, 0xfe, 0x1c, 0x6a, 0x28, 0xb6, 0xa1, 0xdc, 0xe9,
-	0x2f, 0xa4, 0x9d, 0x0d, 0xac, 0xc9, 0xb6, 0xcb, 0x4f, 0xfa, 0xa8, 0x9b, 0x1e, 0x52, 0x45, 0x7d,
-	0xa3, 0xe6, 0xfd, 0xab, 0x04, 0x9b, 0xaf, 0x78, 0x6a, 0x56, 0x4d, 0xff, 0xbb, 0xec, 0x22, 0xfb,
-	0x50, 0x3f, 0x63, 0x23, 0x21, 0x19, 0xfa, 0xad, 0xb5, 0xdf, 0xeb, 0x9b, 0x54, 0xee, 0x67, 0xa9,
-	0xdc, 0x3f, 0xcd, 0x52, 0xd9, 0xb7, 0x48, 0xf2, 0x4b, 0xa8, 0xd1, 0x91, 0x62, 0x12, 0x5d, 0x78,
-	0xb3, 0x8a, 0x01, 0x92, 0x3e, 0x34, 0x46, 0x3c, 0x52, 0x4c, 0x6a, 0xb7, 0x56, 0x76, 0x5a, 0xfb,
-	0x5b, 0x18, 0xd6, 0xdc, 0xea, 0x2f, 0x50, 0xe8, 0x67, 0x20, 0xed, 0xea, 0x88, 0x4f, 0xb8, 0x42,
-	0x57, 0xd7, 0x7c, 0x43, 0x90, 0x6d, 0xa8, 0x8b, 0xd1, 0x28, 0x65, 0xca, 0xad, 0x23, 0xdb, 0x52,
-	0xde, 0x13, 0xd8, 0x58, 0x5a, 0x49, 0x2f, 0x30, 0xe2, 0x2c, 0xca, 0x0e, 0x6d, 0x08, 0xcd, 0xbd,
-	0xa4, 0xd1, 0x94, 0xd9, 0xb4, 0x36, 0x84, 0xf7, 0x19, 0x90, 0xa2, 0xfb, 0x6c, 0x54, 0x7e, 0x0e,
-	0x75, 0x74, 0xaf, 0x4e, 0x44, 0x6d, 0x71, 0x07, 0x2d, 0x46, 0xd0, 0x91, 0x62, 0x13, 0xdf, 0x4a,
-	0xbd, 0xdf, 0x57, 0xa0, 0x99, 0x73, 0x6f, 0x08, 0xfd, 0x8a, 0x80, 0x94, 0x57, 0x05, 0xe4, 0x11,
-	0x40, 0xaa, 0xa8, 0x54, 0x81, 0x2e, 0x21, 0x3f, 0xc2, 0xc3, 0x4d, 0x44, 0x6b, 0x9a, 0x3c, 0x04,
-	0x87, 0xc5, 0xa1, 0x51, 0xac, 0xfe, 0x47, 0xc5, 0x06, 0x8b, 0x43, 0x54, 0x5b, 0xc8, 0x6b, 0xc7,
-	0xe6, 0x35, 0x79, 0x0f, 0x5a, 0xa1, 0xbd, 0x39, 0xc1, 0x24, 0x45, 0x8f, 0x97, 0x7d, 0xc8, 0x58,
-	0xaf, 0x53, 0x72, 0x07, 0x9a, 0x52, 0x08, 0x15, 0xc4, 0x74, 0xc2, 0xdc, 0x06, 0x1e, 0xc5, 0xd1,
-	0x8c, 0xaf, 0xe8, 0x84, 0x91, 0x9f, 0x02, 0xd8, 0xaa, 0xa2, 0x0f, 0xea, 0x2c, 0xd6, 0x99, 0x90,
-	0xdc, 0x83, 0xf5, 0x90, 0x25, 0x91, 0x98, 0x65, 0xae, 0x68, 0x22, 0xa2, 0x3d, 0x67, 0x1e, 0x85,
-	0xe4, 0x3e, 0x6c, 0xc8, 0x69, 0xac, 0x4f, 0x13, 0x5c, 0x32, 0x99, 0xea, 0xbb, 0x0d, 0x08, 0xeb,
-	0x58, 0xf6, 0xb7, 0x86, 0xeb, 0x11, 0xe8, 0x62, 0x00, 0x85, 0x88, 0xb2, 0xf4, 0xf7, 0x3e, 0xb3,
-	0x77, 0xc2, 0xf0, 0x6c, 0x4c, 0xef, 0x43, 0x4d, 0x69, 0x86, 0x0d, 0xe9, 0x26, 0x86, 0xf4, 0xd9,
-	0x50, 0x1f, 0xe8, 0xb9, 0x88, 0x47, 0x7c, 0xec, 0x1b, 0xb9, 0xf7, 0x14, 0xda, 0xcf, 0x69, 0x42,
-	0xcf, 0x78, 0xc4, 0x15, 0x67, 0x29, 0xe9, 0x81, 0x33, 0x14, 0x13, 0x6d, 0x97, 0xa9, 0x4b, 0x8e,
-	0x9f, 0xd3, 0xda, 0x7d, 0x74, 0x1a, 0x72, 0x85, 0xe1, 0x74, 0x7c, 0x43, 0x78, 0xff, 0x6c, 0x40,
-	0xbb, 0xb8, 0x32, 0xe9, 0x40, 0x39, 0xcf, 0x89, 0x32, 0x0f, 0x09, 0x81, 0x2a, 0x7a, 0xce, 0x24,
-	0x01, 0xfe, 0x27, 0xb7, 0xa1, 0xca, 0x87, 0x22, 0x36, 0xa5, 0x69, 0xb0, 0xe6, 0x23, 0xf5, 0x43,
-	0xa9, 0xa4, 0x83, 0x41, 0x71, 0x31, 0xe3, 0xed, 0x2a, 0xea, 0x80, 0x61, 0xa1, 0xbf, 0xdf, 0x05,
-	0x87, 0x26, 0xdc, 0x48, 0x4d, 0x79, 0x6a, 0xd0, 0x84, 0xa3, 0x68, 0x3f, 0xd7, 0x55, 0xb3, 0x84,
-	0x61, 0x20, 0x3b, 0xfb, 0x9b, 0xb6, 0xac, 0x18, 0x13, 0x4f, 0x67, 0x09, 0xcb, 0x96, 0xd3, 0xff,
-	0xc9, 0x73, 0xe8, 0xf0, 0x49, 0xa2, 0x2b, 0x50, 0xac, 0x4c, 0x55, 0x6d, 0xa0, 0xda, 0x9d, 0x05,
-	0xb5, 0xa3, 0x05, 0x88, 0xbf, 0xa4, 0x42, 0xf6, 0xa0, 0xce, 0x75, 0xcf, 0x49, 0x5d, 0x07, 0xdd,
-	0x7d, 0x1b, 0xdd, 0x6d, 0x03, 0xf4, 0x85, 0xbe, 0x90, 0xd6, 0xe9, 0x16, 0x46, 0x1e, 0x80, 0x23,
-	0x6d, 0xa8, 0xdc, 0x26, 0xaa, 0xb8, 0x56, 0xc5, 0x30, 0x8b, 0x3a, 0x39, 0x92, 0xf4, 0xa1, 0xa6,
-	0xb8, 0x8a, 0x18, 0x26, 0x47, 0x2b, 0x6b, 0x18, 0x4a, 0xf2, 0x78, 0x7c, 0xca, 0x26, 0x49, 0x44,
-	0x15, 0x1b, 0x94, 0x7c, 0x83, 0xd1, 0xbe, 0x7c, 0x08, 0xcd, 0x73, 0x16, 0x25, 0x81, 0x62, 0x57,
-	0xca, 0x6d, 0xbd, 0x5d, 0xa7, 0xec, 0x3b, 0x1a, 0x77, 0xca, 0xae, 0x94, 0x56, 0xbb, 0xa7, 0x5b,
-	0xa3, 0xe2, 0x6a, 0x16, 0xa4, 0x3c, 0x1e, 0x47, 0xcc, 0x6d, 0x9b, 0x94, 0x35, 0xcc, 0x13, 0xe4,
-	0x15, 0x40, 0x49, 0x34, 0x95, 0x34, 0x72, 0xd7, 0x8b, 0xa0, 0x63, 0xe4, 0x91, 0x87, 0xd0, 0x1e,
-	0x16, 0x92, 0xcb, 0xed, 0xa0, 0x0d, 0x26, 0x19, 0x8b, 0x59, 0xe7, 0x2f, 0xc0, 0xc8, 0xa7, 0xb0,
-	0x21, 0x99, 0xb6, 0x2c, 0x0c, 0x4c, 0xa4, 0x52, 0x77, 0x03, 0x9d, 0xb4, 0x51, 0x48, 0xe3, 0x57,
-	0x3c, 0xbe, 0xf0, 0x3b, 0x16, 0x67, 0x58, 0x29, 0x79, 0x0a, 0x90, 0xd0, 0x31, 0x8f, 0x4d, 0x24,
-	0xbb, 0xb8, 0xdd, 0x1d, 0xb3, 0xdd, 0x54, 0xa6, 0x42, 0x1e, 0xe7, 0x42, 0xe3, 0xdc, 0x41, 0xc5,
-	0x2f, 0x28, 0xe8, 0xc3, 0xdf, 0xd7, 0xf5, 0x38, 0xbe, 0x48, 0xdd, 0xcd, 0xc2, 0xc5, 0x79, 0x71,
-	0xa5, 0x98, 0x8c, 0x69, 0x84, 0x7b, 0x1a, 0x39, 0x79, 0x01, 0xdb, 0x2c, 0x56, 0x72, 0x86, 0x26,
-	0x5e, 0x6a, 0x47, 0x64, 0xb6, 0x92, 0xd5, 0xb6, 0x6e, 0x21, 0xfc, 0x99, 0x45, 0x67, 0x16, 0x3f,
-	0x86, 0x2e, 0x9b, 0x9c, 0xb1, 0x30, 0x2c, 0x1c, 0xf6, 0xd6, 0xea, 0x05, 0x36, 0x32, 0x60, 0xa6,
-	0xfb, 0x39, 0x74, 0xc7, 0x4c, 0x05, 0x73, 0x33, 0x44, 0xec, 0x6e, 0xe1, 0x99, 0x97, 0x75, 0x07,
-	0x55, 0xbf, 0x33, 0x66, 0xea, 0x45, 0x66, 0x01, 0x9e, 0xf5, 0xa0, 0x01, 0xb5, 0x40, 0xdf, 0xbb,
-	0x03, 0x07, 0xea, 0x01, 0x66, 0x0d, 0xce, 0x2d, 0x79, 0xce, 0x1c, 0xac, 0x43, 0x2b, 0x98, 0xbb,
-	0xe7, 0xe0, 0x16, 0x6c, 0x06, 0xcb, 0x1b, 0x7a, 0xff, 0xa8, 0x02, 0xb9, 0x9e, 0xe9, 0xe4, 0x01,
-	0x74, 0xb0, 0x13, 0x05, 0x91, 0x18, 0x9a, 0x68, 0x64, 0xe3, 0x9b, 0xb6, 0xec, 0xcb, 0x54, 0xc4,
-	0xc7, 0x54, 0x9d, 0xfb, 0xeb, 0x08, 0x7a, 0x65, 0x31, 0x64, 0x17, 0xc0, 0x68, 0xe1, 0x05, 0x2e,
-	0xe3, 0x4d, 0x6c, 0xd9, 0x9b, 0x88, 0x57, 0xb7, 0x89, 0x62, 0xbc, 0xb9, 0xef, 0x43, 0x3b, 0xe4,
-	0x69, 0x12, 0xd1, 0x99, 0x29, 0x06, 0x76, 0xca, 0xb1, 0x3c, 0x2c, 0x08, 0xba, 0xf8, 0x5a, 0x88,
-	0x90, 0x21, 0x93, 0x76, 0xd2, 0xc9, 0xf4, 0xbe, 0xd6, 0x3c, 0xe2, 0x42, 0xe3, 0x92, 0xa7, 0xfc,
-	0x2c, 0x62, 0xb6, 0x2d, 0x64, 0xe4, 0xe2, 0xfd, 0xa9, 0xbf, 0xfd, 0xfe, 0xac, 0x2d, 0xde, 0x9f,
-	0x47, 0xb0, 0x1e, 0x09, 0x71, 0x31, 0x4d, 0xb2, 0x98, 0x34, 0x56, 0xc7, 0xa4, 0xe4, 0xb7, 0x0d,
-	0x2e, 0x8f, 0xc8, 0xca, 0x88, 0x3a, 0xab, 0xb5, 0xcb, 0x2b, 0x22, 0xaa, 0xe7, 0x86, 0x48, 0x0c,
-	0x2f, 0x98, 0x69, 0x33, 0x8e, 0x6f, 0x29, 0xf2, 0x1b, 0xdd, 0x85, 0x46, 0x74, 0x1a, 0xa9, 0xc0,
-	0x8c, 0x05, 0x50, 0xb8, 0x89, 0x87, 0x46, 0xf2, 0xad, 0x16, 0x0c, 0x2a, 0xba, 0x35, 0xcd, 0x69,
-	0xbd, 0xe8, 0x63, 0x68, 0x25, 0x11, 0x1d, 0xb2, 0x73, 0x11, 0x69, 0x1f, 0xde, 0x50, 0x48, 0xaa,
-	0x7e, 0x11, 0x99, 0xcd, 0xc1, 0xf3, 0x7c, 0xea, 0x42, 0x27, 0x58, 0x70, 0xcd, 0xca, 0x94, 0x42,
-	0xd8, 0x82, 0xb9, 0x07, 0x1d, 0x68, 0x07, 0x85, 0x95, 0xbd, 0x3f, 0x57, 0xe0, 0xd6, 0x8a, 0x5a,
-	0xf9, 0xff, 0x9c, 0x75, 0x3d, 0x70, 0x52, 0x21, 0x15, 0xd5, 0x2b, 0x36, 0x4c, 0xe3, 0xce, 0x68,
-	0xb2, 0x03, 0x55, 0x5d, 0xb4, 0xde, 0x96, 0x4a, 0x25, 0x1f, 0xc5, 0xb6, 0xf6, 0xf3, 0x09, 0x1d,
-	0xb3, 0x20, 0x91, 0xec, 0x92, 0xb3, 0x37, 0x36, 0x8f, 0xda, 0xc8, 0x3c, 0x36, 0xbc, 0xa5, 0xa0,
-	0xea, 0x2a, 0xa2, 0xd5, 0xbd, 0xdf, 0x41, 0xbb, 0x98, 0x4a, 0xc4, 0xd5, 0x0f, 0x1c, 0x6d, 0xb2,
-	0x99, 0x05, 0x06, 0x6b, 0xbe, 0xa5, 0x49, 0x0f, 0x1a, 0x3c, 0x56, 0x6c, 0xcc, 0xa4, 0x79, 0xc3,
-	0x0c, 0xd6, 0xfc, 0x8c, 0x41, 0xb6, 0xa1, 0x36, 0x8a, 0x04, 0x55, 0xe8, 0xd9, 0xf2, 0x60, 0xcd,
-	0x37, 0x24, 0xd9, 0x82, 0xea, 0x99, 0x10, 0x51, 0xfe, 0xde, 0x42, 0x4a, 0x6f, 0x6e, 0x46, 0xdb,
-	0x01, 0x74, 0x16, 0xfd, 0xa3, 0x1d, 0xa2, 0xec, 0x7f, 0x3b, 0x8c, 0xe4, 0xb4, 0x96, 0x4d, 0xa8,
-	0xbc, 0x08, 0xc5, 0x9b, 0xd8, 0x0e, 0x33, 0x39, 0xed, 0xfd, 0x0c, 0x9c, 0x2c, 0x51, 0xf4, 0xe8,
-	0x92, 0x50, 0x75, 0x6e, 0xf5, 0xf1, 0xbf, 0xf7, 0x87, 0x12, 0xb4, 0x8b, 0x0d, 0x81, 0x7c, 0x04,
-	0xb5, 0x88, 0x9e, 0xb1, 0xc8, 0xe6, 0xda, 0xaa, 0x60, 0xf9, 0x06, 0x41, 0xee, 0x43, 0xf5, 0x5c,
-	0xb2, 0x91, 0x7d, 0x81, 0xac, 0x44, 0x22, 0xe0, 0xad, 0xf3, 0x51, 0x5e, 0xb3, 0xbd, 0x3f, 0x96,
-	0x00, 0xe6, 0x31, 0x24, 0xb7, 0xa1, 0xa1, 0x07, 0xba, 0xf9, 0x34, 0x5e, 0xd7, 0xe4, 0x51, 0x48,
-	0x3e, 0x80, 0x6a, 0x48, 0x15, 0x75, 0xcb, 0xd8, 0x54, 0xba, 0xe6, 0xc6, 0x53, 0x45, 0x5f, 0xd3,
-	0x24, 0xe1, 0xf1, 0xd8, 0x47, 0xe9, 0x7c, 0xb4, 0xa8, 0xdc, 0x94, 0x70, 0xf9, 0x68, 0x31, 0xef,
-	0x18, 0xde, 0x5f, 0xab, 0xb0, 0xbd, 0xba, 0xb9, 0x92, 0x27, 0x50, 0xc3, 0x91, 0xdd, 0x3a, 0xe6,
-	0xfe, 0x0d, 0x8d, 0xb8, 0x5f, 0x9c, 0x78, 0x8c, 0x16, 0x79, 0x04, 0x15, 0x16, 0x87, 0xd6, 0x57,
-	0x3f, 0x5a, 0x59, 0xeb, 0x90, 0x97, 0xd0, 0x4c, 0x74, 0x16, 0xa7, 0xfc, 0xfb, 0xec, 0x48, 0xbb,
-	0x37, 0x2d, 0x70, 0x4c, 0xc7, 0xec, 0x84, 0x7f, 0xcf, 0xb2, 0x91, 0x2b, 0xb1, 0x34, 0xd9, 0x85,
-	0x66, 0xcc, 0xae, 0x94, 0xee, 0x82, 0xd9, 0x4b, 0x63, 0xa9, 0x96, 0x38, 0x5a, 0xae, 0xf5, 0x49,
-	0x1f, 0x5a, 0xf8, 0x84, 0x0e, 0x86, 0x62, 0x1a, 0x9b, 0x07, 0xdd, 0x35, 0x34, 0x20, 0xe2, 0xb9,
-	0x06, 0xf4, 0xce, 0xa1, 0x55, 0xac, 0x5d, 0xf7, 0x60, 0x5d, 0x9a, 0x3e, 0x1a, 0xe0, 0x94, 0x68,
-	0xe3, 0xd8, 0xb6, 0x4c, 0xf3, 0x51, 0xe3, 0x01, 0x74, 0xb2, 0x71, 0x30, 0x30, 0xcf, 0xbe, 0xf2,
-	0xca, 0x02, 0x27, 0x8b, 0xc5, 0xb1, 0xf7, 0xa7, 0x12, 0x74, 0x16, 0x8f, 0xf8, 0x3f, 0xdc, 0xcd,
-	0x3c, 0x79, 0x8a, 0xcd, 0xa6, 0x62, 0xeb, 0x5f, 0xa1, 0x38, 0x78, 0x33, 0x68, 0x15, 0xb2, 0x90,
-	0x74, 0xa1, 0x72, 0xc1, 0x66, 0xd6, 0x08, 0xfd, 0x97, 0xdc, 0xb3, 0x57, 0x6f, 0xd5, 0x8e, 0xfa,
-	0x42, 0x68, 0x21, 0xd9, 0x85, 0xba, 0x38, 0xd3, 0x2f, 0x2d, 0x1b, 0xe4, 0x6b, 0xe9, 0xad, 0x8b,
-	0x8e, 0x41, 0xe4, 0xa5, 0x62, 0xf7, 0x23, 0xe8, 0x2c, 0x7e, 0x65, 0x21, 0x2d, 0x68, 0xa4, 0xd3,
-	0xe1, 0x90, 0xa5, 0x69, 0x77, 0x8d, 0x00, 0xd4, 0x47, 0x94, 0x47, 0x2c, 0xec, 0x96, 0xf6, 0xff,
-	0x56, 0x86, 0xca, 0xb3, 0xe3, 0x23, 0xf2, 0x14, 0x3f, 0x7d, 0xe0, 0xec, 0xc6, 0xcc, 0x87, 0x27,
-	0xf2, 0x0e, 0x6e, 0xb5, 0xfc, 0xa9, 0xab, 0xb7, 0xbd, 0xcc, 0xb6, 0xb3, 0xfb, 0xa7, 0xd0, 0xf2,
-	0xa7, 0x71, 0xb6, 0x2f, 0x21, 0x0b, 0x1f, 0x7b, 0xd0, 0xdb, 0xbd, 0x77, 0x16, 0x78, 0xb9, 0xe6,
-	0xaf, 0xc1, 0xc9, 0x3e, 0xa4, 0x90, 0xad, 0x6c, 0xf5, 0xe2, 0x57, 0x98, 0xde, 0x3b, 0x4b, 0x5c,
-	0xab, 0xf8, 0x04, 0x60, 0xfe, 0xda, 0x27, 0xdb, 0x8b, 0xdf, 0x21, 0xb2, 0xe7, 0x63, 0xef, 0xf6,
-	0x35, 0xbe, 0x55, 0x7f, 0x0c, 0xcd, 0xfc, 0x5d, 0x69, 0x4f, 0xbb, 0xfc, 0xf6, 0xec, 0x6d, 0x2f,
-	0xb3, 0x8d, 0xee, 0x59, 0x1d, 0x9b, 0xe7, 0xaf, 0xfe, 0x1d, 0x00, 0x00, 0xff, 0xff, 0x5c, 0xa4,
-	0xa8, 0x08, 0x76, 0x14, 0x00, 0x00,
+	// 2055 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x58, 0xcf, 0x72, 0x1b, 0xc7,
+	0xd1, 0xe7, 0xe2, 0xef, 0xa2, 0x01, 0x82, 0xe0, 0x88, 0xa2, 0xd6, 0xd0, 0xf7, 0xc5, 0xf4, 0xca,
+	0x0e, 0x69, 0xc6, 0x05, 0xc6, 0x8c, 0x54, 0xb1, 0x14, 0xcb, 0x25, 0x51, 0x94, 0x05, 0xba, 0x24,
+	0x9b, 0x5e, 0xb2, 0x7c, 0xdd, 0x1a, 0x62, 0x07, 0xe0, 0x9a, 0x8b, 0x9d, 0xf5, 0xec, 0x80, 0x22,
+	0x9c, 0x4b, 0x0e, 0x49, 0x95, 0x8f, 0xa9, 0x1c, 0xf3, 0x1c, 0x79, 0x83, 0xbc, 0x51, 0x2e, 0xb9,
+	0xa4, 0x52, 0xa9, 0xe9, 0x99, 0x5d, 0x2c, 0x40, 0x90, 0xb6, 0x0f, 0x39, 0xe5, 0xb4, 0xdb, 0xdd,
+	0xbf, 0x9e, 0xe9, 0xe9, 0xee, 0xe9, 0xe9, 0x19, 0x68, 0x88, 0x64, 0xd0, 0x4b, 0x04, 0x97, 0x9c,
+	0x94, 0x45, 0x32, 0xe8, 0xb6, 0xd2, 0xc1, 0x39, 0x1b, 0x53, 0xcd, 0xea, 0xee, 0xf0, 0x84, 0xc5,
+	0x92, 0x45, 0x6c, 0xcc, 0xa4, 0x98, 0xee, 0x21, 0x73, 0x4f, 0x0a, 0x3a, 0x60, 0x7b, 0x97, 0x1f,
+	0xeb, 0x1f, 0x83, 0x7c, 0x77, 0xc4, 0xf9, 0x28, 0x62, 0x1a, 0x72, 0x36, 0x19, 0xee, 0xc9, 0x70,
+	0xcc, 0x52, 0x49, 0xc7, 0x89, 0x06, 0xb8, 0x8f, 0xa1, 0xf3, 0x8a, 0xc9, 0x13, 0x1c, 0xdd, 0x63,
+	0xdf, 0x4d, 0x58, 0x2a, 0xc9, 0x07, 0xd0, 0x66, 0xf1, 0x65, 0x28, 0x78, 0x3c, 0x66, 0xb1, 0xf4,
+	0xc3, 0xc0, 0xb1, 0xb6, 0xac, 0x9d, 0x86, 0xb7, 0x5a, 0xe0, 0x1e, 0x05, 0xee, 0x13, 0x58, 0x2f,
+	0xa8, 0xa6, 0x09, 0x8f, 0x53, 0x46, 0x3e, 0x80, 0x9a, 0x36, 0x15, 0x75, 0x9a, 0xfb, 0xab, 0x7a,
+	0x9e, 0x9e, 0x81, 0x19, 0xa1, 0xfb, 0x57, 0x0b, 0x56, 0x4f, 0xbe, 0x7e, 0xfd, 0xf5, 0x84, 0x89,
+	0xe9, 0x51, 0x9c, 0x4c, 0x24, 0xf9, 0x3f, 0x68, 0x24, 0x82, 0x7f, 0xcb, 0x06, 0xf2, 0xe8, 0xd0,
+	0xcc, 0x37, 0x63, 0x90, 0xf7, 0x61, 0x6e, 0xf2, 0x43, 0xa7, 0x74, 0xdd, 0xa2, 0x43, 0xb2, 0x01,
+	0xd5, 0xef, 0xd4, 0x88, 0x4e, 0x19, 0xa5, 0x9a, 0x20, 0xef, 0x41, 0xe3, 0xad, 0x08, 0x25, 0x7b,
+	0xc3, 0x03, 0xe6, 0x54, 0xb6, 0xac, 0x1d, 0xbb, 0xbf, 0xe2, 0xcd, 0x58, 0x3f, 0x58, 0xd6, 0x41,
+	0x0b, 0xc0, 0xcf, 0x19, 0xee, 0xdf, 0x2d, 0xe8, 0x64, 0xc6, 0xe5, 0x0b, 0xfb, 0x15, 0xd4, 0x52,
+	0x49, 0xe5, 0x24, 0x45, 0xe3, 0xda, 0xfb, 0x77, 0x7a, 0x2a, 0x44, 0x19, 0xec, 0x04, 0x45, 0x9e,
+	0x81, 0x90, 0x8f, 0x60, 0x9d, 0x5d, 0xb1, 0xc1, 0x44, 0x86, 0x3c, 0x3e, 0x9c, 0x08, 0xaa, 0xbe,
+	0x68, 0x72, 0xd5, 0xbb, 0x2e, 0x20, 0x5b, 0xd0, 0x14, 0x2c, 0x9d, 0x44, 0x32, 0xfd, 0xe2, 0xe4,
+	0xab, 0x2f, 0x8d, 0xf1, 0x45, 0x96, 0x72, 0x8e, 0xe4, 0x92, 0x46, 0x1e, 0x7f, 0x9b, 0xe2, 0x12,
+	0xaa, 0xde, 0x8c, 0xa1, 0x96, 0xcd, 0x84, 0xe0, 0xc2, 0xa9, 0xea, 0x65, 0x23, 0xe1, 0x7e, 0x04,
+	0x6b, 0xaf, 0x98, 0x3c, 0x55, 0xc9, 0x90, 0x05, 0xf6, 0x1d, 0xb0, 0x31, 0x39, 0x66, 0x21, 0xad,
+	0x23, 0x7d, 0x14, 0xb8, 0x1e, 0x74, 0x66, 0x68, 0xb3, 0xe4, 0xcf, 0xa0, 0x8a, 0x62, 0x13, 0xca,
+	0x9d, 0xde, 0x5c, 0xda, 0x99, 0xc0, 0xea, 0x6c, 0xbb, 0xfc, 0xb8, 0x87, 0xba, 0xe9, 0x21, 0x95,
+	0xd4, 0xd3, 0x6a, 0xee, 0xbf, 0x2d, 0x58, 0x7f, 0x1d, 0xa6, 0x7a, 0xd4, 0xf4, 0xe7, 0x65, 0x17,
+	0xd9, 0x87, 0xda, 0x19, 0x1b, 0x72, 0xc1, 0xd0, 0x6f, 0xcd, 0xfd, 0x6e, 0x4f, 0xa7, 0x72, 0x2f,
+	0x4b, 0xe5, 0xde, 0x69, 0x96, 0xca, 0x9e, 0x41, 0x92, 0x5f, 0x43, 0x95, 0x0e, 0x25, 0x13, 0x4e,
+	0xf9, 0x47, 0x55, 0x34, 0x90, 0xf4, 0xa0, 0x3e, 0x0c, 0x23, 0xc9, 0x84, 0x72, 0x6b, 0x79, 0xa7,
+	0xb9, 0xbf, 0x81, 0x61, 0xcd, 0xad, 0xfe, 0x1c, 0x85, 0x5e, 0x06, 0x52, 0xae, 0x8e, 0xc2, 0x71,
+	0x28, 0xd1, 0xd5, 0x55, 0x4f, 0x13, 0x64, 0x13, 0x6a, 0x7c, 0x38, 0x4c, 0x99, 0x74, 0x6a, 0xc8,
+	0x36, 0x94, 0xfb, 0x14, 0xd6, 0x16, 0x46, 0x52, 0x03, 0x0c, 0x43, 0x16, 0x65, 0x8b, 0xd6, 0x84,
+	0xe2, 0x5e, 0xd2, 0x68, 0xc2, 0x4c, 0x5a, 0x6b, 0xc2, 0xfd, 0x14, 0x48, 0xd1, 0x7d, 0x26, 0x2a,
+	0xbf, 0x84, 0x1a, 0xba, 0x57, 0x25, 0xa2, 0xb2, 0xb8, 0x8d, 0x16, 0x23, 0xe8, 0x48, 0xb2, 0xb1,
+	0x67, 0xa4, 0xee, 0x1e, 0x90, 0x97, 0x57, 0x09, 0x17, 0x3f, 0x39, 0x05, 0xf6, 0xe1, 0xce, 0x9c,
+	0x82, 0x99, 0xef, 0x3e, 0x34, 0xb8, 0x8c, 0x12, 0xff, 0xdb, 0x94, 0xc7, 0x46, 0xc5, 0x56, 0x8c,
+	0x2f, 0x52, 0x1e, 0xbb, 0x7f, 0x28, 0x43, 0x23, 0x9f, 0xfa, 0x96, 0xc1, 0x97, 0x44, 0xbd, 0xb4,
+	0x2c, 0xea, 0x8f, 0x01, 0x52, 0x49, 0x85, 0xf4, 0x55, 0x9d, 0xfa, 0x09, 0x61, 0x6c, 0x20, 0x5a,
+	0xd1, 0xe4, 0x11, 0xd8, 0x2c, 0x0e, 0xb4, 0x62, 0xe5, 0x47, 0x15, 0xeb, 0x2c, 0x0e, 0x50, 0x6d,
+	0x6e, 0xf3, 0xd8, 0x66, 0xf3, 0x90, 0x77, 0xa1, 0x19, 0x98, 0xed, 0xe9, 0x8f, 0x53, 0x0c, 0x6b,
+	0xc9, 0x83, 0x8c, 0xf5, 0x26, 0x55, 0x5e, 0x11, 0x9c, 0x4b, 0x3f, 0xa6, 0x63, 0xe6, 0xd4, 0xb5,
+	0x57, 0x14, 0xe3, 0x4b, 0x3a, 0x66, 0xe4, 0xff, 0x01, 0x4c, 0xe9, 0x52, 0x0b, 0xb5, 0xe7, 0x8b,
+	0x59, 0x40, 0x1e, 0xc0, 0x6a, 0xc0, 0x92, 0x88, 0x4f, 0x33, 0x57, 0x34, 0x10, 0xd1, 0x9a, 0x31,
+	0x8f, 0x02, 0xb2, 0x0d, 0x6b, 0x62, 0x12, 0xab, 0xd5, 0xf8, 0x97, 0x4c, 0xa4, 0xaa, 0x80, 0x00,
+	0xc2, 0xda, 0x86, 0xfd, 0x8d, 0xe6, 0xba, 0x04, 0x3a, 0x98, 0x25, 0x9c, 0x47, 0xd9, 0x1e, 0x73,
+	0x3f, 0x85, 0xf5, 0x02, 0xcf, 0x04, 0x72, 0x1b, 0xaa, 0x52, 0x31, 0x4c, 0xde, 0xac, 0x63, 0xde,
+	0x3c, 0x1f, 0xa8, 0x05, 0xbd, 0xe0, 0xf1, 0x30, 0x1c, 0x79, 0x5a, 0xee, 0x3e, 0x83, 0xd6, 0x0b,
+	0x9a, 0xd0, 0xb3, 0x30, 0x0a, 0x65, 0xc8, 0x52, 0xd2, 0x05, 0x7b, 0xc0, 0xc7, 0xca, 0x2e, 0x5d,
+	0xfc, 0x6c, 0x2f, 0xa7, 0x95, 0xfb, 0xe8, 0x24, 0x08, 0x25, 0x86, 0xd3, 0xf6, 0x34, 0xe1, 0xfe,
+	0xab, 0x0e, 0xad, 0xe2, 0xc8, 0xa4, 0x0d, 0xa5, 0x3c, 0x27, 0x4a, 0x61, 0x40, 0x08, 0x54, 0xd0,
+	0x73, 0x3a, 0x09, 0xf0, 0x9f, 0xdc, 0x83, 0x4a, 0x38, 0xe0, 0xb1, 0xae, 0x7f, 0xfd, 0x15, 0x0f,
+	0xa9, 0x1f, 0x2c, 0x4b, 0x05, 0x83, 0xe2, 0x60, 0xda, 0xdb, 0x15, 0xd4, 0x01, 0xcd, 0x42, 0x7f,
+	0xbf, 0x03, 0x36, 0x4d, 0x42, 0x2d, 0xd5, 0x35, 0xb0, 0x4e, 0x93, 0x10, 0x45, 0xfb, 0xb9, 0xae,
+	0x9c, 0x26, 0x0c, 0x03, 0xd9, 0xde, 0x5f, 0x37, 0xb5, 0x4b, 0x9b, 0x78, 0x3a, 0x4d, 0x58, 0x36,
+	0x9c, 0xfa, 0x27, 0x2f, 0xa0, 0x1d, 0x8e, 0x13, 0x55, 0xe6, 0x62, 0xa9, 0x4b, 0x77, 0x1d, 0xd5,
+	0xee, 0xcf, 0xa9, 0x1d, 0xcd, 0x41, 0xbc, 0x05, 0x15, 0xb2, 0x07, 0xb5, 0x50, 0x1d, 0x6c, 0xa9,
+	0x63, 0xa3, 0xbb, 0xef, 0xa1, 0xbb, 0x4d, 0x80, 0x3e, 0x57, 0xbb, 0xde, 0x38, 0xdd, 0xc0, 0xc8,
+	0x43, 0xb0, 0x85, 0x09, 0x95, 0xd3, 0x40, 0x15, 0xc7, 0xa8, 0x68, 0x66, 0x51, 0x27, 0x47, 0x92,
+	0x1e, 0x54, 0x65, 0x28, 0x23, 0x86, 0xc9, 0xd1, 0xcc, 0x4e, 0x25, 0x29, 0xc2, 0x78, 0x74, 0xca,
+	0xc6, 0x49, 0x44, 0x25, 0xeb, 0x5b, 0x9e, 0xc6, 0x28, 0x5f, 0x3e, 0x82, 0xc6, 0x39, 0x8b, 0x12,
+	0x5f, 0xb2, 0x2b, 0xe9, 0x34, 0x6f, 0xd6, 0x29, 0x79, 0xb6, 0xc2, 0x9d, 0xb2, 0x2b, 0xa9, 0xd4,
+	0x1e, 0xa8, 0xf3, 0x57, 0x86, 0x72, 0xea, 0xa7, 0x61, 0x3c, 0x8a, 0x98, 0xd3, 0xd2, 0x29, 0xab,
+	0x99, 0x27, 0xc8, 0x2b, 0x80, 0x92, 0x68, 0x22, 0x68, 0xe4, 0xac, 0x16, 0x41, 0xc7, 0xc8, 0x23,
+	0x8f, 0xa0, 0x35, 0x28, 0x24, 0x97, 0xd3, 0xde, 0xb2, 0xf2, 0x64, 0x2c, 0x66, 0x9d, 0x37, 0x07,
+	0x23, 0x9f, 0xc0, 0x9a, 0x60, 0xca, 0xb2, 0xc0, 0xd7, 0x91, 0x4a, 0x9d, 0x35, 0x74, 0xd2, 0x5a,
+	0x21, 0x8d, 0x5f, 0x87, 0xf1, 0x85, 0xd7, 0x36, 0x38, 0xcd, 0x4a, 0xc9, 0x33, 0x80, 0x84, 0x8e,
+	0xc2, 0x58, 0x47, 0xb2, 0x83, 0xd3, 0xdd, 0xd7, 0xd3, 0x4d, 0x44, 0xca, 0xc5, 0x71, 0x2e, 0xd4,
+	0xce, 0xed, 0x97, 0xbd, 0x82, 0x82, 0x5a, 0xfc, 0xb6, 0x2a, 0xfa, 0xf1, 0x45, 0xea, 0xac, 0x17,
+	0x36, 0xce, 0xcb, 0x2b, 0xc9, 0x44, 0x4c, 0x23, 0x9c, 0x53, 0xcb, 0xc9, 0x4b, 0xd8, 0x64, 0xb1,
+	0x14, 0x53, 0x34, 0xf1, 0x32, 0x94, 0xfa, 0x47, 0xd9, 0x4a, 0x96, 0xdb, 0xba, 0x81, 0xf0, 0xe7,
+	0x06, 0x9d, 0x59, 0xfc, 0x04, 0x3a, 0x6c, 0x7c, 0xc6, 0x82, 0xa0, 0xb0, 0xd8, 0x3b, 0xcb, 0x07,
+	0x58, 0xcb, 0x80, 0x99, 0xee, 0x67, 0xd0, 0x19, 0x31, 0xe9, 0xcf, 0xcc, 0xe0, 0xb1, 0xb3, 0xb1,
+	0x65, 0x2d, 0xd1, 0xed, 0x57, 0xbc, 0xf6, 0x88, 0xc9, 0x97, 0x99, 0x05, 0xb8, 0xd6, 0x83, 0x3a,
+	0x54, 0x7d, 0xb5, 0xef, 0x0e, 0x6c, 0xa8, 0xf9, 0x98, 0x35, 0xd8, 0x1c, 0xe5, 0x39, 0x73, 0xb0,
+	0x0a, 0x4d, 0x7f, 0xe6, 0x9e, 0x83, 0x3b, 0xb0, 0xee, 0x2f, 0x4e, 0xe8, 0xfe, 0xa3, 0x02, 0xe4,
+	0x7a, 0xa6, 0x93, 0x87, 0xd0, 0xc6, 0xe3, 0xce, 0x8f, 0xf8, 0x40, 0x47, 0x23, 0xeb, 0x11, 0x95,
+	0x65, 0xea, 0x3c, 0x39, 0xa6, 0xf2, 0xdc, 0x5b, 0x45, 0xd0, 0x6b, 0x83, 0x21, 0xbb, 0x00, 0x5a,
+	0x0b, 0x37, 0x70, 0x09, 0x77, 0x62, 0xd3, 0xec, 0x44, 0xdc, 0xba, 0x0d, 0x14, 0xab, 0x5f, 0xf2,
+	0x1e, 0xb4, 0x82, 0x30, 0x4d, 0x22, 0x3a, 0xd5, 0xc5, 0xc0, 0xb4, 0x52, 0x86, 0x87, 0x05, 0x41,
+	0x15, 0x5f, 0x03, 0xe1, 0x22, 0x60, 0xc2, 0xb4, 0x53, 0x99, 0xde, 0x57, 0x8a, 0x47, 0x1c, 0xa8,
+	0x5f, 0x86, 0x69, 0x78, 0x16, 0x31, 0x73, 0x2c, 0x64, 0xe4, 0xfc, 0xfe, 0xa9, 0xdd, 0xbc, 0x7f,
+	0x56, 0xe6, 0xf7, 0xcf, 0x63, 0x58, 0x8d, 0x38, 0xbf, 0x98, 0x24, 0x59, 0x4c, 0xea, 0xcb, 0x63,
+	0x62, 0x79, 0x2d, 0x8d, 0xcb, 0x23, 0xb2, 0x34, 0xa2, 0xf6, 0x72, 0xed, 0xd2, 0x92, 0x88, 0xaa,
+	0xe6, 0x24, 0xe2, 0x83, 0x0b, 0xa6, 0x8f, 0x19, 0xdb, 0x33, 0x14, 0xf9, 0x9d, 0x3a, 0x85, 0x86,
+	0x74, 0x12, 0x49, 0x5f, 0xf7, 0x1e, 0x50, 0xd8, 0x89, 0x87, 0x5a, 0xf2, 0x8d, 0x12, 0xf4, 0xcb,
+	0xea, 0x68, 0x9a, 0xd1, 0x6a, 0xd0, 0x27, 0xd0, 0x4c, 0x22, 0x3a, 0x60, 0xe7, 0x3c, 0x52, 0x3e,
+	0xbc, 0xa5, 0x90, 0x54, 0xbc, 0x22, 0x32, 0x6b, 0xb6, 0x67, 0xf9, 0xd4, 0x81, 0xb6, 0x3f, 0xe7,
+	0x9a, 0xa5, 0x29, 0x85, 0xb0, 0x39, 0x73, 0x0f, 0xda, 0xd0, 0xf2, 0x0b, 0x23, 0xbb, 0x7f, 0x29,
+	0xc3, 0x9d, 0x25, 0xb5, 0xf2, 0x7f, 0x39, 0xeb, 0xba, 0x60, 0xa7, 0x5c, 0x48, 0xaa, 0x46, 0xac,
+	0xeb, 0x83, 0x3b, 0xa3, 0xc9, 0x0e, 0x54, 0x54, 0xd1, 0xba, 0x29, 0x95, 0x2c, 0x0f, 0xc5, 0xa6,
+	0xf6, 0x87, 0x63, 0x3a, 0x62, 0x7e, 0x22, 0xd8, 0x65, 0xc8, 0xde, 0x9a, 0x3c, 0x6a, 0x21, 0xf3,
+	0x58, 0xf3, 0x16, 0x82, 0xaa, 0xaa, 0x88, 0x52, 0x77, 0x7f, 0x0f, 0xad, 0x62, 0x2a, 0x11, 0x47,
+	0xdd, 0xa2, 0x94, 0xc9, 0xba, 0x17, 0xe8, 0xaf, 0x78, 0x86, 0x26, 0x5d, 0xa8, 0x87, 0xb1, 0x64,
+	0x23, 0x26, 0xf4, 0x45, 0xa9, 0xbf, 0xe2, 0x65, 0x0c, 0xb2, 0x09, 0xd5, 0x61, 0xc4, 0xa9, 0x44,
+	0xcf, 0x96, 0xfa, 0x2b, 0x9e, 0x26, 0xc9, 0x06, 0x54, 0xce, 0x38, 0x8f, 0xf2, 0x4b, 0x1d, 0x52,
+	0x6a, 0x72, 0xdd, 0x3f, 0xf7, 0xa1, 0x3d, 0xef, 0x1f, 0xe5, 0x10, 0x69, 0xfe, 0xb3, 0x56, 0x56,
+	0x16, 0x64, 0x63, 0x2a, 0x2e, 0x02, 0xfe, 0x36, 0x36, 0xcd, 0x4c, 0x4e, 0xbb, 0xbf, 0x00, 0x3b,
+	0x4b, 0x14, 0xd5, 0xba, 0x24, 0x54, 0x9e, 0x1b, 0x7d, 0xfc, 0x77, 0xff, 0x68, 0x41, 0xab, 0x78,
+	0x20, 0x90, 0x0f, 0xa1, 0x1a, 0xd1, 0x33, 0x16, 0x39, 0xd6, 0x8d, 0xc1, 0xf2, 0x34, 0x82, 0x6c,
+	0x43, 0xe5, 0x5c, 0xb0, 0xa1, 0x53, 0xba, 0x19, 0x89, 0x80, 0x1b, 0xfb, 0xa3, 0xbc, 0x66, 0xbb,
+	0x7f, 0xb2, 0x00, 0x66, 0x31, 0x24, 0xf7, 0xa0, 0x2e, 0x39, 0x8f, 0x66, 0xdd, 0x78, 0x4d, 0x91,
+	0x47, 0x01, 0x79, 0x1f, 0x2a, 0x01, 0x95, 0xd4, 0x29, 0xe1, 0xa1, 0xd2, 0xd1, 0x3b, 0x9e, 0x4a,
+	0xfa, 0x86, 0x26, 0x49, 0x18, 0x8f, 0x3c, 0x94, 0xce, 0x5a, 0x8b, 0xf2, 0x6d, 0x09, 0x97, 0xb7,
+	0x16, 0xb3, 0x13, 0xc3, 0xfd, 0x5b, 0x05, 0x36, 0x97, 0x1f, 0xae, 0xe4, 0x29, 0x54, 0xb1, 0x65,
+	0x37, 0x8e, 0xd9, 0xbe, 0xe5, 0x20, 0xee, 0x15, 0x3b, 0x1e, 0xad, 0x45, 0x1e, 0x43, 0x99, 0xc5,
+	0x81, 0x53, 0xfa, 0x79, 0xca, 0x4a, 0x87, 0xbc, 0x82, 0x46, 0xa2, 0xb2, 0x38, 0x0d, 0xbf, 0xcf,
+	0x96, 0xb4, 0x7b, 0xdb, 0x00, 0xc7, 0x74, 0xc4, 0x4e, 0xc2, 0xef, 0x59, 0xd6, 0x72, 0x25, 0x86,
+	0x26, 0xbb, 0xd0, 0x88, 0xd9, 0x95, 0x54, 0xa7, 0x60, 0x76, 0xd3, 0x58, 0xa8, 0x25, 0xb6, 0x92,
+	0x2b, 0x7d, 0xd2, 0x83, 0x26, 0xde, 0xd3, 0xfd, 0x01, 0x9f, 0xc4, 0xfa, 0xd6, 0x78, 0x0d, 0x0d,
+	0x88, 0x78, 0xa1, 0x00, 0xdd, 0x73, 0x68, 0x16, 0x6b, 0xd7, 0x03, 0x58, 0x15, 0xfa, 0x1c, 0xf5,
+	0xb1, 0x4b, 0x34, 0x71, 0x6c, 0x19, 0xa6, 0x7e, 0x39, 0x79, 0x08, 0xed, 0xac, 0x1d, 0xf4, 0xf5,
+	0xdd, 0xb2, 0xb4, 0xb4, 0xc0, 0x89, 0x62, 0x71, 0xec, 0xfe, 0xd9, 0x82, 0xf6, 0xfc, 0x12, 0xff,
+	0x8b, 0xb3, 0xe9, 0x2b, 0x4f, 0xf1, 0xb0, 0x29, 0x9b, 0xfa, 0x57, 0x28, 0x0e, 0xee, 0x14, 0x9a,
+	0x85, 0x2c, 0x24, 0x1d, 0x28, 0x5f, 0xb0, 0xa9, 0x31, 0x42, 0xfd, 0x92, 0x07, 0x66, 0xeb, 0x2d,
+	0x9b, 0x51, 0x6d, 0x08, 0x25, 0x24, 0xbb, 0x50, 0xe3, 0x67, 0xea, 0xa6, 0x65, 0x82, 0x7c, 0x2d,
+	0xbd, 0x55, 0xd1, 0xd1, 0x88, 0xbc, 0x54, 0xec, 0x7e, 0x08, 0xed, 0xf9, 0xa7, 0x1c, 0xd2, 0x84,
+	0x7a, 0x3a, 0x19, 0x0c, 0x58, 0x9a, 0x76, 0x56, 0x08, 0x40, 0x6d, 0x48, 0xc3, 0x88, 0x05, 0x1d,
+	0x6b, 0xff, 0x9f, 0x25, 0x28, 0x3f, 0x3f, 0x3e, 0x22, 0xcf, 0xf0, 0x7d, 0x05, 0x7b, 0x37, 0xa6,
+	0x5f, 0xb7, 0xc8, 0x5d, 0x9c, 0x6a, 0xf1, 0x3d, 0xad, 0xbb, 0xb9, 0xc8, 0x36, 0xbd, 0xfb, 0x27,
+	0xd0, 0xf4, 0x26, 0x71, 0x36, 0x2f, 0x21, 0x73, 0x2f, 0x4a, 0xe8, 0xed, 0xee, 0xdd, 0x39, 0x5e,
+	0xae, 0xf9, 0x5b, 0xb0, 0xb3, 0xd7, 0x1a, 0xb2, 0x91, 0x8d, 0x5e, 0xbc, 0xe7, 0x77, 0xef, 0x2e,
+	0x70, 0x8d, 0xe2, 0x53, 0x80, 0xd9, 0x93, 0x02, 0xd9, 0x9c, 0x7f, 0xec, 0xc8, 0xae, 0x8f, 0xdd,
+	0x7b, 0xd7, 0xf8, 0x46, 0xfd, 0x19, 0x34, 0x0b, 0x4f, 0x04, 0xe4, 0x9e, 0xe9, 0x84, 0x17, 0x5f,
+	0x19, 0xba, 0xce, 0x75, 0x81, 0x19, 0xe1, 0x09, 0x34, 0xf2, 0x9b, 0xa9, 0xf1, 0xd7, 0xe2, 0xed,
+	0xb5, 0xbb, 0xb9, 0xc8, 0xd6, 0xba, 0x67, 0x35, 0x3c, 0x7e, 0x7f, 0xf3, 0x9f, 0x01, 0x00, 0xb8,
+	0x34, 0xdb, 0x53, 0x1d, 0x15, 0x00, 0x00,
 }
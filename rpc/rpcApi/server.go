@@ -3,8 +3,9 @@ package rpcApi
 import (
 	"context"
 	"encoding/json"
-	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/teamkeel/keel/cmd/localTraceExporter"
 	"github.com/teamkeel/keel/db"
@@ -12,7 +13,6 @@ import (
 	"github.com/teamkeel/keel/rpc/rpc"
 	"github.com/teamkeel/keel/tools"
 	"github.com/twitchtv/twirp"
-	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -66,57 +66,56 @@ func (s *Server) RunSQLQuery(ctx context.Context, input *rpc.SQLQueryInput) (*rp
 }
 
 func (s *Server) ListTraces(ctx context.Context, input *rpc.ListTracesRequest) (*rpc.ListTracesResponse, error) {
-	traces := localTraceExporter.Summary()
-
-	verbose := GetTraceVerbosity(ctx)
-
-	list := []*rpc.TraceItem{}
-
-	for k, v := range traces {
-		if input.After != nil && v.StartTime.Before(input.After.AsTime()) {
-			continue
-		}
+	query := &localTraceExporter.TraceQuery{
+		RequestsOnly: !GetTraceVerbosity(ctx),
+		Attributes:   map[string]string{},
+		Limit:        int(input.Limit),
+		Offset:       int(input.Offset),
+	}
 
-		if input.Before != nil && v.StartTime.After(input.Before.AsTime()) {
-			continue
-		}
+	if input.After != nil {
+		after := input.After.AsTime()
+		query.After = &after
+	}
 
-		if input.Filters != nil {
-			filteredOut := false
-			for _, f := range input.Filters {
-				switch f.Field {
-				case "error":
-					if f.Value == "true" && !v.HasError {
-						filteredOut = true
-						break
-					} else if f.Value == "false" && v.HasError {
-						filteredOut = true
-						break
-					}
-				}
-			}
-			if filteredOut {
-				continue
-			}
-		}
+	if input.Before != nil {
+		before := input.Before.AsTime()
+		query.Before = &before
+	}
 
-		if !verbose {
-			if v.Type != "request" {
-				continue
-			}
-			if v.RootName == "GET /_health" {
-				continue
+	for _, f := range input.Filters {
+		switch {
+		case f.Field == "error":
+			hasError, err := strconv.ParseBool(f.Value)
+			if err != nil {
+				return nil, twirp.InvalidArgumentError(f.Field, "must be true or false")
 			}
-			if strings.HasSuffix(v.RootName, "openapi.json") {
-				continue
+			query.HasError = &hasError
+		case f.Field == "duration.min" || f.Field == "duration.max":
+			ms, err := strconv.ParseFloat(f.Value, 64)
+			if err != nil {
+				return nil, twirp.InvalidArgumentError(f.Field, "must be a duration in milliseconds")
 			}
-			if strings.HasPrefix(v.RootName, "OPTIONS") {
-				continue
+			duration := time.Duration(ms * float64(time.Millisecond))
+			if f.Field == "duration.min" {
+				query.MinDuration = &duration
+			} else {
+				query.MaxDuration = &duration
 			}
+		case strings.HasPrefix(f.Field, "attribute."):
+			query.Attributes[strings.TrimPrefix(f.Field, "attribute.")] = f.Value
 		}
+	}
+
+	traces, err := localTraceExporter.ListTraces(ctx, query)
+	if err != nil {
+		return nil, twirp.NewError(twirp.Internal, err.Error())
+	}
 
+	list := []*rpc.TraceItem{}
+	for _, v := range traces {
 		list = append(list, &rpc.TraceItem{
-			TraceId:    k,
+			TraceId:    v.TraceID,
 			RootName:   v.RootName,
 			StartTime:  timestamppb.New(v.StartTime),
 			EndTime:    timestamppb.New(v.EndTime),
@@ -125,46 +124,43 @@ func (s *Server) ListTraces(ctx context.Context, input *rpc.ListTracesRequest) (
 		})
 	}
 
-	sort.Slice(list, func(i, j int) bool {
-		return list[i].StartTime.AsTime().After(list[j].StartTime.AsTime())
-	})
+	return &rpc.ListTracesResponse{
+		Traces: list,
+	}, nil
+}
 
-	if int(input.Offset) > len(list) {
-		list = []*rpc.TraceItem{}
-	} else {
-		list = list[input.Offset:]
+func (s *Server) GetTrace(ctx context.Context, input *rpc.GetTraceRequest) (*rpc.GetTraceResponse, error) {
+	trace, err := localTraceExporter.GetTrace(ctx, input.TraceId)
+	if err != nil {
+		return nil, twirp.NewError(twirp.Internal, err.Error())
 	}
 
-	if input.Limit > int32(len(list)) {
-		input.Limit = int32(len(list))
+	if trace == nil {
+		return nil, twirp.NewError(twirp.NotFound, "trace not found")
 	}
 
-	list = list[:input.Limit]
-
-	return &rpc.ListTracesResponse{
-		Traces: list,
+	return &rpc.GetTraceResponse{
+		Trace: localTraceExporter.TracesData(trace),
 	}, nil
 }
 
-func (s *Server) GetTrace(ctx context.Context, input *rpc.GetTraceRequest) (*rpc.GetTraceResponse, error) {
-	trace := localTraceExporter.GetTrace(input.TraceId)
+func (s *Server) ExportTrace(ctx context.Context, input *rpc.ExportTraceRequest) (*rpc.ExportTraceResponse, error) {
+	trace, err := localTraceExporter.GetTrace(ctx, input.TraceId)
+	if err != nil {
+		return nil, twirp.NewError(twirp.Internal, err.Error())
+	}
 
 	if trace == nil {
 		return nil, twirp.NewError(twirp.NotFound, "trace not found")
 	}
 
-	traceData := v1.TracesData{
-		ResourceSpans: []*v1.ResourceSpans{
-			{
-				ScopeSpans: []*v1.ScopeSpans{
-					{Spans: trace},
-				},
-			},
-		},
+	b, err := localTraceExporter.MarshalOTLPJSON(trace)
+	if err != nil {
+		return nil, twirp.NewError(twirp.Internal, err.Error())
 	}
 
-	return &rpc.GetTraceResponse{
-		Trace: &traceData,
+	return &rpc.ExportTraceResponse{
+		OtlpJson: string(b),
 	}, nil
 }
 
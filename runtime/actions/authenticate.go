@@ -45,6 +45,23 @@ func ResetRequestPassword(scope *Scope, input map[string]any) error {
 		return common.RuntimeError{Code: common.ErrInvalidInput, Message: "invalid redirect URL"}
 	}
 
+	clientIp := runtimectx.GetClientIp(scope.Context)
+
+	lockedUntil, err := oauth.RateLimitLockedOut(scope.Context, oauth.RateLimitPasswordReset, emailString, clientIp)
+	if err != nil {
+		return err
+	}
+
+	if lockedUntil != nil {
+		return common.RuntimeError{Code: common.ErrTooManyRequests, Message: "too many password reset requests have been made, please try again later"}
+	}
+
+	// Every request counts as an attempt, as each one sends an email.
+	_, err = oauth.RecordRateLimitAttempt(scope.Context, oauth.RateLimitPasswordReset, emailString, clientIp)
+	if err != nil {
+		return err
+	}
+
 	var identity auth.Identity
 	identity, err = FindIdentityByEmail(scope.Context, scope.Schema, emailString, oauth.KeelIssuer)
 	if err != nil {
@@ -68,16 +85,39 @@ func ResetRequestPassword(scope *Scope, input map[string]any) error {
 		return err
 	}
 
+	email, err := runtimectx.GetMailTemplates(scope.Context).Render(mail.TemplatePasswordReset, &mail.PasswordResetData{
+		Identity: TemplateIdentity(identity),
+		ResetUrl: redirectUrl.String(),
+	})
+	if err != nil {
+		return err
+	}
+
 	err = client.Send(scope.Context, &mail.SendEmailRequest{
-		To:        identity["email"].(string),
-		From:      "hi@keel.xyz",
-		Subject:   "[Keel] Reset password request",
-		PlainText: fmt.Sprintf("Please follow this link to reset your password: %s", redirectUrl),
+		To:        identity[parser.IdentityFieldNameEmail].(string),
+		Subject:   email.Subject,
+		PlainText: email.PlainText,
+		HTML:      email.HTML,
 	})
 
 	return err
 }
 
+// TemplateIdentity returns the details of the identity which are available in email templates.
+func TemplateIdentity(identity auth.Identity) mail.TemplateIdentity {
+	t := mail.TemplateIdentity{}
+	t.Id, _ = identity[parser.FieldNameId].(string)
+	t.Email, _ = identity[parser.IdentityFieldNameEmail].(string)
+	t.Name, _ = identity[parser.IdentityFieldNameName].(string)
+
+	// Fall back to the given name if the identity has no full name
+	if t.Name == "" {
+		t.Name, _ = identity[parser.IdentityFieldNameGivenName].(string)
+	}
+
+	return t
+}
+
 // Deprecated: we will be deprecating the authenticate action and password flow in favour of the new auth endpoints
 func ResetPassword(scope *Scope, input map[string]any) error {
 	typedInput := typed.New(input)
@@ -23,7 +23,8 @@ func AuthoriseAction(scope *Scope, input map[string]any, rowsToAuthorise []map[s
 		return false, errors.New("cannot authorise with AuthoriseAction if no operation is provided in scope")
 	}
 
-	if scope.Action.Type == proto.ActionType_ACTION_TYPE_UPDATE || scope.Action.Type == proto.ActionType_ACTION_TYPE_LIST {
+	switch scope.Action.Type {
+	case proto.ActionType_ACTION_TYPE_UPDATE, proto.ActionType_ACTION_TYPE_LIST, proto.ActionType_ACTION_TYPE_UPDATE_MANY, proto.ActionType_ACTION_TYPE_DELETE_MANY:
 		var ok bool
 		input, ok = input["where"].(map[string]any)
 		if !ok {
@@ -242,12 +243,16 @@ func getEmailAndDomain(ctx context.Context) (email string, domain string, verifi
 		return "", "", false, ErrIdentityNotFound
 	}
 
-	e := identity[parser.IdentityFieldNameEmail].(string)
+	e, _ := identity[parser.IdentityFieldNameEmail].(string)
 	if e == "" {
 		return "", "", false, nil
 	}
 
+	// Identities which sign in with a password are only verified once they confirm
+	// the link sent by the email verification endpoint.
+	verified, _ = identity[parser.IdentityFieldNameEmailVerified].(bool)
+
 	segments := strings.Split(e, "@")
-	domain = segments[1]
-	return e, domain, identity[parser.IdentityFieldNameEmailVerified].(bool), nil
+	domain = segments[len(segments)-1]
+	return e, domain, verified, nil
 }
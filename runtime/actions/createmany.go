@@ -0,0 +1,175 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+// CreateMany creates a record for each of the values in the input within a single transaction,
+// and returns the ids of the records created.
+func CreateMany(scope *Scope, input map[string]any) (res map[string]any, err error) {
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+
+	// Attempt to resolve permissions early; i.e. before row-based database querying.
+	canResolveEarly, authorised, err := TryResolveAuthorisationEarly(scope, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if canResolveEarly && !authorised {
+		return nil, common.NewPermissionError()
+	}
+
+	values, ok := input["values"].([]any)
+	if !ok {
+		values = []any{}
+	}
+
+	cfg, err := runtimectx.GetActionsConfig(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) > cfg.BatchSizeLimit() {
+		return nil, common.NewValidationError(fmt.Sprintf("values cannot have more than %d items", cfg.BatchSizeLimit()))
+	}
+
+	ids := []string{}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		rows := []*Row{}
+		for i, v := range values {
+			row, ok := v.(map[string]any)
+			if !ok {
+				return common.NewInputMalformedError(fmt.Sprintf("values[%d] is not an object", i))
+			}
+
+			if scope.Model.HasFiles() {
+				// handle file uploads
+				row, err = handleFileUploads(scope, row)
+				if err != nil {
+					return fmt.Errorf("handling file uploads: %w", err)
+				}
+			}
+
+			query := NewQuery(scope.Model)
+			err := query.captureCreateManyValues(scope, row)
+			if err != nil {
+				return err
+			}
+
+			rows = append(rows, query.writeValues)
+		}
+
+		// Records which are created along with related records are inserted one at a time, as the
+		// related records depend on the id of each one. All others are inserted with a single statement.
+		ids = make([]string, len(rows))
+		flat := []map[string]*QueryOperand{}
+		flatIndexes := []int{}
+		for i, row := range rows {
+			if row.isFlat() {
+				flat = append(flat, row.values)
+				flatIndexes = append(flatIndexes, i)
+				continue
+			}
+
+			query := NewQuery(scope.Model)
+			query.writeValues = row
+
+			created, err := query.InsertStatement(scope.Context).ExecuteToSingle(scope.Context)
+			if err != nil {
+				return err
+			}
+
+			id, ok := created["id"].(string)
+			if !ok {
+				return errors.New("could not parse id key")
+			}
+
+			ids[i] = id
+		}
+
+		if len(flat) > 0 {
+			query := NewQuery(scope.Model)
+			created, _, err := query.InsertManyStatement(scope.Context, flat).ExecuteToMany(scope.Context, nil)
+			if err != nil {
+				return err
+			}
+
+			if len(created) != len(flat) {
+				return errors.New("could not create every record")
+			}
+
+			// The rows are returned in the order they are inserted
+			for i, row := range created {
+				id, ok := row["id"].(string)
+				if !ok {
+					return errors.New("could not parse id key")
+				}
+
+				ids[flatIndexes[i]] = id
+			}
+		}
+
+		if canResolveEarly || len(ids) == 0 {
+			return nil
+		}
+
+		// Every created record must satisfy the permissions, otherwise none are created
+		isAuthorised, err := AuthoriseAction(scope, input, idRows(ids))
+		if err != nil {
+			return err
+		}
+
+		if !isAuthorised {
+			return common.NewPermissionError()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bulkActionResult(ids), nil
+}
+
+// captureCreateManyValues captures the values of the record for one of the values of a createMany action.
+func (query *QueryBuilder) captureCreateManyValues(scope *Scope, values map[string]any) error {
+	err := query.captureWriteValues(scope, values)
+	if err != nil {
+		return err
+	}
+
+	return query.captureSetValues(scope, values)
+}
+
+// bulkActionResult is the response of the createMany, updateMany and deleteMany actions.
+func bulkActionResult(ids []string) map[string]any {
+	return map[string]any{
+		"ids":   ids,
+		"count": len(ids),
+	}
+}
+
+// idRows converts ids into rows which can be authorised.
+func idRows(ids []string) []map[string]any {
+	rows := make([]map[string]any, len(ids))
+	for i, id := range ids {
+		rows[i] = map[string]any{"id": id}
+	}
+	return rows
+}
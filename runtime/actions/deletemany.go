@@ -0,0 +1,99 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+)
+
+// DeleteMany deletes all the records which match the filters in the input within a single transaction,
+// and returns the ids of the records deleted.
+func DeleteMany(scope *Scope, input map[string]any) (res map[string]any, err error) {
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+
+	// Attempt to resolve permissions early; i.e. before row-based database querying.
+	canResolveEarly, authorised, err := TryResolveAuthorisationEarly(scope, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if canResolveEarly && !authorised {
+		return nil, common.NewPermissionError()
+	}
+
+	where, ok := input["where"].(map[string]any)
+	if !ok {
+		where = map[string]any{}
+	}
+
+	ids := []string{}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		ids, err = findBulkActionIds(scope, where)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		// Every record must satisfy the permissions before it is deleted, otherwise none are deleted
+		if !canResolveEarly {
+			isAuthorised, err := AuthoriseAction(scope, input, idRows(ids))
+			if err != nil {
+				return err
+			}
+
+			if !isAuthorised {
+				return common.NewPermissionError()
+			}
+		}
+
+		// Generate the SQL statement
+		query := NewQuery(scope.Model)
+		statement, err := GenerateDeleteManyStatement(query, scope, ids)
+		if err != nil {
+			return err
+		}
+
+		rows, _, err := statement.ExecuteToMany(scope.Context, nil)
+		if err != nil {
+			return err
+		}
+
+		ids = lo.Map(rows, func(row map[string]any, _ int) string {
+			id, _ := row["id"].(string)
+			return id
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bulkActionResult(ids), nil
+}
+
+// GenerateDeleteManyStatement generates the statement which deletes the records with the given ids.
+func GenerateDeleteManyStatement(query *QueryBuilder, scope *Scope, ids []string) (*Statement, error) {
+	err := query.Where(IdField(), OneOf, Value(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	query.AppendReturning(IdField())
+
+	return query.DeleteStatement(scope.Context), nil
+}
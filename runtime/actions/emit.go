@@ -0,0 +1,36 @@
+package actions
+
+import (
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/proto"
+)
+
+// emitEvents emits the user-defined events of the action, with payloads populated from
+// the record which the action has written.
+func emitEvents(scope *Scope, result any) error {
+	record, ok := result.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	for _, eventName := range scope.Action.Emits {
+		event := proto.FindEvent(scope.Schema.Events, eventName)
+		if event == nil || !event.IsUserDefined() {
+			continue
+		}
+
+		data := map[string]any{}
+		for _, field := range scope.Schema.FindMessage(event.MessageName).Fields {
+			if value, ok := record[field.Name]; ok {
+				data[field.Name] = value
+			}
+		}
+
+		err := events.Emit(scope.Context, eventName, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
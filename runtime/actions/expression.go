@@ -8,6 +8,7 @@ import (
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/auth"
 	"github.com/teamkeel/keel/runtime/expressions"
+	"github.com/teamkeel/keel/runtime/expressions/sqlgen"
 	"github.com/teamkeel/keel/schema/parser"
 )
 
@@ -95,7 +96,12 @@ func (query *QueryBuilder) whereByCondition(scope *Scope, condition *parser.Cond
 	var left, right *QueryOperand
 
 	// Generate lhs QueryOperand
-	left, err := generateQueryOperand(lhsResolver, args)
+	var err error
+	if lhsResolver.IsComputed() {
+		left, err = query.computedQueryOperand(scope, lhsResolver, args)
+	} else {
+		left, err = generateQueryOperand(lhsResolver, args)
+	}
 	if err != nil {
 		return err
 	}
@@ -141,7 +147,11 @@ func (query *QueryBuilder) whereByCondition(scope *Scope, condition *parser.Cond
 		}
 
 		// Generate the rhs QueryOperand
-		right, err = generateQueryOperand(rhsResolver, args)
+		if rhsResolver.IsComputed() {
+			right, err = query.computedQueryOperand(scope, rhsResolver, args)
+		} else {
+			right, err = generateQueryOperand(rhsResolver, args)
+		}
 		if err != nil {
 			return err
 		}
@@ -346,3 +356,128 @@ func generateQueryOperand(resolver *expressions.OperandResolver, args map[string
 
 	return queryOperand, nil
 }
+
+// Generates a QueryOperand for an operand which is computed from other operands, such as post.price * quantity
+// or lower(post.title). If none of the operands reside in the database then the value is computed in this process,
+// otherwise the equivalent SQL is generated and any joins required by model fields are added to the query.
+func (query *QueryBuilder) computedQueryOperand(scope *Scope, resolver *expressions.OperandResolver, args map[string]any) (*QueryOperand, error) {
+	if !resolver.IsComputedFromDatabase() {
+		value, err := resolver.ResolveValue(args)
+		if err != nil {
+			return nil, err
+		}
+
+		if value == nil {
+			return Null(), nil
+		}
+		return Value(value), nil
+	}
+
+	term, err := query.computedTerm(scope, resolver, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return term.operand, nil
+}
+
+// An operand within a computed value along with the type it resolves to.
+type computedTerm struct {
+	operand *QueryOperand
+	typ     proto.Type
+}
+
+func (query *QueryBuilder) computedTerm(scope *Scope, resolver *expressions.OperandResolver, args map[string]any) (*computedTerm, error) {
+	if duration := resolver.Duration(); duration != nil {
+		return &computedTerm{
+			operand: Raw(fmt.Sprintf("INTERVAL '%s'", duration.ToString())),
+			typ:     proto.Type_TYPE_UNKNOWN,
+		}, nil
+	}
+
+	operandType, _, err := resolver.GetOperandType()
+	if err != nil {
+		return nil, err
+	}
+
+	if !resolver.IsComputed() {
+		if resolver.IsModelDbColumn() {
+			fragments, err := resolver.NormalisedFragments()
+			if err != nil {
+				return nil, err
+			}
+
+			// Generates joins based on the fragments that make up the operand
+			err = query.addJoinFromFragments(scope, fragments)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		operand, err := generateQueryOperand(resolver, args)
+		if err != nil {
+			return nil, err
+		}
+
+		// Values are cast so that the database can determine the types of the operations
+		if operand.IsValue() {
+			operand = Computed("%s"+sqlCast(operandType), operand)
+		}
+
+		return &computedTerm{operand: operand, typ: operandType}, nil
+	}
+
+	name, arguments := resolver.Function()
+	if name == "" {
+		resolvers, operators := resolver.ArithmeticTerms()
+
+		terms := make([]*computedTerm, len(resolvers))
+		for i, r := range resolvers {
+			terms[i], err = query.computedTerm(scope, r, args)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return parser.FoldArithmetic(terms, operators, func(lhs *computedTerm, operator string, rhs *computedTerm) (*computedTerm, error) {
+			t := sqlgen.ArithmeticType(lhs.typ, rhs.typ)
+			return &computedTerm{operand: Computed(sqlgen.Arithmetic(operator, t), lhs.operand, rhs.operand), typ: t}, nil
+		})
+	}
+
+	operands := make([]*QueryOperand, len(arguments))
+	for i, r := range arguments {
+		term, err := query.computedTerm(scope, r, args)
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = term.operand
+	}
+
+	template, err := sqlgen.Function(name, len(operands))
+	if err != nil {
+		return nil, err
+	}
+
+	return &computedTerm{operand: Computed(template, operands...), typ: operandType}, nil
+}
+
+// The SQL cast for a value of the given type.
+func sqlCast(t proto.Type) string {
+	switch t {
+	case proto.Type_TYPE_INT:
+		return "::INTEGER"
+	case proto.Type_TYPE_DECIMAL:
+		return "::NUMERIC"
+	case proto.Type_TYPE_BOOL:
+		return "::BOOLEAN"
+	case proto.Type_TYPE_DATE:
+		return "::DATE"
+	case proto.Type_TYPE_DATETIME, proto.Type_TYPE_TIMESTAMP:
+		return "::TIMESTAMPTZ"
+	case proto.Type_TYPE_STRING, proto.Type_TYPE_MARKDOWN:
+		return "::TEXT"
+	default:
+		return ""
+	}
+}
@@ -4,19 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/teamkeel/keel/db"
 	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
 	"github.com/teamkeel/keel/runtime/runtimectx"
 	"github.com/teamkeel/keel/storage"
 )
 
 // handleFileUploads will check the inputs for any file uploads for the scope's action and upload them
 //
-// Currently inline files will be provided as input in a data-url format, we will store these files and change the inputs
-// to a structure that will be then saved in the db
+// Inline files will be provided as input in a data-url format, we will store these files and change the inputs
+// to a structure that will be then saved in the db. Files which have already been uploaded directly using an upload
+// ticket are instead provided as input with their file key, which is only accepted once and only from the identity
+// which the ticket was issued to for the same field.
 func handleFileUploads(scope *Scope, inputs map[string]any) (map[string]any, error) {
-	// we handle file uploads for UPDATE and CREATE actions
-	if scope.Action.Type != proto.ActionType_ACTION_TYPE_UPDATE && scope.Action.Type != proto.ActionType_ACTION_TYPE_CREATE {
+	// we handle file uploads for UPDATE and CREATE actions, including their bulk variants
+	actionType := proto.SingleActionType(scope.Action.Type)
+	if actionType != proto.ActionType_ACTION_TYPE_UPDATE && actionType != proto.ActionType_ACTION_TYPE_CREATE {
 		return inputs, nil
 	}
 	// check if the values input message for the action has any files
@@ -28,6 +35,10 @@ func handleFileUploads(scope *Scope, inputs map[string]any) (map[string]any, err
 	if err != nil {
 		return inputs, fmt.Errorf("invalid file storage: %w", err)
 	}
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return inputs, err
+	}
 
 	for _, field := range message.Fields {
 		// foreach message field that is of inline file type...
@@ -42,10 +53,36 @@ func handleFileUploads(scope *Scope, inputs map[string]any) (map[string]any, err
 				if !ok {
 					return inputs, fmt.Errorf("invalid input for field: %s", field.Name)
 				}
-				// .. we store the fi
-				fi, err := storer.Store(data)
-				if err != nil {
-					return inputs, fmt.Errorf("storing file: %w", err)
+
+				var fi storage.FileResponse
+				if strings.HasPrefix(data, "data:") {
+					// .. we store the fi
+					fi, err = storer.Store(data)
+					if err != nil {
+						return inputs, fmt.Errorf("storing file: %w", err)
+					}
+				} else {
+					// ... or the file has been uploaded directly, so we retrieve its info
+					notFound := common.NewValidationError(fmt.Sprintf("no uploaded file found with key '%s' for field: %s", data, field.Name))
+
+					fi, err = storer.GetFileInfo(data)
+					if err != nil || fi.Key == "" {
+						return inputs, notFound
+					}
+
+					// ... and make sure it was uploaded with a ticket for this field and identity which hasn't been used yet
+					fieldName := field.Name
+					if len(field.Target) == 1 {
+						fieldName = field.Target[0]
+					}
+
+					consumed, err := storage.ConsumeUploadTicket(scope.Context, database, data, scope.Model.Name, fieldName, auth.GetIdentityId(scope.Context))
+					if err != nil {
+						return inputs, err
+					}
+					if !consumed {
+						return inputs, notFound
+					}
 				}
 
 				// ... and then change the input with the file data that should be saved in the db
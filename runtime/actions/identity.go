@@ -105,6 +105,50 @@ func CreateIdentity(ctx context.Context, schema *proto.Schema, email string, pas
 	return result, nil
 }
 
+// CreatePasswordlessIdentity creates an identity without a password for an email address which has
+// been verified by signing in with a code sent to it.
+func CreatePasswordlessIdentity(ctx context.Context, schema *proto.Schema, email string, issuer string) (auth.Identity, error) {
+	identityModel := schema.FindModel(parser.IdentityModelName)
+
+	query := NewQuery(identityModel)
+	query.AddWriteValues(map[string]*QueryOperand{
+		parser.IdentityFieldNameEmail:         Value(email),
+		parser.IdentityFieldNameEmailVerified: Value(true),
+		parser.IdentityFieldNameIssuer:        Value(issuer),
+	})
+	query.Select(AllFields())
+	query.AppendReturning(IdField())
+
+	result, err := query.InsertStatement(ctx).ExecuteToSingle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// VerifyIdentityEmail marks the identity's email address as verified.
+func VerifyIdentityEmail(ctx context.Context, schema *proto.Schema, id string) (auth.Identity, error) {
+	identityModel := schema.FindModel(parser.IdentityModelName)
+
+	query := NewQuery(identityModel)
+	err := query.Where(IdField(), Equals, Value(id))
+	if err != nil {
+		return nil, err
+	}
+
+	query.AddWriteValue(Field(parser.IdentityFieldNameEmailVerified), Value(true))
+	query.Select(AllFields())
+	query.AppendReturning(AllFields())
+
+	result, err := query.UpdateStatement(ctx).ExecuteToSingle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func CreateIdentityWithClaims(ctx context.Context, schema *proto.Schema, externalId string, issuer string, standardClaims *oauth.IdTokenClaims, customClaims map[string]any) (auth.Identity, error) {
 	ctx, span := tracer.Start(ctx, "Create Identity")
 	defer span.End()
@@ -116,6 +116,59 @@ func (query *QueryBuilder) applyImplicitFiltersFromMessage(scope *Scope, message
 	return nil
 }
 
+// Applies @nearest ordering to the query, along with the optional maximum distance filter.
+func (query *QueryBuilder) applyNearest(scope *Scope, nearest map[string]any) error {
+	if scope.Action.Nearest == nil {
+		return nil
+	}
+
+	vector, err := toVector(nearest["to"])
+	if err != nil {
+		return common.NewInputMalformedError("nearest.to must be a vector of numbers")
+	}
+
+	operator, err := distanceMetricToActionOperator(scope.Action.Nearest.Metric)
+	if err != nil {
+		return err
+	}
+
+	distance, err := query.Distance(Field(scope.Action.Nearest.FieldName), operator, vector)
+	if err != nil {
+		return common.NewInputMalformedError(err.Error())
+	}
+
+	if maxDistance, ok := nearest["maxDistance"]; ok && maxDistance != nil {
+		err = query.Where(distance, LessThanEquals, Value(maxDistance))
+		if err != nil {
+			return err
+		}
+		query.And()
+	}
+
+	query.AppendOrderBy(distance, "ASC")
+
+	return nil
+}
+
+func toVector(value any) ([]float64, error) {
+	switch v := value.(type) {
+	case []float64:
+		return v, nil
+	case []any:
+		vector := make([]float64, len(v))
+		for i, item := range v {
+			f, err := toFloat(item)
+			if err != nil {
+				return nil, err
+			}
+			vector[i] = f
+		}
+		return vector, nil
+	default:
+		return nil, fmt.Errorf("%v is not a vector", value)
+	}
+}
+
 // Applies schema-defined @orderBy ordering to the query.
 func (query *QueryBuilder) applySchemaOrdering(scope *Scope) error {
 	for _, orderBy := range scope.Action.OrderBy {
@@ -232,6 +285,17 @@ func GenerateListStatement(query *QueryBuilder, scope *Scope, input map[string]a
 		return nil, nil, err
 	}
 
+	nearest, ok := input["nearest"].(map[string]any)
+	if !ok {
+		nearest = map[string]any{}
+	}
+
+	// Nearest neighbour ordering takes precedence over any other ordering
+	err = query.applyNearest(scope, nearest)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	err = query.applySchemaOrdering(scope)
 	if err != nil {
 		return nil, nil, err
@@ -57,6 +57,10 @@ const (
 	AnyOnOrAfter
 	AllOnOrBefore
 	AnyOnOrBefore
+
+	CosineDistance
+	L2Distance
+	InnerProductDistance
 )
 
 // queryOperatorToActionOperator converts the conditional operators that are used
@@ -178,6 +182,21 @@ func expressionOperatorToActionOperator(in string) (out ActionOperator, err erro
 	}
 }
 
+// distanceMetricToActionOperator converts the distance metric of a @nearest
+// attribute to its symbolic constant, machine-readable, ActionOperator value.
+func distanceMetricToActionOperator(in proto.DistanceMetric) (out ActionOperator, err error) {
+	switch in {
+	case proto.DistanceMetric_DISTANCE_METRIC_COSINE:
+		return CosineDistance, nil
+	case proto.DistanceMetric_DISTANCE_METRIC_L2:
+		return L2Distance, nil
+	case proto.DistanceMetric_DISTANCE_METRIC_INNER_PRODUCT:
+		return InnerProductDistance, nil
+	default:
+		return Unknown, fmt.Errorf("this is not a recognized distance metric: %s", in)
+	}
+}
+
 func toSql(o proto.OrderDirection) (string, error) {
 	switch o {
 	case proto.OrderDirection_ORDER_DIRECTION_ASCENDING:
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 	"strconv"
@@ -67,6 +68,12 @@ func Raw(sql string) *QueryOperand {
 	return &QueryOperand{raw: sql}
 }
 
+// Represents an operand computed from other operands by a SQL template, such as lower(%s).
+// Each operand is formatted into the template in the order given.
+func Computed(template string, operands ...*QueryOperand) *QueryOperand {
+	return &QueryOperand{raw: template, operands: operands}
+}
+
 // Represents a value operand.
 func Value(value any) *QueryOperand {
 	return &QueryOperand{value: value}
@@ -85,11 +92,12 @@ func ValueOrNullIfEmpty(value any) *QueryOperand {
 }
 
 type QueryOperand struct {
-	query  *QueryBuilder
-	raw    string
-	table  string
-	column string
-	value  any
+	query    *QueryBuilder
+	raw      string
+	operands []*QueryOperand
+	table    string
+	column   string
+	value    any
 }
 
 // A query builder to be evaluated and injected as an operand.
@@ -171,7 +179,14 @@ func (o *QueryOperand) toSqlOperandString(query *QueryBuilder) string {
 	case o.IsNull():
 		return "NULL"
 	case o.IsRaw():
-		return o.raw
+		if len(o.operands) == 0 {
+			return o.raw
+		}
+		operands := make([]any, len(o.operands))
+		for i, operand := range o.operands {
+			operands[i] = operand.toSqlOperandString(query)
+		}
+		return fmt.Sprintf(o.raw, operands...)
 	case o.IsInlineQuery():
 		return fmt.Sprintf("(%s)", o.query.SelectStatement().SqlTemplate())
 	default:
@@ -192,7 +207,13 @@ func (o *QueryOperand) toSqlArgs() []any {
 		}
 
 		return inValues
-	case o.IsField(), o.IsNull(), o.IsRaw():
+	case o.IsRaw():
+		args := []any{}
+		for _, operand := range o.operands {
+			args = append(args, operand.toSqlArgs()...)
+		}
+		return args
+	case o.IsField(), o.IsNull():
 		return []any{}
 	case o.IsInlineQuery():
 		return o.query.SelectStatement().SqlArgs()
@@ -392,6 +413,38 @@ func (query *QueryBuilder) DistinctOn(operand *QueryOperand) {
 	}
 }
 
+// Represents the distance between a Vector field and a vector value, using one of the distance operators.
+// The vector is written into the SQL as a literal so that the operand can also be used in ORDER BY and
+// DISTINCT ON, as well as in the inline queries of cursor filters.
+func (query *QueryBuilder) Distance(field *QueryOperand, operator ActionOperator, vector []float64) (*QueryOperand, error) {
+	var sqlOperator string
+	switch operator {
+	case CosineDistance:
+		sqlOperator = "<=>"
+	case L2Distance:
+		sqlOperator = "<->"
+	case InnerProductDistance:
+		// pgvector returns the negative inner product so that smaller is nearer, as with the other metrics
+		sqlOperator = "<#>"
+	default:
+		return nil, fmt.Errorf("operator: %v is not a distance operator", operator)
+	}
+
+	if len(vector) == 0 {
+		return nil, errors.New("cannot measure the distance to an empty vector")
+	}
+
+	values := make([]string, len(vector))
+	for i, v := range vector {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errors.New("vector values must be finite numbers")
+		}
+		values[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	return Raw(fmt.Sprintf("(%s %s '[%s]'::vector)", field.toSqlOperandString(query), sqlOperator, strings.Join(values, ","))), nil
+}
+
 // Include a WHERE condition, ANDed to the existing filters (unless an OR has been specified)
 func (query *QueryBuilder) Where(left *QueryOperand, operator ActionOperator, right *QueryOperand) error {
 	template, args, err := query.generateConditionTemplate(left, operator, right)
@@ -701,6 +754,34 @@ func (query *QueryBuilder) SelectStatement() *Statement {
 	}
 }
 
+// Generates an executable SELECT statement which returns at most limit rows for each distinct
+// value of the partition operand, ordered by id. Each row also includes the total number of rows
+// in its partition so that paging information can be derived with ExecuteToPartitions.
+func (query *QueryBuilder) SelectPartitionedStatement(partition *QueryOperand, limit int) *Statement {
+	if len(query.selection) == 0 {
+		query.Select(AllFields())
+	}
+
+	partitionBy := partition.toSqlOperandString(query)
+	query.SelectClause(fmt.Sprintf("ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS %s", partitionBy, IdField().toSqlOperandString(query), partitionRowAlias))
+	query.SelectClause(fmt.Sprintf("COUNT(*) OVER (PARTITION BY %s) AS %s", partitionBy, partitionCountAlias))
+
+	inner := query.SelectStatement()
+
+	sql := fmt.Sprintf("SELECT * FROM (%s) AS %s WHERE %s <= ? ORDER BY %s, %s",
+		inner.template,
+		sqlQuote("partitioned"),
+		partitionRowAlias,
+		sqlQuote(partition.column),
+		sqlQuote(IdField().column))
+
+	return &Statement{
+		template: sql,
+		args:     append(inner.args, limit),
+		model:    query.Model,
+	}
+}
+
 // Generates an executable INSERT statement with the list of arguments.
 func (query *QueryBuilder) InsertStatement(ctx context.Context) *Statement {
 	ctes := []string{}
@@ -732,6 +813,93 @@ func (query *QueryBuilder) InsertStatement(ctx context.Context) *Statement {
 	}
 }
 
+// Generates an executable INSERT statement which inserts many rows of the query's model with a single multi-row INSERT,
+// with the list of arguments. The values cannot include inline queries. Any column which a row does not have a value
+// for is set to its default.
+func (query *QueryBuilder) InsertManyStatement(ctx context.Context, rows []map[string]*QueryOperand) *Statement {
+	args := []any{}
+
+	columns := []string{}
+	for _, row := range rows {
+		for col := range row {
+			if !lo.Contains(columns, col) {
+				columns = append(columns, col)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	// Every row needs at least one column, and so the id is set to its default if there are no values
+	if len(columns) == 0 {
+		columns = append(columns, IdField().column)
+	}
+
+	rowValues := []string{}
+	for _, row := range rows {
+		columnValues := []string{}
+		for _, col := range columns {
+			operand, ok := row[col]
+			if !ok {
+				columnValues = append(columnValues, "DEFAULT")
+				continue
+			}
+
+			columnValues = append(columnValues, operand.toSqlOperandString(query))
+			args = append(args, operand.toSqlArgs()...)
+		}
+		rowValues = append(rowValues, fmt.Sprintf("(%s)", strings.Join(columnValues, ", ")))
+	}
+
+	columnNames := lo.Map(columns, func(col string, _ int) string {
+		return casing.ToSnake(col)
+	})
+
+	alias := fmt.Sprintf("new_%s", query.table)
+
+	selection := []string{"*"}
+	if auth.IsAuthenticated(ctx) {
+		identity, _ := auth.GetIdentity(ctx)
+		selection = append(selection, setIdentityIdClause())
+		args = append(args, identity[parser.FieldNameId].(string))
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		selection = append(selection, setTraceIdClause())
+		args = append(args, spanContext.TraceID().String())
+	}
+
+	statement := fmt.Sprintf("WITH %s AS (INSERT INTO %s (%s) VALUES %s RETURNING *) SELECT %s FROM %s",
+		alias,
+		sqlQuote(query.table),
+		strings.Join(columnNames, ", "),
+		strings.Join(rowValues, ", "),
+		strings.Join(selection, ", "),
+		alias)
+
+	return &Statement{
+		model:    query.Model,
+		template: statement,
+		args:     args,
+	}
+}
+
+// isFlat is true if the row can be inserted on its own, as it has no related rows to insert and no values which
+// are selected from other tables.
+func (row *Row) isFlat() bool {
+	if len(row.references) > 0 || len(row.referencedBy) > 0 {
+		return false
+	}
+
+	for _, operand := range row.values {
+		if operand.IsInlineQuery() {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Recursively generates in common table expression insert query for the write values graph.
 func (query *QueryBuilder) generateInsertCte(ctes []string, args []any, row *Row, foreignKey *proto.Field, primaryKeyTableAlias string) ([]string, []any, string) {
 	alias := fmt.Sprintf("new_%v_%s", makeAlias(query.writeValues, row), casing.ToSnake(row.model.Name))
@@ -1221,6 +1389,11 @@ func (statement *Statement) ExecuteToMany(ctx context.Context, page *Page) (Rows
 		}
 	}
 
+	err = decryptSecretFields(ctx, statement.model, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return toLowerCamelMaps(rows), pageInfo, nil
 }
 
@@ -1240,6 +1413,43 @@ func (statement *Statement) ExecuteToSingle(ctx context.Context) (map[string]any
 	return results[0], nil
 }
 
+// Execute a statement generated by SelectPartitionedStatement and group the rows by the value of partitionField,
+// along with the paging information for each partition.
+func (statement *Statement) ExecuteToPartitions(ctx context.Context, partitionField string) (map[any]Rows, map[any]*PageInfo, error) {
+	results, _, err := statement.ExecuteToMany(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	partitions := map[any]Rows{}
+	pageInfos := map[any]*PageInfo{}
+
+	for _, row := range results {
+		key := row[partitionField]
+		count, _ := row[casing.ToLowerCamel(partitionCountAlias)].(int64)
+
+		delete(row, casing.ToLowerCamel(partitionRowAlias))
+		delete(row, casing.ToLowerCamel(partitionCountAlias))
+
+		pageInfo, ok := pageInfos[key]
+		if !ok {
+			pageInfo = &PageInfo{
+				TotalCount:  int(count),
+				StartCursor: row["id"].(string),
+			}
+			pageInfos[key] = pageInfo
+		}
+
+		pageInfo.Count++
+		pageInfo.EndCursor = row["id"].(string)
+		pageInfo.HasNextPage = pageInfo.Count < pageInfo.TotalCount
+
+		partitions[key] = append(partitions[key], row)
+	}
+
+	return partitions, pageInfos, nil
+}
+
 func ParsePostgresArray[T any](array string, parse func(string) (T, error)) ([]T, error) {
 	out := []T{}
 	var arrayOpened, quoteOpened, escapeOpened bool
@@ -1456,6 +1666,11 @@ func toRuntimeError(err error) error {
 		case db.PgUniqueConstraintViolation:
 			return common.NewUniquenessError(value.Columns)
 		case db.PgForeignKeyConstraintViolation:
+			// A deleted row is still referenced by a foreign key with the restrict or no action
+			// referential action, in which case the table is the one with the foreign key
+			if strings.HasPrefix(value.Message, "update or delete on table") {
+				return common.NewRestrictedDeleteError(value.Table)
+			}
 			return common.NewForeignKeyConstraintError(value.Columns[0])
 		default:
 			return common.RuntimeError{
@@ -1469,8 +1684,10 @@ func toRuntimeError(err error) error {
 }
 
 const (
-	setIdentityIdAlias = "__keel_identity_id"
-	setTraceIdAlias    = "__keel_trace_id"
+	setIdentityIdAlias  = "__keel_identity_id"
+	setTraceIdAlias     = "__keel_trace_id"
+	partitionRowAlias   = "__keel_partition_row"
+	partitionCountAlias = "__keel_partition_count"
 )
 
 func setIdentityIdClause() string {
@@ -3375,6 +3375,245 @@ var testCases = []testCase{
 				ORDER BY "book"."id" ASC LIMIT ?`,
 		expectedArgs: []any{identity["id"].(string), identity["id"].(string), 50},
 	},
+	{
+		name: "list_op_nearest",
+		keelSchema: `
+			model Document {
+				fields {
+					title Text
+					embedding Vector
+				}
+				actions {
+					list searchDocuments(title) {
+						@nearest(embedding: cosine)
+					}
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "searchDocuments",
+		input: map[string]any{
+			"where": map[string]any{
+				"title": map[string]any{
+					"equals": "Report",
+				},
+			},
+			"nearest": map[string]any{
+				"to": []any{0.5, 1.0, -2.25},
+			},
+		},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON(("document"."embedding" <=> '[0.5,1,-2.25]'::vector), "document"."id") "document".*,
+				CASE WHEN LEAD("document"."id") OVER (ORDER BY ("document"."embedding" <=> '[0.5,1,-2.25]'::vector) ASC, "document"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT (("document"."embedding" <=> '[0.5,1,-2.25]'::vector), "document"."id")) FROM "document" WHERE "document"."title" IS NOT DISTINCT FROM ?) AS totalCount
+			FROM
+				"document"
+			WHERE
+				"document"."title" IS NOT DISTINCT FROM ?
+			ORDER BY
+				("document"."embedding" <=> '[0.5,1,-2.25]'::vector) ASC,
+				"document"."id" ASC
+			LIMIT ?`,
+		expectedArgs: []any{"Report", "Report", 50},
+	},
+	{
+		name: "list_op_nearest_max_distance",
+		keelSchema: `
+			model Document {
+				fields {
+					embedding Vector
+				}
+				actions {
+					list searchDocuments() {
+						@nearest(embedding: l2)
+					}
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "searchDocuments",
+		input: map[string]any{
+			"nearest": map[string]any{
+				"to":          []any{1.0, 2.0},
+				"maxDistance": 0.75,
+			},
+		},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON(("document"."embedding" <-> '[1,2]'::vector), "document"."id") "document".*,
+				CASE WHEN LEAD("document"."id") OVER (ORDER BY ("document"."embedding" <-> '[1,2]'::vector) ASC, "document"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT (("document"."embedding" <-> '[1,2]'::vector), "document"."id")) FROM "document" WHERE ("document"."embedding" <-> '[1,2]'::vector) <= ?) AS totalCount
+			FROM
+				"document"
+			WHERE
+				("document"."embedding" <-> '[1,2]'::vector) <= ?
+			ORDER BY
+				("document"."embedding" <-> '[1,2]'::vector) ASC,
+				"document"."id" ASC
+			LIMIT ?`,
+		expectedArgs: []any{0.75, 0.75, 50},
+	},
+	{
+		name: "list_op_expression_functions",
+		keelSchema: `
+			model Thing {
+				fields {
+					title Text
+				}
+				actions {
+					list listThings(search: Text) {
+						@where(lower(thing.title) == lower(trim(search)))
+					}
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listThings",
+		input: map[string]any{
+			"where": map[string]any{
+				"search": "  Some Title ",
+			},
+		},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON("thing"."id") "thing".*, CASE WHEN LEAD("thing"."id") OVER (ORDER BY "thing"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT "thing"."id") FROM "thing" WHERE lower("thing"."title") IS NOT DISTINCT FROM ?) AS totalCount
+			FROM
+				"thing"
+			WHERE
+				lower("thing"."title") IS NOT DISTINCT FROM ?
+			ORDER BY
+				"thing"."id" ASC LIMIT ?`,
+		expectedArgs: []any{"some title", "some title", 50},
+	},
+	{
+		// As with Postgres, trim only removes spaces
+		name: "list_op_expression_trim_spaces_only",
+		keelSchema: `
+			model Thing {
+				fields {
+					title Text
+				}
+				actions {
+					list listThings(search: Text) {
+						@where(thing.title == trim(search))
+					}
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listThings",
+		input: map[string]any{
+			"where": map[string]any{
+				"search": " \tSome Title\n ",
+			},
+		},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON("thing"."id") "thing".*, CASE WHEN LEAD("thing"."id") OVER (ORDER BY "thing"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT "thing"."id") FROM "thing" WHERE "thing"."title" IS NOT DISTINCT FROM ?) AS totalCount
+			FROM
+				"thing"
+			WHERE
+				"thing"."title" IS NOT DISTINCT FROM ?
+			ORDER BY
+				"thing"."id" ASC LIMIT ?`,
+		expectedArgs: []any{"\tSome Title\n", "\tSome Title\n", 50},
+	},
+	{
+		name: "list_op_expression_arithmetic",
+		keelSchema: `
+			model Item {
+				fields {
+					price Decimal
+					quantity Number
+					category Category
+				}
+				actions {
+					list listExpensiveItems() {
+						@where(item.price * item.quantity + item.category.fee > 100)
+					}
+				}
+				@permission(expression: true, actions: [list])
+			}
+			model Category {
+				fields {
+					fee Decimal
+				}
+			}`,
+		actionName: "listExpensiveItems",
+		input:      map[string]any{},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON("item"."id") "item".*, CASE WHEN LEAD("item"."id") OVER (ORDER BY "item"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT "item"."id") FROM "item" LEFT JOIN "category" AS "item$category" ON "item$category"."id" = "item"."category_id" WHERE (("item"."price" * "item"."quantity") + "item$category"."fee") > ?) AS totalCount
+			FROM
+				"item"
+			LEFT JOIN
+				"category" AS "item$category" ON "item$category"."id" = "item"."category_id"
+			WHERE
+				(("item"."price" * "item"."quantity") + "item$category"."fee") > ?
+			ORDER BY
+				"item"."id" ASC LIMIT ?`,
+		expectedArgs: []any{int64(100), int64(100), 50},
+	},
+	{
+		name: "list_op_expression_date_arithmetic",
+		keelSchema: `
+			model Invoice {
+				fields {
+					issuedOn Date
+					paidOn Date
+				}
+				actions {
+					list listLatePaidInvoices() {
+						@where(invoice.issuedOn + 30 days < invoice.paidOn)
+					}
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listLatePaidInvoices",
+		input:      map[string]any{},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON("invoice"."id") "invoice".*, CASE WHEN LEAD("invoice"."id") OVER (ORDER BY "invoice"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT "invoice"."id") FROM "invoice" WHERE ("invoice"."issued_on" + INTERVAL '30 days')::DATE < "invoice"."paid_on") AS totalCount
+			FROM
+				"invoice"
+			WHERE
+				("invoice"."issued_on" + INTERVAL '30 days')::DATE < "invoice"."paid_on"
+			ORDER BY
+				"invoice"."id" ASC LIMIT ?`,
+		expectedArgs: []any{50},
+	},
+	{
+		name: "update_op_set_attribute_arithmetic",
+		keelSchema: `
+			model Product {
+				fields {
+					stock Number
+				}
+				actions {
+					update removeStock(id) with (quantity: Number) {
+						@set(product.stock = product.stock - quantity)
+					}
+				}
+				@permission(expression: true, actions: [update])
+			}`,
+		actionName: "removeStock",
+		input: map[string]any{
+			"where": map[string]any{
+				"id": "xyz",
+			},
+			"values": map[string]any{
+				"quantity": 5,
+			},
+		},
+		expectedTemplate: `
+			UPDATE "product"
+			SET
+				stock = ("product"."stock" - ?::INTEGER)
+			WHERE "product"."id" IS NOT DISTINCT FROM ?
+			RETURNING "product".*`,
+		expectedArgs: []any{5, "xyz"},
+	},
 }
 
 func TestQueryBuilder(t *testing.T) {
@@ -3478,6 +3717,34 @@ func TestInsertStatement(t *testing.T) {
 	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
 }
 
+func TestInsertManyStatement(t *testing.T) {
+	model := &proto.Model{Name: "Person"}
+	query := actions.NewQuery(model)
+	stmt := query.InsertManyStatement(context.Background(), []map[string]*actions.QueryOperand{
+		{"name": actions.Value("Fred"), "age": actions.Value(30)},
+		{"name": actions.Value("Jane")},
+	})
+
+	expected := `
+		WITH new_person AS (INSERT INTO "person" (age, name) VALUES (?, ?), (DEFAULT, ?) RETURNING *)
+		SELECT * FROM new_person`
+
+	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
+	require.Equal(t, []any{30, "Fred", "Jane"}, stmt.SqlArgs())
+}
+
+func TestInsertManyStatementNoValues(t *testing.T) {
+	model := &proto.Model{Name: "Person"}
+	query := actions.NewQuery(model)
+	stmt := query.InsertManyStatement(context.Background(), []map[string]*actions.QueryOperand{{}, {}})
+
+	expected := `
+		WITH new_person AS (INSERT INTO "person" (id) VALUES (DEFAULT), (DEFAULT) RETURNING *)
+		SELECT * FROM new_person`
+
+	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
+}
+
 func TestUpdateStatement(t *testing.T) {
 	model := &proto.Model{Name: "Person"}
 	query := actions.NewQuery(model)
@@ -3509,6 +3776,28 @@ func TestDeleteStatement(t *testing.T) {
 	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
 }
 
+func TestSelectPartitionedStatement(t *testing.T) {
+	model := &proto.Model{Name: "Post"}
+	query := actions.NewQuery(model)
+	err := query.Where(actions.Field("authorId"), actions.OneOf, actions.Value([]any{"1", "2"}))
+	require.NoError(t, err)
+	stmt := query.SelectPartitionedStatement(actions.Field("authorId"), 10)
+
+	expected := `
+		SELECT * FROM (
+			SELECT "post".*,
+				ROW_NUMBER() OVER (PARTITION BY "post"."author_id" ORDER BY "post"."id") AS __keel_partition_row,
+				COUNT(*) OVER (PARTITION BY "post"."author_id") AS __keel_partition_count
+			FROM "post"
+			WHERE "post"."author_id" = ANY(ARRAY[?, ?]::TEXT[])
+		) AS "partitioned"
+		WHERE __keel_partition_row <= ?
+		ORDER BY "author_id", "id"`
+
+	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
+	require.Equal(t, []any{"1", "2", 10}, stmt.SqlArgs())
+}
+
 func TestInsertStatementWithAuditing(t *testing.T) {
 	ctx := context.Background()
 	ctx = withIdentity(ctx)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/teamkeel/keel/db"
 	"github.com/teamkeel/keel/events"
 	"github.com/teamkeel/keel/functions"
 	"github.com/teamkeel/keel/proto"
@@ -124,7 +125,11 @@ func Execute(scope *Scope, input any) (result any, meta *common.ResponseMetadata
 				return nil, nil, fmt.Errorf("input %v is not in correct format", input)
 			}
 		}
-		result, err = executeAutoAction(scope, inputsAsMap)
+		if len(scope.Action.Emits) > 0 {
+			result, err = executeAutoActionAndEmit(scope, inputsAsMap)
+		} else {
+			result, err = executeAutoAction(scope, inputsAsMap)
+		}
 	default:
 		return nil, nil, fmt.Errorf("unhandled unknown action %s of type %s", scope.Action.Name, scope.Action.Implementation)
 	}
@@ -221,6 +226,28 @@ func executeRuntimeAction(scope *Scope, inputs map[string]any) (any, error) {
 	}
 }
 
+// executeAutoActionAndEmit executes the action and emits its events within the same transaction, so that the
+// events are only recorded if the action's write is committed and the write is rolled back if they can't be.
+func executeAutoActionAndEmit(scope *Scope, inputs map[string]any) (result any, err error) {
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		result, err = executeAutoAction(scope, inputs)
+		if err != nil {
+			return err
+		}
+
+		return emitEvents(scope, result)
+	})
+
+	return result, err
+}
+
 func executeAutoAction(scope *Scope, inputs map[string]any) (any, error) {
 	switch scope.Action.Type {
 	case proto.ActionType_ACTION_TYPE_GET:
@@ -242,6 +269,15 @@ func executeAutoAction(scope *Scope, inputs map[string]any) (any, error) {
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		result, err := Delete(scope, inputs)
 		return result, err
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY:
+		result, err := CreateMany(scope, inputs)
+		return result, err
+	case proto.ActionType_ACTION_TYPE_UPDATE_MANY:
+		result, err := UpdateMany(scope, inputs)
+		return result, err
+	case proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		result, err := DeleteMany(scope, inputs)
+		return result, err
 	case proto.ActionType_ACTION_TYPE_LIST:
 		result, err := List(scope, inputs)
 		return result, err
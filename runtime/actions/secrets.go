@@ -0,0 +1,60 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iancoleman/strcase"
+	"github.com/teamkeel/keel/encryption"
+	"github.com/teamkeel/keel/proto"
+)
+
+var errNoEncryptionKey = errors.New("an encryption key is required to write Secret fields, set with the ENCRYPTION_KEY secret or the KEEL_ENCRYPTION_KEY environment variable")
+
+// encryptSecretValue encrypts the value being written to the field if it is a Secret field. Other values
+// are returned unchanged.
+func encryptSecretValue(ctx context.Context, field *proto.Field, value any) (any, error) {
+	if field == nil || field.Type.Type != proto.Type_TYPE_SECRET {
+		return value, nil
+	}
+
+	plaintext, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	keyring := encryption.GetKeyring(ctx)
+	if keyring == nil {
+		return nil, errNoEncryptionKey
+	}
+
+	return keyring.Encrypt(plaintext)
+}
+
+// decryptSecretFields decrypts the values of the model's Secret fields in rows read from the database.
+func decryptSecretFields(ctx context.Context, model *proto.Model, rows []map[string]any) error {
+	for _, f := range model.SecretFields() {
+		col := strcase.ToSnake(f.Name)
+		for _, row := range rows {
+			value, ok := row[col].(string)
+			if !ok || !encryption.IsEncrypted(value) {
+				continue
+			}
+
+			keyring := encryption.GetKeyring(ctx)
+			if keyring == nil {
+				return fmt.Errorf("an encryption key is required to read the Secret field %s", f.Name)
+			}
+
+			plaintext, err := keyring.Decrypt(value)
+			if err != nil {
+				return fmt.Errorf("decrypting Secret field %s: %w", f.Name, err)
+			}
+
+			row[col] = plaintext
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+)
+
+// UpdateMany updates all the records which match the filters in the input within a single transaction,
+// and returns the ids of the records updated.
+func UpdateMany(scope *Scope, input map[string]any) (res map[string]any, err error) {
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+
+	// Attempt to resolve permissions early; i.e. before row-based database querying.
+	canResolveEarly, authorised, err := TryResolveAuthorisationEarly(scope, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if canResolveEarly && !authorised {
+		return nil, common.NewPermissionError()
+	}
+
+	where, ok := input["where"].(map[string]any)
+	if !ok {
+		where = map[string]any{}
+	}
+
+	values, ok := input["values"].(map[string]any)
+	if !ok {
+		values = map[string]any{}
+	}
+
+	ids := []string{}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		ids, err = findBulkActionIds(scope, where)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		// Every record must satisfy the permissions before it is updated, otherwise none are updated
+		if !canResolveEarly {
+			isAuthorised, err := AuthoriseAction(scope, input, idRows(ids))
+			if err != nil {
+				return err
+			}
+
+			if !isAuthorised {
+				return common.NewPermissionError()
+			}
+		}
+
+		// Files are only stored once the records are known to be updated
+		if scope.Model.HasFiles() {
+			// handle file uploads and change input values to file data if applicable
+			values, err = handleFileUploads(scope, values)
+			if err != nil {
+				return fmt.Errorf("handling file uploads: %w", err)
+			}
+		}
+
+		// Generate the SQL statement
+		query := NewQuery(scope.Model)
+		statement, err := GenerateUpdateManyStatement(query, scope, values, ids)
+		if err != nil {
+			return err
+		}
+
+		rows, _, err := statement.ExecuteToMany(scope.Context, nil)
+		if err != nil {
+			return err
+		}
+
+		ids = lo.Map(rows, func(row map[string]any, _ int) string {
+			id, _ := row["id"].(string)
+			return id
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bulkActionResult(ids), nil
+}
+
+// GenerateUpdateManyStatement generates the statement which updates the records with the given ids using the values of an updateMany action.
+func GenerateUpdateManyStatement(query *QueryBuilder, scope *Scope, values map[string]any, ids []string) (*Statement, error) {
+	err := query.captureWriteValues(scope, values)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.captureSetValues(scope, values)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Where(IdField(), OneOf, Value(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the ids of the updated rows
+	query.AppendReturning(IdField())
+
+	return query.UpdateStatement(scope.Context), nil
+}
+
+// findBulkActionIds returns the ids of the records which match the filters of an updateMany or deleteMany action.
+func findBulkActionIds(scope *Scope, where map[string]any) ([]string, error) {
+	query := NewQuery(scope.Model)
+
+	err := query.applyImplicitFiltersForList(scope, where)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.applyExpressionFilters(scope, where)
+	if err != nil {
+		return nil, err
+	}
+
+	query.Select(IdField())
+	query.DistinctOn(IdField())
+
+	rows, _, err := query.SelectStatement().ExecuteToMany(scope.Context, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(rows, func(row map[string]any, _ int) string {
+		id, _ := row["id"].(string)
+		return id
+	}), nil
+}
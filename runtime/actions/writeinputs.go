@@ -98,7 +98,20 @@ func (query *QueryBuilder) captureSetValues(scope *Scope, args map[string]any) e
 
 		// Set the field on all rows.
 		for _, row := range currRows {
-			if rhsResolver.IsModelDbColumn() {
+			if rhsResolver.IsComputed() {
+				// Computed values can only reference the fields of the model being written to
+				computedQuery := NewQuery(scope.Model)
+				operand, err := computedQuery.computedQueryOperand(scope, rhsResolver, args)
+				if err != nil {
+					return err
+				}
+
+				if len(computedQuery.joins) > 0 || (rhsResolver.IsComputedFromDatabase() && proto.SingleActionType(scope.Action.Type) != proto.ActionType_ACTION_TYPE_UPDATE) {
+					return fmt.Errorf("set expression %s can only compute values from the existing fields of the model when updating", setExpression.Source)
+				}
+
+				row.values[field] = operand
+			} else if rhsResolver.IsModelDbColumn() {
 				rhsFragments, err := rhsResolver.NormalisedFragments()
 				if err != nil {
 					return err
@@ -134,6 +147,11 @@ func (query *QueryBuilder) captureSetValues(scope *Scope, args map[string]any) e
 					return err
 				}
 
+				value, err = encryptSecretValue(scope.Context, proto.FindField(scope.Schema.Models, row.model.Name, field), value)
+				if err != nil {
+					return err
+				}
+
 				row.values[field] = Value(value)
 			}
 		}
@@ -332,6 +350,10 @@ func (query *QueryBuilder) captureWriteValuesFromMessage(scope *Scope, message *
 			value, ok := args[input.Name]
 			// Only add the arg value if it was provided as an input.
 			if ok {
+				value, err := encryptSecretValue(scope.Context, field, value)
+				if err != nil {
+					return nil, nil, err
+				}
 				newRow.values[input.Name] = Value(value)
 			}
 		}
@@ -0,0 +1,153 @@
+package authapi
+
+import (
+	"net/http"
+
+	email "net/mail"
+
+	"github.com/teamkeel/keel/mail"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+const (
+	EmailVerificationPath        = "/auth/verify-email"
+	EmailVerificationConfirmPath = "/auth/verify-email/confirm"
+)
+
+// EmailVerificationHandler emails a link to verify the email address of an identity which signs in with a password.
+// The link is to the given redirect URL with the token in the query, which the app then confirms. The response is
+// the same whether or not an unverified identity exists with the email address.
+func EmailVerificationHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Email Verification")
+		defer span.End()
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the email verification endpoint only accepts POST", nil)
+		}
+
+		inputs, resp := requestInputs(r.WithContext(ctx))
+		if inputs == nil {
+			return resp
+		}
+
+		emailAddress, hasEmail := inputs[ArgEmail].(string)
+		if !hasEmail || emailAddress == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the email address to verify in the 'email' field is required", nil)
+		}
+
+		if _, err := email.ParseAddress(emailAddress); err != nil {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "invalid email address", nil)
+		}
+
+		redirect, hasRedirect := inputs[ArgRedirectUrl].(string)
+		if !hasRedirect || redirect == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the URL to link to in the 'redirect_url' field is required", nil)
+		}
+
+		verifyUrl, resp := appLinkUrl(ctx, redirect)
+		if verifyUrl == nil {
+			return resp
+		}
+
+		identity, err := actions.FindIdentityByEmail(ctx, schema, emailAddress, oauth.KeelIssuer)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if identity == nil {
+			return common.NewJsonResponse(http.StatusOK, nil, nil)
+		}
+
+		if verified, _ := identity[parser.IdentityFieldNameEmailVerified].(bool); verified {
+			return common.NewJsonResponse(http.StatusOK, nil, nil)
+		}
+
+		token, err := oauth.NewEmailVerificationToken(ctx, identity[parser.FieldNameId].(string), emailAddress)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		q := verifyUrl.Query()
+		q.Set(ArgToken, token)
+		verifyUrl.RawQuery = q.Encode()
+
+		client, err := runtimectx.GetMailClient(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		message, err := runtimectx.GetMailTemplates(ctx).Render(mail.TemplateEmailVerification, &mail.EmailVerificationData{
+			Identity:  actions.TemplateIdentity(identity),
+			VerifyUrl: verifyUrl.String(),
+		})
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		err = client.Send(ctx, &mail.SendEmailRequest{
+			To:        emailAddress,
+			Subject:   message.Subject,
+			PlainText: message.PlainText,
+			HTML:      message.HTML,
+		})
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
+
+// EmailVerificationConfirmHandler consumes the token sent by email and marks the identity's email address as verified.
+func EmailVerificationConfirmHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Email Verification Confirm")
+		defer span.End()
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the email verification confirm endpoint only accepts POST", nil)
+		}
+
+		inputs, resp := requestInputs(r.WithContext(ctx))
+		if inputs == nil {
+			return resp
+		}
+
+		token, hasToken := inputs[ArgToken].(string)
+		if !hasToken || token == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the token from the verification link in the 'token' field is required", nil)
+		}
+
+		isValid, identityId, emailAddress, err := oauth.ConsumeEmailVerificationToken(ctx, token)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if !isValid {
+			return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the token has already been used or has expired", nil)
+		}
+
+		identity, err := actions.FindIdentityById(ctx, schema, identityId)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		// The identity's email address may have changed since the token was sent
+		if identity == nil || identity[parser.IdentityFieldNameEmail] != emailAddress {
+			return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the email address the token was sent to is no longer the identity's email address", nil)
+		}
+
+		_, err = actions.VerifyIdentityEmail(ctx, schema, identityId)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
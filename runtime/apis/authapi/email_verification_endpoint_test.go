@@ -0,0 +1,191 @@
+package authapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+var verifyUrlRegex = regexp.MustCompile(`https://myapp.com/verify\S*`)
+
+// sentVerificationToken extracts the token from the link in the last email sent.
+func (c *capturingMailClient) sentVerificationToken(t *testing.T) string {
+	link, err := url.Parse(verifyUrlRegex.FindString(c.sent[len(c.sent)-1].PlainText))
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestEmailVerification_RequiredToSignIn(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	redirectUrl := "https://myapp.com/callback"
+	requireEmailVerification := true
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl:              &redirectUrl,
+		RequireEmailVerification: &requireEmailVerification,
+	})
+
+	// The identity is created, but cannot sign in until verified
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrEmailNotVerified, errorResponse.Error)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+	require.Equal(t, false, identities[0]["email_verified"])
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationRequest(ctx, "user@example.com", "https://myapp.com/verify"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, client.sent, 1)
+	require.Equal(t, "user@example.com", client.sent[0].To)
+
+	token := client.sentVerificationToken(t)
+	require.NotEmpty(t, token)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationConfirmRequest(ctx, token))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Equal(t, true, identities[0]["email_verified"])
+
+	// The token cannot be used again
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationConfirmRequest(ctx, token))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidClient, errorResponse.Error)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+
+	// No email is sent once verified
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationRequest(ctx, "user@example.com", "https://myapp.com/verify"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, client.sent, 1)
+}
+
+func TestEmailVerification_NotRequiredByDefault(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+}
+
+func TestEmailVerification_UnknownEmail(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	redirectUrl := "https://myapp.com/callback"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl: &redirectUrl,
+	})
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationRequest(ctx, "user@example.com", "https://myapp.com/verify"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, client.sent, 0)
+}
+
+func TestEmailVerification_EmailChangedSinceSent(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	redirectUrl := "https://myapp.com/callback"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl: &redirectUrl,
+	})
+
+	_, _, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	_, _, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationRequest(ctx, "user@example.com", "https://myapp.com/verify"))
+	require.NoError(t, err)
+
+	err = database.GetDB().Exec("UPDATE identity SET email = ?", "other@example.com").Error
+	require.NoError(t, err)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationConfirmRequest(ctx, client.sentVerificationToken(t)))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Equal(t, false, identities[0]["email_verified"])
+}
+
+func TestEmailVerification_RedirectUrlOrigin(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	redirectUrl := "https://myapp.com/callback"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl: &redirectUrl,
+	})
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationRequest(ctx, "user@example.com", "https://attacker.com/verify"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the redirect URL must have the same origin as the auth redirectUrl", errorResponse.ErrorDescription)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeEmailVerificationRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the URL to link to in the 'redirect_url' field is required", errorResponse.ErrorDescription)
+}
+
+func makeEmailVerificationRequest(ctx context.Context, email string, redirectUrl string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so"+authapi.EmailVerificationPath, nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("email", email)
+	if redirectUrl != "" {
+		form.Add("redirect_url", redirectUrl)
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
+
+func makeEmailVerificationConfirmRequest(ctx context.Context, token string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so"+authapi.EmailVerificationConfirmPath, nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("token", token)
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
@@ -0,0 +1,85 @@
+package authapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+const (
+	JwksPath                = "/auth/.well-known/jwks.json"
+	OpenIdConfigurationPath = "/auth/.well-known/openid-configuration"
+)
+
+// Clients may cache the keys, and so a new key should be added after the signing key, and only
+// moved to the start to sign tokens once clients have had time to fetch it
+const wellKnownCacheControl = "public, max-age=300"
+
+// OpenIdConfiguration is the discovery document which describes the endpoints and keys of the server.
+// Keel is not an OpenID provider, as it issues access tokens and not ID tokens, and so only the metadata
+// which allows clients to obtain and verify access tokens is published.
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type OpenIdConfiguration struct {
+	Issuer                string   `json:"issuer"`
+	JwksUri               string   `json:"jwks_uri"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	RevocationEndpoint    string   `json:"revocation_endpoint"`
+	SubjectTypesSupported []string `json:"subject_types_supported"`
+	GrantTypesSupported   []string `json:"grant_types_supported"`
+}
+
+// JwksHandler publishes the public keys which verify the access tokens issued by Keel,
+// including any previous keys which are still accepted during a key rotation.
+func JwksHandler() common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "JWKS")
+		defer span.End()
+
+		privateKeys, err := runtimectx.GetPrivateKeys(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if len(privateKeys) == 0 {
+			return common.InternalServerErrorResponse(ctx, errors.New("no private key set"))
+		}
+
+		return common.NewJsonResponse(http.StatusOK, oauth.NewJSONWebKeySet(privateKeys), &common.ResponseMetadata{
+			Headers: http.Header{"Cache-Control": {wellKnownCacheControl}},
+		})
+	}
+}
+
+// OpenIdConfigurationHandler serves the OpenID discovery document.
+func OpenIdConfigurationHandler() common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "OpenID Configuration")
+		defer span.End()
+
+		apiUrl, err := url.ParseRequestURI(os.Getenv("KEEL_API_URL"))
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		configuration := OpenIdConfiguration{
+			// The issuer is the iss claim of the access tokens, which is the same for every Keel app, and so it
+			// does not match the URL this document is served from. Clients which verify that the issuer matches
+			// the discovery URL must instead be configured with the issuer and the JWKS URI.
+			Issuer:                oauth.KeelIssuer,
+			JwksUri:               apiUrl.JoinPath(JwksPath).String(),
+			TokenEndpoint:         apiUrl.JoinPath("/auth/token").String(),
+			RevocationEndpoint:    apiUrl.JoinPath("/auth/revoke").String(),
+			SubjectTypesSupported: []string{"public"},
+			GrantTypesSupported:   SupportedGrantTypes,
+		}
+
+		return common.NewJsonResponse(http.StatusOK, configuration, &common.ResponseMetadata{
+			Headers: http.Header{"Cache-Control": {wellKnownCacheControl}},
+		})
+	}
+}
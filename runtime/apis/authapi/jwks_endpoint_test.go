@@ -0,0 +1,66 @@
+package authapi_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+func TestJwks(t *testing.T) {
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	previousKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ctx := runtimectx.WithPrivateKeys(context.Background(), []*rsa.PrivateKey{signingKey, previousKey})
+	request := httptest.NewRequest(http.MethodGet, authapi.JwksPath, nil).WithContext(ctx)
+
+	response := authapi.JwksHandler()(request)
+	require.Equal(t, http.StatusOK, response.Status)
+
+	var jwks oauth.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(response.Body, &jwks))
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, oauth.KeyId(&signingKey.PublicKey), jwks.Keys[0].KeyId)
+	require.Equal(t, oauth.KeyId(&previousKey.PublicKey), jwks.Keys[1].KeyId)
+}
+
+func TestJwksNoPrivateKey(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, authapi.JwksPath, nil)
+
+	response := authapi.JwksHandler()(request)
+	require.Equal(t, http.StatusInternalServerError, response.Status)
+}
+
+func TestOpenIdConfiguration(t *testing.T) {
+	t.Setenv("KEEL_API_URL", "https://api.example.com")
+
+	request := httptest.NewRequest(http.MethodGet, authapi.OpenIdConfigurationPath, nil)
+
+	response := authapi.OpenIdConfigurationHandler()(request)
+	require.Equal(t, http.StatusOK, response.Status)
+
+	var configuration authapi.OpenIdConfiguration
+	require.NoError(t, json.Unmarshal(response.Body, &configuration))
+	require.Equal(t, oauth.KeelIssuer, configuration.Issuer)
+	require.Equal(t, "https://api.example.com/auth/.well-known/jwks.json", configuration.JwksUri)
+	require.Equal(t, "https://api.example.com/auth/token", configuration.TokenEndpoint)
+	require.Equal(t, "https://api.example.com/auth/revoke", configuration.RevocationEndpoint)
+	require.Equal(t, []string{"refresh_token", "token_exchange", "authorization_code", "password", "mfa_otp", "passwordless"}, configuration.GrantTypesSupported)
+
+	// Keel does not implement the authorization code flow or issue ID tokens
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(response.Body, &metadata))
+	require.NotContains(t, metadata, "authorization_endpoint")
+	require.NotContains(t, metadata, "response_types_supported")
+	require.NotContains(t, metadata, "id_token_signing_alg_values_supported")
+}
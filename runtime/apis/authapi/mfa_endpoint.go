@@ -0,0 +1,184 @@
+package authapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+const (
+	MfaEnrolPath   = "/auth/mfa/enrol"
+	MfaConfirmPath = "/auth/mfa/confirm"
+	MfaDisablePath = "/auth/mfa/disable"
+)
+
+// The issuer shown in authenticator apps if KEEL_API_URL is not set.
+const defaultMfaIssuer = "Keel"
+
+type MfaEnrolmentResponse struct {
+	Secret        string   `json:"secret"`
+	OtpauthUri    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MfaEnrolHandler generates a TOTP secret and recovery codes for the authenticated identity.
+// MFA is only enabled once the enrolment has been confirmed with a code from the authenticator.
+func MfaEnrolHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "MFA Enrol")
+		defer span.End()
+
+		r = r.WithContext(ctx)
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the MFA enrol endpoint only accepts POST", nil)
+		}
+
+		identity, resp := passwordIdentity(r, schema)
+		if identity == nil {
+			return resp
+		}
+
+		enrolment, err := oauth.EnrolMfa(ctx, identity[parser.FieldNameId].(string))
+		if errors.Is(err, oauth.ErrMfaAlreadyEnabled) {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "MFA is already enabled for this identity and must be disabled before enrolling again", nil)
+		}
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		issuer := defaultMfaIssuer
+		if apiUrl, err := url.ParseRequestURI(os.Getenv("KEEL_API_URL")); err == nil && apiUrl.Hostname() != "" {
+			issuer = apiUrl.Hostname()
+		}
+
+		email, _ := identity[parser.IdentityFieldNameEmail].(string)
+
+		return common.NewJsonResponse(http.StatusOK, &MfaEnrolmentResponse{
+			Secret:        enrolment.Secret,
+			OtpauthUri:    oauth.TotpUri(issuer, email, enrolment.Secret),
+			RecoveryCodes: enrolment.RecoveryCodes,
+		}, nil)
+	}
+}
+
+// MfaConfirmHandler enables MFA for the authenticated identity once they provide a valid code from their authenticator.
+func MfaConfirmHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "MFA Confirm")
+		defer span.End()
+
+		r = r.WithContext(ctx)
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the MFA confirm endpoint only accepts POST", nil)
+		}
+
+		identity, resp := passwordIdentity(r, schema)
+		if identity == nil {
+			return resp
+		}
+
+		code, resp := mfaCode(r)
+		if code == "" {
+			return resp
+		}
+
+		isValid, err := oauth.ConfirmMfa(ctx, identity[parser.FieldNameId].(string), code)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if !isValid {
+			return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the code is incorrect or has already been used, or there is no pending enrolment", nil)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
+
+// MfaDisableHandler disables MFA for the authenticated identity once they provide a valid code or recovery code.
+func MfaDisableHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "MFA Disable")
+		defer span.End()
+
+		r = r.WithContext(ctx)
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the MFA disable endpoint only accepts POST", nil)
+		}
+
+		identity, resp := passwordIdentity(r, schema)
+		if identity == nil {
+			return resp
+		}
+
+		code, resp := mfaCode(r)
+		if code == "" {
+			return resp
+		}
+
+		isValid, err := oauth.DisableMfa(ctx, identity[parser.FieldNameId].(string), code)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if !isValid {
+			return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the code is incorrect or has already been used, or MFA is not enabled", nil)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
+
+// passwordIdentity authenticates the request with the bearer token, and checks that
+// the identity authenticates with a password as MFA only applies to the password grant.
+func passwordIdentity(r *http.Request, schema *proto.Schema) (auth.Identity, common.Response) {
+	ctx := r.Context()
+
+	identity, err := actions.HandleAuthorizationHeader(ctx, schema, r.Header)
+	if err != nil || identity == nil {
+		return nil, jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "a valid access token is required in the Authorization header", err)
+	}
+
+	if issuer, _ := identity[parser.IdentityFieldNameIssuer].(string); issuer != oauth.KeelIssuer {
+		return nil, jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "MFA is only supported for identities which authenticate with a password", nil)
+	}
+
+	return identity, common.Response{}
+}
+
+// mfaCode parses the code from the request body.
+func mfaCode(r *http.Request) (string, common.Response) {
+	ctx := r.Context()
+
+	if !common.HasContentType(r.Header, "application/x-www-form-urlencoded") && !common.HasContentType(r.Header, "application/json") {
+		return "", jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the request body must either be an encoded form (Content-Type: application/x-www-form-urlencoded) or JSON (Content-Type: application/json)", nil)
+	}
+
+	data, err := common.ParseRequestData(r)
+	if err != nil {
+		return "", jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+	}
+
+	inputs, ok := data.(map[string]any)
+	if !ok {
+		return "", jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", nil)
+	}
+
+	code, hasCode := inputs[ArgCode].(string)
+	if !hasCode || code == "" {
+		return "", jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the code from the authenticator in the 'code' field is required", nil)
+	}
+
+	return code, common.Response{}
+}
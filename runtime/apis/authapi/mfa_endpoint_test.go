@@ -0,0 +1,202 @@
+package authapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+func TestMfa_EnrolConfirmAndChallenge(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	tokens, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// Enrol
+	enrolment, httpResponse, err := handleRuntimeRequest[authapi.MfaEnrolmentResponse](schema, makeMfaRequest(ctx, authapi.MfaEnrolPath, tokens.AccessToken, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, enrolment.Secret)
+	require.Contains(t, enrolment.OtpauthUri, "otpauth://totp/")
+	require.Contains(t, enrolment.OtpauthUri, "secret="+enrolment.Secret)
+	require.Len(t, enrolment.RecoveryCodes, 10)
+
+	// MFA is not required until the enrolment is confirmed
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// Confirm with an incorrect code
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, authapi.MfaConfirmPath, tokens.AccessToken, "000000"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidClient, errorResponse.Error)
+
+	// Confirm with the code from the previous period, which is still accepted to allow for clock drift
+	code, err := oauth.TotpCode(enrolment.Secret, time.Now().Add(-30*time.Second))
+	require.NoError(t, err)
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, authapi.MfaConfirmPath, tokens.AccessToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// Enrolling again is not possible once enabled
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, authapi.MfaEnrolPath, tokens.AccessToken, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidRequest, errorResponse.Error)
+
+	// The password grant now returns an MFA challenge
+	challenge, httpResponse, err := handleRuntimeRequest[authapi.MfaChallengeResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrMfaRequired, challenge.Error)
+	require.NotEmpty(t, challenge.MfaToken)
+
+	code, err = oauth.TotpCode(enrolment.Secret, time.Now())
+	require.NoError(t, err)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+	require.NotEmpty(t, response.RefreshToken)
+	require.False(t, response.Created)
+
+	sub, err := oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+
+	tokenSub, err := oauth.ValidateAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, tokenSub, sub)
+
+	// The challenge cannot be used again
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	// Nor can the same code be used with a new challenge
+	challenge, _, err = handleRuntimeRequest[authapi.MfaChallengeResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	// A recovery code can be used instead, but only once
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, enrolment.RecoveryCodes[0]))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	challenge, _, err = handleRuntimeRequest[authapi.MfaChallengeResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, enrolment.RecoveryCodes[0]))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	// Disable
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, authapi.MfaDisablePath, tokens.AccessToken, enrolment.RecoveryCodes[1]))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestMfa_ChallengeAttemptsExhausted(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	tokens, _, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	enrolment, _, err := handleRuntimeRequest[authapi.MfaEnrolmentResponse](schema, makeMfaRequest(ctx, authapi.MfaEnrolPath, tokens.AccessToken, ""))
+	require.NoError(t, err)
+
+	code, err := oauth.TotpCode(enrolment.Secret, time.Now().Add(-30*time.Second))
+	require.NoError(t, err)
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, authapi.MfaConfirmPath, tokens.AccessToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	challenge, _, err := handleRuntimeRequest[authapi.MfaChallengeResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, "000000"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	code, err = oauth.TotpCode(enrolment.Secret, time.Now())
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+}
+
+func TestMfa_EnrolNotAuthenticated(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, authapi.MfaEnrolPath, "", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidClient, errorResponse.Error)
+}
+
+func TestMfaOtpGrant_NoMfaToken(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, "", "123456"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidRequest, errorResponse.Error)
+	require.Equal(t, "the MFA token from the password grant in the 'mfa_token' field is required", errorResponse.ErrorDescription)
+}
+
+func makeMfaRequest(ctx context.Context, path string, accessToken string, code string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so"+path, nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	if accessToken != "" {
+		request.Header.Add("Authorization", "Bearer "+accessToken)
+	}
+
+	form := url.Values{}
+	if code != "" {
+		form.Add("code", code)
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
+
+func makeMfaOtpFormRequest(ctx context.Context, mfaToken string, code string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/token", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("grant_type", "mfa_otp")
+	form.Add("mfa_token", mfaToken)
+	form.Add("code", code)
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
@@ -103,6 +103,16 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 							},
 						},
 					},
+					"403": {
+						Description: "MFA Code Required or Email Not Verified",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/MfaChallengeResponse",
+								},
+							},
+						},
+					},
 				},
 			},
 		}
@@ -180,6 +190,209 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 			},
 		}
 
+		definition.Paths[MfaEnrolPath] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "MFA Enrolment",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/MfaEnrolmentResponse",
+								},
+							},
+						},
+					},
+					"400": {
+						Description: "MFA Enrolment Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "MFA Enrolment Request Not Authenticated",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for path, description := range map[string]string{MfaConfirmPath: "MFA Confirm", MfaDisablePath: "MFA Disable"} {
+			definition.Paths[path] = openapi.PathItemObject{
+				Post: &openapi.OperationObject{
+					RequestBody: &openapi.RequestBodyObject{
+						Description: fmt.Sprintf("%s Request", description),
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/MfaCodeRequest",
+								},
+							},
+							"application/x-www-form-urlencoded": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/MfaCodeRequest",
+								},
+							},
+						},
+						Required: &boolTrue,
+					},
+					Responses: map[string]openapi.ResponseObject{
+						"200": {
+							Description: fmt.Sprintf("%s Succeeded", description),
+						},
+						"400": {
+							Description: fmt.Sprintf("%s Request Badly Formed", description),
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {
+									Schema: jsonschema.JSONSchema{
+										Ref: "#/components/schemas/TokenErrorResponse",
+									},
+								},
+							},
+						},
+						"401": {
+							Description: fmt.Sprintf("%s Request Not Authenticated or Code Invalid", description),
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {
+									Schema: jsonschema.JSONSchema{
+										Ref: "#/components/schemas/TokenErrorResponse",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+		}
+
+		definition.Paths[PasswordlessPath] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "Passwordless Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/PasswordlessRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/PasswordlessRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Passwordless Code Sent",
+					},
+					"400": {
+						Description: "Passwordless Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths[EmailVerificationPath] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "Email Verification Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/EmailVerificationRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/EmailVerificationRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Email Verification Link Sent",
+					},
+					"400": {
+						Description: "Email Verification Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths[EmailVerificationConfirmPath] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "Email Verification Confirm Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/EmailVerificationConfirmRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/EmailVerificationConfirmRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Email Verified",
+					},
+					"400": {
+						Description: "Email Verification Confirm Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "Email Verification Token Invalid",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
 		definition.Components.Schemas["ProvidersResponse"] = jsonschema.JSONSchema{
 			Type: "object",
 			Properties: map[string]jsonschema.JSONSchema{
@@ -271,6 +484,45 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 					Title:                "Refresh Token",
 					AdditionalProperties: &boolFalse,
 				},
+				{
+					Type: "object",
+					Properties: map[string]jsonschema.JSONSchema{
+						"grant_type": {
+							Const:   "mfa_otp",
+							Default: "mfa_otp",
+						},
+						"mfa_token": {
+							Type: "string",
+						},
+						"code": {
+							Type: "string",
+						},
+					},
+					Required:             []string{"grant_type", "mfa_token", "code"},
+					Title:                "MFA One-Time Password",
+					AdditionalProperties: &boolFalse,
+				},
+				{
+					Type: "object",
+					Properties: map[string]jsonschema.JSONSchema{
+						"grant_type": {
+							Const:   "passwordless",
+							Default: "passwordless",
+						},
+						"username": {
+							Type: "string",
+						},
+						"code": {
+							Type: "string",
+						},
+						"create_if_not_exists": {
+							Type: "boolean",
+						},
+					},
+					Required:             []string{"grant_type", "username", "code"},
+					Title:                "Passwordless",
+					AdditionalProperties: &boolFalse,
+				},
 			},
 		}
 
@@ -307,6 +559,89 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 			},
 		}
 
+		definition.Components.Schemas["MfaChallengeResponse"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"error": {
+					Type: "string",
+				},
+				"error_description": {
+					Type: "string",
+				},
+				"mfa_token": {
+					Type: "string",
+				},
+			},
+		}
+
+		definition.Components.Schemas["MfaCodeRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"code": {
+					Type: "string",
+				},
+			},
+			Required:             []string{"code"},
+			AdditionalProperties: &boolFalse,
+		}
+
+		definition.Components.Schemas["PasswordlessRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"email": {
+					Type: "string",
+				},
+				"redirect_url": {
+					Type: "string",
+				},
+			},
+			Required:             []string{"email"},
+			AdditionalProperties: &boolFalse,
+		}
+
+		definition.Components.Schemas["EmailVerificationRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"email": {
+					Type: "string",
+				},
+				"redirect_url": {
+					Type: "string",
+				},
+			},
+			Required:             []string{"email", "redirect_url"},
+			AdditionalProperties: &boolFalse,
+		}
+
+		definition.Components.Schemas["EmailVerificationConfirmRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"token": {
+					Type: "string",
+				},
+			},
+			Required:             []string{"token"},
+			AdditionalProperties: &boolFalse,
+		}
+
+		definition.Components.Schemas["MfaEnrolmentResponse"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"secret": {
+					Type: "string",
+				},
+				"otpauth_uri": {
+					Type: "string",
+				},
+				"recovery_codes": {
+					Type: "array",
+					Items: &jsonschema.JSONSchema{
+						Type: "string",
+					},
+				},
+			},
+		}
+
 		definition.Components.Schemas["TokenErrorResponse"] = jsonschema.JSONSchema{
 			Type: "object",
 			Properties: map[string]jsonschema.JSONSchema{
@@ -0,0 +1,178 @@
+package authapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	email "net/mail"
+
+	"github.com/teamkeel/keel/mail"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+const (
+	PasswordlessPath = "/auth/passwordless"
+)
+
+const (
+	ArgEmail       = "email"
+	ArgRedirectUrl = "redirect_url"
+)
+
+// PasswordlessHandler emails a single-use code, and optionally a link containing it, which is then
+// exchanged for tokens with the passwordless grant. The response is the same whether or not an identity
+// exists with the email address, as it will be created on first sign-in.
+func PasswordlessHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Passwordless")
+		defer span.End()
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the passwordless endpoint only accepts POST", nil)
+		}
+
+		inputs, resp := requestInputs(r.WithContext(ctx))
+		if inputs == nil {
+			return resp
+		}
+
+		emailAddress, hasEmail := inputs[ArgEmail].(string)
+		if !hasEmail || emailAddress == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the email address to send the code to in the 'email' field is required", nil)
+		}
+
+		if _, err := email.ParseAddress(emailAddress); err != nil {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "invalid email address", nil)
+		}
+
+		var signInUrl *url.URL
+		if redirect, hasRedirect := inputs[ArgRedirectUrl].(string); hasRedirect && redirect != "" {
+			signInUrl, resp = appLinkUrl(ctx, redirect)
+			if signInUrl == nil {
+				return resp
+			}
+		}
+
+		clientIp := runtimectx.GetClientIp(ctx)
+
+		lockedUntil, err := oauth.RateLimitLockedOut(ctx, oauth.RateLimitPasswordlessCode, emailAddress, clientIp)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if lockedUntil != nil {
+			return tooManyAttemptsResponse(ctx, *lockedUntil)
+		}
+
+		// Every request counts as an attempt, as each one sends an email.
+		_, err = oauth.RecordRateLimitAttempt(ctx, oauth.RateLimitPasswordlessCode, emailAddress, clientIp)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		identity, err := actions.FindIdentityByEmail(ctx, schema, emailAddress, oauth.KeelIssuer)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		templateIdentity := mail.TemplateIdentity{Email: emailAddress}
+		if identity != nil {
+			templateIdentity = actions.TemplateIdentity(identity)
+		}
+
+		code, err := oauth.NewPasswordlessCode(ctx, emailAddress)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		templateData := &mail.PasswordlessSignInData{
+			Identity: templateIdentity,
+			Code:     code,
+		}
+
+		if signInUrl != nil {
+			q := signInUrl.Query()
+			q.Set(ArgEmail, emailAddress)
+			q.Set(ArgCode, code)
+			signInUrl.RawQuery = q.Encode()
+			templateData.SignInUrl = signInUrl.String()
+		}
+
+		client, err := runtimectx.GetMailClient(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		message, err := runtimectx.GetMailTemplates(ctx).Render(mail.TemplatePasswordlessSignIn, templateData)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		err = client.Send(ctx, &mail.SendEmailRequest{
+			To:        emailAddress,
+			Subject:   message.Subject,
+			PlainText: message.PlainText,
+			HTML:      message.HTML,
+		})
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
+
+// requestInputs parses the encoded form or JSON in the request body.
+func requestInputs(r *http.Request) (map[string]any, common.Response) {
+	ctx := r.Context()
+
+	if !common.HasContentType(r.Header, "application/x-www-form-urlencoded") && !common.HasContentType(r.Header, "application/json") {
+		return nil, jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the request body must either be an encoded form (Content-Type: application/x-www-form-urlencoded) or JSON (Content-Type: application/json)", nil)
+	}
+
+	data, err := common.ParseRequestData(r)
+	if err != nil {
+		return nil, jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+	}
+
+	inputs, ok := data.(map[string]any)
+	if !ok {
+		return nil, jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", nil)
+	}
+
+	return inputs, common.Response{}
+}
+
+// appLinkUrl parses the URL of a link to the app which is sent by email. As the link contains
+// a secret, it must have the same origin as the configured auth redirectUrl.
+func appLinkUrl(ctx context.Context, redirect string) (*url.URL, common.Response) {
+	linkUrl, err := url.ParseRequestURI(redirect)
+	if err != nil {
+		return nil, jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "invalid redirect URL", nil)
+	}
+
+	cfg, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return nil, common.InternalServerErrorResponse(ctx, err)
+	}
+
+	if cfg.RedirectUrl == nil {
+		return nil, jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "auth redirectUrl must be configured to send a link by email", nil)
+	}
+
+	appUrl, err := url.Parse(*cfg.RedirectUrl)
+	if err != nil {
+		return nil, common.InternalServerErrorResponse(ctx, err)
+	}
+
+	if linkUrl.Scheme != appUrl.Scheme || linkUrl.Host != appUrl.Host {
+		return nil, jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the redirect URL must have the same origin as the auth redirectUrl", nil)
+	}
+
+	return linkUrl, common.Response{}
+}
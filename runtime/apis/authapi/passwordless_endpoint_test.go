@@ -0,0 +1,356 @@
+package authapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/mail"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+type capturingMailClient struct {
+	sent []*mail.SendEmailRequest
+}
+
+func (c *capturingMailClient) Send(ctx context.Context, req *mail.SendEmailRequest) error {
+	c.sent = append(c.sent, req)
+	return nil
+}
+
+// sentCode extracts the code from the subject of the last email sent.
+func (c *capturingMailClient) sentCode() string {
+	subject := c.sent[len(c.sent)-1].Subject
+	return subject[strings.LastIndex(subject, " ")+1:]
+}
+
+func TestPasswordless_CreatesIdentityOnFirstUse(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, client.sent, 1)
+	require.Equal(t, "user@example.com", client.sent[0].To)
+
+	code := client.sentCode()
+	require.Len(t, code, 6)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+	require.NotEmpty(t, response.RefreshToken)
+	require.True(t, response.Created)
+
+	sub, err := oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+	require.Equal(t, sub, identities[0]["id"])
+	require.Equal(t, "user@example.com", identities[0]["email"])
+	require.Equal(t, true, identities[0]["email_verified"])
+	require.Nil(t, identities[0]["password"])
+
+	// The code cannot be used again
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidClient, errorResponse.Error)
+
+	// Signing in again uses the same identity
+	_, _, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	response, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", client.sentCode(), nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.False(t, response.Created)
+
+	sub2, err := oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, sub, sub2)
+
+	// The identity has no password to sign in with
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+}
+
+func TestPasswordless_ExistingPasswordIdentity(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	tokens, _, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	_, _, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", client.sentCode(), nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.False(t, response.Created)
+
+	sub, err := oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+
+	tokenSub, err := oauth.ValidateAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, tokenSub, sub)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+	require.Equal(t, true, identities[0]["email_verified"])
+}
+
+func TestPasswordless_IncorrectCodeAttemptsExhausted(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	_, _, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	code := client.sentCode()
+	incorrect := "000000"
+	if code == incorrect {
+		incorrect = "111111"
+	}
+
+	// The code is only valid for the email it was sent to
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "other@example.com", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	for i := 0; i < 5; i++ {
+		_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", incorrect, nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+}
+
+func TestPasswordless_NewCodeKeepsAttempts(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	_, _, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	incorrect := "000000"
+	if client.sentCode() == incorrect {
+		incorrect = "111111"
+	}
+
+	for i := 0; i < 5; i++ {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", incorrect, nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	// Requesting another code doesn't allow any more attempts until the exhausted code has expired
+	_, _, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", client.sentCode(), nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	database.GetDB().Exec("UPDATE keel_passwordless_code SET expires_at = now() - interval '1 second'")
+
+	_, _, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", client.sentCode(), nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestPasswordless_IncorrectCodesLockedOut(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+	ctx = withRateLimits(ctx, 3, 100)
+
+	_, _, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	code := client.sentCode()
+	incorrect := "000000"
+	if code == incorrect {
+		incorrect = "111111"
+	}
+
+	for i := 0; i < 3; i++ {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", incorrect, nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	// Even the correct code is rejected during the lockout
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrTooManyAttempts, errorResponse.Error)
+}
+
+func TestPasswordless_CodeRequestsRateLimited(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+	ctx = withRateLimits(ctx, 3, 100)
+
+	for i := 0; i < 3; i++ {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	}
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrTooManyAttempts, errorResponse.Error)
+	require.Len(t, client.sent, 3)
+
+	// Codes can still be requested for other email addresses
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "other@example.com", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestPasswordless_CreateIfNotExistsFalse(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	_, _, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", ""))
+	require.NoError(t, err)
+
+	createIfNotExists := false
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessFormRequest(ctx, "user@example.com", client.sentCode(), &createIfNotExists))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidClient, errorResponse.Error)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 0)
+}
+
+func TestPasswordless_SignInLink(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	client := &capturingMailClient{}
+	ctx = runtimectx.WithMailClient(ctx, client)
+
+	// A link can only be sent once the app's redirect url is configured
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", "https://myapp.com/sign-in"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "auth redirectUrl must be configured to send a link by email", errorResponse.ErrorDescription)
+
+	redirectUrl := "https://myapp.com/callback"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl: &redirectUrl,
+	})
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", "https://attacker.com/sign-in"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the redirect URL must have the same origin as the auth redirectUrl", errorResponse.ErrorDescription)
+	require.Len(t, client.sent, 0)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user@example.com", "https://myapp.com/sign-in?next=home"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, client.sent, 1)
+
+	code := client.sentCode()
+	link := "https://myapp.com/sign-in?" + url.Values{"code": {code}, "email": {"user@example.com"}, "next": {"home"}}.Encode()
+	require.Contains(t, client.sent[0].PlainText, link)
+}
+
+func TestPasswordless_InvalidEmail(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrInvalidRequest, errorResponse.Error)
+	require.Equal(t, "invalid email address", errorResponse.ErrorDescription)
+}
+
+func makePasswordlessRequest(ctx context.Context, email string, redirectUrl string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so"+authapi.PasswordlessPath, nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("email", email)
+	if redirectUrl != "" {
+		form.Add("redirect_url", redirectUrl)
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
+
+func makePasswordlessFormRequest(ctx context.Context, username string, code string, createIfNotExists *bool) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/token", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("grant_type", "passwordless")
+	form.Add("username", username)
+	form.Add("code", code)
+
+	if createIfNotExists != nil {
+		form.Add("create_if_not_exists", strconv.FormatBool(*createIfNotExists))
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
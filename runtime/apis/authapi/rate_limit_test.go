@@ -0,0 +1,220 @@
+package authapi_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+func withRateLimits(ctx context.Context, maxAttemptsPerIdentity int, maxAttemptsPerIp int) context.Context {
+	window := 60
+	lockout := 300
+	return runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RateLimits: config.RateLimits{
+			MaxAttemptsPerIdentity: &maxAttemptsPerIdentity,
+			MaxAttemptsPerIp:       &maxAttemptsPerIp,
+			Window:                 &window,
+			Lockout:                &lockout,
+		},
+	})
+}
+
+func TestRateLimit_IdentityLockedOut(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	ctx = withRateLimits(ctx, 3, 100)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	for i := 0; i < 3; i++ {
+		errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "incorrect", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+		require.Equal(t, authapi.TokenErrInvalidClient, errorResponse.Error)
+	}
+
+	// Even the correct password is rejected during the lockout
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrTooManyAttempts, errorResponse.Error)
+
+	retryAfter, err := strconv.Atoi(httpResponse.Header.Get("Retry-After"))
+	require.NoError(t, err)
+	require.Greater(t, retryAfter, 0)
+	require.LessOrEqual(t, retryAfter, 300)
+
+	// Other identities are not locked out
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "other@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestRateLimit_SuccessfulSignInResetsAttempts(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	ctx = withRateLimits(ctx, 3, 100)
+
+	_, _, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "incorrect", nil))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+		}
+
+		_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	}
+}
+
+func TestRateLimit_IpLockedOut(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	ctx = withRateLimits(ctx, 100, 3)
+
+	createIfNotExists := false
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, email, "incorrect", &createIfNotExists))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "d@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrTooManyAttempts, errorResponse.Error)
+
+	// X-Forwarded-For is ignored as the request is not from a trusted proxy
+	request := makePasswordFormRequest(ctx, "d@example.com", "myP@ssword1234!", nil)
+	request.Header.Set("X-Forwarded-For", "203.0.113.7")
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+}
+
+func TestRateLimit_TrustedProxy(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttemptsPerIp := 3
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RateLimits: config.RateLimits{
+			MaxAttemptsPerIp: &maxAttemptsPerIp,
+			// Requests made by httptest are from 192.0.2.1
+			TrustedProxies: []string{"192.0.2.0/24", "10.0.0.1"},
+		},
+	})
+
+	createIfNotExists := false
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		request := makePasswordFormRequest(ctx, email, "incorrect", &createIfNotExists)
+		request.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	// The client forwarded by the proxies is locked out, even if it adds its own X-Forwarded-For address
+	request := makePasswordFormRequest(ctx, "d@example.com", "myP@ssword1234!", nil)
+	request.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7, 10.0.0.1")
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrTooManyAttempts, errorResponse.Error)
+
+	// Another client forwarded by the proxies is not
+	request = makePasswordFormRequest(ctx, "d@example.com", "myP@ssword1234!", nil)
+	request.Header.Set("X-Forwarded-For", "198.51.100.1, 10.0.0.1")
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	enabled := false
+	maxAttempts := 1
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RateLimits: config.RateLimits{
+			Enabled:                &enabled,
+			MaxAttemptsPerIdentity: &maxAttempts,
+		},
+	})
+
+	_, _, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "incorrect", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestRateLimit_MfaCodesLockedOutAcrossChallenges(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	ctx = withRateLimits(ctx, 3, 100)
+
+	tokens, _, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+
+	enrolment, _, err := handleRuntimeRequest[authapi.MfaEnrolmentResponse](schema, makeMfaRequest(ctx, authapi.MfaEnrolPath, tokens.AccessToken, ""))
+	require.NoError(t, err)
+
+	code, err := oauth.TotpCode(enrolment.Secret, time.Now().Add(-30*time.Second))
+	require.NoError(t, err)
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, authapi.MfaConfirmPath, tokens.AccessToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// Each incorrect code is made with a new challenge from the correct password
+	for i := 0; i < 3; i++ {
+		challenge, httpResponse, err := handleRuntimeRequest[authapi.MfaChallengeResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+
+		errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, "000000"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+		require.Equal(t, authapi.TokenErrInvalidClient, errorResponse.Error)
+	}
+
+	// The correct password still issues a challenge, but even the correct code is rejected during the lockout
+	challenge, httpResponse, err := handleRuntimeRequest[authapi.MfaChallengeResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+
+	code, err = oauth.TotpCode(enrolment.Secret, time.Now())
+	require.NoError(t, err)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaOtpFormRequest(ctx, challenge.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, authapi.TokenErrTooManyAttempts, errorResponse.Error)
+}
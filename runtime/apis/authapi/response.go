@@ -2,7 +2,11 @@ package authapi
 
 import (
 	"context"
+	"math"
+	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/teamkeel/keel/runtime/common"
 	"go.opentelemetry.io/otel/attribute"
@@ -58,3 +62,20 @@ func redirectErrResponse(ctx context.Context, redirectUrl *url.URL, errorType st
 
 	return common.NewRedirectResponse(redirectUrl)
 }
+
+// Returned when the identity or client is locked out for exceeding the rate limits.
+func tooManyAttemptsResponse(ctx context.Context, lockedUntil time.Time) common.Response {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("auth.locked_until", lockedUntil.UTC().Format(time.RFC3339)),
+	)
+
+	resp := jsonErrResponse(ctx, http.StatusTooManyRequests, TokenErrTooManyAttempts, "there have been too many attempts and further attempts are not allowed until the lockout expires", nil)
+
+	retryAfter := int(math.Ceil(time.Until(lockedUntil).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	resp.Headers["Retry-After"] = []string{strconv.Itoa(retryAfter)}
+
+	return resp
+}
@@ -1,6 +1,7 @@
 package authapi
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/teamkeel/keel/schema/parser"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -35,6 +37,7 @@ const (
 	ArgUsername           = "username"
 	ArgPassword           = "password"
 	ArgCreateIfNotExists  = "create_if_not_exists"
+	ArgMfaToken           = "mfa_token"
 )
 
 const (
@@ -50,11 +53,22 @@ type TokenResponse struct {
 	Created      bool   `json:"identity_created"`
 }
 
+// Returned by the password grant when the identity has MFA enabled. The mfa_token and a code
+// from the identity's authenticator are then exchanged for tokens with the mfa_otp grant.
+type MfaChallengeResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	MfaToken         string `json:"mfa_token"`
+}
+
 // https://datatracker.ietf.org/doc/html/rfc6749#section-5.2
 const (
 	TokenErrUnsupportedGrantType = "unsupported_grant_type"
 	TokenErrInvalidClient        = "invalid_client"
 	TokenErrInvalidRequest       = "invalid_request"
+	TokenErrMfaRequired          = "mfa_required"
+	TokenErrEmailNotVerified     = "email_not_verified"
+	TokenErrTooManyAttempts      = "too_many_attempts"
 )
 
 const (
@@ -64,8 +78,20 @@ const (
 	GrantTypeAuthCode          = "authorization_code"
 	GrantTypeRefreshToken      = "refresh_token"
 	GrantTypeTokenExchange     = "token_exchange"
+	GrantTypeMfaOtp            = "mfa_otp"
+	GrantTypePasswordless      = "passwordless"
 )
 
+// The grant types which are handled by the token endpoint
+var SupportedGrantTypes = []string{
+	GrantTypeRefreshToken,
+	GrantTypeTokenExchange,
+	GrantTypeAuthCode,
+	GrantTypePassword,
+	GrantTypeMfaOtp,
+	GrantTypePasswordless,
+}
+
 // TokenEndpointHandler handles requests to the token endpoint for the various grant types we support.
 // OAuth2.0 specification: https://datatracker.ietf.org/doc/html/rfc6749#section-3.2
 // OpenID Connect specification for Token Endpoint: https://openid.net/specs/openid-connect-standard-1_0-21_orig.html#token_ep
@@ -105,7 +131,7 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 
 		grantType, hasGrantType := inputs[ArgGrantType].(string)
 		if !hasGrantType || grantType == "" {
-			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", nil)
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'mfa_otp' or 'passwordless'", nil)
 		}
 
 		span.SetAttributes(
@@ -181,6 +207,17 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the identity's password in the 'password' field is required", nil)
 			}
 
+			clientIp := runtimectx.GetClientIp(ctx)
+
+			lockedUntil, err := oauth.RateLimitLockedOut(ctx, oauth.RateLimitPassword, username, clientIp)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if lockedUntil != nil {
+				return tooManyAttemptsResponse(ctx, *lockedUntil)
+			}
+
 			ident, err := actions.FindIdentityByEmail(ctx, schema, username, oauth.KeelIssuer)
 			if err != nil {
 				return common.InternalServerErrorResponse(ctx, err)
@@ -188,6 +225,11 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 
 			if ident == nil {
 				if !createIfNotExists {
+					_, err = oauth.RecordRateLimitAttempt(ctx, oauth.RateLimitPassword, username, clientIp)
+					if err != nil {
+						return common.InternalServerErrorResponse(ctx, err)
+					}
+
 					return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist or the credentials are incorrect", nil)
 				}
 
@@ -203,10 +245,41 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 
 				identityCreated = true
 			} else {
-				correct := bcrypt.CompareHashAndPassword([]byte(ident[parser.IdentityFieldNamePassword].(string)), []byte(password)) == nil
+				// Identities created with the passwordless grant do not have a password
+				hashedPassword, _ := ident[parser.IdentityFieldNamePassword].(string)
+				correct := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
 				if !correct {
+					_, err = oauth.RecordRateLimitAttempt(ctx, oauth.RateLimitPassword, username, clientIp)
+					if err != nil {
+						return common.InternalServerErrorResponse(ctx, err)
+					}
+
 					return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist or the credentials are incorrect", nil)
 				}
+
+				err = oauth.ResetRateLimitAttempts(ctx, oauth.RateLimitPassword, username)
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+			}
+
+			// Tokens are not granted until the email address is verified, if required.
+			if verified, _ := ident[parser.IdentityFieldNameEmailVerified].(bool); !verified && cfg.EmailVerificationRequired() {
+				if identityCreated {
+					err = functions.CallPredefinedHook(auth.WithIdentity(ctx, ident), config.HookAfterIdentityCreated)
+					if err != nil {
+						return common.InternalServerErrorResponse(ctx, err)
+					}
+				}
+
+				return jsonErrResponse(ctx, http.StatusForbidden, TokenErrEmailNotVerified, "the identity's email address must be verified before signing in", nil)
+			}
+
+			// The password is correct, but tokens are only granted once the MFA challenge is completed.
+			if !identityCreated {
+				if resp, required := mfaChallenge(ctx, ident[parser.FieldNameId].(string)); required {
+					return resp
+				}
 			}
 
 			// Generate a refresh token.
@@ -245,6 +318,150 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 				return common.InternalServerErrorResponse(ctx, err)
 			}
 
+		case GrantTypeMfaOtp:
+			mfaToken, hasMfaToken := inputs[ArgMfaToken].(string)
+			if !hasMfaToken || mfaToken == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the MFA token from the password grant in the 'mfa_token' field is required", nil)
+			}
+
+			code, hasCode := inputs[ArgCode].(string)
+			if !hasCode || code == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the code from the authenticator, or a recovery code, in the 'code' field is required", nil)
+			}
+
+			challengeIdentityId, err := oauth.MfaChallengeIdentity(ctx, mfaToken)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if challengeIdentityId == "" {
+				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the code is incorrect or has already been used, or the MFA token has expired", nil)
+			}
+
+			// Each challenge only allows a few attempts, but a new challenge can be obtained with the password, and so
+			// the codes are also rate limited for the identity. Only a correct code resets these attempts.
+			clientIp := runtimectx.GetClientIp(ctx)
+
+			lockedUntil, err := oauth.RateLimitLockedOut(ctx, oauth.RateLimitMfa, challengeIdentityId, clientIp)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if lockedUntil != nil {
+				return tooManyAttemptsResponse(ctx, *lockedUntil)
+			}
+
+			// Consume the MFA challenge
+			isValid, identityId, err := oauth.ConsumeMfaChallenge(ctx, mfaToken, code)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if !isValid {
+				_, err = oauth.RecordRateLimitAttempt(ctx, oauth.RateLimitMfa, challengeIdentityId, clientIp)
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+
+				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the code is incorrect or has already been used, or the MFA token has expired", nil)
+			}
+
+			err = oauth.ResetRateLimitAttempts(ctx, oauth.RateLimitMfa, identityId)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			// Generate a refresh token.
+			refreshToken, err = oauth.NewRefreshToken(ctx, identityId)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			identity, err = actions.FindIdentityById(ctx, schema, identityId)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+		case GrantTypePasswordless:
+			username, hasUsername := inputs[ArgUsername].(string)
+			if !hasUsername || username == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the identity's email in the 'username' field is required", nil)
+			}
+
+			code, hasCode := inputs[ArgCode].(string)
+			if !hasCode || code == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the code sent to the identity's email in the 'code' field is required", nil)
+			}
+
+			clientIp := runtimectx.GetClientIp(ctx)
+
+			lockedUntil, err := oauth.RateLimitLockedOut(ctx, oauth.RateLimitPasswordless, username, clientIp)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if lockedUntil != nil {
+				return tooManyAttemptsResponse(ctx, *lockedUntil)
+			}
+
+			// Consume the passwordless code
+			isValid, err := oauth.ConsumePasswordlessCode(ctx, username, code)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if !isValid {
+				_, err = oauth.RecordRateLimitAttempt(ctx, oauth.RateLimitPasswordless, username, clientIp)
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+
+				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the code is incorrect, has already been used or has expired", nil)
+			}
+
+			err = oauth.ResetRateLimitAttempts(ctx, oauth.RateLimitPasswordless, username)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			ident, err := actions.FindIdentityByEmail(ctx, schema, username, oauth.KeelIssuer)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			// Signing in with the code proves ownership of the email address
+			if ident == nil {
+				if !createIfNotExists {
+					return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist or the credentials are incorrect", nil)
+				}
+
+				ident, err = actions.CreatePasswordlessIdentity(ctx, schema, username, oauth.KeelIssuer)
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+
+				identityCreated = true
+			} else {
+				if verified, _ := ident[parser.IdentityFieldNameEmailVerified].(bool); !verified {
+					ident, err = actions.VerifyIdentityEmail(ctx, schema, ident[parser.FieldNameId].(string))
+					if err != nil {
+						return common.InternalServerErrorResponse(ctx, err)
+					}
+				}
+
+				if resp, required := mfaChallenge(ctx, ident[parser.FieldNameId].(string)); required {
+					return resp
+				}
+			}
+
+			// Generate a refresh token.
+			refreshToken, err = oauth.NewRefreshToken(ctx, ident[parser.FieldNameId].(string))
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			identity = ident
+
 		case GrantTypeTokenExchange:
 			idTokenRaw, hasIdTokenRaw := inputs[ArgSubjectToken].(string)
 			if !hasIdTokenRaw || idTokenRaw == "" {
@@ -319,7 +536,7 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 			identity = ident
 
 		default:
-			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrUnsupportedGrantType, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", nil)
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrUnsupportedGrantType, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'mfa_otp' or 'passwordless'", nil)
 		}
 
 		ctx = auth.WithIdentity(ctx, identity)
@@ -348,3 +565,29 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 		return common.NewJsonResponse(http.StatusOK, response, nil)
 	}
 }
+
+// mfaChallenge determines if the identity has MFA enabled, in which case the response contains
+// a challenge to be completed with the mfa_otp grant before tokens are granted.
+func mfaChallenge(ctx context.Context, identityId string) (common.Response, bool) {
+	mfaEnabled, err := oauth.IsMfaEnabled(ctx, identityId)
+	if err != nil {
+		return common.InternalServerErrorResponse(ctx, err), true
+	}
+
+	if !mfaEnabled {
+		return common.Response{}, false
+	}
+
+	mfaToken, err := oauth.NewMfaChallenge(ctx, identityId)
+	if err != nil {
+		return common.InternalServerErrorResponse(ctx, err), true
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("auth.error", TokenErrMfaRequired))
+
+	return common.NewJsonResponse(http.StatusForbidden, &MfaChallengeResponse{
+		Error:            TokenErrMfaRequired,
+		ErrorDescription: "a code from the identity's authenticator is required with the 'mfa_otp' grant",
+		MfaToken:         mfaToken,
+	}, nil), true
+}
@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 	"github.com/teamkeel/keel/config"
 	"github.com/teamkeel/keel/proto"
@@ -810,7 +811,7 @@ func TestTokenEndpointJson_MissingGrantType(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
 	require.Equal(t, "invalid_request", errorResponse.Error)
-	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", errorResponse.ErrorDescription)
+	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'mfa_otp' or 'passwordless'", errorResponse.ErrorDescription)
 	require.True(t, common.HasContentType(httpResponse.Header, "application/json"))
 }
 
@@ -830,7 +831,7 @@ func TestTokenEndpoint_MissingGrantType(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
 	require.Equal(t, "invalid_request", errorResponse.Error)
-	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", errorResponse.ErrorDescription)
+	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'mfa_otp' or 'passwordless'", errorResponse.ErrorDescription)
 	require.True(t, common.HasContentType(httpResponse.Header, "application/json"))
 }
 
@@ -851,10 +852,40 @@ func TestTokenEndpoint_WrongGrantType(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
 	require.Equal(t, "unsupported_grant_type", errorResponse.Error)
-	require.Equal(t, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", errorResponse.ErrorDescription)
+	require.Equal(t, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'mfa_otp' or 'passwordless'", errorResponse.ErrorDescription)
 	require.True(t, common.HasContentType(httpResponse.Header, "application/json"))
 }
 
+func TestTokenEndpoint_SupportedGrantTypesAreAccepted(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
+	defer database.Close()
+
+	grantTypes := []string{
+		authapi.GrantTypeImplicit,
+		authapi.GrantTypePassword,
+		authapi.GrantTypeClientCredentials,
+		authapi.GrantTypeAuthCode,
+		authapi.GrantTypeRefreshToken,
+		authapi.GrantTypeTokenExchange,
+		authapi.GrantTypeMfaOtp,
+		authapi.GrantTypePasswordless,
+	}
+
+	// Every grant accepted by the token endpoint is listed as supported, and no others
+	for _, grantType := range grantTypes {
+		request := makeTokenExchangeFormRequest(ctx, "mock_token", nil)
+		form := url.Values{}
+		form.Add("grant_type", grantType)
+		request.URL.RawQuery = form.Encode()
+
+		errorResponse, _, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+		require.NoError(t, err)
+
+		accepted := errorResponse.Error != authapi.TokenErrUnsupportedGrantType
+		require.Equal(t, accepted, lo.Contains(authapi.SupportedGrantTypes, grantType), grantType)
+	}
+}
+
 func TestTokenExchangeGrant_NoSubjectToken(t *testing.T) {
 	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), authTestSchema, true)
 	defer database.Close()
@@ -0,0 +1,210 @@
+package filesapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/apis/httpjson"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var tracer = otel.Tracer("github.com/teamkeel/keel/runtime")
+
+const (
+	TicketPath = "/files/ticket"
+	UploadPath = storage.UploadPath
+)
+
+const (
+	ArgModel       = "model"
+	ArgField       = "field"
+	ArgFilename    = "filename"
+	ArgContentType = "contentType"
+	ArgSize        = "size"
+)
+
+// TicketHandler issues upload tickets for files which will be uploaded directly to the storage service, rather
+// than as a dataURL in the action's inputs. The key of the ticket is then used as the input to the File field.
+//
+// A ticket is only issued if the caller could be permitted to run an action which writes to the field, and it
+// can then only be used as the input to that field by the same identity.
+func TicketHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Upload Ticket")
+		defer span.End()
+
+		if r.Method != http.MethodPost {
+			return httpjson.NewErrorResponse(ctx, common.NewHttpMethodNotAllowedError("the upload ticket endpoint only accepts POST"), nil)
+		}
+
+		identity, err := actions.HandleAuthorizationHeader(ctx, schema, r.Header)
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, err, nil)
+		}
+		if identity != nil {
+			ctx = auth.WithIdentity(ctx, identity)
+		}
+
+		data, err := common.ParseRequestData(r)
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, common.NewInputMalformedError("request payload is malformed"), nil)
+		}
+
+		inputs, ok := data.(map[string]any)
+		if !ok {
+			return httpjson.NewErrorResponse(ctx, common.NewInputMalformedError("request payload is malformed"), nil)
+		}
+
+		modelName, _ := inputs[ArgModel].(string)
+		fieldName, _ := inputs[ArgField].(string)
+		filename, _ := inputs[ArgFilename].(string)
+		contentType, _ := inputs[ArgContentType].(string)
+		size, _ := inputs[ArgSize].(float64)
+
+		span.SetAttributes(
+			attribute.String("model", modelName),
+			attribute.String("field", fieldName),
+		)
+
+		field := proto.FindField(schema.Models, modelName, fieldName)
+		if field == nil || field.Type.Type != proto.Type_TYPE_FILE {
+			return httpjson.NewErrorResponse(ctx, common.NewValidationError(fmt.Sprintf("'%s.%s' is not a File field", modelName, fieldName)), nil)
+		}
+
+		if size <= 0 || size != float64(int64(size)) {
+			return httpjson.NewErrorResponse(ctx, common.NewValidationError("the size of the file in bytes must be provided"), nil)
+		}
+
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		permitted, err := canWriteField(ctx, schema, modelName, fieldName)
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, err, nil)
+		}
+		if !permitted {
+			return httpjson.NewErrorResponse(ctx, common.NewPermissionError(), nil)
+		}
+
+		storer, err := runtimectx.GetStorage(ctx)
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, err, nil)
+		}
+
+		database, err := db.GetDatabase(ctx)
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, err, nil)
+		}
+
+		ticket, err := storage.IssueUploadTicket(ctx, database, storer, storage.UploadRequest{
+			ModelName:   modelName,
+			FieldName:   fieldName,
+			IdentityId:  auth.GetIdentityId(ctx),
+			Filename:    filename,
+			ContentType: contentType,
+			Size:        int64(size),
+		})
+		if errors.Is(err, storage.ErrUploadTooLarge) {
+			return httpjson.NewErrorResponse(ctx, common.NewValidationError(err.Error()), nil)
+		}
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, err, nil)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, ticket, nil)
+	}
+}
+
+// UploadHandler receives direct uploads for storage services which don't accept uploads themselves,
+// such as the database storage.
+func UploadHandler() common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Upload File")
+		defer span.End()
+
+		if r.Method != http.MethodPut {
+			return httpjson.NewErrorResponse(ctx, common.NewHttpMethodNotAllowedError("the upload endpoint only accepts PUT"), nil)
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, UploadPath)
+		if key == "" || strings.Contains(key, "/") {
+			return httpjson.NewErrorResponse(ctx, common.NewNotFoundError("upload not found"), nil)
+		}
+
+		span.SetAttributes(attribute.String("file.key", key))
+
+		storer, err := runtimectx.GetStorage(ctx)
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, err, nil)
+		}
+
+		receiver, ok := storer.(storage.UploadReceiver)
+		if !ok {
+			return httpjson.NewErrorResponse(ctx, common.NewNotFoundError("files must be uploaded to the url given by the upload ticket"), nil)
+		}
+
+		fi, err := receiver.ReceiveUpload(key, r.Body)
+		if errors.Is(err, storage.ErrUploadNotFound) {
+			return httpjson.NewErrorResponse(ctx, common.NewNotFoundError(err.Error()), nil)
+		}
+		if errors.Is(err, storage.ErrUploadTooLarge) {
+			return httpjson.NewErrorResponse(ctx, common.NewValidationError(err.Error()), nil)
+		}
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, err, nil)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, fi, nil)
+	}
+}
+
+// canWriteField returns true if the caller could be permitted to run any create or update action which accepts
+// the field as an input. Permissions which can only be checked against the written rows require the caller to
+// be authenticated.
+func canWriteField(ctx context.Context, schema *proto.Schema, modelName string, fieldName string) (bool, error) {
+	model := schema.FindModel(modelName)
+
+	for _, action := range model.Actions {
+		actionType := proto.SingleActionType(action.Type)
+		if action.IsFunction() || (actionType != proto.ActionType_ACTION_TYPE_CREATE && actionType != proto.ActionType_ACTION_TYPE_UPDATE) {
+			continue
+		}
+
+		message := proto.FindValuesInputMessage(schema, action.Name)
+		if message == nil {
+			continue
+		}
+
+		writesField := lo.ContainsBy(message.Fields, func(f *proto.MessageField) bool {
+			return len(f.Target) == 1 && f.Target[0] == fieldName
+		})
+		if !writesField {
+			continue
+		}
+
+		scope := actions.NewScope(ctx, action, schema)
+		canResolveEarly, authorised, err := actions.TryResolveAuthorisationEarly(scope, proto.PermissionsForAction(schema, action))
+		if err != nil {
+			return false, err
+		}
+
+		if authorised || (!canResolveEarly && auth.IsAuthenticated(ctx)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,276 @@
+package filesapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/storage"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+var filesTestSchema = `
+model Document {
+	fields {
+		title Text
+		attachment File?
+		cover File?
+		owner Identity
+	}
+	actions {
+		create createDocument() with (title, attachment?, cover?) {
+			@set(document.owner = ctx.identity)
+		}
+		update updateDocument(id) with (attachment)
+	}
+	@permission(
+		expression: document.owner == ctx.identity,
+		actions: [create, update]
+	)
+}
+
+model Photo {
+	fields {
+		image File
+	}
+	actions {
+		create createPhoto() with (image)
+	}
+	@permission(
+		expression: true,
+		actions: [create]
+	)
+}`
+
+func setupFilesTest(t *testing.T) (context.Context, *proto.Schema) {
+	t.Setenv("KEEL_API_URL", "http://localhost:8000")
+
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), filesTestSchema, true)
+	t.Cleanup(func() {
+		database.Close()
+	})
+
+	return ctx, schema
+}
+
+func createIdentityToken(t *testing.T, ctx context.Context, schema *proto.Schema, email string) string {
+	identity, err := actions.CreateIdentity(ctx, schema, email, "1234", oauth.KeelIssuer)
+	require.NoError(t, err)
+
+	token, _, err := oauth.GenerateAccessToken(ctx, identity["id"].(string))
+	require.NoError(t, err)
+
+	return token
+}
+
+func request(t *testing.T, ctx context.Context, schema *proto.Schema, method string, path string, token string, body []byte) (int, map[string]any) {
+	req := httptest.NewRequest(method, "http://localhost:8000"+path, bytes.NewReader(body))
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	runtime.NewHttpHandler(schema).ServeHTTP(w, req)
+
+	res := map[string]any{}
+	if w.Body.Len() > 0 {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	}
+
+	return w.Code, res
+}
+
+func requestTicket(t *testing.T, ctx context.Context, schema *proto.Schema, token string, input map[string]any) (int, map[string]any) {
+	body, err := json.Marshal(input)
+	require.NoError(t, err)
+
+	return request(t, ctx, schema, http.MethodPost, "/files/ticket", token, body)
+}
+
+func upload(t *testing.T, ctx context.Context, schema *proto.Schema, ticket map[string]any, contents string) (int, map[string]any) {
+	uploadUrl, err := url.Parse(ticket["url"].(string))
+	require.NoError(t, err)
+
+	return request(t, ctx, schema, http.MethodPut, uploadUrl.Path, "", []byte(contents))
+}
+
+func TestTicketRequiresAuthenticationForRowPermissions(t *testing.T) {
+	ctx, schema := setupFilesTest(t)
+
+	status, res := requestTicket(t, ctx, schema, "", map[string]any{
+		"model":       "Document",
+		"field":       "attachment",
+		"filename":    "report.pdf",
+		"contentType": "application/pdf",
+		"size":        8,
+	})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "ERR_PERMISSION_DENIED", res["code"])
+
+	token := createIdentityToken(t, ctx, schema, "user@keel.xyz")
+
+	status, res = requestTicket(t, ctx, schema, token, map[string]any{
+		"model":       "Document",
+		"field":       "attachment",
+		"filename":    "report.pdf",
+		"contentType": "application/pdf",
+		"size":        8,
+	})
+	require.Equal(t, http.StatusOK, status, res)
+	assert.NotEmpty(t, res["key"])
+	assert.Equal(t, http.MethodPut, res["method"])
+	assert.True(t, strings.HasPrefix(res["url"].(string), "http://localhost:8000/files/upload/"))
+}
+
+func TestTicketAllowedByPermission(t *testing.T) {
+	ctx, schema := setupFilesTest(t)
+
+	status, res := requestTicket(t, ctx, schema, "", map[string]any{
+		"model":       "Photo",
+		"field":       "image",
+		"filename":    "cat.png",
+		"contentType": "image/png",
+		"size":        3,
+	})
+	require.Equal(t, http.StatusOK, status, res)
+	assert.NotEmpty(t, res["key"])
+}
+
+func TestTicketInvalidRequests(t *testing.T) {
+	ctx, schema := setupFilesTest(t)
+	token := createIdentityToken(t, ctx, schema, "user@keel.xyz")
+
+	status, res := requestTicket(t, ctx, schema, token, map[string]any{
+		"model": "Document",
+		"field": "title",
+		"size":  8,
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "'Document.title' is not a File field", res["message"])
+
+	status, res = requestTicket(t, ctx, schema, token, map[string]any{
+		"model": "Document",
+		"field": "attachment",
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "the size of the file in bytes must be provided", res["message"])
+
+	status, res = requestTicket(t, ctx, schema, token, map[string]any{
+		"model": "Document",
+		"field": "attachment",
+		"size":  100 * 1024 * 1024,
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, storage.ErrUploadTooLarge.Error(), res["message"])
+
+	status, _ = request(t, ctx, schema, http.MethodGet, "/files/ticket", token, nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func TestUploadOnlyOnce(t *testing.T) {
+	ctx, schema := setupFilesTest(t)
+
+	status, ticket := requestTicket(t, ctx, schema, "", map[string]any{
+		"model":       "Photo",
+		"field":       "image",
+		"filename":    "cat.png",
+		"contentType": "image/png",
+		"size":        3,
+	})
+	require.Equal(t, http.StatusOK, status, ticket)
+
+	status, res := upload(t, ctx, schema, ticket, "cat")
+	require.Equal(t, http.StatusOK, status, res)
+	assert.Equal(t, ticket["key"], res["key"])
+	assert.Equal(t, "cat.png", res["filename"])
+	assert.Equal(t, "image/png", res["contentType"])
+	assert.Equal(t, 3.0, res["size"])
+
+	status, _ = upload(t, ctx, schema, ticket, "cat")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = request(t, ctx, schema, http.MethodPut, "/files/upload/unknown", "", []byte("cat"))
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestUploadLargerThanTicket(t *testing.T) {
+	ctx, schema := setupFilesTest(t)
+
+	status, ticket := requestTicket(t, ctx, schema, "", map[string]any{
+		"model":       "Photo",
+		"field":       "image",
+		"filename":    "cat.png",
+		"contentType": "image/png",
+		"size":        3,
+	})
+	require.Equal(t, http.StatusOK, status, ticket)
+
+	status, res := upload(t, ctx, schema, ticket, "a much larger file")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, storage.ErrUploadTooLarge.Error(), res["message"])
+}
+
+func TestUploadedKeyAsInput(t *testing.T) {
+	ctx, schema := setupFilesTest(t)
+	owner := createIdentityToken(t, ctx, schema, "owner@keel.xyz")
+	other := createIdentityToken(t, ctx, schema, "other@keel.xyz")
+
+	status, ticket := requestTicket(t, ctx, schema, owner, map[string]any{
+		"model":       "Document",
+		"field":       "attachment",
+		"filename":    "report.pdf",
+		"contentType": "application/pdf",
+		"size":        8,
+	})
+	require.Equal(t, http.StatusOK, status, ticket)
+
+	status, res := upload(t, ctx, schema, ticket, "%PDF-1.4")
+	require.Equal(t, http.StatusOK, status, res)
+
+	key := ticket["key"].(string)
+	createDocument := func(token string, input map[string]any) (int, map[string]any) {
+		body, err := json.Marshal(input)
+		require.NoError(t, err)
+		return request(t, ctx, schema, http.MethodPost, "/api/json/createDocument", token, body)
+	}
+
+	// The key can't be used by another identity
+	status, res = createDocument(other, map[string]any{"title": "Stolen", "attachment": key})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "no uploaded file found with key '"+key+"' for field: attachment", res["message"])
+
+	// ... or for another field
+	status, res = createDocument(owner, map[string]any{"title": "Wrong field", "cover": key})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "no uploaded file found with key '"+key+"' for field: cover", res["message"])
+
+	// ... but it can be used by the identity it was issued to for the field
+	status, res = createDocument(owner, map[string]any{"title": "Report", "attachment": key})
+	require.Equal(t, http.StatusOK, status, res)
+	attachment := res["attachment"].(map[string]any)
+	assert.Equal(t, key, attachment["key"])
+	assert.Equal(t, "report.pdf", attachment["filename"])
+	assert.Equal(t, 8.0, attachment["size"])
+
+	// ... and only once
+	status, res = createDocument(owner, map[string]any{"title": "Again", "attachment": key})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "no uploaded file found with key '"+key+"' for field: attachment", res["message"])
+
+	// A key which was never issued with a ticket is rejected
+	status, _ = createDocument(owner, map[string]any{"title": "Unknown", "attachment": "2aBcDeF"})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
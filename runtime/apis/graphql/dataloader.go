@@ -0,0 +1,287 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/teamkeel/graphql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/common"
+)
+
+// batchFunc fetches the results for a batch of keys and returns a result for each key.
+type batchFunc func(ctx context.Context, keys []any) (map[any]*loaderResult, error)
+
+type loaderResult struct {
+	value any
+	err   error
+}
+
+// loaders holds the relationship loaders for a single GraphQL request. It is passed
+// to resolvers through the root object so that sibling resolvers share the same loaders.
+type loaders struct {
+	mutex   sync.Mutex
+	loaders map[string]*relationshipLoader
+}
+
+func newLoaders() *loaders {
+	return &loaders{
+		loaders: map[string]*relationshipLoader{},
+	}
+}
+
+// get returns the loader for the given key, creating it with fetch if it doesn't exist yet.
+func (l *loaders) get(key string, fetch batchFunc) *relationshipLoader {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	loader, ok := l.loaders[key]
+	if !ok {
+		loader = &relationshipLoader{fetch: fetch}
+		l.loaders[key] = loader
+	}
+
+	return loader
+}
+
+// loadersFromParams retrieves the request's loaders from the root object, if they exist.
+func loadersFromParams(p graphql.ResolveParams) *loaders {
+	rootValue, ok := p.Info.RootValue.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	l, _ := rootValue["loaders"].(*loaders)
+	return l
+}
+
+// A relationshipLoader collects the keys requested by sibling resolvers of a relationship field
+// and fetches them with a single query the first time any of their values are needed.
+//
+// The GraphQL executor resolves queries breadth-first, calling the returned thunks only once all
+// the fields at the current depth have been resolved, and therefore a batch is issued once per
+// relationship per depth.
+type relationshipLoader struct {
+	mutex   sync.Mutex
+	fetch   batchFunc
+	pending *relationshipBatch
+}
+
+type relationshipBatch struct {
+	once    sync.Once
+	keys    []any
+	results map[any]*loaderResult
+	err     error
+}
+
+// load adds the key to the pending batch and returns a thunk which resolves to the value for that key.
+func (l *relationshipLoader) load(ctx context.Context, key any) func() (interface{}, error) {
+	l.mutex.Lock()
+	if l.pending == nil {
+		l.pending = &relationshipBatch{}
+	}
+	batch := l.pending
+	batch.keys = append(batch.keys, key)
+	l.mutex.Unlock()
+
+	return func() (interface{}, error) {
+		batch.once.Do(func() {
+			// Once a batch has been dispatched any further keys must go into a new batch.
+			l.mutex.Lock()
+			if l.pending == batch {
+				l.pending = nil
+			}
+			l.mutex.Unlock()
+
+			batch.results, batch.err = l.fetch(ctx, batch.keys)
+		})
+
+		if batch.err != nil {
+			return nil, batch.err
+		}
+
+		result, ok := batch.results[key]
+		if !ok {
+			return nil, nil
+		}
+
+		return result.value, result.err
+	}
+}
+
+// relationshipBatchFunc creates the batchFunc which loads the related model records of the given
+// relationship field for a batch of parent lookup values.
+func (mk *graphqlSchemaBuilder) relationshipBatchFunc(model *proto.Model, field *proto.Field, page *actions.Page) batchFunc {
+	return func(ctx context.Context, keys []any) (map[any]*loaderResult, error) {
+		ctx, span := tracer.Start(ctx, fmt.Sprintf("Load %s.%s", model.Name, field.Name))
+		defer span.End()
+
+		span.SetAttributes(attribute.Int("batch.size", len(keys)))
+
+		relatedModel := mk.proto.FindModel(field.Type.ModelName.Value)
+		foreignKeyField := proto.GetForeignKeyFieldName(mk.proto.Models, field)
+
+		// The field on the related model which the parent lookup values are matched against.
+		lookupField := foreignKeyField
+		if field.IsBelongsTo() {
+			lookupField = "id"
+		}
+
+		query := actions.NewQuery(relatedModel)
+		query.Select(actions.AllFields())
+
+		err := query.Where(actions.Field(lookupField), actions.OneOf, actions.Value(keys))
+		if err != nil {
+			span.RecordError(err, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		var groups map[any]actions.Rows
+		var pageInfos map[any]*actions.PageInfo
+		var actionType proto.ActionType
+
+		switch {
+		case field.IsBelongsTo(), field.IsHasOne():
+			actionType = proto.ActionType_ACTION_TYPE_GET
+
+			rows, _, err := query.SelectStatement().ExecuteToMany(ctx, nil)
+			if err != nil {
+				span.RecordError(err, trace.WithStackTrace(true))
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+
+			groups = map[any]actions.Rows{}
+			for _, row := range rows {
+				groups[row[lookupField]] = append(groups[row[lookupField]], row)
+			}
+		case field.IsHasMany():
+			actionType = proto.ActionType_ACTION_TYPE_LIST
+
+			groups, pageInfos, err = query.
+				SelectPartitionedStatement(actions.Field(lookupField), page.First).
+				ExecuteToPartitions(ctx, lookupField)
+			if err != nil {
+				span.RecordError(err, trace.WithStackTrace(true))
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unhandled model relationship configuration for field: %s on model: %s", field.Name, field.ModelName)
+		}
+
+		scope := actions.NewModelScope(ctx, relatedModel, mk.proto)
+
+		authorised, err := authoriseGroups(scope, actionType, keys, groups)
+		if err != nil {
+			span.RecordError(err, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		results := map[any]*loaderResult{}
+		for _, key := range keys {
+			if !authorised[key] {
+				results[key] = &loaderResult{err: common.NewPermissionError()}
+				continue
+			}
+
+			rows := groups[key]
+
+			if actionType == proto.ActionType_ACTION_TYPE_GET {
+				switch len(rows) {
+				case 0:
+					// Return an error if no record if found for the corresponding foreign key
+					results[key] = &loaderResult{err: errors.New("record expected in database but nothing found")}
+				case 1:
+					results[key] = &loaderResult{value: rows[0]}
+				default:
+					results[key] = &loaderResult{err: fmt.Errorf("%v results returned for %s.%s which expects 0 or 1 result", len(rows), model.Name, field.Name)}
+				}
+				continue
+			}
+
+			if rows == nil {
+				rows = actions.Rows{}
+			}
+
+			pageInfo, ok := pageInfos[key]
+			if !ok {
+				pageInfo = &actions.PageInfo{}
+			}
+
+			res, err := connectionResponse(map[string]any{
+				"results":  rows,
+				"pageInfo": pageInfo.ToMap(),
+			})
+			results[key] = &loaderResult{value: res, err: err}
+		}
+
+		return results, nil
+	}
+}
+
+// authoriseGroups checks the permissions for the rows loaded for each key. All the rows are first checked
+// together and only if this fails is each key checked individually, so that a single unauthorised record
+// doesn't fail the rest of the batch.
+func authoriseGroups(scope *actions.Scope, actionType proto.ActionType, keys []any, groups map[any]actions.Rows) (map[any]bool, error) {
+	authorised := map[any]bool{}
+
+	all := actions.Rows{}
+	for _, key := range keys {
+		all = append(all, groups[key]...)
+	}
+
+	allAuthorised := false
+	if len(all) > 0 {
+		var err error
+		allAuthorised, err = actions.AuthoriseForActionType(scope, actionType, all)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Keys without any rows all share the same outcome, so this is checked at most once.
+	var emptyAuthorised *bool
+
+	for _, key := range keys {
+		if _, ok := authorised[key]; ok {
+			continue
+		}
+
+		rows := groups[key]
+
+		switch {
+		case len(rows) == 0 && actionType == proto.ActionType_ACTION_TYPE_GET:
+			// Nothing to authorise, and a missing record is reported when building the result.
+			authorised[key] = true
+		case len(rows) == 0:
+			if emptyAuthorised == nil {
+				ok, err := actions.AuthoriseForActionType(scope, actionType, rows)
+				if err != nil {
+					return nil, err
+				}
+				emptyAuthorised = &ok
+			}
+			authorised[key] = *emptyAuthorised
+		case allAuthorised:
+			authorised[key] = true
+		default:
+			ok, err := actions.AuthoriseForActionType(scope, actionType, rows)
+			if err != nil {
+				return nil, err
+			}
+			authorised[key] = ok
+		}
+	}
+
+	return authorised, nil
+}
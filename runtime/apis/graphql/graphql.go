@@ -134,6 +134,7 @@ func NewHandler(s *proto.Schema, api *proto.Api) common.HandlerFunc {
 			VariableValues: params.Variables,
 			RootObject: map[string]interface{}{
 				"headers": headers,
+				"loaders": newLoaders(),
 			},
 		})
 
@@ -338,6 +339,36 @@ func (mk *graphqlSchemaBuilder) addModel(model *proto.Model) (*graphql.Object, e
 					return nil, nil
 				}
 
+				// Where possible, the lookup is batched together with the same relationship
+				// on the sibling records to avoid a query for each parent record.
+				if loaders := loadersFromParams(p); loaders != nil {
+					switch {
+					case field.IsBelongsTo(), field.IsHasOne():
+						loader := loaders.get(
+							fmt.Sprintf("%s.%s", model.Name, field.Name),
+							mk.relationshipBatchFunc(model, field, nil))
+
+						return loader.load(p.Context, parentFieldValue), nil
+					case field.IsHasMany():
+						page, err := actions.ParsePage(p.Args)
+						if err != nil {
+							span.RecordError(err, trace.WithStackTrace(true))
+							span.SetStatus(codes.Error, err.Error())
+							return nil, err
+						}
+
+						// Only pages from the start of the connection can be batched, as cursors
+						// are specific to the parent record.
+						if page.Cursor() == "" && page.Last == 0 {
+							loader := loaders.get(
+								fmt.Sprintf("%s.%s(first:%d)", model.Name, field.Name, page.First),
+								mk.relationshipBatchFunc(model, field, &page))
+
+							return loader.load(p.Context, parentFieldValue), nil
+						}
+					}
+				}
+
 				var leftOperand *actions.QueryOperand
 				if field.IsBelongsTo() {
 					leftOperand = actions.IdField()
@@ -495,6 +526,11 @@ func (mk *graphqlSchemaBuilder) addAction(
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		field.Type = deleteResponseType
 		mk.mutation.AddFieldConfig(action.Name, field)
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY,
+		proto.ActionType_ACTION_TYPE_UPDATE_MANY,
+		proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		field.Type = graphql.NewNonNull(bulkResponseType)
+		mk.mutation.AddFieldConfig(action.Name, field)
 	case proto.ActionType_ACTION_TYPE_LIST:
 		// for list types we need to wrap the output type in the
 		// connection type which allows for pagination
@@ -150,6 +150,18 @@ var deleteResponseType = graphql.NewObject(graphql.ObjectConfig{
 	},
 })
 
+var bulkResponseType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BulkResponse",
+	Fields: graphql.Fields{
+		"ids": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID))),
+		},
+		"count": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Int),
+		},
+	},
+})
+
 var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
 	Name: "PageInfo",
 	Fields: graphql.Fields{
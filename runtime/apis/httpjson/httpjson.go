@@ -145,6 +145,8 @@ func NewErrorResponse(ctx context.Context, err error, data map[string]any) commo
 			httpCode = http.StatusMethodNotAllowed
 		case common.ErrInputMalformed:
 			httpCode = http.StatusBadRequest
+		case common.ErrTooManyRequests:
+			httpCode = http.StatusTooManyRequests
 		}
 
 		span.SetAttributes(
@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,6 +9,8 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/actions"
 	"github.com/teamkeel/keel/runtime/auth"
@@ -29,8 +32,13 @@ const (
 	JsonRpcInternalErrorCode  = -32603
 	JsonRpcUnauthorized       = -32001 // Not part of the official spec
 	JsonRpcForbidden          = -32003 // Not part of the official spec
+	JsonRpcTooManyRequests    = -32029 // Not part of the official spec
 )
 
+// BatchTransactionHeader is the request header which, when set to "true", runs all the
+// methods of a batch request inside a single database transaction.
+const BatchTransactionHeader = "X-Batch-Transaction"
+
 func NewHandler(schema *proto.Schema, api *proto.Api) common.HandlerFunc {
 	return func(r *http.Request) common.Response {
 		ctx, span := tracer.Start(r.Context(), "JsonRpc")
@@ -49,50 +57,185 @@ func NewHandler(schema *proto.Schema, api *proto.Api) common.HandlerFunc {
 			ctx = auth.WithIdentity(ctx, identity)
 		}
 
-		req, err := parseJsonRpcRequest(r.Body)
+		span.SetAttributes(
+			attribute.String("api.protocol", "RPC"),
+		)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			err = common.NewInputMalformedError(fmt.Sprintf("error reading body: %s", err.Error()))
+			return NewErrorResponse(ctx, nil, err)
+		}
+
+		if isBatch(body) {
+			return handleBatch(ctx, schema, body, r.Header.Get(BatchTransactionHeader) == "true")
+		}
+
+		req, err := parseJsonRpcRequest(body)
 		if err != nil {
-			err = common.NewInputMalformedError(fmt.Sprintf("error parsing JSON: %s", err.Error()))
-			return NewErrorResponse(ctx, &req.ID, err)
+			return NewErrorResponse(ctx, req.ID, requestParseError(err))
 		}
 
 		if !req.Valid() {
 			err = common.NewInputMalformedError("invalid JSON-RPC 2.0 request")
-			return NewErrorResponse(ctx, &req.ID, err)
+			return NewErrorResponse(ctx, req.ID, err)
 		}
 
-		inputs := req.Params
+		if req.ID != nil {
+			span.SetAttributes(attribute.String("request.id", *req.ID))
+		}
 
-		span.SetAttributes(
-			attribute.String("request.id", req.ID),
-			attribute.String("api.protocol", "RPC"),
-		)
+		response, meta, err := execute(ctx, schema, req)
+
+		// Notifications are executed but the server must not reply to them.
+		if req.IsNotification() {
+			return common.Response{
+				Status:  http.StatusNoContent,
+				Headers: map[string][]string{},
+			}
+		}
 
-		action := schema.FindAction(req.Method)
-		if action == nil {
-			err = common.NewMethodNotFoundError()
-			return NewErrorResponse(ctx, &req.ID, err)
+		if err != nil {
+			return NewErrorResponse(ctx, req.ID, err)
 		}
 
-		scope := actions.NewScope(ctx, action, schema)
+		return NewSuccessResponse(ctx, *req.ID, response, meta)
+	}
+}
+
+// execute runs the action for a single JSON-RPC request.
+func execute(ctx context.Context, schema *proto.Schema, req *JsonRpcRequest) (any, *common.ResponseMetadata, error) {
+	action := schema.FindAction(req.Method)
+	if action == nil {
+		return nil, nil, common.NewMethodNotFoundError()
+	}
+
+	scope := actions.NewScope(ctx, action, schema)
+
+	return actions.Execute(scope, req.Params)
+}
+
+// handleBatch runs each request of a JSON-RPC batch in order, responding with an array of
+// success and error responses. Notifications within the batch are executed but omitted from
+// the response, and if the batch consists only of notifications then nothing is returned.
+//
+// When useTransaction is true, all the methods are executed within a single database transaction
+// and the first error will roll back the whole batch. Writes made by custom functions
+// are not part of this transaction.
+func handleBatch(ctx context.Context, schema *proto.Schema, body []byte, useTransaction bool) common.Response {
+	span := trace.SpanFromContext(ctx)
+
+	requests, err := parseJsonRpcBatch(body)
+	if err != nil {
+		return NewErrorResponse(ctx, nil, requestParseError(err))
+	}
+
+	if len(requests) == 0 {
+		err = common.NewInputMalformedError("invalid JSON-RPC 2.0 request")
+		return NewErrorResponse(ctx, nil, err)
+	}
 
-		response, meta, err := actions.Execute(scope, inputs)
+	span.SetAttributes(
+		attribute.Int("batch.size", len(requests)),
+		attribute.Bool("batch.transaction", useTransaction),
+	)
+
+	headers := map[string][]string{}
+	responses := make([]any, len(requests))
+
+	run := func(ctx context.Context) error {
+		for i, req := range requests {
+			if req == nil || !req.Valid() {
+				err := common.NewInputMalformedError("invalid JSON-RPC 2.0 request")
+				responses[i] = newErrorResponseBody(ctx, requestIdOrNil(req), err)
+				if useTransaction {
+					return err
+				}
+				continue
+			}
+
+			result, meta, err := execute(ctx, schema, req)
+			if err != nil {
+				responses[i] = newErrorResponseBody(ctx, req.ID, err)
+				if useTransaction {
+					return err
+				}
+				continue
+			}
+
+			if meta != nil {
+				for k, v := range meta.Headers {
+					headers[k] = v
+				}
+			}
+
+			responses[i] = JsonRpcSuccessResponse{
+				JsonRpc: "2.0",
+				ID:      lo.FromPtr(req.ID),
+				Result:  result,
+			}
+		}
+		return nil
+	}
+
+	if useTransaction {
+		database, err := db.GetDatabase(ctx)
 		if err != nil {
-			return NewErrorResponse(ctx, &req.ID, err)
+			return NewErrorResponse(ctx, nil, err)
 		}
 
-		return NewSuccessResponse(ctx, req.ID, response, meta)
+		err = database.Transaction(ctx, run)
+		if err != nil {
+			// Every method in the batch has been rolled back, so none of them can report success.
+			rolledBack := common.RuntimeError{
+				Code:    common.ErrInternal,
+				Message: "batch transaction rolled back",
+			}
+			for i, req := range requests {
+				if _, failed := responses[i].(JsonRpcErrorResponse); !failed {
+					responses[i] = newErrorResponseBody(ctx, requestIdOrNil(req), rolledBack)
+				}
+			}
+		}
+	} else {
+		_ = run(ctx)
+	}
+
+	batch := []any{}
+	for i, req := range requests {
+		if req != nil && req.Valid() && req.IsNotification() {
+			continue
+		}
+		batch = append(batch, responses[i])
 	}
+
+	if len(batch) == 0 {
+		return common.Response{
+			Status:  http.StatusNoContent,
+			Headers: headers,
+		}
+	}
+
+	return common.NewJsonResponse(http.StatusOK, batch, &common.ResponseMetadata{
+		Headers: headers,
+	})
 }
 
 type JsonRpcRequest struct {
-	JsonRpc string         `json:"jsonrpc"`
-	ID      string         `json:"id"`
-	Method  string         `json:"method"`
-	Params  map[string]any `json:"params"`
+	JsonRpc string `json:"jsonrpc"`
+	// ID is nil when the request is a notification.
+	ID     *string        `json:"id"`
+	Method string         `json:"method"`
+	Params map[string]any `json:"params"`
 }
 
 func (r JsonRpcRequest) Valid() bool {
-	return r.Method != "" && r.ID != "" && r.JsonRpc == "2.0"
+	return r.Method != "" && (r.ID == nil || *r.ID != "") && r.JsonRpc == "2.0"
+}
+
+// IsNotification is true when the request has no id, meaning the client is not expecting a response.
+func (r JsonRpcRequest) IsNotification() bool {
+	return r.ID == nil
 }
 
 type JsonRpcSuccessResponse struct {
@@ -122,12 +265,23 @@ func NewSuccessResponse(ctx context.Context, requestId string, response any, met
 }
 
 func NewErrorResponse(ctx context.Context, requestId *string, err error) common.Response {
+	return common.NewJsonResponse(http.StatusOK, newErrorResponseBody(ctx, requestId, err), nil)
+}
+
+func newErrorResponseBody(ctx context.Context, requestId *string, err error) JsonRpcErrorResponse {
 	span := trace.SpanFromContext(ctx)
 
 	var response JsonRpcError
 	var runtimeError common.RuntimeError
 
+	var parseErr *parseError
+
 	switch {
+	case errors.As(err, &parseErr):
+		response = JsonRpcError{
+			Code:    JsonRpcParseErrorCode,
+			Message: parseErr.Error(),
+		}
 	case errors.As(err, &runtimeError):
 		response = JsonRpcError{
 			Code:    runtimeErrorCodeToJsonRpcErrorCode(runtimeError.Code),
@@ -148,24 +302,76 @@ func NewErrorResponse(ctx context.Context, requestId *string, err error) common.
 	span.RecordError(err, trace.WithStackTrace(true))
 	span.SetStatus(codes.Error, err.Error())
 
-	return common.NewJsonResponse(http.StatusOK, JsonRpcErrorResponse{
+	return JsonRpcErrorResponse{
 		JsonRpc: "2.0",
 		ID:      requestId,
 		Error:   response,
-	}, nil)
+	}
 }
 
-func parseJsonRpcRequest(b io.ReadCloser) (req *JsonRpcRequest, err error) {
-	body, err := io.ReadAll(b)
-	if err != nil {
-		return nil, err
+// parseError is returned when the body of the request is not valid JSON.
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("error parsing JSON: %s", e.err.Error())
+}
+
+func (e *parseError) Unwrap() error {
+	return e.err
+}
+
+// requestParseError converts an error from parsing the request body into a parse error if the body is not valid
+// JSON, or otherwise an invalid request error as the JSON is not a valid request object.
+func requestParseError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &parseError{err: err}
 	}
 
+	return common.NewInputMalformedError(fmt.Sprintf("error parsing JSON: %s", err.Error()))
+}
+
+func parseJsonRpcRequest(body []byte) (req *JsonRpcRequest, err error) {
 	req = &JsonRpcRequest{}
 	err = json.Unmarshal(body, req)
 	return req, err
 }
 
+// parseJsonRpcBatch parses a batch request. Any entries which are not valid request objects
+// are returned as nil so that an error response can be produced for them.
+func parseJsonRpcBatch(body []byte) ([]*JsonRpcRequest, error) {
+	var raw []json.RawMessage
+	err := json.Unmarshal(body, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]*JsonRpcRequest, len(raw))
+	for i, r := range raw {
+		req, err := parseJsonRpcRequest(r)
+		if err == nil {
+			requests[i] = req
+		}
+	}
+
+	return requests, nil
+}
+
+// isBatch determines if the body is a JSON array, which indicates a batch request.
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+func requestIdOrNil(req *JsonRpcRequest) *string {
+	if req == nil {
+		return nil
+	}
+	return req.ID
+}
+
 func runtimeErrorCodeToJsonRpcErrorCode(code string) int {
 	switch code {
 	case common.ErrAuthenticationFailed:
@@ -178,6 +384,8 @@ func runtimeErrorCodeToJsonRpcErrorCode(code string) int {
 		return JsonRpcMethodNotFoundCode
 	case common.ErrInputMalformed:
 		return JsonRpcInvalidRequestCode
+	case common.ErrTooManyRequests:
+		return JsonRpcTooManyRequests
 	default:
 		return JsonRpcInternalErrorCode
 	}
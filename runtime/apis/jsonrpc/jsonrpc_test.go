@@ -0,0 +1,140 @@
+package jsonrpc_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/apis/jsonrpc"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/schema/reader"
+)
+
+const testSchema = `
+	model Thing {
+		actions {
+			get getThing(id)
+		}
+	}
+	api Test {
+		models {
+			Thing
+		}
+	}`
+
+func TestBatchRequest(t *testing.T) {
+	builder := schema.Builder{}
+	protoSchema, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{{Contents: testSchema}},
+	})
+	require.NoError(t, err)
+
+	handler := jsonrpc.NewHandler(protoSchema, protoSchema.Apis[0])
+
+	body := `[
+		{"jsonrpc": "2.0", "id": "1", "method": "unknownOne"},
+		{"jsonrpc": "2.0", "method": "unknownTwo"},
+		{"jsonrpc": "1.0", "id": "3", "method": "getThing"},
+		"not a request"
+	]`
+
+	r := httptest.NewRequest(http.MethodPost, "/test/rpc", strings.NewReader(body))
+	response := handler(r)
+	require.Equal(t, http.StatusOK, response.Status)
+
+	var res []map[string]any
+	require.NoError(t, json.Unmarshal(response.Body, &res))
+
+	// The notification is omitted from the response.
+	require.Len(t, res, 3)
+
+	assert.Equal(t, "1", res[0]["id"])
+	assert.Equal(t, float64(jsonrpc.JsonRpcMethodNotFoundCode), res[0]["error"].(map[string]any)["code"])
+
+	assert.Equal(t, "3", res[1]["id"])
+	assert.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), res[1]["error"].(map[string]any)["code"])
+
+	assert.Nil(t, res[2]["id"])
+	assert.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), res[2]["error"].(map[string]any)["code"])
+}
+
+func TestBatchRequestEmpty(t *testing.T) {
+	builder := schema.Builder{}
+	protoSchema, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{{Contents: testSchema}},
+	})
+	require.NoError(t, err)
+
+	handler := jsonrpc.NewHandler(protoSchema, protoSchema.Apis[0])
+
+	r := httptest.NewRequest(http.MethodPost, "/test/rpc", strings.NewReader(`[]`))
+	response := handler(r)
+
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(response.Body, &res))
+	assert.Nil(t, res["id"])
+	assert.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), res["error"].(map[string]any)["code"])
+}
+
+func TestNotification(t *testing.T) {
+	builder := schema.Builder{}
+	protoSchema, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{{Contents: testSchema}},
+	})
+	require.NoError(t, err)
+
+	handler := jsonrpc.NewHandler(protoSchema, protoSchema.Apis[0])
+
+	r := httptest.NewRequest(http.MethodPost, "/test/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "unknown"}`))
+	response := handler(r)
+
+	assert.Equal(t, http.StatusNoContent, response.Status)
+	assert.Empty(t, response.Body)
+}
+
+func TestParseError(t *testing.T) {
+	builder := schema.Builder{}
+	protoSchema, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{{Contents: testSchema}},
+	})
+	require.NoError(t, err)
+
+	handler := jsonrpc.NewHandler(protoSchema, protoSchema.Apis[0])
+
+	for _, body := range []string{
+		`{"jsonrpc": "2.0", "id": "1", "method": "getThing"`,
+		`[{"jsonrpc": "2.0", "id": "1", "method": "getThing"},`,
+		`not json`,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/test/rpc", strings.NewReader(body))
+		response := handler(r)
+		require.Equal(t, http.StatusOK, response.Status)
+
+		var res map[string]any
+		require.NoError(t, json.Unmarshal(response.Body, &res))
+		assert.Nil(t, res["id"], body)
+		assert.Equal(t, float64(jsonrpc.JsonRpcParseErrorCode), res["error"].(map[string]any)["code"], body)
+	}
+}
+
+func TestInvalidRequest(t *testing.T) {
+	builder := schema.Builder{}
+	protoSchema, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{{Contents: testSchema}},
+	})
+	require.NoError(t, err)
+
+	handler := jsonrpc.NewHandler(protoSchema, protoSchema.Apis[0])
+
+	// Valid JSON which isn't a request object is an invalid request rather than a parse error
+	r := httptest.NewRequest(http.MethodPost, "/test/rpc", strings.NewReader(`{"jsonrpc": "2.0", "id": "1", "method": 123}`))
+	response := handler(r)
+
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(response.Body, &res))
+	assert.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), res["error"].(map[string]any)["code"])
+}
@@ -3,6 +3,8 @@ package auth
 import (
 	"context"
 	"fmt"
+
+	"github.com/teamkeel/keel/schema/parser"
 )
 
 type contextKey string
@@ -32,3 +34,14 @@ func GetIdentity(ctx context.Context) (Identity, error) {
 func IsAuthenticated(ctx context.Context) bool {
 	return ctx.Value(identityContextKey) != nil
 }
+
+// GetIdentityId returns the id of the authenticated identity, or an empty string if there is none.
+func GetIdentityId(ctx context.Context) string {
+	identity, err := GetIdentity(ctx)
+	if err != nil {
+		return ""
+	}
+
+	id, _ := identity[parser.FieldNameId].(string)
+	return id
+}
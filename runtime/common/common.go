@@ -97,6 +97,8 @@ const (
 	ErrMethodNotFound = "ERR_ACTION_NOT_FOUND"
 	// The HTTP method is not allowed for this request.
 	ErrHttpMethodNotAllowed = "ERR_HTTP_METHOD_NOT_ALLOWED"
+	// Too many requests have been made and further requests are not allowed for a period.
+	ErrTooManyRequests = "ERR_TOO_MANY_REQUESTS"
 	// An unexpected error happened from user code
 	ErrUnknown = "ERR_UNKNOWN"
 )
@@ -220,6 +222,17 @@ func NewForeignKeyConstraintError(column string) RuntimeError {
 	}
 }
 
+func NewRestrictedDeleteError(table string) RuntimeError {
+	// Parses from the database casing back to the schema casing.
+	// Important since these error messages are delivered to the user.
+	model := casing.ToCamel(table)
+
+	return RuntimeError{
+		Code:    ErrInvalidInput,
+		Message: fmt.Sprintf("the record cannot be deleted as it is still referenced by %s records", model),
+	}
+}
+
 func NewPermissionError() RuntimeError {
 	return RuntimeError{
 		Code:    ErrPermissionDenied,
@@ -0,0 +1,366 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/expressions/sqlgen"
+	"github.com/teamkeel/keel/runtime/types"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// IsComputed returns true if the expression operand is computed from other operands using
+// an arithmetic operation or a function, such as item.price * quantity or lower(email).
+func (resolver *OperandResolver) IsComputed() bool {
+	return resolver.operand.IsComputed()
+}
+
+// IsComputedFromDatabase returns true if the operand is computed from at least one value which
+// resides in the database, such as lower(post.title).
+func (resolver *OperandResolver) IsComputedFromDatabase() bool {
+	if !resolver.IsComputed() {
+		return false
+	}
+
+	var operands []*OperandResolver
+	if resolver.operand.Arithmetic != nil {
+		operands, _ = resolver.ArithmeticTerms()
+	} else {
+		_, operands = resolver.Function()
+	}
+
+	for _, operand := range operands {
+		if operand.IsModelDbColumn() || operand.IsContextDbColumn() || operand.IsComputedFromDatabase() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ArithmeticTerms returns resolvers for the operands of an arithmetic operation along with the operators
+// between them, in the order they are written.
+func (resolver *OperandResolver) ArithmeticTerms() ([]*OperandResolver, []string) {
+	operands, operators := resolver.operand.ArithmeticTerms()
+	return resolver.resolvers(operands), operators
+}
+
+// Function returns the name of the function and resolvers for each of its arguments.
+func (resolver *OperandResolver) Function() (string, []*OperandResolver) {
+	if resolver.operand.Function == nil {
+		return "", nil
+	}
+
+	return resolver.operand.Function.Name.Fragment, resolver.resolvers(resolver.operand.Function.Arguments)
+}
+
+// Duration returns the duration if the operand is a duration literal, such as 7 days.
+func (resolver *OperandResolver) Duration() *parser.Duration {
+	if resolver.IsComputed() {
+		return nil
+	}
+
+	return resolver.operand.Duration
+}
+
+func (resolver *OperandResolver) resolvers(operands []*parser.Operand) []*OperandResolver {
+	resolvers := make([]*OperandResolver, len(operands))
+	for i, operand := range operands {
+		resolvers[i] = NewOperandResolver(resolver.Context, resolver.Schema, resolver.Model, resolver.Action, operand)
+	}
+	return resolvers
+}
+
+// computedType returns the type of the value computed by an arithmetic operation or function.
+func (resolver *OperandResolver) computedType() (proto.Type, error) {
+	if resolver.operand.Arithmetic != nil {
+		operands, operators := resolver.ArithmeticTerms()
+
+		types := make([]proto.Type, len(operands))
+		for i, operand := range operands {
+			t, _, err := operand.GetOperandType()
+			if err != nil {
+				return proto.Type_TYPE_UNKNOWN, err
+			}
+			types[i] = t
+		}
+
+		return parser.FoldArithmetic(types, operators, func(lhs proto.Type, _ string, rhs proto.Type) (proto.Type, error) {
+			return sqlgen.ArithmeticType(lhs, rhs), nil
+		})
+	}
+
+	name, args := resolver.Function()
+
+	argType := proto.Type_TYPE_UNKNOWN
+	if len(args) > 0 {
+		t, _, err := args[0].GetOperandType()
+		if err != nil {
+			return proto.Type_TYPE_UNKNOWN, err
+		}
+		argType = t
+	}
+
+	return sqlgen.FunctionType(name, argType)
+}
+
+// computedValue evaluates an arithmetic operation or function in this process.
+func (resolver *OperandResolver) computedValue(args map[string]any) (any, error) {
+	if resolver.operand.Arithmetic != nil {
+		operands, operators := resolver.ArithmeticTerms()
+
+		values := make([]any, len(operands))
+		for i, operand := range operands {
+			v, err := operand.ResolveValue(args)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+
+		return parser.FoldArithmetic(values, operators, applyArithmetic)
+	}
+
+	name, operands := resolver.Function()
+
+	values := make([]any, len(operands))
+	for i, operand := range operands {
+		v, err := operand.ResolveValue(args)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return applyFunction(name, values)
+}
+
+func applyArithmetic(lhs any, operator string, rhs any) (any, error) {
+	// As with SQL, an operation with null results in null
+	if lhs == nil || rhs == nil {
+		return nil, nil
+	}
+
+	if d, ok := rhs.(*parser.Duration); ok {
+		t, ok := asTime(lhs)
+		if !ok {
+			return nil, fmt.Errorf("cannot add a duration to %T", lhs)
+		}
+
+		sign := 1
+		if operator == parser.OperatorSubtract {
+			sign = -1
+		}
+		t = addDuration(t, d, sign)
+
+		if _, ok := lhs.(types.Date); ok {
+			return types.Date{Time: t}, nil
+		}
+		return t, nil
+	}
+
+	if l, ok := lhs.(string); ok {
+		r, ok := rhs.(string)
+		if !ok || operator != parser.OperatorAdd {
+			return nil, fmt.Errorf("cannot use operator %s with %T and %T", operator, lhs, rhs)
+		}
+		return l + r, nil
+	}
+
+	l, lIsInt := toInt(lhs)
+	r, rIsInt := toInt(rhs)
+	if lIsInt && rIsInt {
+		switch operator {
+		case parser.OperatorAdd:
+			return l + r, nil
+		case parser.OperatorSubtract:
+			return l - r, nil
+		case parser.OperatorMultiply:
+			return l * r, nil
+		case parser.OperatorDivide:
+			if r == 0 {
+				return nil, errors.New("division by zero")
+			}
+			return l / r, nil
+		case parser.OperatorModulo:
+			if r == 0 {
+				return nil, errors.New("division by zero")
+			}
+			return l % r, nil
+		}
+	}
+
+	lf, lok := toFloat(lhs)
+	rf, rok := toFloat(rhs)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot use operator %s with %T and %T", operator, lhs, rhs)
+	}
+
+	switch operator {
+	case parser.OperatorAdd:
+		return lf + rf, nil
+	case parser.OperatorSubtract:
+		return lf - rf, nil
+	case parser.OperatorMultiply:
+		return lf * rf, nil
+	case parser.OperatorDivide:
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return lf / rf, nil
+	case parser.OperatorModulo:
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return math.Mod(lf, rf), nil
+	default:
+		return nil, fmt.Errorf("unknown arithmetic operator %s", operator)
+	}
+}
+
+func applyFunction(name string, args []any) (any, error) {
+	if name == parser.FunctionCoalesce {
+		for _, arg := range args {
+			if arg != nil {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	}
+
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s expects a single argument", name)
+	}
+
+	// As with SQL, functions of null result in null
+	arg := args[0]
+	if arg == nil {
+		return nil, nil
+	}
+
+	switch name {
+	case parser.FunctionLower, parser.FunctionUpper, parser.FunctionTrim, parser.FunctionLength:
+		s, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s expects text but was given %T", name, arg)
+		}
+
+		switch name {
+		case parser.FunctionLower:
+			return strings.ToLower(s), nil
+		case parser.FunctionUpper:
+			return strings.ToUpper(s), nil
+		case parser.FunctionTrim:
+			// Postgres only trims spaces, not all whitespace
+			return strings.Trim(s, " "), nil
+		default:
+			return int64(utf8.RuneCountInString(s)), nil
+		}
+	case parser.FunctionAbs, parser.FunctionRound, parser.FunctionFloor, parser.FunctionCeil:
+		if i, ok := toInt(arg); ok {
+			if name == parser.FunctionAbs && i < 0 {
+				return -i, nil
+			}
+			return i, nil
+		}
+
+		f, ok := toFloat(arg)
+		if !ok {
+			return nil, fmt.Errorf("%s expects a number but was given %T", name, arg)
+		}
+
+		switch name {
+		case parser.FunctionAbs:
+			return math.Abs(f), nil
+		case parser.FunctionRound:
+			return int64(math.Round(f)), nil
+		case parser.FunctionFloor:
+			return int64(math.Floor(f)), nil
+		default:
+			return int64(math.Ceil(f)), nil
+		}
+	case parser.FunctionYear, parser.FunctionMonth, parser.FunctionDay:
+		t, ok := asTime(arg)
+		if !ok {
+			return nil, fmt.Errorf("%s expects a date or timestamp but was given %T", name, arg)
+		}
+
+		switch name {
+		case parser.FunctionYear:
+			return int64(t.Year()), nil
+		case parser.FunctionMonth:
+			return int64(t.Month()), nil
+		default:
+			return int64(t.Day()), nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+}
+
+func addDuration(t time.Time, d *parser.Duration, sign int) time.Time {
+	n := int(d.Value) * sign
+
+	switch strings.TrimSuffix(d.Unit, "s") {
+	case "second":
+		return t.Add(time.Duration(n) * time.Second)
+	case "minute":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "hour":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "day":
+		return t.AddDate(0, 0, n)
+	case "week":
+		return t.AddDate(0, 0, n*7)
+	case "month":
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(n, 0, 0)
+	}
+}
+
+func toInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	if i, ok := toInt(v); ok {
+		return float64(i), true
+	}
+
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func asTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case types.Date:
+		return t.Time, true
+	case types.Timestamp:
+		return t.Time, true
+	default:
+		return time.Time{}, false
+	}
+}
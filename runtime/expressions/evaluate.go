@@ -3,6 +3,7 @@ package expressions
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/segmentio/ksuid"
 	"github.com/teamkeel/keel/proto"
@@ -44,11 +45,12 @@ func canResolveConditionEarly(ctx context.Context, schema *proto.Schema, model *
 	lhsResolver := NewOperandResolver(ctx, schema, model, action, condition.LHS)
 
 	if condition.Type() == parser.ValueCondition {
-		return !lhsResolver.IsModelDbColumn() && !lhsResolver.IsContextDbColumn()
+		return !lhsResolver.IsModelDbColumn() && !lhsResolver.IsContextDbColumn() && !lhsResolver.IsComputedFromDatabase()
 	}
 
 	rhsResolver := NewOperandResolver(ctx, schema, model, action, condition.RHS)
-	referencesDatabaseColumns := lhsResolver.IsModelDbColumn() || rhsResolver.IsModelDbColumn() || lhsResolver.IsContextDbColumn() || rhsResolver.IsContextDbColumn()
+	referencesDatabaseColumns := lhsResolver.IsModelDbColumn() || rhsResolver.IsModelDbColumn() || lhsResolver.IsContextDbColumn() || rhsResolver.IsContextDbColumn() ||
+		lhsResolver.IsComputedFromDatabase() || rhsResolver.IsComputedFromDatabase()
 
 	return !(referencesDatabaseColumns)
 }
@@ -118,6 +120,20 @@ func evaluate(
 			rhs = int64(v) // todo: https://linear.app/keel/issue/RUN-98/number-type-as-int32-or-int64
 		}
 		return compareInt(lhs.(int64), rhs.(int64), operator)
+	case proto.Type_TYPE_DECIMAL:
+		l, lok := toFloat(lhs)
+		r, rok := toFloat(rhs)
+		if !lok || !rok {
+			return false, fmt.Errorf("cannot compare %T with %T", lhs, rhs)
+		}
+		return compareDecimal(l, r, operator)
+	case proto.Type_TYPE_DATE, proto.Type_TYPE_DATETIME, proto.Type_TYPE_TIMESTAMP:
+		l, lok := asTime(lhs)
+		r, rok := asTime(rhs)
+		if !lok || !rok {
+			return false, fmt.Errorf("cannot compare %T with %T", lhs, rhs)
+		}
+		return compareTime(l, r, operator)
 	case proto.Type_TYPE_BOOL:
 		return compareBool(lhs.(bool), rhs.(bool), operator)
 	case proto.Type_TYPE_ENUM:
@@ -167,6 +183,52 @@ func compareInt(
 	}
 }
 
+func compareDecimal(
+	lhs float64,
+	rhs float64,
+	operator *parser.Operator,
+) (bool, error) {
+	switch operator.Symbol {
+	case parser.OperatorEquals:
+		return lhs == rhs, nil
+	case parser.OperatorNotEquals:
+		return lhs != rhs, nil
+	case parser.OperatorGreaterThan:
+		return lhs > rhs, nil
+	case parser.OperatorGreaterThanOrEqualTo:
+		return lhs >= rhs, nil
+	case parser.OperatorLessThan:
+		return lhs < rhs, nil
+	case parser.OperatorLessThanOrEqualTo:
+		return lhs <= rhs, nil
+	default:
+		return false, fmt.Errorf("operator: %s, not supported for type: %s", operator.Symbol, proto.Type_TYPE_DECIMAL)
+	}
+}
+
+func compareTime(
+	lhs time.Time,
+	rhs time.Time,
+	operator *parser.Operator,
+) (bool, error) {
+	switch operator.Symbol {
+	case parser.OperatorEquals:
+		return lhs.Equal(rhs), nil
+	case parser.OperatorNotEquals:
+		return !lhs.Equal(rhs), nil
+	case parser.OperatorGreaterThan:
+		return lhs.After(rhs), nil
+	case parser.OperatorGreaterThanOrEqualTo:
+		return !lhs.Before(rhs), nil
+	case parser.OperatorLessThan:
+		return lhs.Before(rhs), nil
+	case parser.OperatorLessThanOrEqualTo:
+		return !lhs.After(rhs), nil
+	default:
+		return false, fmt.Errorf("operator: %s, not supported for type: %s", operator.Symbol, proto.Type_TYPE_TIMESTAMP)
+	}
+}
+
 func compareBool(
 	lhs bool,
 	rhs bool,
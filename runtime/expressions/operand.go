@@ -96,6 +96,10 @@ func (resolver *OperandResolver) NormalisedFragments() ([]string, error) {
 // For example, a number or string literal written straight into the Keel schema,
 // such as the right-hand side operand in @where(person.age > 21).
 func (resolver *OperandResolver) IsLiteral() bool {
+	if resolver.IsComputed() {
+		return false
+	}
+
 	// Check if literal or array of literals, such as a "keel" or ["keel", "weave"]
 	isLiteral, _ := resolver.operand.IsLiteralType()
 	if isLiteral {
@@ -118,7 +122,7 @@ func (resolver *OperandResolver) IsLiteral() bool {
 		// Check if an array of enums, such as [Sport.Cricket, Sport.Rugby]
 		isEnumLiteralArray := true
 		for _, item := range resolver.operand.Array.Values {
-			if !proto.EnumExists(resolver.Schema.Enums, item.Ident.Fragments[0].Fragment) {
+			if item.Ident == nil || !proto.EnumExists(resolver.Schema.Enums, item.Ident.Fragments[0].Fragment) {
 				isEnumLiteralArray = false
 			}
 		}
@@ -134,7 +138,7 @@ func (resolver *OperandResolver) IsLiteral() bool {
 // For example, an input value provided in a create action might require validation,
 // such as: create createThing() with (name) @validation(name != "")
 func (resolver *OperandResolver) IsImplicitInput() bool {
-	isSingleFragment := resolver.operand.Ident != nil && len(resolver.operand.Ident.Fragments) == 1
+	isSingleFragment := !resolver.IsComputed() && resolver.operand.Ident != nil && len(resolver.operand.Ident.Fragments) == 1
 
 	if !isSingleFragment {
 		return false
@@ -164,7 +168,7 @@ func (resolver *OperandResolver) IsImplicitInput() bool {
 // For example, a where condition might use an explicit input,
 // such as: list listThings(isActive: Boolean) @where(thing.isActive == isActive)
 func (resolver *OperandResolver) IsExplicitInput() bool {
-	isSingleFragmentIdent := resolver.operand.Ident != nil && len(resolver.operand.Ident.Fragments) == 1
+	isSingleFragmentIdent := !resolver.IsComputed() && resolver.operand.Ident != nil && len(resolver.operand.Ident.Fragments) == 1
 
 	if !isSingleFragmentIdent {
 		return false
@@ -194,7 +198,8 @@ func (resolver *OperandResolver) IsExplicitInput() bool {
 // For example, a where condition might filter on reading data,
 // such as: @where(post.author.isActive)
 func (resolver *OperandResolver) IsModelDbColumn() bool {
-	return !resolver.IsLiteral() &&
+	return !resolver.IsComputed() &&
+		!resolver.IsLiteral() &&
 		!resolver.IsContext() &&
 		!resolver.IsExplicitInput() &&
 		!resolver.IsImplicitInput()
@@ -204,7 +209,7 @@ func (resolver *OperandResolver) IsModelDbColumn() bool {
 // which will require database access (such as with identity backlinks),
 // such as: @permission(expression: ctx.identity.user.isActive)
 func (resolver *OperandResolver) IsContextDbColumn() bool {
-	return resolver.operand.Ident.IsContextIdentity() && !resolver.operand.Ident.IsContextIdentityId()
+	return !resolver.IsComputed() && resolver.operand.Ident.IsContextIdentity() && !resolver.operand.Ident.IsContextIdentityId()
 }
 
 // IsContextField returns true if the expression operand refers to a value on the context
@@ -222,7 +227,7 @@ func (resolver *OperandResolver) IsContextField() bool {
 }
 
 func (resolver *OperandResolver) IsContext() bool {
-	return resolver.operand.Ident.IsContext()
+	return !resolver.IsComputed() && resolver.operand.Ident.IsContext()
 }
 
 // GetOperandType returns the equivalent protobuf type for the expression operand and whether it is an array or not
@@ -232,9 +237,15 @@ func (resolver *OperandResolver) GetOperandType() (proto.Type, bool, error) {
 	schema := resolver.Schema
 
 	switch {
+	case resolver.IsComputed():
+		t, err := resolver.computedType()
+		return t, false, err
 	case resolver.IsLiteral():
 		if operand.Ident == nil {
 			switch {
+			case operand.Duration != nil:
+				// Durations only exist within arithmetic operations on dates and timestamps
+				return proto.Type_TYPE_UNKNOWN, false, nil
 			case operand.String != nil:
 				return proto.Type_TYPE_STRING, false, nil
 			case operand.Number != nil:
@@ -297,13 +308,13 @@ func (resolver *OperandResolver) GetOperandType() (proto.Type, bool, error) {
 		inputName := operand.Ident.Fragments[0].Fragment
 		var field *proto.MessageField
 		switch action.Type {
-		case proto.ActionType_ACTION_TYPE_CREATE:
+		case proto.ActionType_ACTION_TYPE_CREATE, proto.ActionType_ACTION_TYPE_CREATE_MANY:
 			message := proto.FindValuesInputMessage(schema, action.Name)
 			field = message.FindField(inputName)
-		case proto.ActionType_ACTION_TYPE_GET, proto.ActionType_ACTION_TYPE_LIST, proto.ActionType_ACTION_TYPE_DELETE:
+		case proto.ActionType_ACTION_TYPE_GET, proto.ActionType_ACTION_TYPE_LIST, proto.ActionType_ACTION_TYPE_DELETE, proto.ActionType_ACTION_TYPE_DELETE_MANY:
 			message := proto.FindWhereInputMessage(schema, action.Name)
 			field = message.FindField(inputName)
-		case proto.ActionType_ACTION_TYPE_UPDATE:
+		case proto.ActionType_ACTION_TYPE_UPDATE, proto.ActionType_ACTION_TYPE_UPDATE_MANY:
 			message := proto.FindValuesInputMessage(schema, action.Name)
 			field = message.FindField(inputName)
 			if field == nil {
@@ -341,6 +352,8 @@ func (resolver *OperandResolver) ResolveValue(args map[string]any) (any, error)
 	}
 
 	switch {
+	case resolver.IsComputed():
+		return resolver.computedValue(args)
 	case resolver.IsLiteral():
 		isLiteral, _ := resolver.operand.IsLiteralType()
 		if isLiteral {
@@ -439,6 +452,8 @@ func toNative(v *parser.Operand, fieldType proto.Type) (any, error) {
 	}
 
 	switch {
+	case v.Duration != nil:
+		return v.Duration, nil
 	case v.False:
 		return false, nil
 	case v.True:
@@ -0,0 +1,86 @@
+// Package sqlgen generates the SQL for the functions and arithmetic operations available in expressions,
+// so that permission rules and the expressions evaluated by the runtime produce the same SQL.
+package sqlgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// The SQL templates for each of the functions available in expressions. Functions which result
+// in a Number are cast to integers.
+var functions = map[string]string{
+	parser.FunctionLower:  "lower(%s)",
+	parser.FunctionUpper:  "upper(%s)",
+	parser.FunctionTrim:   "trim(%s)",
+	parser.FunctionLength: "char_length(%s)",
+	parser.FunctionAbs:    "abs(%s)",
+	parser.FunctionRound:  "round(%s)::INTEGER",
+	parser.FunctionFloor:  "floor(%s)::INTEGER",
+	parser.FunctionCeil:   "ceil(%s)::INTEGER",
+	parser.FunctionYear:   "EXTRACT(YEAR FROM %s)::INTEGER",
+	parser.FunctionMonth:  "EXTRACT(MONTH FROM %s)::INTEGER",
+	parser.FunctionDay:    "EXTRACT(DAY FROM %s)::INTEGER",
+}
+
+// Function returns the SQL template for calling the function with the given number of arguments,
+// with a %s verb for each argument.
+func Function(name string, argCount int) (string, error) {
+	if name == parser.FunctionCoalesce {
+		return fmt.Sprintf("COALESCE(%s)", strings.TrimSuffix(strings.Repeat("%s, ", argCount), ", ")), nil
+	}
+
+	template, ok := functions[name]
+	if !ok {
+		return "", fmt.Errorf("function %s is not supported", name)
+	}
+
+	return template, nil
+}
+
+// FunctionType returns the type resulting from the function, where argType is the type of its first argument.
+func FunctionType(name string, argType proto.Type) (proto.Type, error) {
+	switch name {
+	case parser.FunctionLower, parser.FunctionUpper, parser.FunctionTrim:
+		return proto.Type_TYPE_STRING, nil
+	case parser.FunctionLength, parser.FunctionRound, parser.FunctionFloor, parser.FunctionCeil,
+		parser.FunctionYear, parser.FunctionMonth, parser.FunctionDay:
+		return proto.Type_TYPE_INT, nil
+	case parser.FunctionAbs, parser.FunctionCoalesce:
+		return argType, nil
+	default:
+		return proto.Type_TYPE_UNKNOWN, fmt.Errorf("unknown function %s", name)
+	}
+}
+
+// Arithmetic returns the SQL template for an arithmetic operation which results in the type t,
+// with %s verbs for the left and right hand sides.
+func Arithmetic(operator string, t proto.Type) string {
+	switch {
+	case t == proto.Type_TYPE_STRING:
+		return "(%s || %s)"
+	case operator == parser.OperatorModulo:
+		return "mod(%s, %s)"
+	case t == proto.Type_TYPE_DATE:
+		// Adding an interval to a date results in a timestamp
+		return fmt.Sprintf("(%%s %s %%s)::DATE", operator)
+	default:
+		return fmt.Sprintf("(%%s %s %%s)", operator)
+	}
+}
+
+// ArithmeticType returns the type resulting from an arithmetic operation between two types. A duration is added to or
+// subtracted from a date or timestamp, and has an unknown type.
+func ArithmeticType(lhs proto.Type, rhs proto.Type) proto.Type {
+	switch {
+	case lhs == proto.Type_TYPE_DECIMAL || rhs == proto.Type_TYPE_DECIMAL:
+		return proto.Type_TYPE_DECIMAL
+	case lhs == proto.Type_TYPE_MARKDOWN:
+		return proto.Type_TYPE_STRING
+	default:
+		return lhs
+	}
+}
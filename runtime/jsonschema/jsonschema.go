@@ -36,6 +36,20 @@ var (
 			},
 		},
 	}
+
+	bulkResponseSchema = JSONSchema{
+		Type: "object",
+		Properties: map[string]JSONSchema{
+			"ids": {
+				Type:  "array",
+				Items: &JSONSchema{Type: "string"},
+			},
+			"count": {
+				Type: "number",
+			},
+		},
+		Required: []string{"ids", "count"},
+	}
 )
 
 type JSONSchema struct {
@@ -160,6 +174,10 @@ func JSONSchemaForActionResponse(ctx context.Context, schema *proto.Schema, acti
 		return JSONSchema{
 			Type: "string",
 		}
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY, proto.ActionType_ACTION_TYPE_UPDATE_MANY, proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		// ids and count of the records written
+
+		return bulkResponseSchema
 	default:
 		return JSONSchema{}
 	}
@@ -496,6 +514,9 @@ func jsonSchemaForField(ctx context.Context, schema *proto.Schema, action *proto
 	case proto.Type_TYPE_DECIMAL:
 		prop.Type = "number"
 		prop.Format = "float"
+	case proto.Type_TYPE_VECTOR:
+		prop.Type = "array"
+		prop.Items = &JSONSchema{Type: "number", Format: "float"}
 	case proto.Type_TYPE_MODEL:
 		model := schema.FindModel(t.ModelName.Value)
 
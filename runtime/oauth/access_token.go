@@ -2,6 +2,7 @@ package oauth
 
 import (
 	"context"
+	"crypto/rsa"
 	"errors"
 	"fmt"
 	"time"
@@ -89,6 +90,8 @@ func generateToken(ctx context.Context, sub string, aud []string, expiresIn time
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyId(&privateKey.PublicKey)
+
 	tokenString, err := token.SignedString(privateKey)
 	if err != nil {
 		return "", fmt.Errorf("cannot create signed jwt: %w", err)
@@ -100,21 +103,18 @@ func validateToken(ctx context.Context, tokenString string, audienceClaim string
 	ctx, span := tracer.Start(ctx, "Validate access token")
 	defer span.End()
 
-	privateKey, err := runtimectx.GetPrivateKey(ctx)
+	privateKeys, err := runtimectx.GetPrivateKeys(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	if privateKey == nil {
+	if len(privateKeys) == 0 {
 		return "", errors.New("no private key set")
 	}
 
-	var token *jwt.Token
 	claims := &AccessTokenClaims{}
 
-	token, err = jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
-		return &privateKey.PublicKey, nil
-	})
+	token, err := parseWithKeys(tokenString, claims, privateKeys)
 
 	var validationErr *jwt.ValidationError
 	if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
@@ -149,3 +149,32 @@ func validateToken(ctx context.Context, tokenString string, audienceClaim string
 
 	return claims.Subject, nil
 }
+
+var errKeyMismatch = errors.New("token was not signed with this key")
+
+// parseWithKeys parses and verifies the token with the key identified by its kid header. Tokens issued
+// before key ids were introduced have no kid, and so each key is tried in turn.
+func parseWithKeys(tokenString string, claims *AccessTokenClaims, privateKeys []*rsa.PrivateKey) (*jwt.Token, error) {
+	var token *jwt.Token
+	var err error
+
+	for _, privateKey := range privateKeys {
+		publicKey := &privateKey.PublicKey
+
+		token, err = jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+			if kid, ok := t.Header["kid"].(string); ok && kid != KeyId(publicKey) {
+				return nil, errKeyMismatch
+			}
+			return publicKey, nil
+		})
+
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && (errors.Is(validationErr.Inner, errKeyMismatch) || validationErr.Errors&jwt.ValidationErrorSignatureInvalid != 0) {
+			continue
+		}
+
+		return token, err
+	}
+
+	return token, err
+}
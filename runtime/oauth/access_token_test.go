@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
 	"testing"
 	"time"
 
@@ -357,3 +359,97 @@ func TestResetTokenMissingAudIsInvalid(t *testing.T) {
 	require.ErrorIs(t, oauth.ErrInvalidToken, err)
 	require.Empty(t, parsedId)
 }
+
+func TestAccessTokenHasKeyId(t *testing.T) {
+	ctx := newContextWithPK()
+	pk, err := runtimectx.GetPrivateKey(ctx)
+	require.NoError(t, err)
+
+	jwtToken, _, err := oauth.GenerateAccessToken(ctx, ksuid.New().String())
+	require.NoError(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(jwtToken, &oauth.AccessTokenClaims{})
+	require.NoError(t, err)
+	require.Equal(t, oauth.KeyId(&pk.PublicKey), token.Header["kid"])
+}
+
+func TestAccessTokenValidWithPreviousKeyDuringRotation(t *testing.T) {
+	identityId := ksuid.New()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ctx := runtimectx.WithPrivateKey(context.Background(), oldKey)
+	oldToken, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	require.NoError(t, err)
+
+	ctx = runtimectx.WithPrivateKeys(context.Background(), []*rsa.PrivateKey{newKey, oldKey})
+	newToken, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	require.NoError(t, err)
+
+	parsedId, err := oauth.ValidateAccessToken(ctx, oldToken)
+	require.NoError(t, err)
+	require.Equal(t, identityId.String(), parsedId)
+
+	parsedId, err = oauth.ValidateAccessToken(ctx, newToken)
+	require.NoError(t, err)
+	require.Equal(t, identityId.String(), parsedId)
+
+	// Once the old key is removed, its tokens are no longer valid
+	ctx = runtimectx.WithPrivateKeys(context.Background(), []*rsa.PrivateKey{newKey})
+	_, err = oauth.ValidateAccessToken(ctx, oldToken)
+	require.ErrorIs(t, oauth.ErrInvalidToken, err)
+}
+
+func TestAccessTokenWithoutKeyIdValidWithPreviousKey(t *testing.T) {
+	identityId := ksuid.New()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	claims := oauth.AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   identityId.String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    oauth.KeelIssuer,
+		},
+	}
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(oldKey)
+	require.NoError(t, err)
+
+	ctx := runtimectx.WithPrivateKeys(context.Background(), []*rsa.PrivateKey{newKey, oldKey})
+	parsedId, err := oauth.ValidateAccessToken(ctx, legacyToken)
+	require.NoError(t, err)
+	require.Equal(t, identityId.String(), parsedId)
+}
+
+func TestJSONWebKeySet(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := oauth.NewJSONWebKeySet([]*rsa.PrivateKey{key1, key2})
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, oauth.KeyId(&key1.PublicKey), jwks.Keys[0].KeyId)
+	require.Equal(t, oauth.KeyId(&key2.PublicKey), jwks.Keys[1].KeyId)
+	require.NotEqual(t, jwks.Keys[0].KeyId, jwks.Keys[1].KeyId)
+	require.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	require.Equal(t, "RS256", jwks.Keys[0].Algorithm)
+	require.Equal(t, "AQAB", jwks.Keys[0].Exponent)
+}
+
+func TestKeyIdIsJWKThumbprint(t *testing.T) {
+	// The example key from https://www.rfc-editor.org/rfc/rfc7638#section-3.1
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	require.NoError(t, err)
+
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+	require.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", oauth.KeyId(publicKey))
+}
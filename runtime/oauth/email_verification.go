@@ -0,0 +1,113 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/teamkeel/keel/db"
+)
+
+const (
+	// Character length of crypo-generated email verification token
+	emailVerificationTokenLength = 32
+	emailVerificationTokenExpiry = time.Duration(24) * time.Hour
+)
+
+// NewEmailVerificationToken generates a new single-use token which verifies the identity's email address,
+// replacing any token previously issued for the identity. The token is only valid for the email address
+// it was sent to, so that it cannot verify an address the identity has since changed to.
+func NewEmailVerificationToken(ctx context.Context, identityId string, email string) (string, error) {
+	ctx, span := tracer.Start(ctx, "New Email Verification Token")
+	defer span.End()
+
+	if identityId == "" {
+		return "", errors.New("identity ID cannot be empty when generating new email verification token")
+	}
+
+	token := uniuri.NewLen(emailVerificationTokenLength)
+	hash, err := hashToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(emailVerificationTokenExpiry)
+
+	sql := `
+		INSERT INTO
+			keel_email_verification (identity_id, token, email, expires_at, created_at)
+		VALUES
+			(?, ?, ?, ?, ?)
+		ON CONFLICT (identity_id) DO UPDATE SET
+			token = EXCLUDED.token,
+			email = EXCLUDED.email,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at`
+
+	db := database.GetDB().Exec(sql, identityId, hash, email, expiresAt, now)
+	if db.Error != nil {
+		return "", db.Error
+	}
+
+	if db.RowsAffected != 1 {
+		return "", errors.New("failed to insert email verification token into database")
+	}
+
+	return token, nil
+}
+
+// ConsumeEmailVerificationToken checks that the token has not expired and, if so, consumes it and
+// returns the identity and email address it was issued for.
+func ConsumeEmailVerificationToken(ctx context.Context, token string) (isValid bool, identityId string, email string, err error) {
+	ctx, span := tracer.Start(ctx, "Consume Email Verification Token")
+	defer span.End()
+
+	tokenHash, err := hashToken(token)
+	if err != nil {
+		return false, "", "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, "", "", err
+	}
+
+	sql := `
+		DELETE FROM
+			keel_email_verification
+		WHERE
+			token = ? AND
+			expires_at >= now()
+		RETURNING
+			identity_id, email`
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw(sql, tokenHash).Scan(&rows).Error
+	if err != nil {
+		return false, "", "", err
+	}
+
+	// There was no token found, and thus it is not valid
+	if len(rows) != 1 {
+		return false, "", "", nil
+	}
+
+	identityId, ok := rows[0]["identity_id"].(string)
+	if !ok {
+		return false, "", "", errors.New("could not parse identity_id from database result")
+	}
+
+	email, ok = rows[0]["email"].(string)
+	if !ok {
+		return false, "", "", errors.New("could not parse email from database result")
+	}
+
+	return true, identityId, email, nil
+}
@@ -0,0 +1,91 @@
+package oauth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JSONWebKey is the public part of a signing key, as published in a JWKS.
+// https://www.rfc-editor.org/rfc/rfc7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JSONWebKeySet is the response of the JWKS endpoint.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeyId generates the kid of a key, which is its JWK thumbprint and so does not need to be configured.
+// https://www.rfc-editor.org/rfc/rfc7638
+func KeyId(publicKey *rsa.PublicKey) string {
+	n, e := encodePublicKey(publicKey)
+
+	// The members must be in lexicographic order with no whitespace
+	thumbprint := sha256.Sum256([]byte(fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, e, n)))
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:])
+}
+
+// NewJSONWebKeySet creates the JWKS of the public keys for the given private keys.
+func NewJSONWebKeySet(privateKeys []*rsa.PrivateKey) JSONWebKeySet {
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for _, privateKey := range privateKeys {
+		n, e := encodePublicKey(&privateKey.PublicKey)
+		jwks.Keys = append(jwks.Keys, JSONWebKey{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: "RS256",
+			KeyId:     KeyId(&privateKey.PublicKey),
+			Modulus:   n,
+			Exponent:  e,
+		})
+	}
+
+	return jwks
+}
+
+// ParsePrivateKeys parses one or more PEM encoded RSA private keys. The first key signs new tokens, and any
+// which follow are previous keys which are still accepted, so that a key can be rotated by adding a new key
+// to the start and removing the old one once the tokens it has signed have expired.
+func ParsePrivateKeys(data []byte) ([]*rsa.PrivateKey, error) {
+	privateKeys := []*rsa.PrivateKey{}
+
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = rest
+
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		privateKeys = append(privateKeys, privateKey)
+	}
+
+	if len(privateKeys) == 0 {
+		return nil, errors.New("private key PEM either invalid or empty")
+	}
+
+	return privateKeys, nil
+}
+
+func encodePublicKey(publicKey *rsa.PublicKey) (string, string) {
+	n := base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	return n, e
+}
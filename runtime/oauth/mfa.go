@@ -0,0 +1,397 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/teamkeel/keel/db"
+	"gorm.io/gorm"
+)
+
+const (
+	// Character length of crypo-generated MFA challenge token
+	mfaChallengeLength = 32
+	mfaChallengeExpiry = time.Duration(5) * time.Minute
+	// The number of incorrect codes which can be attempted before the challenge is no longer valid
+	mfaChallengeMaxAttempts = 5
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var recoveryCodeChars = []byte("abcdefghijkmnpqrstuvwxyz23456789")
+
+var ErrMfaAlreadyEnabled = errors.New("multi-factor authentication is already enabled for this identity")
+
+type MfaEnrolment struct {
+	// The TOTP secret encoded as base32
+	Secret string
+	// Single-use codes which can be used in place of a TOTP code if the authenticator is lost
+	RecoveryCodes []string
+}
+
+// EnrolMfa generates a new TOTP secret and recovery codes for the identity. MFA is not enabled
+// until the enrolment is confirmed with a code from the authenticator. Enrolling again before
+// confirming replaces the secret and recovery codes.
+func EnrolMfa(ctx context.Context, identityId string) (*MfaEnrolment, error) {
+	ctx, span := tracer.Start(ctx, "Enrol MFA")
+	defer span.End()
+
+	if identityId == "" {
+		return nil, errors.New("identity ID cannot be empty when enrolling MFA")
+	}
+
+	secret, err := NewTotpSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolment := &MfaEnrolment{
+		Secret: secret,
+	}
+
+	err = database.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sql := `
+			INSERT INTO
+				keel_mfa (identity_id, secret, created_at)
+			VALUES
+				(?, ?, now())
+			ON CONFLICT (identity_id) DO UPDATE SET
+				secret = EXCLUDED.secret,
+				last_used_step = NULL,
+				created_at = EXCLUDED.created_at
+			WHERE
+				keel_mfa.enabled_at IS NULL`
+
+		result := tx.Exec(sql, identityId, secret)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != 1 {
+			return ErrMfaAlreadyEnabled
+		}
+
+		err := tx.Exec("DELETE FROM keel_mfa_recovery_code WHERE identity_id = ?", identityId).Error
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < recoveryCodeCount; i++ {
+			code := uniuri.NewLenChars(recoveryCodeLength, recoveryCodeChars)
+			hash, err := hashToken(code)
+			if err != nil {
+				return err
+			}
+
+			err = tx.Exec("INSERT INTO keel_mfa_recovery_code (code, identity_id, created_at) VALUES (?, ?, now())", hash, identityId).Error
+			if err != nil {
+				return err
+			}
+
+			enrolment.RecoveryCodes = append(enrolment.RecoveryCodes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enrolment, nil
+}
+
+// ConfirmMfa enables MFA for the identity if the code is valid for the secret generated on enrolment.
+func ConfirmMfa(ctx context.Context, identityId string, code string) (isValid bool, err error) {
+	ctx, span := tracer.Start(ctx, "Confirm MFA")
+	defer span.End()
+
+	return verifyTotpCode(ctx, identityId, code, false)
+}
+
+// DisableMfa removes the identity's MFA secret and recovery codes if the code, which may also be a recovery code, is valid.
+func DisableMfa(ctx context.Context, identityId string, code string) (isValid bool, err error) {
+	ctx, span := tracer.Start(ctx, "Disable MFA")
+	defer span.End()
+
+	isValid, err = verifyMfaCode(ctx, identityId, code)
+	if err != nil || !isValid {
+		return false, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	err = database.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM keel_mfa WHERE identity_id = ?", identityId).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec("DELETE FROM keel_mfa_recovery_code WHERE identity_id = ?", identityId).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsMfaEnabled determines if the identity has confirmed an MFA enrolment.
+func IsMfaEnabled(ctx context.Context, identityId string) (bool, error) {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw("SELECT identity_id FROM keel_mfa WHERE identity_id = ? AND enabled_at IS NOT NULL", identityId).Scan(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	return len(rows) == 1, nil
+}
+
+// NewMfaChallenge generates a single-use token for the identity which, along with a valid code,
+// can be exchanged for tokens once the identity's password has already been verified.
+func NewMfaChallenge(ctx context.Context, identityId string) (string, error) {
+	ctx, span := tracer.Start(ctx, "New MFA Challenge")
+	defer span.End()
+
+	if identityId == "" {
+		return "", errors.New("identity ID cannot be empty when generating new MFA challenge")
+	}
+
+	token := uniuri.NewLen(mfaChallengeLength)
+	hash, err := hashToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(mfaChallengeExpiry)
+
+	sql := `
+		INSERT INTO
+			keel_mfa_challenge (token, identity_id, expires_at, created_at)
+		VALUES
+			(?, ?, ?, ?)`
+
+	db := database.GetDB().Exec(sql, hash, identityId, expiresAt, now)
+	if db.Error != nil {
+		return "", db.Error
+	}
+
+	if db.RowsAffected != 1 {
+		return "", errors.New("failed to insert MFA challenge into database")
+	}
+
+	return token, nil
+}
+
+// MfaChallengeIdentity returns the ID of the identity which the challenge was issued to, or an empty string if
+// the challenge does not exist, has expired or its attempts are exhausted.
+func MfaChallengeIdentity(ctx context.Context, token string) (string, error) {
+	ctx, span := tracer.Start(ctx, "MFA Challenge Identity")
+	defer span.End()
+
+	tokenHash, err := hashToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sql := `
+		SELECT
+			identity_id
+		FROM
+			keel_mfa_challenge
+		WHERE
+			token = ? AND
+			expires_at >= now() AND
+			attempts < ?`
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw(sql, tokenHash, mfaChallengeMaxAttempts).Scan(&rows).Error
+	if err != nil {
+		return "", err
+	}
+
+	if len(rows) != 1 {
+		return "", nil
+	}
+
+	identityId, ok := rows[0]["identity_id"].(string)
+	if !ok {
+		return "", errors.New("could not parse identity_id from database result")
+	}
+
+	return identityId, nil
+}
+
+// ConsumeMfaChallenge checks that the challenge has not expired and that the code, which may also be a recovery
+// code, is valid for the identity. If so, the challenge is consumed and the identity it is associated with is returned.
+// Each incorrect code counts as an attempt, and the challenge is no longer valid once the attempts are exhausted.
+func ConsumeMfaChallenge(ctx context.Context, token string, code string) (isValid bool, identityId string, err error) {
+	ctx, span := tracer.Start(ctx, "Consume MFA Challenge")
+	defer span.End()
+
+	tokenHash, err := hashToken(token)
+	if err != nil {
+		return false, "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, "", err
+	}
+
+	sql := `
+		UPDATE
+			keel_mfa_challenge
+		SET
+			attempts = attempts + 1
+		WHERE
+			token = ? AND
+			expires_at >= now() AND
+			attempts < ?
+		RETURNING
+			identity_id`
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw(sql, tokenHash, mfaChallengeMaxAttempts).Scan(&rows).Error
+	if err != nil {
+		return false, "", err
+	}
+
+	// There was no challenge found, and thus it is not valid
+	if len(rows) != 1 {
+		return false, "", nil
+	}
+
+	identityId, ok := rows[0]["identity_id"].(string)
+	if !ok {
+		return false, "", errors.New("could not parse identity_id from database result")
+	}
+
+	isValid, err = verifyMfaCode(ctx, identityId, code)
+	if err != nil || !isValid {
+		return false, "", err
+	}
+
+	err = database.GetDB().WithContext(ctx).Exec("DELETE FROM keel_mfa_challenge WHERE token = ?", tokenHash).Error
+	if err != nil {
+		return false, "", err
+	}
+
+	return true, identityId, nil
+}
+
+// verifyMfaCode checks the code against the identity's enabled TOTP secret, or otherwise consumes it as a recovery code.
+func verifyMfaCode(ctx context.Context, identityId string, code string) (bool, error) {
+	isValid, err := verifyTotpCode(ctx, identityId, code, true)
+	if err != nil || isValid {
+		return isValid, err
+	}
+
+	hash, err := hashToken(normaliseRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sql := `
+		DELETE FROM
+			keel_mfa_recovery_code
+		WHERE
+			code = ? AND
+			identity_id = ? AND
+			identity_id IN (SELECT identity_id FROM keel_mfa WHERE enabled_at IS NOT NULL)
+		RETURNING
+			code`
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw(sql, hash, identityId).Scan(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	return len(rows) == 1, nil
+}
+
+// verifyTotpCode checks the code against the identity's TOTP secret, which is enabled or pending confirmation.
+// A code cannot be used more than once, nor can a code older than one already used.
+func verifyTotpCode(ctx context.Context, identityId string, code string, enabled bool) (bool, error) {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw("SELECT secret FROM keel_mfa WHERE identity_id = ? AND (enabled_at IS NOT NULL) = ?", identityId, enabled).Scan(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	if len(rows) != 1 {
+		return false, nil
+	}
+
+	secret, ok := rows[0]["secret"].(string)
+	if !ok {
+		return false, errors.New("could not parse secret from database result")
+	}
+
+	isValid, step, err := ValidateTotpCode(secret, code, time.Now())
+	if err != nil || !isValid {
+		return false, err
+	}
+
+	// Record the step of the code so that it cannot be replayed, which also enables MFA on confirmation.
+	sql := `
+		UPDATE
+			keel_mfa
+		SET
+			last_used_step = ?,
+			enabled_at = COALESCE(enabled_at, now())
+		WHERE
+			identity_id = ? AND
+			(last_used_step IS NULL OR last_used_step < ?)`
+
+	result := database.GetDB().WithContext(ctx).Exec(sql, step, identityId, step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func normaliseRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
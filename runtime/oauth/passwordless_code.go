@@ -0,0 +1,135 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/teamkeel/keel/db"
+)
+
+const (
+	// Character length of crypo-generated passwordless sign-in code
+	passwordlessCodeLength = 6
+	passwordlessCodeExpiry = time.Duration(15) * time.Minute
+	// The number of incorrect codes which can be attempted before the code is no longer valid
+	passwordlessCodeMaxAttempts = 5
+)
+
+var passwordlessCodeChars = []byte("0123456789")
+
+// NewPasswordlessCode generates a new single-use sign-in code for the email address, replacing any
+// code previously issued for it. The code is short enough to be typed in, and so it is only valid
+// for the email address it was issued to and for a limited number of attempts. A code which replaces
+// one that hasn't yet expired keeps its attempts and expiry, so that requesting another code doesn't
+// allow any more guesses.
+func NewPasswordlessCode(ctx context.Context, email string) (string, error) {
+	ctx, span := tracer.Start(ctx, "New Passwordless Code")
+	defer span.End()
+
+	if email == "" {
+		return "", errors.New("email cannot be empty when generating new passwordless code")
+	}
+
+	code := uniuri.NewLenChars(passwordlessCodeLength, passwordlessCodeChars)
+	hash, err := hashPasswordlessCode(email, code)
+	if err != nil {
+		return "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(passwordlessCodeExpiry)
+
+	sql := `
+		INSERT INTO
+			keel_passwordless_code (email, code, expires_at, created_at)
+		VALUES
+			(?, ?, ?, ?)
+		ON CONFLICT (email) DO UPDATE SET
+			code = EXCLUDED.code,
+			attempts = CASE WHEN keel_passwordless_code.expires_at < now() THEN 0 ELSE keel_passwordless_code.attempts END,
+			expires_at = CASE WHEN keel_passwordless_code.expires_at < now() THEN EXCLUDED.expires_at ELSE keel_passwordless_code.expires_at END,
+			created_at = EXCLUDED.created_at`
+
+	db := database.GetDB().Exec(sql, email, hash, expiresAt, now)
+	if db.Error != nil {
+		return "", db.Error
+	}
+
+	if db.RowsAffected != 1 {
+		return "", errors.New("failed to insert passwordless code into database")
+	}
+
+	return code, nil
+}
+
+// ConsumePasswordlessCode checks that the code was issued to the email address and has not expired.
+// If so, the code is consumed so that it cannot be used again. Each incorrect code counts as an attempt,
+// and the code is no longer valid once the attempts are exhausted.
+func ConsumePasswordlessCode(ctx context.Context, email string, code string) (isValid bool, err error) {
+	ctx, span := tracer.Start(ctx, "Consume Passwordless Code")
+	defer span.End()
+
+	hash, err := hashPasswordlessCode(email, code)
+	if err != nil {
+		return false, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sql := `
+		UPDATE
+			keel_passwordless_code
+		SET
+			attempts = attempts + 1
+		WHERE
+			email = ? AND
+			expires_at >= now() AND
+			attempts < ?
+		RETURNING
+			code`
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw(sql, email, passwordlessCodeMaxAttempts).Scan(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	// There was no code found, and thus it is not valid
+	if len(rows) != 1 {
+		return false, nil
+	}
+
+	stored, ok := rows[0]["code"].(string)
+	if !ok {
+		return false, errors.New("could not parse code from database result")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) != 1 {
+		return false, nil
+	}
+
+	// Deleting on the code as well ensures that only one of any concurrent requests can consume it
+	rows = []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw("DELETE FROM keel_passwordless_code WHERE email = ? AND code = ? RETURNING email", email, hash).Scan(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	return len(rows) == 1, nil
+}
+
+// hashPasswordlessCode hashes the code along with the email address as there are few possible codes.
+func hashPasswordlessCode(email string, code string) (string, error) {
+	return hashToken(email + ":" + code)
+}
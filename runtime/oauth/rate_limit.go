@@ -0,0 +1,229 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// The actions which are rate limited.
+const (
+	RateLimitPassword         = "password"
+	RateLimitPasswordReset    = "password_reset"
+	RateLimitPasswordless     = "passwordless"
+	RateLimitPasswordlessCode = "passwordless_code"
+	// The codes of the MFA grant, which are limited by identity ID rather than email address
+	RateLimitMfa = "mfa"
+)
+
+// rateLimit is the number of attempts allowed within the window before a lockout.
+type rateLimit struct {
+	key         string
+	maxAttempts int
+}
+
+// RateLimitLockedOut returns the time until which the identity with the email address, or the client's
+// IP address, is locked out of the action. If neither are locked out then nil is returned.
+func RateLimitLockedOut(ctx context.Context, action string, email string, clientIp string) (*time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Rate Limit Locked Out")
+	defer span.End()
+
+	limits, err := rateLimits(ctx, action, email, clientIp)
+	if err != nil || len(limits) == 0 {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, limit := range limits {
+		keys = append(keys, limit.key)
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `
+		SELECT
+			locked_until
+		FROM
+			keel_rate_limit
+		WHERE
+			key IN ? AND
+			locked_until > now()
+		ORDER BY
+			locked_until DESC
+		LIMIT 1`
+
+	rows := []map[string]any{}
+	err = database.GetDB().WithContext(ctx).Raw(sql, keys).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	lockedUntil, ok := rows[0]["locked_until"].(time.Time)
+	if !ok {
+		return nil, errors.New("could not parse locked_until from database result")
+	}
+
+	span.SetAttributes(attribute.String("auth.locked_until", lockedUntil.UTC().Format(time.RFC3339)))
+
+	return &lockedUntil, nil
+}
+
+// RecordRateLimitAttempt counts an attempt of the action by the identity with the email address and from the
+// client's IP address. If either exceed the configured attempts within the window then they are locked out,
+// and the time until which the lockout applies is returned.
+func RecordRateLimitAttempt(ctx context.Context, action string, email string, clientIp string) (*time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Record Rate Limit Attempt")
+	defer span.End()
+
+	limits, err := rateLimits(ctx, action, email, clientIp)
+	if err != nil || len(limits) == 0 {
+		return nil, err
+	}
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	window := config.RateLimitWindow().Seconds()
+	lockout := config.RateLimitLockout().Seconds()
+
+	// The count restarts if the window has passed since the first attempt counted.
+	sql := `
+		INSERT INTO
+			keel_rate_limit (key, attempts, window_started_at)
+		VALUES
+			(?, 1, now())
+		ON CONFLICT (key) DO UPDATE SET
+			attempts = CASE WHEN keel_rate_limit.window_started_at < now() - make_interval(secs => ?) THEN 1 ELSE keel_rate_limit.attempts + 1 END,
+			window_started_at = CASE WHEN keel_rate_limit.window_started_at < now() - make_interval(secs => ?) THEN now() ELSE keel_rate_limit.window_started_at END
+		RETURNING
+			attempts`
+
+	lockoutSql := `
+		UPDATE
+			keel_rate_limit
+		SET
+			attempts = 0,
+			window_started_at = now(),
+			locked_until = now() + make_interval(secs => ?)
+		WHERE
+			key = ?
+		RETURNING
+			locked_until`
+
+	var lockedUntil *time.Time
+	for _, limit := range limits {
+		rows := []map[string]any{}
+		err = database.GetDB().WithContext(ctx).Raw(sql, limit.key, window, window).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) != 1 {
+			return nil, errors.New("failed to record rate limit attempt")
+		}
+
+		attempts, ok := rows[0]["attempts"].(int64)
+		if !ok {
+			return nil, errors.New("could not parse attempts from database result")
+		}
+
+		if int(attempts) < limit.maxAttempts {
+			continue
+		}
+
+		rows = []map[string]any{}
+		err = database.GetDB().WithContext(ctx).Raw(lockoutSql, lockout, limit.key).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) != 1 {
+			return nil, errors.New("failed to lock out after exceeding the rate limit")
+		}
+
+		until, ok := rows[0]["locked_until"].(time.Time)
+		if !ok {
+			return nil, errors.New("could not parse locked_until from database result")
+		}
+
+		if lockedUntil == nil || until.After(*lockedUntil) {
+			lockedUntil = &until
+		}
+	}
+
+	if lockedUntil != nil {
+		span.SetAttributes(attribute.String("auth.locked_until", lockedUntil.UTC().Format(time.RFC3339)))
+	}
+
+	return lockedUntil, nil
+}
+
+// ResetRateLimitAttempts clears the attempts of the action by the identity with the email address,
+// such as once they have successfully signed in. Attempts from the IP address are still counted.
+func ResetRateLimitAttempts(ctx context.Context, action string, email string) error {
+	ctx, span := tracer.Start(ctx, "Reset Rate Limit Attempts")
+	defer span.End()
+
+	limits, err := rateLimits(ctx, action, email, "")
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	return database.GetDB().WithContext(ctx).Exec("DELETE FROM keel_rate_limit WHERE key = ? AND (locked_until IS NULL OR locked_until <= now())", limits[0].key).Error
+}
+
+// rateLimits returns the limits which apply to the action, or none if rate limits are disabled.
+// The keys are hashed so that email and IP addresses are not stored.
+func rateLimits(ctx context.Context, action string, email string, clientIp string) ([]*rateLimit, error) {
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.RateLimitsEnabled() {
+		return nil, nil
+	}
+
+	limits := []*rateLimit{}
+
+	if email != "" {
+		key, err := hashToken(action + ":identity:" + email)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, &rateLimit{key: key, maxAttempts: config.RateLimitMaxAttemptsPerIdentity()})
+	}
+
+	if clientIp != "" {
+		key, err := hashToken(action + ":ip:" + clientIp)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, &rateLimit{key: key, maxAttempts: config.RateLimitMaxAttemptsPerIp()})
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,73 @@
+package oauth_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/oauth"
+)
+
+// The SHA1 test vectors from RFC 6238, truncated to six digits.
+// https://datatracker.ietf.org/doc/html/rfc6238#appendix-B
+func TestTotpCodeRfcTestVectors(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := oauth.TotpCode(secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidateTotpCodeAllowsClockDrift(t *testing.T) {
+	secret, err := oauth.NewTotpSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000010, 0)
+
+	for _, offset := range []time.Duration{-30 * time.Second, 0, 30 * time.Second} {
+		code, err := oauth.TotpCode(secret, now.Add(offset))
+		require.NoError(t, err)
+
+		isValid, step, err := oauth.ValidateTotpCode(secret, code, now)
+		require.NoError(t, err)
+		require.True(t, isValid)
+		require.Equal(t, now.Add(offset).Unix()/30, step)
+	}
+
+	code, err := oauth.TotpCode(secret, now.Add(-90*time.Second))
+	require.NoError(t, err)
+
+	isValid, _, err := oauth.ValidateTotpCode(secret, code, now)
+	require.NoError(t, err)
+	require.False(t, isValid)
+
+	isValid, _, err = oauth.ValidateTotpCode(secret, "12345", now)
+	require.NoError(t, err)
+	require.False(t, isValid)
+}
+
+func TestTotpUri(t *testing.T) {
+	uri, err := url.Parse(oauth.TotpUri("myapp.keelapps.xyz", "user@example.com", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/myapp.keelapps.xyz:user@example.com", uri.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	require.Equal(t, "myapp.keelapps.xyz", uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+	require.Equal(t, "30", uri.Query().Get("period"))
+}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/actions"
 	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/apis/filesapi"
 	"github.com/teamkeel/keel/runtime/apis/graphql"
 	"github.com/teamkeel/keel/runtime/apis/httpjson"
 	"github.com/teamkeel/keel/runtime/apis/jsonrpc"
@@ -40,9 +42,11 @@ func GetVersion() string {
 func NewHttpHandler(currSchema *proto.Schema) http.Handler {
 	var apiHandler common.HandlerFunc
 	var authHandler func(http.ResponseWriter, *http.Request) common.Response
+	var filesHandler common.HandlerFunc
 	if currSchema != nil {
 		apiHandler = NewApiHandler(currSchema)
 		authHandler = NewAuthHandler(currSchema)
+		filesHandler = NewFilesHandler(currSchema)
 	}
 
 	httpHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -53,7 +57,7 @@ func NewHttpHandler(currSchema *proto.Schema) http.Handler {
 			attribute.String("runtime_version", Version),
 		)
 
-		if apiHandler == nil || authHandler == nil {
+		if apiHandler == nil || authHandler == nil || filesHandler == nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			_, _ = w.Write([]byte("cannot serve requests when handlers are not set up"))
 			return
@@ -66,6 +70,8 @@ func NewHttpHandler(currSchema *proto.Schema) http.Handler {
 		switch {
 		case strings.HasPrefix(path, "/auth"):
 			response = authHandler(w, r)
+		case path == filesapi.TicketPath || strings.HasPrefix(path, filesapi.UploadPath):
+			response = filesHandler(r)
 		default:
 			response = apiHandler(r)
 		}
@@ -97,6 +103,14 @@ func NewAuthHandler(schema *proto.Schema) func(http.ResponseWriter, *http.Reques
 	handleAuthorize := authapi.AuthorizeHandler(schema)
 	handleCallback := authapi.CallbackHandler(schema)
 	handleOpenApiRequest := authapi.OAuthOpenApiSchema()
+	handleJwks := authapi.JwksHandler()
+	handleOpenIdConfiguration := authapi.OpenIdConfigurationHandler()
+	handleMfaEnrol := authapi.MfaEnrolHandler(schema)
+	handleMfaConfirm := authapi.MfaConfirmHandler(schema)
+	handleMfaDisable := authapi.MfaDisableHandler(schema)
+	handlePasswordless := authapi.PasswordlessHandler(schema)
+	handleEmailVerification := authapi.EmailVerificationHandler(schema)
+	handleEmailVerificationConfirm := authapi.EmailVerificationConfirmHandler(schema)
 
 	return func(w http.ResponseWriter, r *http.Request) common.Response {
 		// Collect request headers and add to runtime context
@@ -105,7 +119,9 @@ func NewAuthHandler(schema *proto.Schema) func(http.ResponseWriter, *http.Reques
 		for k := range r.Header {
 			headers[k] = r.Header.Values(k)
 		}
-		r = r.WithContext(runtimectx.WithRequestHeaders(r.Context(), headers))
+		ctx := runtimectx.WithRequestHeaders(r.Context(), headers)
+		ctx = runtimectx.WithClientIp(ctx, clientIp(r))
+		r = r.WithContext(ctx)
 
 		switch {
 		case r.URL.Path == "/auth/providers":
@@ -120,6 +136,22 @@ func NewAuthHandler(schema *proto.Schema) func(http.ResponseWriter, *http.Reques
 			return handleCallback(r)
 		case r.URL.Path == "/auth/openapi.json":
 			return handleOpenApiRequest(r)
+		case r.URL.Path == authapi.JwksPath:
+			return handleJwks(r)
+		case r.URL.Path == authapi.OpenIdConfigurationPath:
+			return handleOpenIdConfiguration(r)
+		case r.URL.Path == authapi.MfaEnrolPath:
+			return handleMfaEnrol(r)
+		case r.URL.Path == authapi.MfaConfirmPath:
+			return handleMfaConfirm(r)
+		case r.URL.Path == authapi.MfaDisablePath:
+			return handleMfaDisable(r)
+		case r.URL.Path == authapi.PasswordlessPath:
+			return handlePasswordless(r)
+		case r.URL.Path == authapi.EmailVerificationPath:
+			return handleEmailVerification(r)
+		case r.URL.Path == authapi.EmailVerificationConfirmPath:
+			return handleEmailVerificationConfirm(r)
 		default:
 			return common.Response{
 				Status: http.StatusNotFound,
@@ -128,6 +160,25 @@ func NewAuthHandler(schema *proto.Schema) func(http.ResponseWriter, *http.Reques
 	}
 }
 
+// NewFilesHandler handles requests to the direct file upload endpoints
+func NewFilesHandler(schema *proto.Schema) common.HandlerFunc {
+	handleTicket := filesapi.TicketHandler(schema)
+	handleUpload := filesapi.UploadHandler()
+
+	return withRequestResponseLogging(func(r *http.Request) common.Response {
+		switch {
+		case r.URL.Path == filesapi.TicketPath:
+			return handleTicket(r)
+		case strings.HasPrefix(r.URL.Path, filesapi.UploadPath):
+			return handleUpload(r)
+		default:
+			return common.Response{
+				Status: http.StatusNotFound,
+			}
+		}
+	})
+}
+
 // NewApiHandler handles requests to the customer APIs
 func NewApiHandler(s *proto.Schema) common.HandlerFunc {
 	handlers := map[string]common.HandlerFunc{}
@@ -164,12 +215,65 @@ func NewApiHandler(s *proto.Schema) common.HandlerFunc {
 			headers[k] = r.Header.Values(k)
 		}
 		ctx = runtimectx.WithRequestHeaders(ctx, headers)
+		ctx = runtimectx.WithClientIp(ctx, clientIp(r))
 		r = r.WithContext(ctx)
 
 		return handler(r)
 	})
 }
 
+// clientIp returns the IP address of the client which made the request. This is the address which connected to the
+// runtime, unless that is a trusted proxy in which case X-Forwarded-For is read from the right, skipping any other
+// trusted proxies. Addresses to the left of the first untrusted address may have been set by the client and so are
+// never used.
+func clientIp(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	authConfig, err := runtimectx.GetOAuthConfig(r.Context())
+	if err != nil {
+		return remote
+	}
+
+	proxies := authConfig.RateLimitTrustedProxies()
+	if !isTrustedProxy(proxies, remote) {
+		return remote
+	}
+
+	client := remote
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip == "" {
+			continue
+		}
+
+		client = ip
+		if !isTrustedProxy(proxies, ip) {
+			break
+		}
+	}
+
+	return client
+}
+
+func isTrustedProxy(proxies []*net.IPNet, address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type JobHandler struct {
 	schema *proto.Schema
 }
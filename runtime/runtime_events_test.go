@@ -8,6 +8,7 @@ import (
 
 	"github.com/karlseguin/typed"
 	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
 	"github.com/teamkeel/keel/events"
 	"github.com/teamkeel/keel/runtime/actions"
 	"github.com/teamkeel/keel/schema/parser"
@@ -432,3 +433,215 @@ func TestFailedEventHandling(t *testing.T) {
 	_, ok := result.(map[string]any)
 	require.True(t, ok)
 }
+
+var emitSchema = `
+model Order {
+	fields {
+		trackingNumber Text?
+	}
+	actions {
+		create createOrder()
+		update shipOrder(id) with (trackingNumber) {
+			@emit(order.shipped)
+		}
+	}
+	@permission(expression: true, actions: [create, update])
+}
+event order.shipped {
+	fields {
+		id ID
+		trackingNumber Text?
+	}
+	@on(notifyCustomer)
+}
+`
+
+func TestEmitWithinActionTransaction(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), emitSchema, true)
+	defer database.Close()
+
+	result, _, err := actions.Execute(
+		actions.NewScope(ctx, schema.FindAction("createOrder"), schema),
+		map[string]any{})
+	require.NoError(t, err)
+	order := result.(map[string]any)
+
+	_, _, err = actions.Execute(
+		actions.NewScope(ctx, schema.FindAction("shipOrder"), schema),
+		map[string]any{"where": map[string]any{"id": order["id"]}, "values": map[string]any{"trackingNumber": "123"}})
+	require.NoError(t, err)
+
+	var emitted int64
+	require.NoError(t, database.GetDB().Table(events.EventTableName).Where("name = ?", "order.shipped").Count(&emitted).Error)
+	require.Equal(t, int64(1), emitted)
+
+	// If the event can't be written then the action's write is rolled back
+	require.NoError(t, database.GetDB().Exec("DROP TABLE "+events.EventTableName).Error)
+
+	_, _, err = actions.Execute(
+		actions.NewScope(ctx, schema.FindAction("shipOrder"), schema),
+		map[string]any{"where": map[string]any{"id": order["id"]}, "values": map[string]any{"trackingNumber": "456"}})
+	require.Error(t, err)
+
+	var trackingNumber string
+	require.NoError(t, database.GetDB().Raw(`SELECT tracking_number FROM "order" WHERE id = ?`, order["id"]).Scan(&trackingNumber).Error)
+	require.Equal(t, "123", trackingNumber)
+}
+
+func TestDeliveryLeaseExpiry(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), eventsSchema, true)
+	defer database.Close()
+
+	maxAttempts := 3
+	ctx = events.WithConfig(ctx, &config.EventsConfig{MaxAttempts: &maxAttempts})
+
+	ctx, err := events.WithEventHandler(ctx, func(ctx context.Context, subscriber string, event *events.Event, traceparent string) error {
+		return errors.New("something went wrong")
+	})
+	require.NoError(t, err)
+
+	_, _, err = actions.Execute(actions.NewScope(ctx, schema.FindAction("createWedding"), schema), map[string]any{"name": "Dave"})
+	require.NoError(t, err)
+
+	delivery := func() typed.Typed {
+		result, err := database.ExecuteQuery(ctx, "SELECT * FROM keel_event_delivery")
+		require.NoError(t, err)
+		require.Len(t, result.Rows, 1)
+		return typed.New(result.Rows[0])
+	}
+
+	// The first attempt failed and so the delivery will be retried
+	require.Equal(t, events.DeliveryPending, delivery().String("status"))
+	require.Equal(t, 1, delivery().Int("attempts"))
+	require.Equal(t, "something went wrong", delivery().String("last_error"))
+
+	// A process leases the delivery to attempt it, but dies before it records the outcome
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_event_delivery SET attempts = attempts + 1, next_attempt_at = now() + interval '5 minutes'")
+	require.NoError(t, err)
+
+	delivered := 0
+	handler := func(ctx context.Context, subscriber string, event *events.Event, traceparent string) error {
+		delivered++
+		return nil
+	}
+
+	// Whilst the lease is held, no other process attempts the delivery
+	n, err := events.DeliverPending(ctx, handler, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	// ... but once it has expired the delivery is attempted again
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_event_delivery SET next_attempt_at = now() - interval '1 second'")
+	require.NoError(t, err)
+
+	n, err = events.DeliverPending(ctx, handler, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, events.DeliveryDelivered, delivery().String("status"))
+	require.Equal(t, 3, delivery().Int("attempts"))
+}
+
+func TestDeliveryRetriedUntilDeadLettered(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), eventsSchema, true)
+	defer database.Close()
+
+	maxAttempts := 3
+	ctx = events.WithConfig(ctx, &config.EventsConfig{MaxAttempts: &maxAttempts})
+
+	handler := func(ctx context.Context, subscriber string, event *events.Event, traceparent string) error {
+		return errors.New("something went wrong")
+	}
+
+	ctx, err := events.WithEventHandler(ctx, handler)
+	require.NoError(t, err)
+
+	_, _, err = actions.Execute(actions.NewScope(ctx, schema.FindAction("createWedding"), schema), map[string]any{"name": "Dave"})
+	require.NoError(t, err)
+
+	delivery := func() typed.Typed {
+		result, err := database.ExecuteQuery(ctx, "SELECT * FROM keel_event_delivery")
+		require.NoError(t, err)
+		require.Len(t, result.Rows, 1)
+		return typed.New(result.Rows[0])
+	}
+
+	retry := func() int {
+		_, err := database.ExecuteStatement(ctx, "UPDATE keel_event_delivery SET next_attempt_at = now() - interval '1 second'")
+		require.NoError(t, err)
+
+		n, err := events.DeliverPending(ctx, handler, 10)
+		require.NoError(t, err)
+		return n
+	}
+
+	require.Equal(t, events.DeliveryPending, delivery().String("status"))
+	require.Equal(t, 1, delivery().Int("attempts"))
+
+	// The retry fails and so is retried again after the backoff
+	require.Equal(t, 1, retry())
+	require.Equal(t, events.DeliveryPending, delivery().String("status"))
+	require.Equal(t, 2, delivery().Int("attempts"))
+	require.True(t, delivery().Time("next_attempt_at").After(time.Now()))
+
+	// The last attempt fails and so the delivery is dead-lettered
+	require.Equal(t, 1, retry())
+	require.Equal(t, events.DeliveryDead, delivery().String("status"))
+	require.Equal(t, 3, delivery().Int("attempts"))
+	require.Equal(t, "something went wrong", delivery().String("last_error"))
+
+	// ... and is not attempted again
+	require.Equal(t, 0, retry())
+	require.Equal(t, 3, delivery().Int("attempts"))
+}
+
+var undeliverableEventsSchema = `
+model Order {
+	fields {
+		trackingNumber Text?
+	}
+	actions {
+		create createOrder()
+		update archiveOrder(id) with (trackingNumber) {
+			@emit(order.archived)
+		}
+	}
+	@on([update], auditOrder)
+	@permission(expression: true, actions: [create, update])
+}
+event order.archived {
+	fields {
+		id ID
+	}
+}
+`
+
+func TestUndeliverableEventsDoNotDropOtherEvents(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), undeliverableEventsSchema, true)
+	defer database.Close()
+
+	handler := NewEventHandler(t)
+	ctx, err := events.WithEventHandler(ctx, handler.HandleEvent)
+	require.NoError(t, err)
+
+	result, _, err := actions.Execute(actions.NewScope(ctx, schema.FindAction("createOrder"), schema), map[string]any{})
+	require.NoError(t, err)
+	order := result.(map[string]any)
+
+	// An event with a name which isn't in the schema, as could be emitted from a function
+	require.NoError(t, events.Emit(ctx, "order.unknown", map[string]any{}))
+
+	// order.archived is emitted by the action, but it has no subscribers or webhooks
+	_, _, err = actions.Execute(
+		actions.NewScope(ctx, schema.FindAction("archiveOrder"), schema),
+		map[string]any{"where": map[string]any{"id": order["id"]}, "values": map[string]any{"trackingNumber": "123"}})
+	require.NoError(t, err)
+
+	// The model event is still sent
+	require.Len(t, handler.handledEvents["auditOrder"], 1)
+	require.Equal(t, "order.updated", handler.handledEvents["auditOrder"][0].EventName)
+
+	var unprocessed int64
+	require.NoError(t, database.GetDB().Table(events.EventTableName).Where("processed_at IS NULL").Count(&unprocessed).Error)
+	require.Equal(t, int64(0), unprocessed)
+}
@@ -1,15 +1,19 @@
 package runtime_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/samber/lo"
 	"github.com/sanity-io/litter"
 	"github.com/stretchr/testify/require"
 	"github.com/teamkeel/graphql/gqlerrors"
@@ -23,6 +27,7 @@ import (
 	"github.com/teamkeel/keel/schema"
 	"github.com/teamkeel/keel/storage"
 	"github.com/teamkeel/keel/testhelpers"
+	keeltesting "github.com/teamkeel/keel/testing"
 	"gorm.io/gorm"
 )
 
@@ -2782,3 +2787,154 @@ var testCases = []testCase{
 		},
 	},
 }
+
+var relationshipsSchema = `
+	model Author {
+		fields {
+			name Text
+			posts Post[]
+		}
+		actions {
+			get getAuthor(id)
+			list listAuthors()
+		}
+		@permission(expression: true, actions: [get, list])
+	}
+	model Post {
+		fields {
+			title Text
+			published Boolean
+			author Author
+			reviewer Reviewer?
+		}
+		actions {
+			list listPosts()
+		}
+		@permission(expression: post.published == true, actions: [list])
+	}
+	model Reviewer {
+		fields {
+			name Text
+			public Boolean
+		}
+		actions {
+			get getReviewer(id)
+		}
+		@permission(expression: reviewer.public == true, actions: [get])
+	}
+	api Test {
+		models {
+			Author
+			Post
+			Reviewer
+		}
+	}
+`
+
+// queryRecordingDatabase records the SQL of each query so that tests can assert which queries were executed
+type queryRecordingDatabase struct {
+	db.Database
+	mutex   sync.Mutex
+	queries []string
+}
+
+func (d *queryRecordingDatabase) ExecuteQuery(ctx context.Context, sql string, args ...any) (*db.ExecuteQueryResult, error) {
+	d.mutex.Lock()
+	d.queries = append(d.queries, sql)
+	d.mutex.Unlock()
+
+	return d.Database.ExecuteQuery(ctx, sql, args...)
+}
+
+// selecting returns the queries which select the rows of the given table
+func (d *queryRecordingDatabase) selecting(table string) []string {
+	return lo.Filter(d.queries, func(sql string, _ int) bool {
+		return strings.Contains(sql, fmt.Sprintf(`"%s".*`, table))
+	})
+}
+
+func executeRelationshipsQuery(t *testing.T, setup func(database *gorm.DB), query string) (respFields, *queryRecordingDatabase) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), relationshipsSchema, true)
+	defer database.Close()
+
+	setup(database.GetDB())
+
+	recorder := &queryRecordingDatabase{Database: database}
+	ctx = db.WithDatabase(ctx, recorder)
+
+	request := httptest.NewRequest(http.MethodPost, "/test/graphql", strings.NewReader(queryAsJSONPayload(t, query, nil)))
+	response := runtime.NewApiHandler(schema)(request.WithContext(ctx))
+
+	body := respFields{}
+	require.NoError(t, json.Unmarshal(response.Body, &body))
+
+	return body, recorder
+}
+
+func TestGraphQLBelongsToLoadedInOneQuery(t *testing.T) {
+	body, recorder := executeRelationshipsQuery(t, func(database *gorm.DB) {
+		for _, id := range []string{"a1", "a2", "a3"} {
+			require.NoError(t, database.Table("author").Create(initRow(map[string]any{"id": id, "name": "Author " + id})).Error)
+			require.NoError(t, database.Table("post").Create(initRow(map[string]any{"id": "p_" + id, "title": "Post by " + id, "published": true, "authorId": id})).Error)
+		}
+	}, `{ listPosts { edges { node { title author { name } } } } }`)
+
+	require.Empty(t, body.Errors)
+
+	edges := body.Data["listPosts"].(map[string]any)["edges"].([]any)
+	require.Len(t, edges, 3)
+	for _, edge := range edges {
+		node := edge.(map[string]any)["node"].(map[string]any)
+		author := node["author"].(map[string]any)
+		require.Equal(t, "Post by "+strings.TrimPrefix(author["name"].(string), "Author "), node["title"])
+	}
+
+	// The author of each post is loaded with a single query
+	require.Len(t, recorder.selecting("author"), 1)
+}
+
+func TestGraphQLHasManyLoadedInOneQueryWithPermissionsPerParent(t *testing.T) {
+	body, recorder := executeRelationshipsQuery(t, func(database *gorm.DB) {
+		require.NoError(t, database.Table("author").Create(initRow(map[string]any{"id": "a1", "name": "Sue"})).Error)
+		require.NoError(t, database.Table("author").Create(initRow(map[string]any{"id": "a2", "name": "Fred"})).Error)
+		require.NoError(t, database.Table("post").Create(initRow(map[string]any{"id": "p1", "title": "Published", "published": true, "authorId": "a1"})).Error)
+		require.NoError(t, database.Table("post").Create(initRow(map[string]any{"id": "p2", "title": "Draft", "published": false, "authorId": "a2"})).Error)
+	}, `{ listAuthors { edges { node { name posts { edges { node { title } } } } } } }`)
+
+	// The posts of every author are loaded with a single query
+	require.Len(t, recorder.selecting("post"), 1)
+
+	// Sue's posts are all published and so can be listed, but Fred's are not, and so only Fred's posts are denied
+	require.Len(t, body.Errors, 1)
+	require.Equal(t, []any{"listAuthors", "edges", 1.0, "node", "posts"}, body.Errors[0].Path)
+	require.Equal(t, "not authorized to access this action", body.Errors[0].Message)
+}
+
+func TestGraphQLBelongsToPermissionsPerParent(t *testing.T) {
+	body, recorder := executeRelationshipsQuery(t, func(database *gorm.DB) {
+		require.NoError(t, database.Table("reviewer").Create(initRow(map[string]any{"id": "r1", "name": "Public", "public": true})).Error)
+		require.NoError(t, database.Table("reviewer").Create(initRow(map[string]any{"id": "r2", "name": "Private", "public": false})).Error)
+		require.NoError(t, database.Table("author").Create(initRow(map[string]any{"id": "a1", "name": "Sue"})).Error)
+		require.NoError(t, database.Table("post").Create(initRow(map[string]any{"id": "p1", "title": "First", "published": true, "authorId": "a1", "reviewerId": "r1"})).Error)
+		require.NoError(t, database.Table("post").Create(initRow(map[string]any{"id": "p2", "title": "Second", "published": true, "authorId": "a1", "reviewerId": "r2"})).Error)
+	}, `{ listPosts { edges { node { title reviewer { name } } } } }`)
+
+	// The reviewers of every post are loaded with a single query
+	require.Len(t, recorder.selecting("reviewer"), 1)
+
+	edges := body.Data["listPosts"].(map[string]any)["edges"].([]any)
+	require.Len(t, edges, 2)
+
+	first := edges[0].(map[string]any)["node"].(map[string]any)
+	require.Equal(t, "First", first["title"])
+	require.Equal(t, "Public", first["reviewer"].(map[string]any)["name"])
+
+	// The private reviewer is denied without failing the rest of the batch
+	second := edges[1].(map[string]any)["node"].(map[string]any)
+	require.Equal(t, "Second", second["title"])
+	require.Nil(t, second["reviewer"])
+
+	require.Len(t, body.Errors, 1)
+	require.Equal(t, []any{"listPosts", "edges", 1.0, "node", "reviewer"}, body.Errors[0].Path)
+	require.Equal(t, "not authorized to access this action", body.Errors[0].Message)
+}
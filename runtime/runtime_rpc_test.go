@@ -601,4 +601,347 @@ var rpcTestCases = []rpcTestCase{
 			rtt.AssertValueAtPath(t, data, "timestamps", []any{"2023-03-13T10:00:45Z", "2024-01-01T00:00:00.3Z"})
 		},
 	},
+	{
+		name: "rpc_delete_restricted_by_relationship",
+		keelSchema: `
+			model Customer {
+				fields {
+					name Text
+					invoices Invoice[]
+				}
+				actions {
+					delete deleteCustomer(id)
+				}
+				@permission(
+					expression: true,
+					actions: [delete]
+				)
+			}
+			model Invoice {
+				fields {
+					customer Customer @relation(invoices, onDelete: restrict)
+				}
+			}
+			api Test {
+				models {
+					Customer
+					Invoice
+				}
+			}
+		`,
+		databaseSetup: func(t *testing.T, db *gorm.DB) {
+			customer := initRow(map[string]any{
+				"id":   "customer_1",
+				"name": "Acme",
+			})
+			require.NoError(t, db.Table("customer").Create(customer).Error)
+			invoice := initRow(map[string]any{
+				"id":          "invoice_1",
+				"customer_id": "customer_1",
+			})
+			require.NoError(t, db.Table("invoice").Create(invoice).Error)
+		},
+		Path:   "deleteCustomer",
+		Body:   `{"id": "customer_1"}`,
+		Method: http.MethodPost,
+		assertError: func(t *testing.T, data map[string]any, statusCode int) {
+			assert.Equal(t, http.StatusBadRequest, statusCode)
+			assert.Equal(t, "ERR_INVALID_INPUT", data["code"])
+			assert.Equal(t, "the record cannot be deleted as it is still referenced by Invoice records", data["message"])
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			var count int64
+			require.NoError(t, db.Table("customer").Count(&count).Error)
+			assert.Equal(t, int64(1), count)
+		},
+	},
+	{
+		name: "rpc_create_many",
+		keelSchema: `
+			model Thing {
+				fields {
+					name Text
+					score Number @default(0)
+				}
+				actions {
+					createMany createThings() with (name, score?)
+				}
+				@permission(
+					expression: true,
+					actions: [create]
+				)
+			}
+			api Test {
+				models {
+					Thing
+				}
+			}
+		`,
+		Path:   "createThings",
+		Body:   `{"values": [{"name": "a"}, {"name": "b", "score": 2}, {"name": "c"}]}`,
+		Method: http.MethodPost,
+		assertResponse: func(t *testing.T, data map[string]any) {
+			assert.Equal(t, 3.0, data["count"])
+			assert.Len(t, data["ids"], 3)
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			var count int64
+			require.NoError(t, db.Table("thing").Count(&count).Error)
+			assert.Equal(t, int64(3), count)
+			require.NoError(t, db.Table("thing").Where("score = ?", 2).Count(&count).Error)
+			assert.Equal(t, int64(1), count)
+		},
+	},
+	{
+		name: "rpc_create_many_with_related_records",
+		keelSchema: `
+			model Thing {
+				fields {
+					name Text
+					tags Tag[]
+				}
+				actions {
+					createMany createThings() with (name, tags.name)
+				}
+				@permission(
+					expression: true,
+					actions: [create]
+				)
+			}
+			model Tag {
+				fields {
+					name Text
+					thing Thing
+				}
+			}
+			api Test {
+				models {
+					Thing
+				}
+			}
+		`,
+		Path:   "createThings",
+		Body:   `{"values": [{"name": "a", "tags": []}, {"name": "b", "tags": [{"name": "x"}, {"name": "y"}]}, {"name": "c", "tags": []}]}`,
+		Method: http.MethodPost,
+		assertResponse: func(t *testing.T, data map[string]any) {
+			assert.Equal(t, 3.0, data["count"])
+			assert.Len(t, data["ids"], 3)
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			ids := data.(map[string]any)["ids"].([]any)
+
+			// The ids are in the same order as the values
+			for i, name := range []string{"a", "b", "c"} {
+				var count int64
+				require.NoError(t, db.Table("thing").Where("id = ? AND name = ?", ids[i], name).Count(&count).Error)
+				assert.Equal(t, int64(1), count)
+			}
+
+			var count int64
+			require.NoError(t, db.Table("tag").Where("thing_id = ?", ids[1]).Count(&count).Error)
+			assert.Equal(t, int64(2), count)
+		},
+	},
+	{
+		name: "rpc_create_many_exceeds_max_batch_size",
+		keelSchema: `
+			model Thing {
+				fields {
+					name Text
+				}
+				actions {
+					createMany createThings() with (name)
+				}
+				@permission(
+					expression: true,
+					actions: [create]
+				)
+			}
+			api Test {
+				models {
+					Thing
+				}
+			}
+		`,
+		Path:   "createThings",
+		Body:   fmt.Sprintf(`{"values": [%s]}`, strings.Repeat(`{"name": "a"}, `, 1000)+`{"name": "a"}`),
+		Method: http.MethodPost,
+		assertError: func(t *testing.T, data map[string]any, statusCode int) {
+			assert.Equal(t, http.StatusBadRequest, statusCode)
+			assert.Equal(t, "ERR_INVALID_INPUT", data["code"])
+			assert.Equal(t, "values cannot have more than 1000 items", data["message"])
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			var count int64
+			require.NoError(t, db.Table("thing").Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+		},
+	},
+	{
+		name: "rpc_create_many_not_authorised_rolls_back",
+		keelSchema: `
+			model Thing {
+				fields {
+					name Text
+				}
+				actions {
+					createMany createThings() with (name)
+				}
+				@permission(
+					expression: thing.name != "forbidden",
+					actions: [create]
+				)
+			}
+			api Test {
+				models {
+					Thing
+				}
+			}
+		`,
+		Path:   "createThings",
+		Body:   `{"values": [{"name": "a"}, {"name": "forbidden"}]}`,
+		Method: http.MethodPost,
+		assertError: func(t *testing.T, data map[string]any, statusCode int) {
+			assert.Equal(t, http.StatusForbidden, statusCode)
+			assert.Equal(t, "ERR_PERMISSION_DENIED", data["code"])
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			var count int64
+			require.NoError(t, db.Table("thing").Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+		},
+	},
+	{
+		name: "rpc_update_many",
+		keelSchema: `
+			model Thing {
+				fields {
+					category Text
+					archived Boolean @default(false)
+				}
+				actions {
+					updateMany archiveThings(category) with (archived)
+				}
+				@permission(
+					expression: true,
+					actions: [update]
+				)
+			}
+			api Test {
+				models {
+					Thing
+				}
+			}
+		`,
+		databaseSetup: func(t *testing.T, db *gorm.DB) {
+			for id, category := range map[string]string{"thing_1": "old", "thing_2": "old", "thing_3": "new"} {
+				row := initRow(map[string]any{
+					"id":       id,
+					"category": category,
+					"archived": false,
+				})
+				require.NoError(t, db.Table("thing").Create(row).Error)
+			}
+		},
+		Path:   "archiveThings",
+		Body:   `{"where": {"category": {"equals": "old"}}, "values": {"archived": true}}`,
+		Method: http.MethodPost,
+		assertResponse: func(t *testing.T, data map[string]any) {
+			assert.Equal(t, 2.0, data["count"])
+			assert.ElementsMatch(t, []any{"thing_1", "thing_2"}, data["ids"])
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			var count int64
+			require.NoError(t, db.Table("thing").Where("archived = ?", true).Count(&count).Error)
+			assert.Equal(t, int64(2), count)
+		},
+	},
+	{
+		name: "rpc_delete_many",
+		keelSchema: `
+			model Thing {
+				fields {
+					category Text
+				}
+				actions {
+					deleteMany deleteThings(category)
+				}
+				@permission(
+					expression: true,
+					actions: [delete]
+				)
+			}
+			api Test {
+				models {
+					Thing
+				}
+			}
+		`,
+		databaseSetup: func(t *testing.T, db *gorm.DB) {
+			for id, category := range map[string]string{"thing_1": "old", "thing_2": "old", "thing_3": "new"} {
+				row := initRow(map[string]any{
+					"id":       id,
+					"category": category,
+				})
+				require.NoError(t, db.Table("thing").Create(row).Error)
+			}
+		},
+		Path:   "deleteThings",
+		Body:   `{"where": {"category": {"equals": "old"}}}`,
+		Method: http.MethodPost,
+		assertResponse: func(t *testing.T, data map[string]any) {
+			assert.Equal(t, 2.0, data["count"])
+			assert.ElementsMatch(t, []any{"thing_1", "thing_2"}, data["ids"])
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			var count int64
+			require.NoError(t, db.Table("thing").Count(&count).Error)
+			assert.Equal(t, int64(1), count)
+		},
+	},
+	{
+		name: "rpc_delete_many_not_authorised_rolls_back",
+		keelSchema: `
+			model Thing {
+				fields {
+					category Text
+					locked Boolean
+				}
+				actions {
+					deleteMany deleteThings(category)
+				}
+				@permission(
+					expression: thing.locked == false,
+					actions: [delete]
+				)
+			}
+			api Test {
+				models {
+					Thing
+				}
+			}
+		`,
+		databaseSetup: func(t *testing.T, db *gorm.DB) {
+			for id, locked := range map[string]bool{"thing_1": false, "thing_2": true} {
+				row := initRow(map[string]any{
+					"id":       id,
+					"category": "old",
+					"locked":   locked,
+				})
+				require.NoError(t, db.Table("thing").Create(row).Error)
+			}
+		},
+		Path:   "deleteThings",
+		Body:   `{"where": {"category": {"equals": "old"}}}`,
+		Method: http.MethodPost,
+		assertError: func(t *testing.T, data map[string]any, statusCode int) {
+			assert.Equal(t, http.StatusForbidden, statusCode)
+			assert.Equal(t, "ERR_PERMISSION_DENIED", data["code"])
+		},
+		assertDatabase: func(t *testing.T, db *gorm.DB, data interface{}) {
+			var count int64
+			require.NoError(t, db.Table("thing").Count(&count).Error)
+			assert.Equal(t, int64(2), count)
+		},
+	},
 }
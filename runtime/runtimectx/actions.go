@@ -0,0 +1,31 @@
+package runtimectx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teamkeel/keel/config"
+)
+
+const (
+	actionsContextKey contextKey = "actionsConfig"
+)
+
+func WithActionsConfig(ctx context.Context, config *config.ActionsConfig) context.Context {
+	ctx = context.WithValue(ctx, actionsContextKey, config)
+	return ctx
+}
+
+func GetActionsConfig(ctx context.Context) (*config.ActionsConfig, error) {
+	v := ctx.Value(actionsContextKey)
+	if v == nil {
+		return &config.ActionsConfig{}, nil
+	}
+
+	config, ok := v.(*config.ActionsConfig)
+
+	if !ok {
+		return nil, errors.New("actions config in the context has wrong value type")
+	}
+	return config, nil
+}
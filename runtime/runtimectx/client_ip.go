@@ -0,0 +1,19 @@
+package runtimectx
+
+import (
+	"context"
+)
+
+const (
+	clientIpContextKey contextKey = "clientIp"
+)
+
+func WithClientIp(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIpContextKey, ip)
+}
+
+// GetClientIp returns the IP address of the client which made the request, or an empty string if it is not known.
+func GetClientIp(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIpContextKey).(string)
+	return ip
+}
@@ -11,6 +11,7 @@ import (
 type mailContextKey string
 
 var mailKey mailContextKey = "mail"
+var mailTemplatesKey mailContextKey = "mailTemplates"
 
 func GetMailClient(ctx context.Context) (mail.EmailClient, error) {
 	v := ctx.Value(mailKey)
@@ -29,3 +30,16 @@ func GetMailClient(ctx context.Context) (mail.EmailClient, error) {
 func WithMailClient(ctx context.Context, client mail.EmailClient) context.Context {
 	return context.WithValue(ctx, mailKey, client)
 }
+
+// GetMailTemplates returns the email templates of the project, or the built-in templates if none have been set.
+func GetMailTemplates(ctx context.Context) *mail.Templates {
+	templates, ok := ctx.Value(mailTemplatesKey).(*mail.Templates)
+	if !ok {
+		return mail.DefaultTemplates()
+	}
+	return templates
+}
+
+func WithMailTemplates(ctx context.Context, templates *mail.Templates) context.Context {
+	return context.WithValue(ctx, mailTemplatesKey, templates)
+}
@@ -10,20 +10,40 @@ type privateKeyContextKey string
 
 var privateKeyContext privateKeyContextKey = "privateKey"
 
+// GetPrivateKey returns the key which signs new tokens, or nil if there is none.
 func GetPrivateKey(ctx context.Context) (*rsa.PrivateKey, error) {
+	privateKeys, err := GetPrivateKeys(ctx)
+	if err != nil || len(privateKeys) == 0 {
+		return nil, err
+	}
+
+	return privateKeys[0], nil
+}
+
+// GetPrivateKeys returns every key which tokens may have been signed with, starting with the signing key.
+func GetPrivateKeys(ctx context.Context) ([]*rsa.PrivateKey, error) {
 	v := ctx.Value(privateKeyContext)
 	if v == nil {
 		return nil, nil
 	}
 
-	privateKey, ok := v.(*rsa.PrivateKey)
+	privateKeys, ok := v.([]*rsa.PrivateKey)
 
 	if !ok {
 		return nil, errors.New("private key in the context has wrong type")
 	}
-	return privateKey, nil
+	return privateKeys, nil
 }
 
 func WithPrivateKey(ctx context.Context, privateKey *rsa.PrivateKey) context.Context {
-	return context.WithValue(ctx, privateKeyContext, privateKey)
+	if privateKey == nil {
+		return context.WithValue(ctx, privateKeyContext, []*rsa.PrivateKey{})
+	}
+	return WithPrivateKeys(ctx, []*rsa.PrivateKey{privateKey})
+}
+
+// WithPrivateKeys sets the keys for signing and verifying tokens. The first key signs new tokens, whereas tokens
+// signed by any of the keys are accepted so that the signing key can be rotated without invalidating existing tokens.
+func WithPrivateKeys(ctx context.Context, privateKeys []*rsa.PrivateKey) context.Context {
+	return context.WithValue(ctx, privateKeyContext, privateKeys)
 }
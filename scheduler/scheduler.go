@@ -0,0 +1,405 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/karlseguin/typed"
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/cron"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/teamkeel/keel/scheduler")
+
+// The table where the upcoming and past runs of scheduled jobs are kept
+const RunTableName = "keel_job_run"
+
+// Run statuses
+const (
+	// The run is the next occurrence of the job's schedule
+	RunScheduled = "scheduled"
+	// The job is being run
+	RunRunning = "running"
+	// The job completed successfully
+	RunSucceeded = "succeeded"
+	// The job returned an error, or the process running it stopped before it completed
+	RunFailed = "failed"
+	// The job was due whilst its previous run was still running, and so it was not run
+	RunSkipped = "skipped"
+)
+
+const (
+	// How long a run is leased to the process running the job. The lease is renewed whilst the job is running,
+	// and so if the process dies during the run then the run is failed soon after so that the job can be run again.
+	DefaultRunLease = 5 * time.Minute
+	// The key of the advisory lock which is held whilst deciding which jobs are due, so that only one
+	// process fires each run.
+	advisoryLockKey int64 = 0x6b65656c6a6f6273
+)
+
+// JobRunner runs the job with the given name, such as with the ScheduledTrigger.
+type JobRunner func(ctx context.Context, job string) error
+
+// Run is a single occurrence of a scheduled job
+type Run struct {
+	Id           string
+	Job          string
+	Schedule     string
+	Status       string
+	ScheduledFor time.Time
+	StartedAt    *time.Time
+	FinishedAt   *time.Time
+	Error        string
+}
+
+// RunDue runs the scheduled jobs which are due and returns their runs once they have completed. The next
+// run of each job is scheduled from its cron expression, and the runs which are due are claimed whilst
+// holding an advisory lock, so that when multiple processes share a database each run is fired by only one.
+// A job is never run whilst its previous run is still running; instead the run is recorded as skipped.
+func RunDue(ctx context.Context, schema *proto.Schema, runner JobRunner) ([]*Run, error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := []error{}
+
+	due, err := startDue(ctx, schema, runner, DefaultRunLease, &wg, func(_ *Run, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wg.Wait()
+
+	return due, errors.Join(errs...)
+}
+
+// startDue claims the scheduled jobs which are due and starts running them in the background, returning their
+// runs straight away. Once each run has completed, and its outcome has been recorded, done is called.
+func startDue(ctx context.Context, schema *proto.Schema, runner JobRunner, lease time.Duration, wg *sync.WaitGroup, done func(run *Run, err error)) ([]*Run, error) {
+	ctx, span := tracer.Start(ctx, "Start due jobs")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	due := []*Run{}
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		result, err := database.ExecuteQuery(ctx, "SELECT pg_try_advisory_xact_lock(?) AS locked", advisoryLockKey)
+		if err != nil {
+			return err
+		}
+
+		// Another process is already scheduling jobs
+		if len(result.Rows) != 1 || result.Rows[0]["locked"] != true {
+			return nil
+		}
+
+		due, err = claimDue(ctx, database, schema, time.Now().UTC(), lease)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("job.runs", len(due)))
+
+	for _, run := range due {
+		wg.Add(1)
+		go func(run *Run) {
+			defer wg.Done()
+			done(run, complete(ctx, database, runner, run, lease))
+		}(run)
+	}
+
+	return due, nil
+}
+
+// claimDue schedules the next run of each job, and marks the runs which are due as running.
+func claimDue(ctx context.Context, database db.Database, schema *proto.Schema, now time.Time, lease time.Duration) ([]*Run, error) {
+	// Runs whose lease has expired were being run by a process which stopped before the job completed
+	sql := fmt.Sprintf("UPDATE %s SET status = ?, error = ?, finished_at = ? WHERE status = ? AND lease_expires_at < ?", RunTableName)
+	_, err := database.ExecuteStatement(ctx, sql, RunFailed, "the job did not complete before its lease expired", now, RunRunning, now)
+	if err != nil {
+		return nil, err
+	}
+
+	sql = fmt.Sprintf("SELECT * FROM %s WHERE status IN (?, ?)", RunTableName)
+	result, err := database.ExecuteQuery(ctx, sql, RunScheduled, RunRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := map[string]*Run{}
+	running := map[string]bool{}
+	for _, row := range result.Rows {
+		run := runFromRow(row)
+		if run.Status == RunRunning {
+			running[run.Job] = true
+			continue
+		}
+
+		// The upcoming runs of jobs which have been removed, or whose schedule has changed, are discarded
+		job := schema.FindJob(run.Job)
+		if job == nil || job.Schedule == nil || job.Schedule.Expression != run.Schedule {
+			sql = fmt.Sprintf("DELETE FROM %s WHERE id = ?", RunTableName)
+			_, err = database.ExecuteStatement(ctx, sql, run.Id)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		scheduled[run.Job] = run
+	}
+
+	due := []*Run{}
+	for _, job := range schema.Jobs {
+		if job.Schedule == nil {
+			continue
+		}
+
+		upcoming, ok := scheduled[job.Name]
+		if ok && upcoming.ScheduledFor.After(now) {
+			continue
+		}
+
+		if ok {
+			if running[job.Name] {
+				upcoming.Status = RunSkipped
+				upcoming.Error = "the previous run of the job was still running"
+				upcoming.FinishedAt = &now
+				sql = fmt.Sprintf("UPDATE %s SET status = ?, error = ?, finished_at = ? WHERE id = ?", RunTableName)
+				_, err = database.ExecuteStatement(ctx, sql, upcoming.Status, upcoming.Error, now, upcoming.Id)
+			} else {
+				upcoming.Status = RunRunning
+				upcoming.StartedAt = &now
+				sql = fmt.Sprintf("UPDATE %s SET status = ?, started_at = ?, lease_expires_at = ? WHERE id = ?", RunTableName)
+				_, err = database.ExecuteStatement(ctx, sql, upcoming.Status, now, now.Add(lease), upcoming.Id)
+				due = append(due, upcoming)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Occurrences which were missed, such as whilst no process was running, are not caught up on
+		expression, err := cron.ParseExpression(job.Schedule.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("parsing schedule of job %s: %w", job.Name, err)
+		}
+
+		next, err := expression.Next(now)
+		if err != nil {
+			return nil, fmt.Errorf("scheduling job %s: %w", job.Name, err)
+		}
+
+		sql = fmt.Sprintf("INSERT INTO %s (id, job, schedule, status, scheduled_for) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING", RunTableName)
+		_, err = database.ExecuteStatement(ctx, sql, ksuid.New().String(), job.Name, job.Schedule.Expression, RunScheduled, next)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return due, nil
+}
+
+// complete runs the job, renewing the lease of the run until the job has finished, and records the outcome of the run.
+func complete(ctx context.Context, database db.Database, runner JobRunner, run *Run, lease time.Duration) error {
+	ctx, span := tracer.Start(ctx, "Run scheduled job")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("job.name", run.Job),
+		attribute.String("job.run.id", run.Id),
+		attribute.String("job.run.scheduled_for", run.ScheduledFor.Format(time.RFC3339)),
+	)
+
+	renewCtx, stopRenewing := context.WithCancel(ctx)
+	go renewLease(renewCtx, database, run, lease)
+
+	runErr := runner(ctx, run.Job)
+	stopRenewing()
+
+	finishedAt := time.Now().UTC()
+	run.FinishedAt = &finishedAt
+
+	if runErr != nil {
+		run.Status = RunFailed
+		run.Error = runErr.Error()
+		span.RecordError(runErr)
+		span.SetStatus(codes.Error, runErr.Error())
+	} else {
+		run.Status = RunSucceeded
+	}
+
+	// The outcome is recorded even if the scheduler was stopped whilst the job was running
+	sql := fmt.Sprintf("UPDATE %s SET status = ?, error = ?, finished_at = ?, lease_expires_at = NULL WHERE id = ?", RunTableName)
+	_, err := database.ExecuteStatement(withoutCancel{ctx}, sql, run.Status, nullString(run.Error), finishedAt, run.Id)
+	return err
+}
+
+// withoutCancel is a context with the values of its parent, such as the database and the span, but which is
+// never cancelled and has no deadline.
+type withoutCancel struct {
+	context.Context
+}
+
+func (withoutCancel) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (withoutCancel) Done() <-chan struct{} {
+	return nil
+}
+
+func (withoutCancel) Err() error {
+	return nil
+}
+
+// renewLease extends the lease of the run until the context is cancelled. The lease is renewed a few times
+// within each lease period, so that a failed renewal is retried before the lease expires.
+func renewLease(ctx context.Context, database db.Database, run *Run, lease time.Duration) {
+	ticker := time.NewTicker(lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sql := fmt.Sprintf("UPDATE %s SET lease_expires_at = ? WHERE id = ? AND status = ?", RunTableName)
+			_, _ = database.ExecuteStatement(ctx, sql, time.Now().UTC().Add(lease), run.Id, RunRunning)
+		}
+	}
+}
+
+// History returns the most recent runs of the job, up to the limit, including its next scheduled run.
+func History(ctx context.Context, job string, limit int) ([]*Run, error) {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf("SELECT * FROM %s WHERE job = ? ORDER BY scheduled_for DESC LIMIT ?", RunTableName)
+	result, err := database.ExecuteQuery(ctx, sql, job, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []*Run{}
+	for _, row := range result.Rows {
+		runs = append(runs, runFromRow(row))
+	}
+
+	return runs, nil
+}
+
+func runFromRow(row map[string]any) *Run {
+	r := typed.New(row)
+
+	run := &Run{
+		Id:       r.String("id"),
+		Job:      r.String("job"),
+		Schedule: r.String("schedule"),
+		Status:   r.String("status"),
+		Error:    r.String("error"),
+	}
+
+	if t, ok := row["scheduled_for"].(time.Time); ok {
+		run.ScheduledFor = t.UTC()
+	}
+	if t, ok := row["started_at"].(time.Time); ok {
+		t = t.UTC()
+		run.StartedAt = &t
+	}
+	if t, ok := row["finished_at"].(time.Time); ok {
+		t = t.UTC()
+		run.FinishedAt = &t
+	}
+
+	return run
+}
+
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Scheduler runs scheduled jobs as they become due.
+type Scheduler struct {
+	schema *proto.Schema
+	runner JobRunner
+	// How often to check for jobs which are due
+	Interval time.Duration
+	// How long a run is leased for before it must be renewed
+	Lease time.Duration
+}
+
+func New(schema *proto.Schema, runner JobRunner) *Scheduler {
+	return &Scheduler{
+		schema:   schema,
+		runner:   runner,
+		Interval: 15 * time.Second,
+		Lease:    DefaultRunLease,
+	}
+}
+
+// HasScheduledJobs is true if any of the jobs in the schema have a schedule.
+func HasScheduledJobs(schema *proto.Schema) bool {
+	for _, job := range schema.Jobs {
+		if job.Schedule != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Run fires jobs until the context is cancelled, and then waits for the jobs which are running to finish.
+// The context must have a database. Jobs are run in the background so that other jobs can still be fired
+// whilst they are running. Errors from the jobs themselves are recorded against their runs, and so an error
+// is only returned if the database fails.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	failed := make(chan error, 1)
+	done := func(_ *Run, err error) {
+		if err != nil && ctx.Err() == nil {
+			select {
+			case failed <- err:
+			default:
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-failed:
+			return err
+		case <-ticker.C:
+			_, err := startDue(ctx, s.schema, s.runner, s.Lease, &wg, done)
+			if err != nil && ctx.Err() == nil {
+				return err
+			}
+		}
+	}
+}
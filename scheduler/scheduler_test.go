@@ -0,0 +1,339 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/cron"
+	"github.com/teamkeel/keel/scheduler"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+const schedulerTestSchema = `
+job SendReports {
+	@schedule("every 10 minutes")
+}
+
+job ManualReport {
+	@permission(expression: true)
+}`
+
+type recordingRunner struct {
+	mu   sync.Mutex
+	jobs []string
+	err  error
+}
+
+func (r *recordingRunner) run(ctx context.Context, job string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs = append(r.jobs, job)
+	return r.err
+}
+
+func TestScheduler_SchedulesNextRun(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	runner := &recordingRunner{}
+	runs, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+	require.Len(t, runs, 0)
+	require.Len(t, runner.jobs, 0)
+
+	history, err := scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, scheduler.RunScheduled, history[0].Status)
+
+	expression, err := cron.Parse("every 10 minutes")
+	require.NoError(t, err)
+	next, err := expression.Next(time.Now())
+	require.NoError(t, err)
+	require.Equal(t, next, history[0].ScheduledFor)
+
+	// Jobs without a schedule are not scheduled
+	history, err = scheduler.History(ctx, "ManualReport", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 0)
+
+	// Checking again does not schedule another run
+	_, err = scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+
+	history, err = scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+}
+
+func TestScheduler_RunsDueJob(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	runner := &recordingRunner{}
+	_, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_job_run SET scheduled_for = now() - interval '1 minute'")
+	require.NoError(t, err)
+
+	runs, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, []string{"SendReports"}, runner.jobs)
+
+	history, err := scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, scheduler.RunScheduled, history[0].Status)
+	require.True(t, history[0].ScheduledFor.After(time.Now()))
+	require.Equal(t, scheduler.RunSucceeded, history[1].Status)
+	require.NotNil(t, history[1].StartedAt)
+	require.NotNil(t, history[1].FinishedAt)
+	require.Empty(t, history[1].Error)
+
+	// The job is not run again until the next run is due
+	_, err = scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+	require.Len(t, runner.jobs, 1)
+}
+
+func TestScheduler_RecordsFailedRun(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	runner := &recordingRunner{err: errors.New("report service unavailable")}
+	_, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_job_run SET scheduled_for = now() - interval '1 minute'")
+	require.NoError(t, err)
+
+	runs, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, scheduler.RunFailed, runs[0].Status)
+
+	history, err := scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Equal(t, scheduler.RunFailed, history[1].Status)
+	require.Equal(t, "report service unavailable", history[1].Error)
+}
+
+func TestScheduler_SkipsOverlappingRun(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	runner := &recordingRunner{}
+	_, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_job_run SET scheduled_for = now() - interval '1 minute'")
+	require.NoError(t, err)
+
+	// The previous run is still in progress in another process
+	_, err = database.ExecuteStatement(ctx, `INSERT INTO keel_job_run (id, job, schedule, status, scheduled_for, started_at, lease_expires_at) VALUES ('previous', 'SendReports', '*/10 * ? * * *', 'running', now() - interval '11 minutes', now() - interval '11 minutes', now() + interval '1 hour')`)
+	require.NoError(t, err)
+
+	runs, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+	require.Len(t, runs, 0)
+	require.Len(t, runner.jobs, 0)
+
+	history, err := scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, scheduler.RunScheduled, history[0].Status)
+	require.Equal(t, scheduler.RunSkipped, history[1].Status)
+	require.Equal(t, scheduler.RunRunning, history[2].Status)
+}
+
+func TestScheduler_ExpiredLeaseFailsRun(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	_, err := database.ExecuteStatement(ctx, `INSERT INTO keel_job_run (id, job, schedule, status, scheduled_for, started_at, lease_expires_at) VALUES ('previous', 'SendReports', '*/10 * ? * * *', 'running', now() - interval '2 hours', now() - interval '2 hours', now() - interval '1 hour')`)
+	require.NoError(t, err)
+
+	runner := &recordingRunner{}
+	_, err = scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+
+	history, err := scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, scheduler.RunFailed, history[1].Status)
+	require.Equal(t, "the job did not complete before its lease expired", history[1].Error)
+}
+
+func TestScheduler_ChangedScheduleIsRescheduled(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	_, err := database.ExecuteStatement(ctx, `INSERT INTO keel_job_run (id, job, schedule, status, scheduled_for) VALUES ('old', 'SendReports', '0 9 ? * MON *', 'scheduled', now() - interval '1 minute')`)
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, `INSERT INTO keel_job_run (id, job, schedule, status, scheduled_for) VALUES ('removed', 'RemovedJob', '0 9 ? * MON *', 'scheduled', now() - interval '1 minute')`)
+	require.NoError(t, err)
+
+	runner := &recordingRunner{}
+	runs, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+	require.Len(t, runs, 0)
+
+	history, err := scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.NotEqual(t, "old", history[0].Id)
+	require.Equal(t, "*/10 * ? * * *", history[0].Schedule)
+
+	history, err = scheduler.History(ctx, "RemovedJob", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 0)
+}
+
+func TestScheduler_OnlyOneProcessFires(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	runner := &recordingRunner{}
+	_, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_job_run SET scheduled_for = now() - interval '1 minute'")
+	require.NoError(t, err)
+
+	// Whilst another process holds the lock, the job is not run
+	err = database.Transaction(ctx, func(txCtx context.Context) error {
+		_, err := database.ExecuteQuery(txCtx, "SELECT pg_advisory_xact_lock(?)", int64(0x6b65656c6a6f6273))
+		require.NoError(t, err)
+
+		runs, err := scheduler.RunDue(ctx, schema, runner.run)
+		require.NoError(t, err)
+		require.Len(t, runs, 0)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, runner.jobs, 0)
+
+	runs, err := scheduler.RunDue(ctx, schema, runner.run)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+func TestScheduler_RunsJobsInBackgroundAndRenewsLease(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), `
+job SlowReport {
+	@schedule("every 10 minutes")
+}
+
+job QuickReport {
+	@schedule("every 10 minutes")
+}`, true)
+	defer database.Close()
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	runner := func(ctx context.Context, job string) error {
+		started <- job
+		if job == "SlowReport" {
+			<-release
+		}
+		return nil
+	}
+
+	_, err := scheduler.RunDue(ctx, schema, func(ctx context.Context, job string) error { return nil })
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_job_run SET scheduled_for = now() - interval '1 minute' WHERE job = 'SlowReport'")
+	require.NoError(t, err)
+
+	sched := scheduler.New(schema, runner)
+	sched.Interval = 50 * time.Millisecond
+	sched.Lease = 300 * time.Millisecond
+
+	runCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan error)
+	go func() {
+		stopped <- sched.Run(runCtx)
+	}()
+
+	require.Equal(t, "SlowReport", waitForJob(t, started))
+
+	// Jobs which become due are still run whilst the slow job is running
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_job_run SET scheduled_for = now() - interval '1 minute' WHERE job = 'QuickReport' AND status = 'scheduled'")
+	require.NoError(t, err)
+	require.Equal(t, "QuickReport", waitForJob(t, started))
+
+	// The lease of the slow job is renewed, so it is not failed once its first lease has expired
+	time.Sleep(3 * sched.Lease)
+	result, err := database.ExecuteQuery(ctx, "SELECT status, lease_expires_at > now() AS leased FROM keel_job_run WHERE job = 'SlowReport' AND started_at IS NOT NULL")
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	require.Equal(t, scheduler.RunRunning, result.Rows[0]["status"])
+	require.Equal(t, true, result.Rows[0]["leased"])
+
+	close(release)
+	require.Eventually(t, func() bool {
+		history, err := scheduler.History(ctx, "SlowReport", 10)
+		require.NoError(t, err)
+		return len(history) == 2 && history[1].Status == scheduler.RunSucceeded
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-stopped)
+}
+
+func TestScheduler_RecordsRunWhenStopped(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, context.TODO(), schedulerTestSchema, true)
+	defer database.Close()
+
+	started := make(chan string, 1)
+	runner := func(ctx context.Context, job string) error {
+		started <- job
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	_, err := scheduler.RunDue(ctx, schema, func(ctx context.Context, job string) error { return nil })
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_job_run SET scheduled_for = now() - interval '1 minute'")
+	require.NoError(t, err)
+
+	sched := scheduler.New(schema, runner)
+	sched.Interval = 50 * time.Millisecond
+
+	runCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan error)
+	go func() {
+		stopped <- sched.Run(runCtx)
+	}()
+
+	require.Equal(t, "SendReports", waitForJob(t, started))
+
+	// Stopping the scheduler cancels the running job, and its outcome is still recorded
+	cancel()
+	require.NoError(t, <-stopped)
+
+	history, err := scheduler.History(ctx, "SendReports", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, scheduler.RunFailed, history[1].Status)
+	require.Equal(t, context.Canceled.Error(), history[1].Error)
+	require.NotNil(t, history[1].FinishedAt)
+}
+
+func waitForJob(t *testing.T, started chan string) string {
+	select {
+	case job := <-started:
+		return job
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for job to start")
+		return ""
+	}
+}
@@ -59,10 +59,9 @@ func (scm *Builder) insertBackLinkField(
 	// linking to unless @relation is defined.  If @relation(myFieldName) exists,
 	// then the backlink field will be named using the value provided (i.e. myFieldName).
 	backlinkName := casing.ToLowerCamel(parentModel.Name.Value)
-	relation := query.FieldGetAttribute(forwardRelnField, parser.AttributeRelation)
+	relation := query.FieldRelationAttribute(forwardRelnField)
 	if relation != nil {
-		relationValue, _ := relation.Arguments[0].Expression.ToValue()
-		backlinkName = relationValue.ToString()
+		backlinkName, _ = query.RelationAttributeValue(relation)
 	}
 
 	// If the field already exists don't add another one as this will just create a
@@ -67,7 +67,7 @@ func Completions(schemaFiles []*reader.SchemaFile, pos *node.Position, cfg *conf
 	// switch on nearest (previous) keyword
 	switch enclosingBlock {
 	case parser.KeywordModel:
-		attributes := getAttributeCompletions(tokenAtPos, []string{parser.AttributePermission, parser.AttributeUnique, parser.AttributeOn})
+		attributes := getAttributeCompletions(tokenAtPos, []string{parser.AttributePermission, parser.AttributeUnique, parser.AttributeOn, parser.AttributeIndex})
 		return append(attributes, modelBlockKeywords...)
 	case parser.KeywordRole:
 		return roleBlockKeywords
@@ -254,6 +254,7 @@ func getBlockCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition, keywo
 			parser.AttributeUnique,
 			parser.AttributeDefault,
 			parser.AttributeRelation,
+			parser.AttributeIndex,
 		})
 	}
 
@@ -323,6 +324,7 @@ func getBlockCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition, keywo
 				parser.AttributeUnique,
 				parser.AttributeDefault,
 				parser.AttributeRelation,
+				parser.AttributeIndex,
 			})
 		}
 
@@ -370,9 +372,10 @@ func getActionCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition) []*C
 		parser.ActionTypeDelete,
 		parser.ActionTypeGet,
 		parser.ActionTypeList,
+		parser.ActionTypeDeleteMany,
 		parser.KeywordWith,
 	)
-	// if we're delete, list or get action type and have completed our parenthesis, or there is already a `with`
+	// if we're delete, list, get or deleteMany action type and have completed our parenthesis, or there is already a `with`
 	// clause on this line then there are no further completions that are valid. Return empty list.
 	if tokenAtPos.Prev().EndOfParen() != nil && prev != "" {
 		return []*CompletionItem{}
@@ -392,6 +395,7 @@ func getActionCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition) []*C
 			parser.AttributeOrderBy,
 			parser.AttributeSortable,
 			parser.AttributeFunction,
+			parser.AttributeNearest,
 		})
 	}
 
@@ -468,6 +472,18 @@ var actionBlockKeywords = []*CompletionItem{
 		Label: parser.ActionTypeDelete,
 		Kind:  KindKeyword,
 	},
+	{
+		Label: parser.ActionTypeCreateMany,
+		Kind:  KindKeyword,
+	},
+	{
+		Label: parser.ActionTypeUpdateMany,
+		Kind:  KindKeyword,
+	},
+	{
+		Label: parser.ActionTypeDeleteMany,
+		Kind:  KindKeyword,
+	},
 	{
 		Label: parser.KeywordWith,
 		Kind:  KindKeyword,
@@ -525,6 +541,10 @@ var topLevelKeywords = []*CompletionItem{
 		Label: parser.KeywordJob,
 		Kind:  KindKeyword,
 	},
+	{
+		Label: parser.KeywordEvent,
+		Kind:  KindKeyword,
+	},
 }
 
 var onAttributeActionTypeKeywords = []*CompletionItem{
@@ -1050,6 +1070,7 @@ var namedBlocks = []string{
 	parser.KeywordRole,
 	parser.KeywordMessage,
 	parser.KeywordJob,
+	parser.KeywordEvent,
 }
 
 var unNamedBlocks = []string{
@@ -26,7 +26,7 @@ func TestRootCompletions(t *testing.T) {
 		{
 			name:     "top-level-keyword",
 			schema:   "mod<Cursor>",
-			expected: []string{"api", "enum", "message", "model", "role", "job"},
+			expected: []string{"api", "enum", "message", "model", "role", "job", "event"},
 		},
 		{
 			name: "top-level-keyword-not-first",
@@ -36,12 +36,12 @@ func TestRootCompletions(t *testing.T) {
             }
 
             m<Cursor>`,
-			expected: []string{"api", "enum", "message", "model", "role", "job"},
+			expected: []string{"api", "enum", "message", "model", "role", "job", "event"},
 		},
 		{
 			name:     "top-level-keyword-whitespace",
 			schema:   `<Cursor>`,
-			expected: []string{"api", "enum", "message", "model", "role", "job"},
+			expected: []string{"api", "enum", "message", "model", "role", "job", "event"},
 		},
 		{
 			name: "top-level-keyword-whitespace-partial-schema",
@@ -52,7 +52,7 @@ func TestRootCompletions(t *testing.T) {
 
 			model B {}
 			`,
-			expected: []string{"api", "enum", "message", "model", "role", "job"},
+			expected: []string{"api", "enum", "message", "model", "role", "job", "event"},
 		},
 	}
 
@@ -111,7 +111,7 @@ func TestModelCompletions(t *testing.T) {
 			model A {
 			  <Cursor>
 			}`,
-			expected: []string{"@permission", "@unique", "@on", "fields", "actions"},
+			expected: []string{"@permission", "@unique", "@on", "@index", "fields", "actions"},
 		},
 		// attributes tests
 		{
@@ -120,7 +120,7 @@ func TestModelCompletions(t *testing.T) {
 			model A {
               @<Cursor>
             }`,
-			expected: []string{"@permission", "@unique", "@on", "fields", "actions"},
+			expected: []string{"@permission", "@unique", "@on", "@index", "fields", "actions"},
 		},
 	}
 
@@ -434,7 +434,7 @@ func TestFieldCompletions(t *testing.T) {
 					}
 				}
 			}`,
-			expected: []string{"@unique", "@default", "@relation"},
+			expected: []string{"@unique", "@default", "@relation", "@index"},
 		},
 		{
 			name: "field-attributes-bare-at",
@@ -443,7 +443,7 @@ func TestFieldCompletions(t *testing.T) {
 					name Text @<Cursor>
 				}
 			}`,
-			expected: []string{"@unique", "@default", "@relation"},
+			expected: []string{"@unique", "@default", "@relation", "@index"},
 		},
 		{
 			name: "field-attributes-whitespace",
@@ -453,7 +453,7 @@ func TestFieldCompletions(t *testing.T) {
 					name Text <Cursor>
 				}
 			}`,
-			expected: []string{"@unique", "@default", "@relation"},
+			expected: []string{"@unique", "@default", "@relation", "@index"},
 		},
 	}
 
@@ -656,7 +656,7 @@ func TestActionCompletions(t *testing.T) {
 				}
 			  }
 		    }`,
-			expected: []string{"@function", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
+			expected: []string{"@function", "@nearest", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
 		},
 		{
 			name: "action-attributes-whitespace",
@@ -671,7 +671,7 @@ func TestActionCompletions(t *testing.T) {
 				}
 			  }
 		    }`,
-			expected: []string{"@function", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
+			expected: []string{"@function", "@nearest", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
 		},
 	}
 
@@ -1566,7 +1566,7 @@ func TestRoleCompletions(t *testing.T) {
 			name: "role-keyword",
 			schema: `
 			r<Cursor>`,
-			expected: []string{"api", "enum", "message", "model", "role", "job"},
+			expected: []string{"api", "enum", "message", "model", "role", "job", "event"},
 		},
 		{
 			name: "domains-keyword",
@@ -1608,7 +1608,7 @@ func TestAPICompletions(t *testing.T) {
 			name: "api-keyword",
 			schema: `
 			a<Cursor>`,
-			expected: []string{"api", "enum", "message", "model", "role", "job"},
+			expected: []string{"api", "enum", "message", "model", "role", "job", "event"},
 		},
 		{
 			name: "models-keyword",
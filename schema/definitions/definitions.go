@@ -73,8 +73,8 @@ func GetDefinition(schemaFiles []*reader.SchemaFile, pos Position) *Definition {
 						continue
 					}
 					for _, cond := range arg.Expression.Conditions() {
-						for _, op := range []*parser.Operand{cond.LHS, cond.RHS} {
-							if op == nil || op.Ident == nil {
+						for _, op := range cond.Operands() {
+							if op.Ident == nil {
 								continue
 							}
 							if op.Ident.Fragments[0].Fragment != strcase.ToLowerCamel(model.Name.Value) {
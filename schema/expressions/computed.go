@@ -0,0 +1,243 @@
+package expressions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+var (
+	numericTypes  = []string{parser.FieldTypeNumber, parser.FieldTypeDecimal}
+	textTypes     = []string{parser.FieldTypeText, parser.FieldTypeMarkdown}
+	temporalTypes = []string{parser.FieldTypeDate, parser.FieldTypeDatetime}
+)
+
+type functionSignature struct {
+	// the types accepted by each parameter of the function
+	parameters [][]string
+	// the type of the result, given the types of the arguments
+	returns func(args []string) string
+}
+
+var functionSignatures = map[string]functionSignature{
+	parser.FunctionLower: {
+		parameters: [][]string{textTypes},
+		returns:    returnsType(parser.FieldTypeText),
+	},
+	parser.FunctionUpper: {
+		parameters: [][]string{textTypes},
+		returns:    returnsType(parser.FieldTypeText),
+	},
+	parser.FunctionTrim: {
+		parameters: [][]string{textTypes},
+		returns:    returnsType(parser.FieldTypeText),
+	},
+	parser.FunctionLength: {
+		parameters: [][]string{textTypes},
+		returns:    returnsType(parser.FieldTypeNumber),
+	},
+	parser.FunctionAbs: {
+		parameters: [][]string{numericTypes},
+		returns:    func(args []string) string { return args[0] },
+	},
+	parser.FunctionRound: {
+		parameters: [][]string{numericTypes},
+		returns:    returnsType(parser.FieldTypeNumber),
+	},
+	parser.FunctionFloor: {
+		parameters: [][]string{numericTypes},
+		returns:    returnsType(parser.FieldTypeNumber),
+	},
+	parser.FunctionCeil: {
+		parameters: [][]string{numericTypes},
+		returns:    returnsType(parser.FieldTypeNumber),
+	},
+	parser.FunctionYear: {
+		parameters: [][]string{temporalTypes},
+		returns:    returnsType(parser.FieldTypeNumber),
+	},
+	parser.FunctionMonth: {
+		parameters: [][]string{temporalTypes},
+		returns:    returnsType(parser.FieldTypeNumber),
+	},
+	parser.FunctionDay: {
+		parameters: [][]string{temporalTypes},
+		returns:    returnsType(parser.FieldTypeNumber),
+	},
+}
+
+func returnsType(t string) func(args []string) string {
+	return func(_ []string) string {
+		return t
+	}
+}
+
+// computedTerm is an operand of a computed value along with the type it resolves to
+type computedTerm struct {
+	source string
+	typ    string
+}
+
+// resolveComputed resolves the type of an arithmetic operation or function, checking that the types
+// of its operands are compatible.
+func (o *OperandResolver) resolveComputed() (*ExpressionScopeEntity, *ResolutionError) {
+	var term *computedTerm
+	var err *ResolutionError
+
+	if o.operand.Arithmetic != nil {
+		term, err = o.resolveArithmetic()
+	} else {
+		term, err = o.resolveFunction(o.operand.Function)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExpressionScopeEntity{
+		Type: term.typ,
+	}, nil
+}
+
+func (o *OperandResolver) resolveArithmetic() (*computedTerm, *ResolutionError) {
+	operands, operators := o.operand.ArithmeticTerms()
+
+	terms := []*computedTerm{}
+	for _, operand := range operands {
+		term, err := o.resolveTerm(operand)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	result, err := parser.FoldArithmetic(terms, operators, func(lhs *computedTerm, operator string, rhs *computedTerm) (*computedTerm, error) {
+		t, ok := arithmeticType(lhs.typ, operator, rhs.typ)
+		if !ok {
+			return nil, fmt.Errorf("%s cannot be used between %s (%s) and %s (%s)", operator, lhs.source, lhs.typ, rhs.source, rhs.typ)
+		}
+
+		return &computedTerm{
+			source: fmt.Sprintf("%s %s %s", lhs.source, operator, rhs.source),
+			typ:    t,
+		}, nil
+	})
+	if err != nil {
+		return nil, o.typeError(err.Error())
+	}
+
+	return result, nil
+}
+
+func (o *OperandResolver) resolveFunction(function *parser.Function) (*computedTerm, *ResolutionError) {
+	name := function.Name.Fragment
+
+	args := []*computedTerm{}
+	for _, arg := range function.Arguments {
+		term, err := o.resolveTerm(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, term)
+	}
+
+	argTypes := lo.Map(args, func(a *computedTerm, _ int) string { return a.typ })
+
+	if name == parser.FunctionCoalesce {
+		if len(args) < 2 {
+			return nil, o.typeError("coalesce requires at least two arguments")
+		}
+
+		for _, arg := range args[1:] {
+			if arg.typ != args[0].typ {
+				return nil, o.typeError(fmt.Sprintf("the arguments of coalesce must all be the same type, but %s is %s and %s is %s", args[0].source, args[0].typ, arg.source, arg.typ))
+			}
+		}
+
+		return &computedTerm{source: function.ToString(), typ: args[0].typ}, nil
+	}
+
+	signature, ok := functionSignatures[name]
+	if !ok {
+		return nil, &ResolutionError{
+			operand: o.operand,
+			validationError: errorhandling.NewValidationErrorWithDetails(
+				errorhandling.UndefinedError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s is not a function", name),
+					Hint:    fmt.Sprintf("The available functions are %s", strings.Join(parser.Functions, ", ")),
+				},
+				function.Name,
+			),
+		}
+	}
+
+	if len(args) != len(signature.parameters) {
+		return nil, o.typeError(fmt.Sprintf("%s expects %d argument(s) but was given %d", name, len(signature.parameters), len(args)))
+	}
+
+	for i, arg := range args {
+		if !lo.Contains(signature.parameters[i], arg.typ) {
+			return nil, o.typeError(fmt.Sprintf("%s expects %s but %s is %s", name, strings.Join(signature.parameters[i], " or "), arg.source, arg.typ))
+		}
+	}
+
+	return &computedTerm{source: function.ToString(), typ: signature.returns(argTypes)}, nil
+}
+
+// resolveTerm resolves an operand used within an arithmetic operation or as the argument of a function
+func (o *OperandResolver) resolveTerm(operand *parser.Operand) (*computedTerm, *ResolutionError) {
+	entity, err := NewOperandResolver(operand, o.asts, o.context, o.position).Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	if entity.IsRepeated() {
+		return nil, o.typeError(fmt.Sprintf("%s is an array and cannot be used to compute a value", operand.ToString()))
+	}
+
+	if entity.IsNull() {
+		return nil, o.typeError("null cannot be used to compute a value")
+	}
+
+	t := entity.GetType()
+	if entity.IsEnumField() || entity.IsEnumValue() || entity.Model != nil || entity.Object != nil {
+		return nil, o.typeError(fmt.Sprintf("%s is %s and cannot be used to compute a value", operand.ToString(), t))
+	}
+
+	return &computedTerm{source: operand.ToString(), typ: t}, nil
+}
+
+func (o *OperandResolver) typeError(message string) *ResolutionError {
+	return &ResolutionError{
+		operand: o.operand,
+		validationError: errorhandling.NewValidationErrorWithDetails(
+			errorhandling.TypeError,
+			errorhandling.ErrorDetails{
+				Message: message,
+			},
+			o.operand,
+		),
+	}
+}
+
+// arithmeticType returns the type resulting from an arithmetic operation between two types
+func arithmeticType(lhs string, operator string, rhs string) (string, bool) {
+	switch {
+	case lo.Contains(numericTypes, lhs) && lo.Contains(numericTypes, rhs):
+		if lhs == parser.FieldTypeDecimal || rhs == parser.FieldTypeDecimal {
+			return parser.FieldTypeDecimal, true
+		}
+		return parser.FieldTypeNumber, true
+	case lo.Contains(textTypes, lhs) && lo.Contains(textTypes, rhs):
+		// Text can be concatenated
+		return parser.FieldTypeText, operator == parser.OperatorAdd
+	case lo.Contains(temporalTypes, lhs) && rhs == parser.TypeDuration:
+		return lhs, operator == parser.OperatorAdd || operator == parser.OperatorSubtract
+	default:
+		return "", false
+	}
+}
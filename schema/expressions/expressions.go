@@ -105,6 +105,11 @@ func (o *OperandResolver) Resolve() (entity *ExpressionScopeEntity, err *Resolut
 	// and also what type attribute the expression is used in.
 	o.scope = applyAdditionalOperandScopes(o.asts, o.scope, o.context)
 
+	// Arithmetic operations and functions are resolved from the types of their operands
+	if o.operand.IsComputed() {
+		return o.resolveComputed()
+	}
+
 	// If it is a literal then handle differently.
 	if ok, _ := o.operand.IsLiteralType(); ok {
 		if o.operand.Type() == parser.TypeArray {
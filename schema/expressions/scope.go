@@ -27,19 +27,35 @@ type ResolutionError struct {
 	fragment *parser.IdentFragment
 	parent   string
 	operand  *parser.Operand
+
+	// validationError is set when the operand resolves but is not valid,
+	// such as an arithmetic operation between incompatible types
+	validationError *errorhandling.ValidationError
 }
 
 func (e *ResolutionError) InScopeEntities() []string {
+	if e.scope == nil {
+		return []string{}
+	}
+
 	return lo.Map(e.scope.Entities, func(e *ExpressionScopeEntity, _ int) string {
 		return e.Name
 	})
 }
 
 func (e *ResolutionError) Error() string {
+	if e.validationError != nil {
+		return e.validationError.Message
+	}
+
 	return fmt.Sprintf("Could not resolve %s in %s", e.fragment.Fragment, e.operand.ToString())
 }
 
 func (e *ResolutionError) ToValidationError() *errorhandling.ValidationError {
+	if e.validationError != nil {
+		return e.validationError
+	}
+
 	suggestions := errorhandling.NewCorrectionHint(e.InScopeEntities(), e.fragment.Fragment)
 
 	literals := map[string]string{
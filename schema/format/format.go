@@ -52,6 +52,8 @@ func Format(ast *parser.AST) string {
 				printMessage(writer, decl.Message)
 			case decl.Job != nil:
 				printJob(writer, decl.Job)
+			case decl.Event != nil:
+				printEvent(writer, decl.Event)
 			}
 		})
 	}
@@ -128,6 +130,45 @@ func printJob(writer *Writer, job *parser.JobNode) {
 	})
 }
 
+func printEvent(writer *Writer, event *parser.EventNode) {
+	writer.comments(event, func() {
+		writer.write("event %s", event.Name.ToString())
+		writer.block(func() {
+			for i, section := range event.Sections {
+				if i > 0 {
+					writer.writeLine("")
+				}
+				writer.comments(section, func() {
+					switch {
+					case section.Attribute != nil:
+						printAttributes(writer, []*parser.AttributeNode{section.Attribute})
+					default:
+						writer.write("fields")
+						writer.block(func() {
+							for _, field := range section.Fields {
+								writer.comments(field, func() {
+									fieldType := field.Type.Value
+									if fieldType != parser.FieldTypeID {
+										fieldType = camel(fieldType)
+									}
+									if field.Repeated {
+										fieldType += "[]"
+									}
+									if field.Optional {
+										fieldType += "?"
+									}
+									writer.write("%s %s", lowerCamel(field.Name.Value), fieldType)
+									writer.writeLine("")
+								})
+							}
+						})
+					}
+				})
+			}
+		})
+	})
+}
+
 func printModel(writer *Writer, model *parser.ModelNode) {
 	writer.comments(model, func() {
 		writer.write("model %s", camel(model.Name.Value))
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"hash/crc32"
 	"strings"
 
 	"github.com/samber/lo"
@@ -42,6 +43,8 @@ func (scm *Builder) makeProtoModels() *proto.Schema {
 				scm.makeEnum(decl)
 			case decl.Job != nil:
 				scm.makeJob(decl)
+			case decl.Event != nil:
+				scm.makeEvent(decl)
 			case decl.Message != nil:
 				// noop
 			default:
@@ -61,6 +64,7 @@ func (scm *Builder) makeProtoModels() *proto.Schema {
 				Name: secret,
 			})
 		}
+		scm.makeWebhookEvents()
 	}
 
 	// Only configure a default API if:
@@ -862,6 +866,33 @@ func makeListOrderByMessages(actionName string, fieldNames []string) []*proto.Me
 	return messages
 }
 
+// Creates the input message for a list action with @nearest, which has the vector to measure
+// the distance to and an optional maximum distance of the results.
+func makeNearestMessage(actionName string) *proto.Message {
+	name := makeNearestMessageName(actionName)
+
+	return &proto.Message{
+		Name: name,
+		Fields: []*proto.MessageField{
+			{
+				MessageName: name,
+				Name:        "to",
+				Type: &proto.TypeInfo{
+					Type: proto.Type_TYPE_VECTOR,
+				},
+			},
+			{
+				MessageName: name,
+				Name:        "maxDistance",
+				Optional:    true,
+				Type: &proto.TypeInfo{
+					Type: proto.Type_TYPE_DECIMAL,
+				},
+			},
+		},
+	}
+}
+
 // Creates a proto.Message from a slice of action inputs.
 func (scm *Builder) makeMessageFromActionInputNodes(name string, inputs []*parser.ActionInputNode, model *parser.ModelNode) *proto.Message {
 	fields := []*proto.MessageField{}
@@ -893,13 +924,22 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 	currMessage := rootMessage
 	currModel := model.Name.Value
 
+	isQuery := isQueryInput(action, input)
+
+	// The nested messages of an updateMany action's filters are named after its where message,
+	// so that they do not clash with the nested messages of its values.
+	messagePrefix := action.Name.Value
+	if isQuery && action.Type.Value == parser.ActionTypeUpdateMany {
+		messagePrefix = makeWhereMessageName(action.Name.Value)
+	}
+
 	for currIndex, fragment := range target {
 		if currIndex < len(target)-1 {
 			// If this is not the last target fragment, then we know the current fragment is referring to a related model field.
 			// Therefore, we must create a new message for this related model and add it to the current message as a field (if this hasn't already been done with a previous input).
 
 			// Message name of nested message appended with the target framements. E.g. CreateSaleItemsInput
-			relatedModelMessageName := makeInputMessageName(action.Name.Value, target[0:currIndex+1]...)
+			relatedModelMessageName := makeInputMessageName(messagePrefix, target[0:currIndex+1]...)
 
 			// Does the field already exist from a previous input?
 			fieldAlreadyCreated := false
@@ -922,14 +962,14 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 					Type: &proto.TypeInfo{
 						Type: proto.Type_TYPE_MESSAGE,
 						// Repeated with be true in a 1:M relationship for create only.
-						Repeated: action.Type.Value != parser.ActionTypeList && field.Repeated,
+						Repeated: !isQuery && field.Repeated,
 						MessageName: &wrapperspb.StringValue{
 							Value: relatedModelMessageName,
 						},
 					},
 					Optional: input.Optional,
 					// List op implicit inputs are not nullable, because they will have a query type.
-					Nullable:    action.Type.Value != parser.ActionTypeList && field.Optional,
+					Nullable:    !isQuery && field.Optional,
 					MessageName: currMessage.Name,
 				})
 
@@ -950,7 +990,7 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 		} else {
 			typeInfo, target, targetsOptionalField := scm.inferParserInputType(model, input)
 
-			if action.Type.Value == parser.ActionTypeList {
+			if isQuery {
 				queryMessage, err := scm.makeListQueryInputMessage(typeInfo)
 				if err != nil {
 					panic(err.Error())
@@ -985,6 +1025,82 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 	}
 }
 
+// isQueryInput is true if the implicit input filters the records of a list, updateMany or deleteMany
+// action, and so takes a query message of operators rather than a value.
+func isQueryInput(action *parser.ActionNode, input *parser.ActionInputNode) bool {
+	switch action.Type.Value {
+	case parser.ActionTypeList:
+		return true
+	case parser.ActionTypeUpdateMany, parser.ActionTypeDeleteMany:
+		return lo.Contains(action.Inputs, input)
+	default:
+		return false
+	}
+}
+
+// Adds the fields for the write inputs of an action, i.e. those in its 'with' clause, to the message.
+func (scm *Builder) addWriteInputFields(message *proto.Message, model *parser.ModelNode, action *parser.ActionNode) {
+	for _, input := range action.With {
+		if input.Label == nil {
+			// If its an implicit input, then create a nested object input structure.
+			scm.makeMessageHierarchyFromImplicitInput(message, input, model, action)
+		} else {
+			// This is an explicit input, so the first and only fragment will reference the type used.
+			typeInfo := scm.explicitInputToTypeInfo(input)
+
+			message.Fields = append(message.Fields, &proto.MessageField{
+				Name:        input.Label.Value,
+				Type:        typeInfo,
+				Optional:    input.Optional,
+				Nullable:    false, // TODO: can explicit inputs use the null value?
+				MessageName: message.Name,
+			})
+		}
+	}
+}
+
+// Creates the where message for the filter inputs of a list, updateMany or deleteMany action and adds it to the proto schema.
+func (scm *Builder) makeFilterInputMessage(model *parser.ModelNode, action *parser.ActionNode) *proto.Message {
+	whereMessage := &proto.Message{
+		Name:   makeWhereMessageName(action.Name.Value),
+		Fields: []*proto.MessageField{},
+	}
+
+	for _, input := range action.Inputs {
+		if input.Label == nil {
+			scm.makeMessageHierarchyFromImplicitInput(whereMessage, input, model, action)
+		} else {
+			typeInfo := scm.explicitInputToTypeInfo(input)
+
+			whereMessage.Fields = append(whereMessage.Fields, &proto.MessageField{
+				Name:        input.Name(),
+				Type:        typeInfo,
+				Optional:    input.Optional,
+				MessageName: makeWhereMessageName(action.Name.Value),
+			})
+		}
+	}
+
+	scm.proto.Messages = append(scm.proto.Messages, whereMessage)
+
+	return whereMessage
+}
+
+// Creates the root message field for a nested message, which is optional if all of the nested message's fields are.
+func makeNestedMessageField(name string, parentMessageName string, message *proto.Message) *proto.MessageField {
+	return &proto.MessageField{
+		Name: name,
+		Optional: len(message.Fields) == 0 || lo.EveryBy(message.Fields, func(f *proto.MessageField) bool {
+			return f.Optional
+		}),
+		MessageName: parentMessageName,
+		Type: &proto.TypeInfo{
+			Type:        proto.Type_TYPE_MESSAGE,
+			MessageName: wrapperspb.String(message.Name),
+		},
+	}
+}
+
 // Adds a set of proto.Messages to top level Messages registry for all inputs of an Action
 func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *parser.ActionNode) {
 	switch action.Type.Value {
@@ -995,23 +1111,7 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 		}
 		scm.proto.Messages = append(scm.proto.Messages, rootMessage)
 
-		for _, input := range action.With {
-			if input.Label == nil {
-				// If its an implicit input, then create a nested object input structure.
-				scm.makeMessageHierarchyFromImplicitInput(rootMessage, input, model, action)
-			} else {
-				// This is an explicit input, so the first and only fragment will reference the type used.
-				typeInfo := scm.explicitInputToTypeInfo(input)
-
-				rootMessage.Fields = append(rootMessage.Fields, &proto.MessageField{
-					Name:        input.Label.Value,
-					Type:        typeInfo,
-					Optional:    input.Optional,
-					Nullable:    false, // TODO: can explicit inputs use the null value?
-					MessageName: rootMessage.Name,
-				})
-			}
-		}
+		scm.addWriteInputFields(rootMessage, model, action)
 	case parser.ActionTypeGet, parser.ActionTypeDelete, parser.ActionTypeRead, parser.ActionTypeWrite:
 		// Create message and add it to the proto schema
 		messageName := makeInputMessageName(action.Name.Value)
@@ -1029,74 +1129,70 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 			Fields: []*proto.MessageField{},
 		}
 		scm.proto.Messages = append(scm.proto.Messages, valuesMessage)
-		for _, input := range action.With {
-			if input.Label == nil {
-				// If its an implicit input, then create a nested object input structure.
-				scm.makeMessageHierarchyFromImplicitInput(valuesMessage, input, model, action)
-			} else {
-				// This is an explicit input, so the first and only fragment will reference the type used.
-				typeInfo := scm.explicitInputToTypeInfo(input)
+		scm.addWriteInputFields(valuesMessage, model, action)
 
-				valuesMessage.Fields = append(valuesMessage.Fields, &proto.MessageField{
-					Name:        input.Label.Value,
-					Type:        typeInfo,
-					Optional:    input.Optional,
-					Nullable:    false, // TODO: can explicit inputs use the null value?
-					MessageName: valuesMessage.Name,
-				})
-			}
+		// Create root action message with "where" and "values" fields.
+		scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
+			Name: makeInputMessageName(action.Name.Value),
+			Fields: []*proto.MessageField{
+				makeNestedMessageField("where", makeInputMessageName(action.Name.Value), whereMessage),
+				makeNestedMessageField("values", makeInputMessageName(action.Name.Value), valuesMessage),
+			},
+		})
+	case parser.ActionTypeCreateMany:
+		// Create values message for each record and add it to the proto schema
+		valuesMessage := &proto.Message{
+			Name:   makeValuesMessageName(action.Name.Value),
+			Fields: []*proto.MessageField{},
 		}
+		scm.proto.Messages = append(scm.proto.Messages, valuesMessage)
+		scm.addWriteInputFields(valuesMessage, model, action)
 
-		// Create root action message with "where" and "values" fields.
+		// Create root action message with a "values" field of the records to create.
 		scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
 			Name: makeInputMessageName(action.Name.Value),
 			Fields: []*proto.MessageField{
 				{
-					Name: "where",
-					Optional: len(whereMessage.Fields) == 0 || lo.EveryBy(whereMessage.Fields, func(f *proto.MessageField) bool {
-						return f.Optional
-					}),
+					Name:        "values",
 					MessageName: makeInputMessageName(action.Name.Value),
 					Type: &proto.TypeInfo{
 						Type:        proto.Type_TYPE_MESSAGE,
-						MessageName: wrapperspb.String(makeWhereMessageName(action.Name.Value)),
-					},
-				},
-				{
-					Name: "values",
-					Optional: len(valuesMessage.Fields) == 0 || lo.EveryBy(valuesMessage.Fields, func(f *proto.MessageField) bool {
-						return f.Optional
-					}),
-					MessageName: makeInputMessageName(action.Name.Value),
-					Type: &proto.TypeInfo{
-						Type:        proto.Type_TYPE_MESSAGE,
-						MessageName: wrapperspb.String(makeValuesMessageName(action.Name.Value)),
+						Repeated:    true,
+						MessageName: wrapperspb.String(valuesMessage.Name),
 					},
 				},
 			},
 		})
-	case parser.ActionTypeList:
-		whereMessage := &proto.Message{
-			Name:   makeWhereMessageName(action.Name.Value),
+	case parser.ActionTypeUpdateMany:
+		whereMessage := scm.makeFilterInputMessage(model, action)
+
+		valuesMessage := &proto.Message{
+			Name:   makeValuesMessageName(action.Name.Value),
 			Fields: []*proto.MessageField{},
 		}
+		scm.proto.Messages = append(scm.proto.Messages, valuesMessage)
+		scm.addWriteInputFields(valuesMessage, model, action)
 
-		for _, input := range action.Inputs {
-			if input.Label == nil {
-				scm.makeMessageHierarchyFromImplicitInput(whereMessage, input, model, action)
-			} else {
-				typeInfo := scm.explicitInputToTypeInfo(input)
-
-				whereMessage.Fields = append(whereMessage.Fields, &proto.MessageField{
-					Name:        input.Name(),
-					Type:        typeInfo,
-					Optional:    input.Optional,
-					MessageName: makeWhereMessageName(action.Name.Value),
-				})
-			}
-		}
+		// Create root action message with "where" and "values" fields.
+		scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
+			Name: makeInputMessageName(action.Name.Value),
+			Fields: []*proto.MessageField{
+				makeNestedMessageField("where", makeInputMessageName(action.Name.Value), whereMessage),
+				makeNestedMessageField("values", makeInputMessageName(action.Name.Value), valuesMessage),
+			},
+		})
+	case parser.ActionTypeDeleteMany:
+		whereMessage := scm.makeFilterInputMessage(model, action)
 
-		scm.proto.Messages = append(scm.proto.Messages, whereMessage)
+		// Create root action message with a "where" field.
+		scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
+			Name: makeInputMessageName(action.Name.Value),
+			Fields: []*proto.MessageField{
+				makeNestedMessageField("where", makeInputMessageName(action.Name.Value), whereMessage),
+			},
+		})
+	case parser.ActionTypeList:
+		whereMessage := scm.makeFilterInputMessage(model, action)
 
 		sortableFields, err := query.ActionSortableFieldNames(action)
 		if err != nil {
@@ -1106,17 +1202,7 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 		inputMessage := &proto.Message{
 			Name: makeInputMessageName(action.Name.Value),
 			Fields: []*proto.MessageField{
-				{
-					Name: "where",
-					Optional: len(whereMessage.Fields) == 0 || lo.EveryBy(whereMessage.Fields, func(f *proto.MessageField) bool {
-						return f.Optional
-					}),
-					MessageName: makeInputMessageName(action.Name.Value),
-					Type: &proto.TypeInfo{
-						Type:        proto.Type_TYPE_MESSAGE,
-						MessageName: wrapperspb.String(makeWhereMessageName(action.Name.Value)),
-					},
-				},
+				makeNestedMessageField("where", makeInputMessageName(action.Name.Value), whereMessage),
 				// Include pagination fields
 				{
 					Name:        "first",
@@ -1170,6 +1256,20 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 			inputMessage.Fields = append(inputMessage.Fields, orderByMessageField)
 		}
 
+		if _, found := lo.Find(action.Attributes, func(a *parser.AttributeNode) bool { return a.Name.Value == parser.AttributeNearest }); found {
+			nearestMessage := makeNearestMessage(action.Name.Value)
+
+			scm.proto.Messages = append(scm.proto.Messages, nearestMessage)
+			inputMessage.Fields = append(inputMessage.Fields, &proto.MessageField{
+				Name:        "nearest",
+				MessageName: makeInputMessageName(action.Name.Value),
+				Type: &proto.TypeInfo{
+					Type:        proto.Type_TYPE_MESSAGE,
+					MessageName: wrapperspb.String(nearestMessage.Name),
+				},
+			})
+		}
+
 		scm.proto.Messages = append(scm.proto.Messages, inputMessage)
 	default:
 		panic("unhandled action type when creating input message types")
@@ -1198,9 +1298,84 @@ func (scm *Builder) makeModel(decl *parser.DeclarationNode) {
 		}
 	}
 
+	protoModel.Indexes = scm.makeIndexes(parserModel)
+
 	scm.proto.Models = append(scm.proto.Models, protoModel)
 }
 
+// makeIndexes creates the indexes defined by @index attributes on the model's fields and on the model itself.
+func (scm *Builder) makeIndexes(parserModel *parser.ModelNode) []*proto.Index {
+	indexes := []*proto.Index{}
+
+	for _, field := range query.ModelFields(parserModel) {
+		for _, attr := range field.Attributes {
+			if attr.Name.Value == parser.AttributeIndex {
+				indexes = append(indexes, scm.makeIndex(parserModel, []string{field.Name.Value}, attr.Arguments))
+			}
+		}
+	}
+
+	for _, attr := range query.ModelAttributes(parserModel) {
+		if attr.Name.Value != parser.AttributeIndex {
+			continue
+		}
+
+		value, _ := attr.Arguments[0].Expression.ToValue()
+		fieldNames := lo.Map(value.Array.Values, func(v *parser.Operand, _ int) string {
+			return v.Ident.ToString()
+		})
+
+		indexes = append(indexes, scm.makeIndex(parserModel, fieldNames, attr.Arguments[1:]))
+	}
+
+	return indexes
+}
+
+func (scm *Builder) makeIndex(parserModel *parser.ModelNode, fieldNames []string, arguments []*parser.AttributeArgumentNode) *proto.Index {
+	// Relationship fields are indexed using their foreign key field
+	fieldNames = lo.Map(fieldNames, func(name string, _ int) string {
+		field := query.Field(parserModel, name)
+		if field != nil && query.IsModel(scm.asts, field.Type.Value) {
+			return fmt.Sprintf("%sId", name)
+		}
+		return name
+	})
+
+	index := &proto.Index{
+		Name:       makeIndexName(parserModel.Name.Value, fieldNames),
+		FieldNames: fieldNames,
+		Method:     proto.IndexMethod_INDEX_METHOD_BTREE,
+	}
+
+	for _, arg := range arguments {
+		switch arg.Label.Value {
+		case parser.IndexArgumentMethod:
+			value, _ := arg.Expression.ToValue()
+			switch value.Ident.Fragments[0].Fragment {
+			case parser.IndexMethodGin:
+				index.Method = proto.IndexMethod_INDEX_METHOD_GIN
+			case parser.IndexMethodHnsw:
+				index.Method = proto.IndexMethod_INDEX_METHOD_HNSW
+			}
+		case parser.IndexArgumentMetric:
+			value, _ := arg.Expression.ToValue()
+			index.Metric = mapToDistanceMetric(value.Ident.Fragments[0].Fragment)
+		case parser.IndexArgumentWhere:
+			source, _ := arg.Expression.ToString()
+			index.Where = &proto.Expression{
+				Source: source,
+			}
+		}
+	}
+
+	// hnsw indexes measure the cosine distance unless another metric is given
+	if index.Method == proto.IndexMethod_INDEX_METHOD_HNSW && index.Metric == proto.DistanceMetric_DISTANCE_METRIC_UNKNOWN {
+		index.Metric = proto.DistanceMetric_DISTANCE_METRIC_COSINE
+	}
+
+	return index
+}
+
 func (scm *Builder) makeRole(decl *parser.DeclarationNode) {
 	parserRole := decl.Role
 	protoRole := &proto.Role{
@@ -1262,7 +1437,14 @@ func (scm *Builder) makeAnyType() {
 func (scm *Builder) makeMessage(decl *parser.DeclarationNode) {
 	parserMsg := decl.Message
 
-	fields := lo.Map(parserMsg.Fields, func(f *parser.FieldNode, _ int) *proto.MessageField {
+	scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
+		Name:   parserMsg.Name.Value,
+		Fields: scm.makeMessageFields(parserMsg.Name.Value, parserMsg.Fields),
+	})
+}
+
+func (scm *Builder) makeMessageFields(messageName string, parserFields []*parser.FieldNode) []*proto.MessageField {
+	return lo.Map(parserFields, func(f *parser.FieldNode, _ int) *proto.MessageField {
 		field := &proto.MessageField{
 			Name: f.Name.Value,
 			Type: &proto.TypeInfo{
@@ -1270,7 +1452,7 @@ func (scm *Builder) makeMessage(decl *parser.DeclarationNode) {
 				Repeated: f.Repeated,
 			},
 			Optional:    f.Optional,
-			MessageName: parserMsg.Name.Value,
+			MessageName: messageName,
 		}
 
 		if field.Type.Type == proto.Type_TYPE_ENUM {
@@ -1287,11 +1469,6 @@ func (scm *Builder) makeMessage(decl *parser.DeclarationNode) {
 
 		return field
 	})
-
-	scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
-		Name:   parserMsg.Name.Value,
-		Fields: fields,
-	})
 }
 
 func (scm *Builder) makeEnum(decl *parser.DeclarationNode) {
@@ -1308,6 +1485,32 @@ func (scm *Builder) makeEnum(decl *parser.DeclarationNode) {
 	scm.proto.Enums = append(scm.proto.Enums, enum)
 }
 
+func (scm *Builder) makeEvent(decl *parser.DeclarationNode) {
+	parserEvent := decl.Event
+	eventName := parserEvent.Name.ToString()
+	messageName := makeEventPayloadMessageName(eventName)
+
+	scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
+		Name:   messageName,
+		Fields: scm.makeMessageFields(messageName, parserEvent.Fields()),
+	})
+
+	scm.proto.Events = append(scm.proto.Events, &proto.Event{
+		Name:        eventName,
+		MessageName: messageName,
+	})
+
+	for _, attribute := range parserEvent.Attributes() {
+		if attribute.Name.Value != parser.AttributeOn {
+			continue
+		}
+
+		subscriberArg, _ := attribute.Arguments[0].Expression.ToValue()
+		subscriber := scm.findOrCreateSubscriber(subscriberArg.Ident.Fragments[0].Fragment)
+		subscriber.EventNames = append(subscriber.EventNames, eventName)
+	}
+}
+
 func (scm *Builder) makeJob(decl *parser.DeclarationNode) {
 	parserJob := decl.Job
 	messageName := makeJobMessageName(parserJob.Name.Value)
@@ -1389,6 +1592,11 @@ func (scm *Builder) makeField(parserField *parser.FieldNode, modelName string) *
 			RelatedModelName:  modelField.Type.Value,
 			RelatedModelField: parser.FieldNameId,
 		}
+		protoField.OnDelete = onDeleteAction(modelField)
+	}
+
+	if protoField.Type.Type == proto.Type_TYPE_MODEL {
+		protoField.OnDelete = onDeleteAction(parserField)
 	}
 
 	relationship, err := query.GetRelationship(scm.asts, query.Model(scm.asts, modelName), parserField)
@@ -1423,9 +1631,9 @@ func (scm *Builder) setInverseFieldName(thisParserField *parser.FieldNode, thisP
 	relatedModel := query.Model(scm.asts, nameOfRelatedModel)
 
 	// Use the field name in @relation(fieldName) if this attribute exists
-	relationAttr := query.FieldGetAttribute(thisParserField, parser.AttributeRelation)
+	relationAttr := query.FieldRelationAttribute(thisParserField)
 	if relationAttr != nil {
-		inverseFieldName := attributeFirstArgAsIdentifier(relationAttr)
+		inverseFieldName, _ := query.RelationAttributeValue(relationAttr)
 		thisProtoField.InverseFieldName = wrapperspb.String(inverseFieldName)
 		return
 	}
@@ -1435,9 +1643,9 @@ func (scm *Builder) setInverseFieldName(thisParserField *parser.FieldNode, thisP
 		if remoteField.Type.Value != thisProtoField.ModelName {
 			continue
 		}
-		relationAttr := query.FieldGetAttribute(remoteField, parser.AttributeRelation)
+		relationAttr := query.FieldRelationAttribute(remoteField)
 		if relationAttr != nil {
-			inverseFieldName := attributeFirstArgAsIdentifier(relationAttr)
+			inverseFieldName, _ := query.RelationAttributeValue(relationAttr)
 			if inverseFieldName == thisProtoField.Name {
 				thisProtoField.InverseFieldName = wrapperspb.String(remoteField.Name.Value)
 				return
@@ -1463,14 +1671,31 @@ func (scm *Builder) setInverseFieldName(thisParserField *parser.FieldNode, thisP
 	}
 }
 
-// attributeFirstArgAsIdentifier fishes out the identifier being held
-// by the first argument of the given attribute. It must only be called when
-// you know that it is well formed for that.
-func attributeFirstArgAsIdentifier(attr *parser.AttributeNode) string {
-	expr := attr.Arguments[0].Expression
-	operand, _ := expr.ToValue()
-	theString := operand.Ident.Fragments[0].Fragment
-	return theString
+// onDeleteAction is the referential action defined by @relation(onDelete: ...) on the relationship field.
+func onDeleteAction(field *parser.FieldNode) proto.OnDelete {
+	relationAttr := query.FieldGetAttribute(field, parser.AttributeRelation)
+	if relationAttr == nil {
+		return proto.OnDelete_ON_DELETE_UNKNOWN
+	}
+
+	arg := query.RelationOnDeleteArgument(relationAttr)
+	if arg == nil {
+		return proto.OnDelete_ON_DELETE_UNKNOWN
+	}
+
+	value, _ := arg.Expression.ToValue()
+	switch value.Ident.Fragments[0].Fragment {
+	case parser.OnDeleteCascade:
+		return proto.OnDelete_ON_DELETE_CASCADE
+	case parser.OnDeleteRestrict:
+		return proto.OnDelete_ON_DELETE_RESTRICT
+	case parser.OnDeleteSetNull:
+		return proto.OnDelete_ON_DELETE_SET_NULL
+	case parser.OnDeleteNoAction:
+		return proto.OnDelete_ON_DELETE_NO_ACTION
+	default:
+		return proto.OnDelete_ON_DELETE_UNKNOWN
+	}
 }
 
 func (scm *Builder) makeActions(actions []*parser.ActionNode, modelName string, builtIn bool) []*proto.Action {
@@ -1715,16 +1940,7 @@ func (scm *Builder) applyModelAttribute(parserModel *parser.ModelNode, protoMode
 		subscriberArg, _ := attribute.Arguments[1].Expression.ToValue()
 		subscriberName := subscriberArg.Ident.Fragments[0].Fragment
 
-		// Create the subscriber if it has not yet been created yet.
-		subscriber := proto.FindSubscriber(scm.proto.Subscribers, subscriberName)
-		if subscriber == nil {
-			subscriber = &proto.Subscriber{
-				Name:             subscriberName,
-				InputMessageName: makeSubscriberMessageName(subscriberName),
-				EventNames:       []string{},
-			}
-			scm.proto.Subscribers = append(scm.proto.Subscribers, subscriber)
-		}
+		subscriber := scm.findOrCreateSubscriber(subscriberName)
 
 		// For each event, add to the proto schema if it doesn't exist,
 		// and add it to the current subscriber's EventNames field.
@@ -1748,6 +1964,44 @@ func (scm *Builder) applyModelAttribute(parserModel *parser.ModelNode, protoMode
 	}
 }
 
+// findOrCreateSubscriber creates the subscriber if it has not yet been created yet.
+func (scm *Builder) findOrCreateSubscriber(subscriberName string) *proto.Subscriber {
+	subscriber := proto.FindSubscriber(scm.proto.Subscribers, subscriberName)
+	if subscriber == nil {
+		subscriber = &proto.Subscriber{
+			Name:             subscriberName,
+			InputMessageName: makeSubscriberMessageName(subscriberName),
+			EventNames:       []string{},
+		}
+		scm.proto.Subscribers = append(scm.proto.Subscribers, subscriber)
+	}
+	return subscriber
+}
+
+// makeWebhookEvents adds the events which webhooks are configured to receive, as these
+// may not have any subscribers in the schema.
+func (scm *Builder) makeWebhookEvents() {
+	for _, webhook := range scm.Config.Events.Webhooks {
+		for _, eventName := range webhook.Events {
+			if proto.FindEvent(scm.proto.Events, eventName) != nil {
+				continue
+			}
+
+			for _, model := range scm.proto.Models {
+				for _, actionType := range []proto.ActionType{proto.ActionType_ACTION_TYPE_CREATE, proto.ActionType_ACTION_TYPE_UPDATE, proto.ActionType_ACTION_TYPE_DELETE} {
+					if makeEventName(model.Name, mapToEventType(actionType)) == eventName {
+						scm.proto.Events = append(scm.proto.Events, &proto.Event{
+							Name:       eventName,
+							ModelName:  model.Name,
+							ActionType: actionType,
+						})
+					}
+				}
+			}
+		}
+	}
+}
+
 // makeSubscriberInputMessages creates the event input messages for the subscriber functions.
 // The signature of these messages depends on which events the subscriber is handling.
 func (scm *Builder) makeSubscriberInputMessages() {
@@ -1766,6 +2020,13 @@ func (scm *Builder) makeSubscriberInputMessages() {
 		for _, eventName := range subscriber.EventNames {
 			event := proto.FindEvent(scm.proto.Events, eventName)
 
+			if event.MessageName != "" {
+				eventMessage := scm.makeSubscriberUserEventMessage(subscriber, event)
+				message.Type.UnionNames = append(message.Type.UnionNames, wrapperspb.String(eventMessage.Name))
+				scm.proto.Messages = append(scm.proto.Messages, eventMessage)
+				continue
+			}
+
 			eventMessage := &proto.Message{
 				Name:   makeSubscriberMessageEventName(subscriber.Name, event.ModelName, mapToEventType(event.ActionType)),
 				Fields: []*proto.MessageField{},
@@ -1848,6 +2109,46 @@ func (scm *Builder) makeSubscriberInputMessages() {
 	}
 }
 
+// makeSubscriberUserEventMessage creates the message for a user-defined event received by a subscriber,
+// where the data is the payload of the event.
+func (scm *Builder) makeSubscriberUserEventMessage(subscriber *proto.Subscriber, event *proto.Event) *proto.Message {
+	eventMessage := &proto.Message{
+		Name: makeSubscriberMessageUserEventName(subscriber.Name, event.Name),
+	}
+
+	eventMessage.Fields = []*proto.MessageField{
+		{
+			MessageName: eventMessage.Name,
+			Name:        "eventName",
+			Type: &proto.TypeInfo{
+				Type:               proto.Type_TYPE_STRING_LITERAL,
+				StringLiteralValue: wrapperspb.String(event.Name),
+			},
+		},
+		{
+			MessageName: eventMessage.Name,
+			Name:        "occurredAt",
+			Type:        &proto.TypeInfo{Type: proto.Type_TYPE_TIMESTAMP},
+		},
+		{
+			MessageName: eventMessage.Name,
+			Name:        "identityId",
+			Optional:    true,
+			Type:        &proto.TypeInfo{Type: proto.Type_TYPE_ID},
+		},
+		{
+			MessageName: eventMessage.Name,
+			Name:        "data",
+			Type: &proto.TypeInfo{
+				Type:        proto.Type_TYPE_MESSAGE,
+				MessageName: wrapperspb.String(event.MessageName),
+			},
+		},
+	}
+
+	return eventMessage
+}
+
 func (scm *Builder) applyActionAttributes(action *parser.ActionNode, protoAction *proto.Action, modelName string) {
 	for _, attribute := range action.Attributes {
 		switch attribute.Name.Value {
@@ -1864,6 +2165,9 @@ func (scm *Builder) applyActionAttributes(action *parser.ActionNode, protoAction
 			expr, _ := attribute.Arguments[0].Expression.ToString()
 			set := &proto.Expression{Source: expr}
 			protoAction.SetExpressions = append(protoAction.SetExpressions, set)
+		case parser.AttributeEmit:
+			operand, _ := attribute.Arguments[0].Expression.ToValue()
+			protoAction.Emits = append(protoAction.Emits, operand.Ident.ToString())
 		case parser.AttributeValidate:
 			expr, _ := attribute.Arguments[0].Expression.ToString()
 			set := &proto.Expression{Source: expr}
@@ -1883,6 +2187,13 @@ func (scm *Builder) applyActionAttributes(action *parser.ActionNode, protoAction
 				}
 				protoAction.OrderBy = append(protoAction.OrderBy, orderBy)
 			}
+		case parser.AttributeNearest:
+			arg := attribute.Arguments[0]
+			metric, _ := arg.Expression.ToString()
+			protoAction.Nearest = &proto.NearestNeighbour{
+				FieldName: arg.Label.Value,
+				Metric:    mapToDistanceMetric(metric),
+			}
 		}
 	}
 }
@@ -1967,6 +2278,12 @@ func (scm *Builder) mapToActionType(actionType string) proto.ActionType {
 		return proto.ActionType_ACTION_TYPE_READ
 	case parser.ActionTypeWrite:
 		return proto.ActionType_ACTION_TYPE_WRITE
+	case parser.ActionTypeCreateMany:
+		return proto.ActionType_ACTION_TYPE_CREATE_MANY
+	case parser.ActionTypeUpdateMany:
+		return proto.ActionType_ACTION_TYPE_UPDATE_MANY
+	case parser.ActionTypeDeleteMany:
+		return proto.ActionType_ACTION_TYPE_DELETE_MANY
 	default:
 		return proto.ActionType_ACTION_TYPE_UNKNOWN
 	}
@@ -1996,6 +2313,19 @@ func mapToOrderByDirection(parsedDirection string) proto.OrderDirection {
 	}
 }
 
+func mapToDistanceMetric(parsedMetric string) proto.DistanceMetric {
+	switch parsedMetric {
+	case parser.DistanceMetricCosine:
+		return proto.DistanceMetric_DISTANCE_METRIC_COSINE
+	case parser.DistanceMetricL2:
+		return proto.DistanceMetric_DISTANCE_METRIC_L2
+	case parser.DistanceMetricInnerProduct:
+		return proto.DistanceMetric_DISTANCE_METRIC_INNER_PRODUCT
+	default:
+		return proto.DistanceMetric_DISTANCE_METRIC_UNKNOWN
+	}
+}
+
 func (scm *Builder) applyJobAttribute(protoJob *proto.Job, attribute *parser.AttributeNode) {
 	switch attribute.Name.Value {
 	case parser.AttributePermission:
@@ -2039,6 +2369,21 @@ func stripQuotes(s string) string {
 	return strings.ReplaceAll(s, `"`, "")
 }
 
+// makeIndexName creates the name of the database index for the given fields, which is
+// kept within the 63 character limit Postgres has for identifiers.
+func makeIndexName(modelName string, fieldNames []string) string {
+	snaked := lo.Map(fieldNames, func(s string, _ int) string {
+		return casing.ToSnake(s)
+	})
+
+	name := fmt.Sprintf("%s_%s_idx", casing.ToSnake(modelName), strings.Join(snaked, "_"))
+	if len(name) <= 63 {
+		return name
+	}
+
+	return fmt.Sprintf("%s_%x_idx", name[:50], crc32.ChecksumIEEE([]byte(name)))
+}
+
 func makeInputMessageName(opName string, subMessageNames ...string) string {
 	if len(subMessageNames) > 0 {
 		subFieldNames := strings.Join(
@@ -2054,6 +2399,10 @@ func makeWhereMessageName(opName string) string {
 	return fmt.Sprintf("%sWhere", casing.ToCamel(opName))
 }
 
+func makeNearestMessageName(opName string) string {
+	return fmt.Sprintf("%sNearest", casing.ToCamel(opName))
+}
+
 func makeOrderByMessageName(opName string, fieldName string) string {
 	return fmt.Sprintf("%sOrderBy%s", casing.ToCamel(opName), casing.ToCamel(fieldName))
 }
@@ -2078,6 +2427,14 @@ func makeSubscriberMessageEventTargetName(subscriberName string, modelName strin
 	return fmt.Sprintf("%s%s%sEventTarget", casing.ToCamel(subscriberName), casing.ToCamel(modelName), casing.ToCamel(action))
 }
 
+func makeSubscriberMessageUserEventName(subscriberName string, eventName string) string {
+	return fmt.Sprintf("%s%sEvent", casing.ToCamel(subscriberName), casing.ToCamel(strings.ReplaceAll(eventName, ".", "_")))
+}
+
+func makeEventPayloadMessageName(eventName string) string {
+	return fmt.Sprintf("%sEventPayload", casing.ToCamel(strings.ReplaceAll(eventName, ".", "_")))
+}
+
 func makeEventName(modelName string, action string) string {
 	return fmt.Sprintf("%s.%s", casing.ToSnake(modelName), action)
 }
@@ -16,6 +16,7 @@ const (
 	KeywordWith    = "with"
 	KeywordReturns = "returns"
 	KeywordJob     = "job"
+	KeywordEvent   = "event"
 	KeywordInput   = "inputs"
 )
 
@@ -27,11 +28,13 @@ const (
 	TypeDecimal = "Decimal"
 
 	// These are unique to expressions
-	TypeNull  = "Null"
-	TypeArray = "Array"
-	TypeIdent = "Ident"
-	TypeEnum  = "Enum"
-	TypeModel = "Model"
+	TypeNull     = "Null"
+	TypeArray    = "Array"
+	TypeIdent    = "Ident"
+	TypeEnum     = "Enum"
+	TypeModel    = "Model"
+	TypeDuration = "Duration"
+	TypeFunction = "Function"
 )
 
 const (
@@ -88,6 +91,11 @@ const (
 	ActionTypeList   = "list"
 	ActionTypeDelete = "delete"
 
+	// Bulk action types
+	ActionTypeCreateMany = "createMany"
+	ActionTypeUpdateMany = "updateMany"
+	ActionTypeDeleteMany = "deleteMany"
+
 	// Arbitrary function action types
 	ActionTypeRead  = "read"
 	ActionTypeWrite = "write"
@@ -99,6 +107,9 @@ var ActionTypes = []string{
 	ActionTypeDelete,
 	ActionTypeList,
 	ActionTypeUpdate,
+	ActionTypeCreateMany,
+	ActionTypeUpdateMany,
+	ActionTypeDeleteMany,
 	ActionTypeRead,
 	ActionTypeWrite,
 }
@@ -155,8 +166,58 @@ const (
 	AttributeFunction   = "function"
 	AttributeOn         = "on"
 	AttributeEmbed      = "embed"
+	AttributeIndex      = "index"
+	AttributeNearest    = "nearest"
+	AttributeEmit       = "emit"
+)
+
+// Arguments and methods for the @index attribute
+const (
+	IndexArgumentMethod = "method"
+	IndexArgumentWhere  = "where"
+	IndexArgumentMetric = "metric"
+
+	IndexMethodBtree = "btree"
+	IndexMethodGin   = "gin"
+	IndexMethodHnsw  = "hnsw"
+)
+
+var IndexMethods = []string{
+	IndexMethodBtree,
+	IndexMethodGin,
+	IndexMethodHnsw,
+}
+
+// Arguments and referential actions for the @relation attribute
+const (
+	RelationArgumentOnDelete = "onDelete"
+
+	OnDeleteCascade  = "cascade"
+	OnDeleteRestrict = "restrict"
+	OnDeleteSetNull  = "setNull"
+	OnDeleteNoAction = "noAction"
 )
 
+var OnDeleteActions = []string{
+	OnDeleteCascade,
+	OnDeleteRestrict,
+	OnDeleteSetNull,
+	OnDeleteNoAction,
+}
+
+// The distance metrics for the @nearest attribute and hnsw indexes
+const (
+	DistanceMetricCosine       = "cosine"
+	DistanceMetricL2           = "l2"
+	DistanceMetricInnerProduct = "innerProduct"
+)
+
+var DistanceMetrics = []string{
+	DistanceMetricCosine,
+	DistanceMetricL2,
+	DistanceMetricInnerProduct,
+}
+
 const (
 	OrderByAscending  = "asc"
 	OrderByDescending = "desc"
@@ -110,6 +110,60 @@ var (
 	OperatorDecrement            = "-="
 )
 
+var (
+	OperatorAdd      = "+"
+	OperatorSubtract = "-"
+	OperatorMultiply = "*"
+	OperatorDivide   = "/"
+	OperatorModulo   = "%"
+)
+
+var ArithmeticOperators = []string{
+	OperatorAdd,
+	OperatorSubtract,
+	OperatorMultiply,
+	OperatorDivide,
+	OperatorModulo,
+}
+
+// MultiplicativeOperators take precedence over addition and subtraction
+var MultiplicativeOperators = []string{
+	OperatorMultiply,
+	OperatorDivide,
+	OperatorModulo,
+}
+
+// Functions which can be used in expressions
+var (
+	FunctionLower    = "lower"
+	FunctionUpper    = "upper"
+	FunctionTrim     = "trim"
+	FunctionLength   = "length"
+	FunctionAbs      = "abs"
+	FunctionRound    = "round"
+	FunctionFloor    = "floor"
+	FunctionCeil     = "ceil"
+	FunctionYear     = "year"
+	FunctionMonth    = "month"
+	FunctionDay      = "day"
+	FunctionCoalesce = "coalesce"
+)
+
+var Functions = []string{
+	FunctionLower,
+	FunctionUpper,
+	FunctionTrim,
+	FunctionLength,
+	FunctionAbs,
+	FunctionRound,
+	FunctionFloor,
+	FunctionCeil,
+	FunctionYear,
+	FunctionMonth,
+	FunctionDay,
+	FunctionCoalesce,
+}
+
 var AssignmentOperators = []string{
 	OperatorAssignment,
 }
@@ -125,6 +179,18 @@ var LogicalOperators = []string{
 	OperatorNotIn,
 }
 
+// Operands returns the operands of the condition, including those nested within
+// arithmetic operations and the arguments of functions.
+func (condition *Condition) Operands() []*Operand {
+	operands := []*Operand{}
+	for _, operand := range []*Operand{condition.LHS, condition.RHS} {
+		if operand != nil {
+			operands = append(operands, operand.Operands()...)
+		}
+	}
+	return operands
+}
+
 func (condition *Condition) ToString() string {
 	result := ""
 
@@ -1,8 +1,10 @@
 package parser_test
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/teamkeel/keel/schema/parser"
 )
@@ -29,6 +31,11 @@ func TestRoundTrip(t *testing.T) {
 		"dot notation":           "a.b.c == d.e.f",
 		"negative integer":       "a = -1",
 		"decimal number":         "a = 1.580000", // %f uses a default precision of 6 digits after the decimal point
+		"arithmetic":             "a.b = c * d + e - 1",
+		"negative arithmetic":    "a = b - -1",
+		"duration":               "a.b > ctx.now - 7 days",
+		"function":               "lower(a.b) == lower(trim(c))",
+		"function arguments":     "coalesce(a.b, c, 1) > 2",
 	}
 
 	for name, fixture := range fixtures {
@@ -112,6 +119,8 @@ func TestIsAssignment(t *testing.T) {
 		"a -= 1":          false,
 		"a = 1.23":        true,
 		"a = -1.23":       true,
+		"a = b + 1":       true,
+		"a = b - 1":       true,
 	}
 
 	for input, expected := range fixtures {
@@ -166,3 +175,33 @@ func TestLogicalExpressions(t *testing.T) {
 		})
 	}
 }
+
+func TestArithmeticPrecedence(t *testing.T) {
+	fixtures := map[string]string{
+		"a + b":             "(a + b)",
+		"a + b * c":         "(a + (b * c))",
+		"a * b + c":         "((a * b) + c)",
+		"a - b - c":         "((a - b) - c)",
+		"a / b * c % d - e": "((((a / b) * c) % d) - e)",
+		"a + b * c - d / e": "((a + (b * c)) - (d / e))",
+	}
+
+	for input, expected := range fixtures {
+		t.Run(input, func(t *testing.T) {
+			expr, err := parser.ParseExpression(input)
+			assert.NoError(t, err)
+
+			operand, err := expr.ToValue()
+			assert.NoError(t, err)
+
+			operands, operators := operand.ArithmeticTerms()
+			terms := lo.Map(operands, func(o *parser.Operand, _ int) string { return o.ToString() })
+
+			actual, err := parser.FoldArithmetic(terms, operators, func(lhs string, operator string, rhs string) (string, error) {
+				return fmt.Sprintf("(%s %s %s)", lhs, operator, rhs), nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
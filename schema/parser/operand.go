@@ -2,21 +2,29 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/samber/lo"
 	"github.com/teamkeel/keel/schema/node"
 )
 
 type Operand struct {
 	node.Node
 
-	Number  *int64   `  @('-'? Int)`
-	Decimal *float64 `| @('-'? Float)`
-	String  *string  `| @String`
-	Null    bool     `| @"null"`
-	True    bool     `| @"true"`
-	False   bool     `| @"false"`
-	Array   *Array   `| @@`
-	Ident   *Ident   `| @@`
+	Duration *Duration `( @@`
+	Number   *int64    `| @('-'? Int)`
+	Decimal  *float64  `| @('-'? Float)`
+	String   *string   `| @String`
+	Null     bool      `| @"null"`
+	True     bool      `| @"true"`
+	False    bool      `| @"false"`
+	Array    *Array    `| @@`
+	Function *Function `| @@`
+	Ident    *Ident    `| @@ )`
+
+	// Arithmetic is set when this operand is the first term of an arithmetic operation,
+	// for example item.price * quantity
+	Arithmetic *Arithmetic `@@?`
 }
 
 func (o *Operand) ToString() string {
@@ -24,6 +32,12 @@ func (o *Operand) ToString() string {
 		return ""
 	}
 
+	if o.Arithmetic != nil {
+		operand := *o
+		operand.Arithmetic = nil
+		return fmt.Sprintf("%s %s %s", operand.ToString(), o.Arithmetic.Operator, o.Arithmetic.Operand.ToString())
+	}
+
 	switch o.Type() {
 	case TypeDecimal:
 		return fmt.Sprintf("%f", *o.Decimal)
@@ -50,6 +64,10 @@ func (o *Operand) ToString() string {
 		return r + "]"
 	case TypeIdent:
 		return o.Ident.ToString()
+	case TypeDuration:
+		return o.Duration.ToString()
+	case TypeFunction:
+		return o.Function.ToString()
 	default:
 		return ""
 	}
@@ -57,6 +75,10 @@ func (o *Operand) ToString() string {
 
 func (o *Operand) Type() string {
 	switch {
+	case o.Duration != nil:
+		return TypeDuration
+	case o.Function != nil:
+		return TypeFunction
 	case o.Decimal != nil:
 		return TypeDecimal
 	case o.Number != nil:
@@ -80,6 +102,10 @@ func (o *Operand) Type() string {
 
 func (o *Operand) IsLiteralType() (bool, string) {
 	switch {
+	case o.IsComputed():
+		return false, o.ToString()
+	case o.Duration != nil:
+		return true, o.ToString()
 	case o.Number != nil:
 		return true, o.ToString()
 	case o.Decimal != nil:
@@ -212,3 +238,122 @@ type Array struct {
 
 	Values []*Operand `"[" @@* ( "," @@ )* "]"`
 }
+
+// IsComputed returns true if the value of the operand is computed from other operands,
+// either by an arithmetic operation or a function.
+func (o *Operand) IsComputed() bool {
+	return o.Arithmetic != nil || o.Function != nil
+}
+
+// Operands returns this operand followed by all of the operands nested within it,
+// such as the arguments of a function or the remaining terms of an arithmetic operation.
+func (o *Operand) Operands() []*Operand {
+	operands := []*Operand{o}
+
+	if o.Array != nil {
+		for _, v := range o.Array.Values {
+			operands = append(operands, v.Operands()...)
+		}
+	}
+
+	if o.Function != nil {
+		for _, arg := range o.Function.Arguments {
+			operands = append(operands, arg.Operands()...)
+		}
+	}
+
+	if o.Arithmetic != nil {
+		operands = append(operands, o.Arithmetic.Operand.Operands()...)
+	}
+
+	return operands
+}
+
+// ArithmeticTerms splits an arithmetic operation into its operands and operators in the order
+// they are written. For example, a + b * c has the operands a, b and c and the operators + and *.
+// Precedence is not applied, see FoldArithmetic.
+func (o *Operand) ArithmeticTerms() (operands []*Operand, operators []string) {
+	for curr := o; curr != nil; {
+		operand := *curr
+		operand.Arithmetic = nil
+		operands = append(operands, &operand)
+
+		if curr.Arithmetic == nil {
+			break
+		}
+
+		operators = append(operators, curr.Arithmetic.Operator)
+		curr = curr.Arithmetic.Operand
+	}
+
+	return operands, operators
+}
+
+// FoldArithmetic combines the terms of an arithmetic operation using apply, respecting operator
+// precedence so that multiplication, division and modulo are applied before addition and subtraction.
+// Operators of the same precedence are applied from left to right.
+func FoldArithmetic[T any](operands []T, operators []string, apply func(lhs T, operator string, rhs T) (T, error)) (T, error) {
+	terms := []T{operands[0]}
+	additive := []string{}
+
+	for i, operator := range operators {
+		if lo.Contains(MultiplicativeOperators, operator) {
+			result, err := apply(terms[len(terms)-1], operator, operands[i+1])
+			if err != nil {
+				return result, err
+			}
+			terms[len(terms)-1] = result
+			continue
+		}
+
+		terms = append(terms, operands[i+1])
+		additive = append(additive, operator)
+	}
+
+	result := terms[0]
+	for i, operator := range additive {
+		var err error
+		result, err = apply(result, operator, terms[i+1])
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// Arithmetic is the operator and right hand side of an arithmetic operation. Further operations
+// are chained onto the right hand side operand, so a + b * c is parsed as a, + b, * c.
+type Arithmetic struct {
+	node.Node
+
+	Operator string   `@( "+" | "-" | "*" | "/" | "%" )`
+	Operand  *Operand `@@`
+}
+
+type Function struct {
+	node.Node
+
+	Name      *IdentFragment `@@ "("`
+	Arguments []*Operand     `( @@ ( "," @@ )* )? ")"`
+}
+
+func (f *Function) ToString() string {
+	args := lo.Map(f.Arguments, func(arg *Operand, _ int) string {
+		return arg.ToString()
+	})
+
+	return fmt.Sprintf("%s(%s)", f.Name.Fragment, strings.Join(args, ", "))
+}
+
+// Duration is a literal length of time, such as 7 days, which can be added to or subtracted from dates and timestamps
+type Duration struct {
+	node.Node
+
+	Value int64  `@Int`
+	Unit  string `@( "seconds" | "second" | "minutes" | "minute" | "hours" | "hour" | "days" | "day" | "weeks" | "week" | "months" | "month" | "years" | "year" )`
+}
+
+func (d *Duration) ToString() string {
+	return fmt.Sprintf("%d %s", d.Value, d.Unit)
+}
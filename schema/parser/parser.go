@@ -29,7 +29,8 @@ type DeclarationNode struct {
 	API     *APINode     `| "api" @@`
 	Enum    *EnumNode    `| "enum" @@`
 	Message *MessageNode `| "message" @@`
-	Job     *JobNode     `| "job" @@)`
+	Job     *JobNode     `| "job" @@`
+	Event   *EventNode   `| "event" @@)`
 }
 
 type ModelNode struct {
@@ -154,6 +155,40 @@ type EmailsNode struct {
 	Email string `@String`
 }
 
+type EventNode struct {
+	node.Node
+
+	Name     Ident               `@@`
+	Sections []*EventSectionNode `"{" @@* "}"`
+}
+
+type EventSectionNode struct {
+	node.Node
+
+	Fields    []*FieldNode   `( "fields" "{" @@* "}"`
+	Attribute *AttributeNode `| @@)`
+}
+
+// Fields returns the fields of the event's payload
+func (e *EventNode) Fields() []*FieldNode {
+	fields := []*FieldNode{}
+	for _, section := range e.Sections {
+		fields = append(fields, section.Fields...)
+	}
+	return fields
+}
+
+// Attributes returns the attributes defined on the event
+func (e *EventNode) Attributes() []*AttributeNode {
+	attributes := []*AttributeNode{}
+	for _, section := range e.Sections {
+		if section.Attribute != nil {
+			attributes = append(attributes, section.Attribute)
+		}
+	}
+	return attributes
+}
+
 type JobNode struct {
 	node.Node
 
@@ -179,6 +179,28 @@ func Jobs(asts []*parser.AST) (ret []*parser.JobNode) {
 	return ret
 }
 
+func Events(asts []*parser.AST) (ret []*parser.EventNode) {
+	for _, ast := range asts {
+		for _, decl := range ast.Declarations {
+			if decl.Event != nil {
+				ret = append(ret, decl.Event)
+			}
+		}
+	}
+
+	return ret
+}
+
+func Event(asts []*parser.AST, name string) *parser.EventNode {
+	for _, event := range Events(asts) {
+		if event.Name.ToString() == name {
+			return event
+		}
+	}
+
+	return nil
+}
+
 func IsEnum(asts []*parser.AST, name string) bool {
 	return Enum(asts, name) != nil
 }
@@ -213,12 +235,12 @@ func UserDefinedTypes(asts []*parser.AST) (res []string) {
 }
 
 // ModelCreateActions returns all the actions in the given model, which
-// are create-type actions.
+// are create-type actions, including createMany actions.
 func ModelCreateActions(model *parser.ModelNode, filters ...ModelActionFilter) (res []*parser.ActionNode) {
 	allFilters := []ModelActionFilter{}
 	allFilters = append(allFilters, filters...)
 	allFilters = append(allFilters, func(a *parser.ActionNode) bool {
-		return a.Type.Value == parser.ActionTypeCreate
+		return a.Type.Value == parser.ActionTypeCreate || a.Type.Value == parser.ActionTypeCreateMany
 	})
 	return ModelActions(model, allFilters...)
 }
@@ -572,6 +594,24 @@ func SubscriberNames(asts []*parser.AST) (res []string) {
 					}
 				}
 			}
+
+			if decl.Event != nil {
+				for _, attribute := range decl.Event.Attributes() {
+					if attribute.Name.Value != parser.AttributeOn {
+						continue
+					}
+
+					for _, arg := range attribute.Arguments {
+						operand, err := arg.Expression.ToValue()
+						if err == nil && operand.Ident != nil && len(operand.Ident.Fragments) == 1 {
+							name := operand.Ident.Fragments[0].Fragment
+							if !lo.Contains(res, name) {
+								res = append(res, name)
+							}
+						}
+					}
+				}
+			}
 		}
 	}
 
@@ -614,7 +654,7 @@ func GetRelationshipCandidates(asts []*parser.AST, model *parser.ModelNode, fiel
 					continue
 				}
 
-				attr := FieldGetAttribute(f, parser.AttributeRelation)
+				attr := FieldRelationAttribute(f)
 				if attr != nil {
 					if relation, ok := RelationAttributeValue(attr); ok {
 						if relation == otherField.Name.Value {
@@ -629,7 +669,7 @@ func GetRelationshipCandidates(asts []*parser.AST, model *parser.ModelNode, fiel
 				candidates = append(candidates, &Relationship{Model: otherModel, Field: otherField})
 			}
 
-			if FieldHasAttribute(field, parser.AttributeRelation) || FieldHasAttribute(otherField, parser.AttributeRelation) {
+			if FieldRelationAttribute(field) != nil || FieldRelationAttribute(otherField) != nil {
 				relationAttributeExists = true
 			}
 		}
@@ -640,7 +680,7 @@ func GetRelationshipCandidates(asts []*parser.AST, model *parser.ModelNode, fiel
 		relationOnlyCandidates := []*Relationship{}
 
 		for _, relationship := range candidates {
-			if FieldHasAttribute(field, parser.AttributeRelation) || FieldHasAttribute(relationship.Field, parser.AttributeRelation) {
+			if FieldRelationAttribute(field) != nil || FieldRelationAttribute(relationship.Field) != nil {
 				relationOnlyCandidates = append(relationOnlyCandidates, relationship)
 			}
 		}
@@ -691,7 +731,7 @@ func ValidOneToHasMany(belongsTo *parser.FieldNode, hasMany *parser.FieldNode) b
 	}
 
 	// If belongsTo has @relation, check the field name matches hasMany
-	belongsToAttribute := FieldGetAttribute(belongsTo, parser.AttributeRelation)
+	belongsToAttribute := FieldRelationAttribute(belongsTo)
 	if belongsToAttribute != nil {
 		if relation, ok := RelationAttributeValue(belongsToAttribute); ok {
 			if relation != hasMany.Name.Value {
@@ -703,7 +743,7 @@ func ValidOneToHasMany(belongsTo *parser.FieldNode, hasMany *parser.FieldNode) b
 	}
 
 	// If hasMany has @relation, then this is not a candidate
-	hasManyAttribute := FieldGetAttribute(hasMany, parser.AttributeRelation)
+	hasManyAttribute := FieldRelationAttribute(hasMany)
 
 	return hasManyAttribute == nil
 }
@@ -721,13 +761,13 @@ func ValidUniqueOneToHasOne(hasOne *parser.FieldNode, belongsTo *parser.FieldNod
 		return false
 	}
 
-	otherFieldAttribute := FieldGetAttribute(belongsTo, parser.AttributeRelation)
+	otherFieldAttribute := FieldRelationAttribute(belongsTo)
 	if otherFieldAttribute != nil {
 		return false
 	}
 
 	// If hasOne has @relation, check the field name matches belongsTo
-	hasOneAttribute := FieldGetAttribute(hasOne, parser.AttributeRelation)
+	hasOneAttribute := FieldRelationAttribute(hasOne)
 	if hasOneAttribute != nil {
 		if relation, ok := RelationAttributeValue(hasOneAttribute); ok {
 			if relation != belongsTo.Name.Value {
@@ -739,18 +779,22 @@ func ValidUniqueOneToHasOne(hasOne *parser.FieldNode, belongsTo *parser.FieldNod
 	}
 
 	// If belongsTo has @relation, then this is not a candidate
-	belongsToAttribute := FieldGetAttribute(belongsTo, parser.AttributeRelation)
+	belongsToAttribute := FieldRelationAttribute(belongsTo)
 
 	return belongsToAttribute == nil
 }
 
 // RelationAttributeValue attempts to retrieve the value of the @relation attribute
 func RelationAttributeValue(attr *parser.AttributeNode) (field string, ok bool) {
-	if len(attr.Arguments) != 1 {
+	arguments := lo.Filter(attr.Arguments, func(arg *parser.AttributeArgumentNode, _ int) bool {
+		return arg.Label == nil
+	})
+
+	if len(arguments) != 1 {
 		return "", false
 	}
 
-	expr := attr.Arguments[0].Expression
+	expr := arguments[0].Expression
 	operand, err := expr.ToValue()
 	if err != nil {
 		return "", false
@@ -762,3 +806,26 @@ func RelationAttributeValue(attr *parser.AttributeNode) (field string, ok bool)
 
 	return operand.Ident.Fragments[0].Fragment, true
 }
+
+// FieldRelationAttribute returns the @relation attribute of the field if it names the field on the other
+// model which forms the relationship, and so not when it only has labelled arguments such as onDelete.
+func FieldRelationAttribute(field *parser.FieldNode) *parser.AttributeNode {
+	attr := FieldGetAttribute(field, parser.AttributeRelation)
+	if attr == nil {
+		return nil
+	}
+
+	if len(attr.Arguments) > 0 && lo.EveryBy(attr.Arguments, func(arg *parser.AttributeArgumentNode) bool { return arg.Label != nil }) {
+		return nil
+	}
+
+	return attr
+}
+
+// RelationOnDeleteArgument returns the onDelete argument of the @relation attribute, if it has one.
+func RelationOnDeleteArgument(attr *parser.AttributeNode) *parser.AttributeArgumentNode {
+	arg, _ := lo.Find(attr.Arguments, func(arg *parser.AttributeArgumentNode) bool {
+		return arg.Label != nil && arg.Label.Value == parser.RelationArgumentOnDelete
+	})
+	return arg
+}
@@ -24,7 +24,7 @@ func CreateNestedInputIsMany(asts []*parser.AST, errs *errorhandling.ValidationE
 				return
 			}
 
-			if n.Type.Value == parser.ActionTypeCreate {
+			if n.Type.Value == parser.ActionTypeCreate || n.Type.Value == parser.ActionTypeCreateMany {
 				isCreateAction = true
 			}
 		},
@@ -0,0 +1,263 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+var eventNameFragmentRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// EventsRule validates user-defined event declarations, their subscribers, and the actions which emit them.
+func EventsRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var event *parser.EventNode
+	var model *parser.ModelNode
+	var action *parser.ActionNode
+	fieldNames := map[string]bool{}
+
+	return Visitor{
+		EnterEvent: func(e *parser.EventNode) {
+			event = e
+			fieldNames = map[string]bool{}
+			name := e.Name.ToString()
+
+			validName := len(e.Name.Fragments) > 1
+			for _, fragment := range e.Name.Fragments {
+				if !eventNameFragmentRegex.MatchString(fragment.Fragment) {
+					validName = false
+				}
+			}
+
+			if !validName {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.NamingError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("the event name '%s' must be lower snake case words separated by a dot", name),
+						Hint:    "For example, order.shipped",
+					},
+					e.Name,
+				))
+				return
+			}
+
+			for _, other := range query.Events(asts) {
+				if other != e && other.Name.ToString() == name {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.DuplicateDefinitionError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("there already exists an event with the name '%s'", name),
+						},
+						e.Name,
+					))
+					return
+				}
+			}
+
+			for _, m := range query.Models(asts) {
+				for _, action := range []string{"created", "updated", "deleted"} {
+					if fmt.Sprintf("%s.%s", casing.ToSnake(m.Name.Value), action) == name {
+						errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+							errorhandling.NamingError,
+							errorhandling.ErrorDetails{
+								Message: fmt.Sprintf("the event name '%s' is reserved for the events of the %s model", name, m.Name.Value),
+							},
+							e.Name,
+						))
+						return
+					}
+				}
+			}
+		},
+		LeaveEvent: func(e *parser.EventNode) {
+			event = nil
+		},
+		EnterModel: func(m *parser.ModelNode) {
+			model = m
+		},
+		LeaveModel: func(m *parser.ModelNode) {
+			model = nil
+		},
+		EnterAction: func(a *parser.ActionNode) {
+			action = a
+		},
+		LeaveAction: func(a *parser.ActionNode) {
+			action = nil
+		},
+		EnterField: func(f *parser.FieldNode) {
+			if event == nil {
+				return
+			}
+
+			if fieldNames[f.Name.Value] {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.DuplicateDefinitionError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("field '%s' already defined in event '%s'", f.Name.Value, event.Name.ToString()),
+					},
+					f.Name,
+				))
+			}
+			fieldNames[f.Name.Value] = true
+
+			if !parser.IsBuiltInFieldType(f.Type.Value) && !query.IsUserDefinedType(asts, f.Type.Value) && !query.IsMessage(asts, f.Type.Value) {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.TypeError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("invalid type '%s' - must be a built-in type, model, enum, or message", f.Type.Value),
+					},
+					f.Type,
+				))
+			}
+
+			for _, attribute := range f.Attributes {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeNotAllowedError,
+					errorhandling.ErrorDetails{
+						Message: "fields of an event cannot have attributes",
+					},
+					attribute.Name,
+				))
+			}
+		},
+		EnterAttribute: func(attribute *parser.AttributeNode) {
+			switch {
+			case event != nil && attribute.Name.Value == parser.AttributeOn:
+				validateEventSubscriber(attribute, errs)
+			case action != nil && attribute.Name.Value == parser.AttributeEmit:
+				validateEmit(asts, model, action, attribute, errs)
+			}
+		},
+	}
+}
+
+func validateEventSubscriber(attribute *parser.AttributeNode, errs *errorhandling.ValidationErrors) {
+	if len(attribute.Arguments) != 1 {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "@on requires a single argument when used on an event - the subscriber name",
+				Hint:    "For example, @on(notifyCustomer)",
+			},
+			attribute.Name,
+		))
+		return
+	}
+
+	operand, err := attribute.Arguments[0].Expression.ToValue()
+	if err != nil || operand.Ident == nil || len(operand.Ident.Fragments) != 1 || attribute.Arguments[0].Label != nil {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "@on subscriber argument must be a valid function name",
+				Hint:    "For example, @on(notifyCustomer)",
+			},
+			attribute.Arguments[0],
+		))
+		return
+	}
+
+	name := operand.Ident.Fragments[0].Fragment
+	if name != strcase.ToLowerCamel(name) {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "a valid function name must be in lower camel case",
+				Hint:    fmt.Sprintf("Try use '%s'", strcase.ToLowerCamel(name)),
+			},
+			attribute.Arguments[0],
+		))
+	}
+}
+
+func validateEmit(asts []*parser.AST, model *parser.ModelNode, action *parser.ActionNode, attribute *parser.AttributeNode, errs *errorhandling.ValidationErrors) {
+	if action.Type.Value != parser.ActionTypeCreate && action.Type.Value != parser.ActionTypeUpdate {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeNotAllowedError,
+			errorhandling.ErrorDetails{
+				Message: "@emit can only be used on create and update actions",
+			},
+			attribute.Name,
+		))
+		return
+	}
+
+	// Events are written in the same transaction as the action's write, which for functions is
+	// owned by the functions runtime
+	if action.IsFunction() {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeNotAllowedError,
+			errorhandling.ErrorDetails{
+				Message: "@emit cannot be used on actions implemented by a function",
+				Hint:    "Use emit() from within the function instead",
+			},
+			attribute.Name,
+		))
+		return
+	}
+
+	if len(attribute.Arguments) != 1 {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "@emit requires a single argument - the name of the event",
+				Hint:    "For example, @emit(order.shipped)",
+			},
+			attribute.Name,
+		))
+		return
+	}
+
+	arg := attribute.Arguments[0]
+	operand, err := arg.Expression.ToValue()
+	if err != nil || operand.Ident == nil || arg.Label != nil {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "@emit argument must be the name of an event",
+				Hint:    "For example, @emit(order.shipped)",
+			},
+			arg,
+		))
+		return
+	}
+
+	event := query.Event(asts, operand.Ident.ToString())
+	if event == nil {
+		names := lo.Map(query.Events(asts), func(e *parser.EventNode, _ int) string { return e.Name.ToString() })
+		hint := "Events are declared with the event keyword"
+		if len(names) > 0 {
+			hint = fmt.Sprintf("The events which can be emitted are %s", strings.Join(names, ", "))
+		}
+
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.UndefinedError,
+			errorhandling.ErrorDetails{
+				Message: fmt.Sprintf("event '%s' is not defined", operand.Ident.ToString()),
+				Hint:    hint,
+			},
+			arg,
+		))
+		return
+	}
+
+	// The payload is populated from the model, and so each of its fields must exist on the model
+	for _, field := range event.Fields() {
+		modelField := query.ModelField(model, field.Name.Value)
+		if modelField == nil || modelField.Type.Value != field.Type.Value || modelField.Repeated != field.Repeated || (modelField.Optional && !field.Optional) {
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.TypeError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s cannot be emitted from %s as the event field '%s' is not a field of %s with the same type", event.Name.ToString(), action.Name.Value, field.Name.Value, model.Name.Value),
+				},
+				arg,
+			))
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/schema/expressions"
+	"github.com/teamkeel/keel/schema/node"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+	"github.com/teamkeel/keel/schema/validation/rules/expression"
+)
+
+// IndexAttributeRule validates that @index attributes are valid according to the following rules:
+// - field level @index only accepts the labelled method, metric and where arguments
+// - model level @index requires an array of field names, followed by the optional method, metric and where arguments
+// - @index can't be used on has-many or has-one relations, or on Secret, Password and File fields
+// - the method must be one of btree, gin or hnsw
+// - hnsw can only be used on a single Vector field, and Vector fields can only be indexed with hnsw
+// - the metric can only be given for hnsw indexes and must be one of cosine, l2 or innerProduct
+// - gin can only be used on array fields
+// - the where expression can only compare fields on this model with literal values
+// - a model can only have one index on the same fields
+func IndexAttributeRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+	var currentField *parser.FieldNode
+	var indexed []string
+
+	return Visitor{
+		EnterModel: func(m *parser.ModelNode) {
+			currentModel = m
+			indexed = []string{}
+		},
+		LeaveModel: func(m *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterField: func(f *parser.FieldNode) {
+			currentField = f
+		},
+		LeaveField: func(f *parser.FieldNode) {
+			currentField = nil
+		},
+		EnterAttribute: func(attr *parser.AttributeNode) {
+			if currentModel == nil || attr.Name.Value != parser.AttributeIndex {
+				return
+			}
+
+			arguments := attr.Arguments
+			fields := []*parser.FieldNode{}
+
+			if currentField != nil {
+				fields = append(fields, currentField)
+			} else {
+				if len(arguments) == 0 || arguments[0].Label != nil {
+					errs.AppendError(indexArgumentError(
+						attr.Name,
+						"@index on a model requires an array of the fields to index",
+					))
+					return
+				}
+
+				value, err := arguments[0].Expression.ToValue()
+				if err != nil || value.Array == nil || len(value.Array.Values) == 0 {
+					errs.AppendError(indexArgumentError(
+						arguments[0].Expression,
+						"@index on a model requires an array of the fields to index",
+					))
+					return
+				}
+
+				names := []string{}
+				for _, operand := range value.Array.Values {
+					if operand.Ident == nil || len(operand.Ident.Fragments) != 1 {
+						errs.AppendError(indexArgumentError(operand, "@index fields must be the names of fields on this model"))
+						continue
+					}
+
+					name := operand.Ident.Fragments[0].Fragment
+					field := query.ModelField(currentModel, name)
+					if field == nil {
+						errs.AppendError(indexArgumentError(operand, fmt.Sprintf("'%s' is not a field on the %s model", name, currentModel.Name.Value)))
+						continue
+					}
+
+					if lo.Contains(names, name) {
+						errs.AppendError(indexArgumentError(operand, fmt.Sprintf("Field '%s' has already been specified in this index", name)))
+						continue
+					}
+
+					names = append(names, name)
+					fields = append(fields, field)
+
+					if permitted, reason := indexPermitted(asts, currentModel, field); !permitted {
+						errs.AppendError(indexArgumentError(operand, reason))
+					}
+				}
+
+				arguments = arguments[1:]
+			}
+
+			if currentField != nil {
+				if permitted, reason := indexPermitted(asts, currentModel, currentField); !permitted {
+					errs.AppendError(indexArgumentError(attr.Name, reason))
+				}
+			}
+
+			method := parser.IndexMethodBtree
+			var metric *parser.AttributeArgumentNode
+			labels := []string{}
+
+			for _, arg := range arguments {
+				if arg.Label == nil {
+					errs.AppendError(indexArgumentError(
+						arg,
+						"@index only supports the labelled method, metric and where arguments",
+					))
+					continue
+				}
+
+				label := arg.Label.Value
+				if lo.Contains(labels, label) {
+					errs.AppendError(indexArgumentError(arg.Label, fmt.Sprintf("The %s argument has already been specified", label)))
+					continue
+				}
+				labels = append(labels, label)
+
+				switch label {
+				case parser.IndexArgumentMethod:
+					value, err := arg.Expression.ToValue()
+					if err != nil || value.Ident == nil || len(value.Ident.Fragments) != 1 || !lo.Contains(parser.IndexMethods, value.Ident.Fragments[0].Fragment) {
+						errs.AppendError(indexArgumentError(
+							arg.Expression,
+							fmt.Sprintf("The @index method must be one of %s", strings.Join(parser.IndexMethods, ", ")),
+						))
+						return
+					}
+					method = value.Ident.Fragments[0].Fragment
+				case parser.IndexArgumentMetric:
+					value, err := arg.Expression.ToValue()
+					if err != nil || value.Ident == nil || len(value.Ident.Fragments) != 1 || !lo.Contains(parser.DistanceMetrics, value.Ident.Fragments[0].Fragment) {
+						errs.AppendError(indexArgumentError(
+							arg.Expression,
+							fmt.Sprintf("The @index metric must be one of %s", strings.Join(parser.DistanceMetrics, ", ")),
+						))
+						return
+					}
+					metric = arg
+				case parser.IndexArgumentWhere:
+					validateIndexWhere(asts, currentModel, attr, arg.Expression, errs)
+				default:
+					errs.AppendError(indexArgumentError(
+						arg.Label,
+						fmt.Sprintf("'%s' is not a valid argument for @index, which supports the method, metric and where arguments", label),
+					))
+				}
+			}
+
+			validateIndexMethod(asts, attr, method, fields, errs)
+
+			if metric != nil && method != parser.IndexMethodHnsw {
+				errs.AppendError(indexArgumentError(metric.Label, "The metric argument can only be used with the hnsw index method"))
+			}
+
+			key := strings.Join(lo.Map(fields, func(f *parser.FieldNode, _ int) string { return f.Name.Value }), ",")
+			if lo.Contains(indexed, key) {
+				errs.AppendError(indexArgumentError(attr.Name, "An index on the same fields has already been defined for this model"))
+			}
+			indexed = append(indexed, key)
+		},
+	}
+}
+
+func indexPermitted(asts []*parser.AST, model *parser.ModelNode, field *parser.FieldNode) (bool, string) {
+	if query.IsModel(asts, field.Type.Value) {
+		fk := query.Field(model, fmt.Sprintf("%sId", field.Name.Value))
+		if field.Repeated || fk == nil || !query.IsForeignKey(asts, model, fk) {
+			return false, "@index is not permitted on has many or has one relationships"
+		}
+		return true, ""
+	}
+
+	switch field.Type.Value {
+	case parser.FieldTypeSecret, parser.FieldTypePassword, parser.FieldTypeFile:
+		return false, fmt.Sprintf("@index is not permitted on %s fields", field.Type.Value)
+	}
+
+	return true, ""
+}
+
+func validateIndexMethod(asts []*parser.AST, attr *parser.AttributeNode, method string, fields []*parser.FieldNode, errs *errorhandling.ValidationErrors) {
+	vectors := lo.Filter(fields, func(f *parser.FieldNode, _ int) bool {
+		return f.Type.Value == parser.FieldTypeVector
+	})
+
+	switch method {
+	case parser.IndexMethodHnsw:
+		if len(fields) != 1 || len(vectors) != 1 {
+			errs.AppendError(indexArgumentError(attr.Name, "The hnsw index method can only be used to index a single Vector field"))
+		}
+	case parser.IndexMethodGin:
+		if lo.SomeBy(fields, func(f *parser.FieldNode) bool { return !f.Repeated || query.IsModel(asts, f.Type.Value) }) {
+			errs.AppendError(indexArgumentError(attr.Name, "The gin index method can only be used to index array fields"))
+		}
+	default:
+		if len(vectors) > 0 {
+			errs.AppendError(indexArgumentError(attr.Name, "Vector fields can only be indexed using the hnsw index method"))
+		}
+	}
+}
+
+// validateIndexWhere validates the expression of a partial index, which is evaluated by the database
+// and so can only compare the fields of this model with literal values.
+func validateIndexWhere(asts []*parser.AST, model *parser.ModelNode, attr *parser.AttributeNode, expr *parser.Expression, errs *errorhandling.ValidationErrors) {
+	modelName := casing.ToLowerCamel(model.Name.Value)
+
+	for _, condition := range expr.Conditions() {
+		for _, operand := range []*parser.Operand{condition.LHS, condition.RHS} {
+			if operand == nil {
+				continue
+			}
+
+			operands := []*parser.Operand{operand}
+			if operand.Array != nil {
+				operands = operand.Array.Values
+			}
+
+			for _, o := range operands {
+				if o.IsComputed() {
+					errs.AppendError(indexArgumentError(
+						o,
+						"@index where expressions can only compare fields with literal values",
+					))
+					return
+				}
+
+				if o.Ident == nil {
+					continue
+				}
+
+				fragments := o.Ident.Fragments
+				if len(fragments) == 2 && query.IsEnum(asts, fragments[0].Fragment) {
+					continue
+				}
+
+				if fragments[0].Fragment != modelName || len(fragments) != 2 {
+					errs.AppendError(indexArgumentError(
+						o,
+						fmt.Sprintf("@index where expressions can only refer to the fields of this model, for example %s.fieldName", modelName),
+					))
+					return
+				}
+
+				field := query.ModelField(model, fragments[1].Fragment)
+				if field != nil && query.IsModel(asts, field.Type.Value) {
+					errs.AppendError(indexArgumentError(
+						o,
+						"@index where expressions cannot refer to relationship fields",
+					))
+					return
+				}
+			}
+		}
+	}
+
+	validationErrs := expression.ValidateExpression(
+		asts,
+		expr,
+		[]expression.Rule{expression.OperatorLogicalRule},
+		expressions.ExpressionContext{
+			Model:     model,
+			Attribute: attr,
+		},
+	)
+	for _, e := range validationErrs {
+		errs.AppendError(e.(*errorhandling.ValidationError))
+	}
+}
+
+func indexArgumentError(node node.ParserNode, message string) *errorhandling.ValidationError {
+	return errorhandling.NewValidationErrorWithDetails(
+		errorhandling.AttributeArgumentError,
+		errorhandling.ErrorDetails{
+			Message: message,
+			Hint:    "For example, @index([title, createdAt]) or @index(method: gin)",
+		},
+		node,
+	)
+}
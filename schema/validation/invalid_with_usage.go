@@ -10,7 +10,7 @@ import (
 )
 
 var (
-	ValidActionTypes = []string{parser.ActionTypeCreate, parser.ActionTypeUpdate}
+	ValidActionTypes = []string{parser.ActionTypeCreate, parser.ActionTypeUpdate, parser.ActionTypeCreateMany, parser.ActionTypeUpdateMany}
 )
 
 // InvalidWithUsage checks that the 'with' keyword is only used for actions that receive write values
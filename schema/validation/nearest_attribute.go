@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/schema/node"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// NearestAttributeRule validates the @nearest attribute, which orders the results of a list action by
+// their distance to an input vector. It must be defined at most once on a list action and have a single
+// argument labelled with a Vector field and with one of the supported distance metrics as its value.
+func NearestAttributeRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+	var currentAction *parser.ActionNode
+	var nearestAttributeDefined bool
+
+	return Visitor{
+		EnterModel: func(model *parser.ModelNode) {
+			currentModel = model
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterAction: func(action *parser.ActionNode) {
+			currentAction = action
+			nearestAttributeDefined = false
+		},
+		LeaveAction: func(_ *parser.ActionNode) {
+			currentAction = nil
+		},
+		EnterAttribute: func(attribute *parser.AttributeNode) {
+			if attribute.Name.Value != parser.AttributeNearest || currentAction == nil {
+				return
+			}
+
+			if currentAction.Type.Value != parser.ActionTypeList {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeNotAllowedError,
+					errorhandling.ErrorDetails{
+						Message: "@nearest can only be used on list actions",
+					},
+					attribute.Name,
+				))
+				return
+			}
+
+			if nearestAttributeDefined {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeNotAllowedError,
+					errorhandling.ErrorDetails{
+						Message: "@nearest can only be defined once per action",
+					},
+					attribute.Name,
+				))
+				return
+			}
+
+			nearestAttributeDefined = true
+
+			if len(attribute.Arguments) != 1 {
+				errs.AppendError(nearestArgumentError(attribute.Name, "@nearest requires a single argument labelled with the Vector field to search"))
+				return
+			}
+
+			arg := attribute.Arguments[0]
+			if arg.Label == nil {
+				errs.AppendError(nearestArgumentError(arg, "@nearest requires a single argument labelled with the Vector field to search"))
+				return
+			}
+
+			field := query.ModelField(currentModel, arg.Label.Value)
+			if field == nil {
+				errs.AppendError(nearestArgumentError(
+					arg.Label,
+					fmt.Sprintf("@nearest argument label '%s' must correspond to a field on this model", arg.Label.Value),
+				))
+				return
+			}
+
+			if field.Type.Value != parser.FieldTypeVector || field.Repeated {
+				errs.AppendError(nearestArgumentError(
+					arg.Label,
+					fmt.Sprintf("@nearest can only be used with Vector fields, but '%s' is %s", arg.Label.Value, field.Type.Value),
+				))
+				return
+			}
+
+			operand, err := arg.Expression.ToValue()
+			if err != nil || operand.Ident == nil || len(operand.Ident.Fragments) != 1 || !lo.Contains(parser.DistanceMetrics, operand.Ident.Fragments[0].Fragment) {
+				errs.AppendError(nearestArgumentError(
+					arg.Expression,
+					fmt.Sprintf("@nearest distance metric must be one of %s", strings.Join(parser.DistanceMetrics, ", ")),
+				))
+			}
+		},
+	}
+}
+
+func nearestArgumentError(node node.ParserNode, message string) *errorhandling.ValidationError {
+	return errorhandling.NewValidationErrorWithDetails(
+		errorhandling.AttributeArgumentError,
+		errorhandling.ErrorDetails{
+			Message: message,
+			Hint:    "For example, @nearest(embedding: cosine)",
+		},
+		node,
+	)
+}
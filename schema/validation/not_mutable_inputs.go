@@ -9,7 +9,7 @@ import (
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
 )
 
-// NotMutableInputs checks that the write action inputs for update and create aren't
+// NotMutableInputs checks that the write action inputs for create and update actions, including their bulk variants, aren't
 // setting the id of the root model and aren't setting the createdAt and updatedAt fields on any models.
 func NotMutableInputs(_ []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
 	var action *parser.ActionNode
@@ -26,7 +26,7 @@ func NotMutableInputs(_ []*parser.AST, errs *errorhandling.ValidationErrors) Vis
 				return
 			}
 
-			if action.Type.Value != parser.ActionTypeCreate && action.Type.Value != parser.ActionTypeUpdate {
+			if !lo.Contains(ValidActionTypes, action.Type.Value) {
 				return
 			}
 
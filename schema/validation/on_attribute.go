@@ -20,10 +20,18 @@ var supportedActionTypes = []string{
 func OnAttributeRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
 	var currentAttribute *parser.AttributeNode
 	var arguments []*parser.AttributeArgumentNode
+	var event *parser.EventNode
 
 	return Visitor{
+		EnterEvent: func(e *parser.EventNode) {
+			event = e
+		},
+		LeaveEvent: func(e *parser.EventNode) {
+			event = nil
+		},
 		EnterAttribute: func(attribute *parser.AttributeNode) {
-			if attribute.Name.Value != parser.AttributeOn {
+			// Subscribers to user-defined events are validated by EventsRule
+			if attribute.Name.Value != parser.AttributeOn || event != nil {
 				return
 			}
 
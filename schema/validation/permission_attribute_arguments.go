@@ -111,8 +111,8 @@ func PermissionsAttributeArguments(asts []*parser.AST, errs *errorhandling.Valid
 					// if we don't provide the model as context the error is not very helpful.
 					if action != nil && (action.Type.Value == "read" || action.Type.Value == "write") {
 						for _, cond := range arg.Expression.Conditions() {
-							for _, op := range []*parser.Operand{cond.LHS, cond.RHS} {
-								if op == nil || op.Ident == nil {
+							for _, op := range cond.Operands() {
+								if op.Ident == nil {
 									continue
 								}
 								// An ident must have at least one fragment - we only care about the first one
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/samber/lo"
+
 	"github.com/teamkeel/keel/schema/node"
 	"github.com/teamkeel/keel/schema/parser"
 	"github.com/teamkeel/keel/schema/query"
@@ -154,6 +156,28 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 				return
 			}
 
+			if relationAttr != nil {
+				if currentField.Repeated {
+					errs.AppendError(makeRelationshipError(
+						"The @relation attribute must be defined on the other side of a one to many relationship",
+						learnMore,
+						relationAttr.Name,
+					))
+					return
+				}
+
+				if !validateRelationLabelledArguments(currentField, relationAttr, errs) {
+					return
+				}
+			}
+
+			// The @relation attribute may only define the onDelete action, in which case it does not name a field
+			var onDeleteArg *parser.AttributeArgumentNode
+			if relationAttr != nil {
+				onDeleteArg = query.RelationOnDeleteArgument(relationAttr)
+			}
+			relationAttr = query.FieldRelationAttribute(currentField)
+
 			var relation string
 			if relationAttr != nil {
 				var ok bool
@@ -169,15 +193,9 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 			}
 
 			if relationAttr != nil {
-				// @relation cannot be defined on a repeated field
-				if currentField.Repeated {
-					errs.AppendError(makeRelationshipError(
-						"The @relation attribute must be defined on the other side of a one to many relationship",
-						learnMore,
-						relationAttr.Name,
-					))
-					return
-				}
+				relationArg, _ := lo.Find(relationAttr.Arguments, func(arg *parser.AttributeArgumentNode) bool {
+					return arg.Label == nil
+				})
 
 				// @relation field does not exist
 				otherField := query.Field(otherModel, relation)
@@ -185,7 +203,7 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 					errs.AppendError(makeRelationshipError(
 						fmt.Sprintf("The field '%s' does not exist on %s", relation, otherModel.Name.Value),
 						fmt.Sprintf("The @relation argument must refer to a field on %s which is of type %s. %s", otherModel.Name.Value, currentModel.Name.Value, learnMore),
-						relationAttr.Arguments[0],
+						relationArg,
 					))
 					return
 				}
@@ -195,7 +213,7 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 					errs.AppendError(makeRelationshipError(
 						fmt.Sprintf("The field '%s' on %s must be of type %s in order to establish a relationship", relation, otherModel.Name.Value, currentModel.Name.Value),
 						learnMore,
-						relationAttr.Arguments[0],
+						relationArg,
 					))
 					return
 				}
@@ -205,7 +223,7 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 					errs.AppendError(makeRelationshipError(
 						fmt.Sprintf("Cannot create a relationship to the unique field '%s' on %s", relation, otherModel.Name.Value),
 						fmt.Sprintf("In a one to one relationship, only this side must be marked as @unique. %s", learnMore),
-						relationAttr.Arguments[0],
+						relationArg,
 					))
 					return
 				}
@@ -225,7 +243,7 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 					errs.AppendError(makeRelationshipError(
 						fmt.Sprintf("A one to one relationship cannot be made with repeated field '%s' on %s", otherField.Name.Value, otherModel.Name.Value),
 						fmt.Sprintf("Either make '%s' non-repeated or define a new non-repeated field on %s. %s", otherField.Name.Value, otherModel.Name.Value, learnMore),
-						relationAttr.Arguments[0],
+						relationArg,
 					))
 					return
 				}
@@ -238,6 +256,20 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 				candidates[currentField] = fieldCandidates
 			}
 
+			// In a one to one relationship, the foreign key is on the @unique side
+			if onDeleteArg != nil && !query.FieldIsUnique(currentField) {
+				for _, candidate := range fieldCandidates {
+					if candidate.Field != nil && query.FieldIsUnique(candidate.Field) {
+						errs.AppendError(makeRelationshipError(
+							"The onDelete argument must be defined on the @unique side of a one to one relationship",
+							fmt.Sprintf("Use @relation(onDelete: ...) on the '%s' field on %s instead. %s", candidate.Field.Name.Value, candidate.Model.Name.Value, learnMore),
+							onDeleteArg.Label,
+						))
+						break
+					}
+				}
+			}
+
 			if len(fieldCandidates) == 0 && currentField.Repeated {
 				errs.AppendError(makeRelationshipError(
 					fmt.Sprintf("The field '%s' does not have an associated field on %s", currentField.Name.Value, currentField.Type.Value),
@@ -249,6 +281,50 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 	}
 }
 
+// validateRelationLabelledArguments checks that the only labelled argument of the @relation attribute is
+// onDelete, and that it is one of the referential actions which can be taken for the field.
+func validateRelationLabelledArguments(field *parser.FieldNode, attr *parser.AttributeNode, errs *errorhandling.ValidationErrors) bool {
+	valid := true
+
+	for _, arg := range attr.Arguments {
+		if arg.Label == nil {
+			continue
+		}
+
+		if arg.Label.Value != parser.RelationArgumentOnDelete {
+			errs.AppendError(makeRelationshipError(
+				fmt.Sprintf("'%s' is not a valid argument for @relation", arg.Label.Value),
+				fmt.Sprintf("@relation only supports the labelled onDelete argument. %s", learnMore),
+				arg.Label,
+			))
+			valid = false
+			continue
+		}
+
+		operand, err := arg.Expression.ToValue()
+		if err != nil || operand.Ident == nil || len(operand.Ident.Fragments) != 1 || !lo.Contains(parser.OnDeleteActions, operand.Ident.Fragments[0].Fragment) {
+			errs.AppendError(makeRelationshipError(
+				"The onDelete argument must be one of cascade, restrict, setNull or noAction",
+				fmt.Sprintf("For example, @relation(onDelete: restrict). %s", learnMore),
+				arg.Expression,
+			))
+			valid = false
+			continue
+		}
+
+		if operand.Ident.Fragments[0].Fragment == parser.OnDeleteSetNull && !field.Optional {
+			errs.AppendError(makeRelationshipError(
+				fmt.Sprintf("The field '%s' cannot be set to null when the related record is deleted as it is not optional", field.Name.Value),
+				fmt.Sprintf("Either make '%s' optional or use another onDelete action. %s", field.Name.Value, learnMore),
+				arg.Expression,
+			))
+			valid = false
+		}
+	}
+
+	return valid
+}
+
 func makeRelationshipError(message string, hint string, node node.ParserNode) *errorhandling.ValidationError {
 	return errorhandling.NewValidationErrorWithDetails(
 		errorhandling.RelationshipError,
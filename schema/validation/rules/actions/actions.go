@@ -8,6 +8,7 @@ import (
 	"github.com/teamkeel/keel/schema/parser"
 	"github.com/teamkeel/keel/schema/query"
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
+	"golang.org/x/exp/slices"
 )
 
 var (
@@ -18,6 +19,13 @@ var (
 		parser.ActionTypeList,
 		parser.ActionTypeDelete,
 	}
+
+	// Bulk actions are only implemented by the runtime and so cannot be functions
+	bulkActionTypes = []string{
+		parser.ActionTypeCreateMany,
+		parser.ActionTypeUpdateMany,
+		parser.ActionTypeDeleteMany,
+	}
 )
 
 // validate only read+write can be used with returns
@@ -74,6 +82,21 @@ func ActionTypesRule(asts []*parser.AST) (errs errorhandling.ValidationErrors) {
 				}
 			}
 
+			if lo.Contains(bulkActionTypes, function.Type.Value) {
+				errs.AppendError(
+					errorhandling.NewValidationErrorWithDetails(
+						errorhandling.TypeError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("The '%s' action type cannot be used within a function", function.Type.Value),
+							Hint:    "Remove @function to have the runtime perform this action",
+						},
+						function.Type,
+					),
+				)
+
+				continue
+			}
+
 			if !hasReturns && (function.Type.Value == parser.ActionTypeRead || function.Type.Value == parser.ActionTypeWrite) {
 				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
 					errorhandling.TypeError,
@@ -133,13 +156,15 @@ func ActionTypesRule(asts []*parser.AST) (errs errorhandling.ValidationErrors) {
 				continue
 			}
 
-			if !lo.Contains(validActionTypes, operation.Type.Value) {
+			validOperationActionTypes := append(slices.Clone(validActionTypes), bulkActionTypes...)
+
+			if !lo.Contains(validOperationActionTypes, operation.Type.Value) {
 				errs.AppendError(
 					errorhandling.NewValidationErrorWithDetails(
 						errorhandling.TypeError,
 						errorhandling.ErrorDetails{
-							Message: fmt.Sprintf("%s is not a valid action type. Valid types are %s", operation.Type.Value, formatting.HumanizeList(validActionTypes, formatting.DelimiterOr)),
-							Hint:    fmt.Sprintf("Valid types are %s", formatting.HumanizeList(validActionTypes, formatting.DelimiterOr)),
+							Message: fmt.Sprintf("%s is not a valid action type. Valid types are %s", operation.Type.Value, formatting.HumanizeList(validOperationActionTypes, formatting.DelimiterOr)),
+							Hint:    fmt.Sprintf("Valid types are %s", formatting.HumanizeList(validOperationActionTypes, formatting.DelimiterOr)),
 						},
 						operation.Type,
 					),
@@ -206,14 +231,11 @@ func ActionModelInputsRule(asts []*parser.AST) (errs errorhandling.ValidationErr
 	return
 }
 
-// CreateOperationNoReadInputsRule validates that create actions don't accept
+// CreateOperationNoReadInputsRule validates that create and createMany actions don't accept
 // any read-only inputs
 func CreateOperationNoReadInputsRule(asts []*parser.AST) (errs errorhandling.ValidationErrors) {
 	for _, model := range query.Models(asts) {
-		for _, action := range query.ModelActions(model) {
-			if action.Type.Value != parser.ActionTypeCreate {
-				continue
-			}
+		for _, action := range query.ModelCreateActions(model) {
 
 			if len(action.Inputs) == 0 {
 				continue
@@ -229,7 +229,7 @@ func satisfiedBySetExpr(rootModelName string, dotDelimPath string, action *parse
 		}
 		lhs := assignment.LHS
 
-		if lhs.Ident == nil {
+		if lhs.Ident == nil || lhs.IsComputed() {
 			continue
 		}
 
@@ -44,6 +44,10 @@ func AttributeLocationsRule(asts []*parser.AST) (errs errorhandling.ValidationEr
 		}
 	}
 
+	for _, event := range query.Events(asts) {
+		errs.Concat(checkAttributes(event.Attributes(), parser.KeywordEvent, event.Name.ToString()))
+	}
+
 	for _, api := range query.APIs(asts) {
 		for _, section := range api.Sections {
 			if section.Attribute != nil {
@@ -60,12 +64,14 @@ var attributeLocations = map[string][]string{
 		parser.AttributePermission,
 		parser.AttributeUnique,
 		parser.AttributeOn,
+		parser.AttributeIndex,
 	},
 	parser.KeywordField: {
 		parser.AttributeUnique,
 		parser.AttributeDefault,
 		parser.AttributePrimaryKey,
 		parser.AttributeRelation,
+		parser.AttributeIndex,
 	},
 	parser.KeywordActions: {
 		parser.AttributeSet,
@@ -76,11 +82,16 @@ var attributeLocations = map[string][]string{
 		parser.AttributeSortable,
 		parser.AttributeFunction,
 		parser.AttributeEmbed,
+		parser.AttributeNearest,
+		parser.AttributeEmit,
 	},
 	parser.KeywordJob: {
 		parser.AttributePermission,
 		parser.AttributeSchedule,
 	},
+	parser.KeywordEvent: {
+		parser.AttributeOn,
+	},
 }
 
 func checkAttributes(attributes []*parser.AttributeNode, definedOn string, parentName string) (errs errorhandling.ValidationErrors) {
@@ -0,0 +1,118 @@
+package validation
+
+import (
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/schema/node"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// SecretFieldsRule validates the usage of Secret fields, which are encrypted at rest and so can only be
+// written and read whole:
+// - Secret fields can't be arrays
+// - Secret fields can't be used as action inputs which filter, such as list or get inputs
+// - Secret fields can't be used in @where or @permission expressions
+//
+// Secret fields also can't be @unique or indexed, which is validated by UniqueAttributeRule and IndexAttributeRule.
+func SecretFieldsRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+
+	return Visitor{
+		EnterModel: func(model *parser.ModelNode) {
+			currentModel = model
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterField: func(field *parser.FieldNode) {
+			if currentModel == nil || field.Type.Value != parser.FieldTypeSecret || !field.Repeated {
+				return
+			}
+
+			errs.AppendError(secretFieldError(field.Type, "Secret fields cannot be arrays"))
+		},
+		EnterAction: func(action *parser.ActionNode) {
+			if currentModel == nil {
+				return
+			}
+
+			for _, input := range action.Inputs {
+				field := query.ResolveInputField(asts, input, currentModel)
+				if field != nil && field.Type.Value == parser.FieldTypeSecret {
+					errs.AppendError(secretFieldError(input.Type, "Secret fields cannot be used to filter as they are encrypted"))
+				}
+			}
+		},
+		EnterAttribute: func(attribute *parser.AttributeNode) {
+			if currentModel == nil {
+				return
+			}
+
+			if attribute.Name.Value != parser.AttributeWhere && attribute.Name.Value != parser.AttributePermission {
+				return
+			}
+
+			for _, arg := range attribute.Arguments {
+				if arg.Expression == nil {
+					continue
+				}
+
+				for _, condition := range arg.Expression.Conditions() {
+					for _, operand := range condition.Operands() {
+						if operand.Ident == nil {
+							continue
+						}
+
+						field := identField(asts, currentModel, operand.Ident)
+						if field != nil && field.Type.Value == parser.FieldTypeSecret {
+							errs.AppendError(secretFieldError(operand.Ident, "Secret fields cannot be used in expressions as they are encrypted"))
+						}
+					}
+				}
+			}
+		},
+	}
+}
+
+// identField resolves the field which an ident such as post.author.name or ctx.identity.email refers to
+// from the given model, returning nil if the ident does not refer to a model field.
+func identField(asts []*parser.AST, model *parser.ModelNode, ident *parser.Ident) *parser.FieldNode {
+	fragments := ident.Fragments
+
+	switch {
+	case len(fragments) > 2 && ident.IsContextIdentity():
+		model = query.Model(asts, parser.IdentityModelName)
+		fragments = fragments[2:]
+	case len(fragments) > 1 && fragments[0].Fragment == casing.ToLowerCamel(model.Name.Value):
+		fragments = fragments[1:]
+	default:
+		return nil
+	}
+
+	var field *parser.FieldNode
+	for _, fragment := range fragments {
+		if model == nil {
+			return nil
+		}
+
+		field = query.ModelField(model, fragment.Fragment)
+		if field == nil {
+			return nil
+		}
+
+		model = query.Model(asts, field.Type.Value)
+	}
+
+	return field
+}
+
+func secretFieldError(n node.ParserNode, message string) *errorhandling.ValidationError {
+	return errorhandling.NewValidationErrorWithDetails(
+		errorhandling.TypeError,
+		errorhandling.ErrorDetails{
+			Message: message,
+		},
+		n,
+	)
+}
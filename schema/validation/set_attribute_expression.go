@@ -91,7 +91,7 @@ func SetAttributeExpressionRules(asts []*parser.AST, errs *errorhandling.Validat
 
 			lhs := conditions[0].LHS
 
-			if lhs.Ident == nil {
+			if lhs.Ident == nil || lhs.IsComputed() {
 				errs.AppendError(makeSetExpressionError(
 					"The @set attribute can only be used to set model fields",
 					fmt.Sprintf("For example, assign a value to a field on this model with @set(%s.isActive = true)", strcase.ToLowerCamel(model.Name.Value)),
@@ -12,6 +12,7 @@ import (
 
 // UniqueAttributeRule validates that unique attributes are valid according to the following rules:
 // - @unique can't be used on Timestamp fields
+// - @unique can't be used on Secret fields
 // - @unique can't be used on has-many relations
 // - @unique can't be used on array fields
 // - composite @unique attributes must not have duplicate field names
@@ -152,5 +153,9 @@ func uniquePermitted(f *parser.FieldNode) (bool, string) {
 		return false, "@unique is not permitted on Timestamp fields"
 	}
 
+	if f.Type.Value == parser.FieldTypeSecret {
+		return false, "@unique is not permitted on Secret fields"
+	}
+
 	return true, ""
 }
@@ -175,7 +175,7 @@ func expressionHasUniqueLookup(asts []*parser.AST, expression *parser.Expression
 						break
 					}
 
-					if op.Ident == nil {
+					if op.Ident == nil || op.IsComputed() {
 						continue
 					}
 
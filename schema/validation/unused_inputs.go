@@ -64,8 +64,8 @@ func UnusedInputRule(_ []*parser.AST, errs *errorhandling.ValidationErrors) Visi
 			}
 
 			for _, cond := range expr.Conditions() {
-				for _, operand := range []*parser.Operand{cond.LHS, cond.RHS} {
-					if operand == nil || operand.Ident == nil {
+				for _, operand := range cond.Operands() {
+					if operand.Ident == nil {
 						continue
 					}
 
@@ -73,7 +73,10 @@ var visitorFuncs = []VisitorFunc{
 	UniqueLookup,
 	InvalidWithUsage,
 	UniqueAttributeRule,
+	IndexAttributeRule,
+	SecretFieldsRule,
 	OrderByAttributeRule,
+	NearestAttributeRule,
 	SortableAttributeRule,
 	SetAttributeExpressionRules,
 	Jobs,
@@ -83,6 +86,7 @@ var visitorFuncs = []VisitorFunc{
 	PermissionsAttributeArguments,
 	FunctionDisallowedBehavioursRule,
 	OnAttributeRule,
+	EventsRule,
 	EmbedAttributeRule,
 	RelationshipsRules,
 	ApiModelActions,
@@ -67,6 +67,9 @@ type Visitor struct {
 
 	EnterJobInput func(n *parser.JobInputNode)
 	LeaveJobInput func(n *parser.JobInputNode)
+
+	EnterEvent func(n *parser.EventNode)
+	LeaveEvent func(n *parser.EventNode)
 }
 
 type VisitorFunc func([]*parser.AST, *errorhandling.ValidationErrors) Visitor
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/teamkeel/keel/functions"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/apis/httpjson"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
+)
+
+// Jobs are triggered with a POST request to this path followed by the name of the job, for
+// example /_jobs/sendReminders. The request is authorised with the permissions of the job.
+const JobsPath = "/_jobs/"
+
+func (s *Server) withJobs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, JobsPath) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		response := s.handleJob(r)
+
+		for k, values := range response.Headers {
+			for _, value := range values {
+				w.Header().Add(k, value)
+			}
+		}
+		w.WriteHeader(response.Status)
+		_, _ = w.Write(response.Body)
+	})
+}
+
+func (s *Server) handleJob(r *http.Request) common.Response {
+	ctx, span := tracer.Start(r.Context(), "Trigger job")
+	defer span.End()
+
+	if r.Method != http.MethodPost {
+		return httpjson.NewErrorResponse(ctx, common.NewHttpMethodNotAllowedError("only HTTP POST accepted"), nil)
+	}
+
+	jobName := strings.TrimPrefix(r.URL.Path, JobsPath)
+	if s.schema.FindJob(strcase.ToCamel(jobName)) == nil {
+		return httpjson.NewErrorResponse(ctx, common.NewMethodNotFoundError(), nil)
+	}
+
+	identity, err := actions.HandleAuthorizationHeader(ctx, s.schema, r.Header)
+	if err != nil {
+		return httpjson.NewErrorResponse(ctx, err, nil)
+	}
+	if identity != nil {
+		ctx = auth.WithIdentity(ctx, identity)
+	}
+
+	inputs := map[string]any{}
+	if r.ContentLength != 0 {
+		data, err := common.ParseRequestData(r)
+		if err != nil {
+			return httpjson.NewErrorResponse(ctx, common.NewInputMalformedError("error parsing POST body"), nil)
+		}
+
+		m, ok := data.(map[string]any)
+		if !ok {
+			return httpjson.NewErrorResponse(ctx, common.NewInputMalformedError("job inputs must be an object"), nil)
+		}
+		inputs = m
+	}
+
+	err = s.RunJob(ctx, jobName, inputs, functions.ManualTrigger)
+	if err != nil {
+		return httpjson.NewErrorResponse(ctx, err, nil)
+	}
+
+	return common.NewJsonResponse(http.StatusOK, map[string]any{}, nil)
+}
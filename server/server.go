@@ -0,0 +1,459 @@
+package server
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/cors"
+	log "github.com/sirupsen/logrus"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/encryption"
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/functions"
+	"github.com/teamkeel/keel/mail"
+	"github.com/teamkeel/keel/node"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/scheduler"
+	"github.com/teamkeel/keel/storage"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/teamkeel/keel/server")
+
+// Environment variables which are read when the equivalent option is not given
+const (
+	EnvPort            = "PORT"
+	EnvDbConn          = "KEEL_DB_CONN"
+	EnvPrivateKey      = "KEEL_PRIVATE_KEY"
+	EnvPrivateKeyPath  = "KEEL_PRIVATE_KEY_PATH"
+	EnvFunctionsUrl    = "KEEL_FUNCTIONS_URL"
+	EnvApiUrl          = "KEEL_API_URL"
+	EnvShutdownTimeout = "KEEL_SHUTDOWN_TIMEOUT"
+)
+
+// Secrets are read from environment variables with this prefix followed by the name of the secret,
+// for example KEEL_SECRET_STRIPE_API_KEY
+const SecretEnvPrefix = "KEEL_SECRET_"
+
+// Health endpoints
+const (
+	// Responds with 200 for as long as the process is serving requests
+	HealthPath = "/healthz"
+	// Responds with 200 once the server is ready to handle requests and the database can be reached,
+	// and with 503 once the server has begun to shut down
+	ReadyPath = "/readyz"
+)
+
+const (
+	defaultPort            = "8000"
+	defaultShutdownTimeout = 30 * time.Second
+	readinessTimeout       = 2 * time.Second
+)
+
+// Options configures the server. Any which are empty are read from the environment.
+type Options struct {
+	Port            string
+	DbConn          string
+	PrivateKeyPath  string
+	FunctionsUrl    string
+	ApiUrl          string
+	ShutdownTimeout time.Duration
+	ProjectDir      string
+}
+
+// Server runs the runtime for a built schema, handling requests concurrently.
+type Server struct {
+	schema      *proto.Schema
+	config      *config.ProjectConfig
+	options     Options
+	database    db.Database
+	privateKeys []*rsa.PrivateKey
+	secrets     map[string]string
+	keyring     *encryption.Keyring
+	storage     storage.Storer
+	mailClient  mail.EmailClient
+	templates   *mail.Templates
+	transport   functions.Transport
+	handler     http.Handler
+	ready       atomic.Bool
+}
+
+// New sets up the services needed by the runtime, failing if any of the required settings are missing.
+func New(ctx context.Context, schema *proto.Schema, cfg *config.ProjectConfig, options Options) (*Server, error) {
+	if cfg == nil {
+		cfg = &config.ProjectConfig{}
+	}
+
+	options = withEnvDefaults(options)
+
+	s := &Server{
+		schema:  schema,
+		config:  cfg,
+		options: options,
+		secrets: SecretsFromEnv(os.Environ()),
+	}
+
+	missing, names := cfg.ValidateSecrets(s.secrets)
+	if missing {
+		return nil, fmt.Errorf("missing secrets, which must be set with the %s prefix: %s", SecretEnvPrefix, strings.Join(names, ", "))
+	}
+
+	if options.DbConn == "" {
+		return nil, fmt.Errorf("a database connection string is required, set with --db-conn or %s", EnvDbConn)
+	}
+
+	privateKeys, err := loadPrivateKeys(options.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	s.privateKeys = privateKeys
+
+	s.keyring, err = encryption.LoadKeyring(s.secrets)
+	if err != nil {
+		return nil, err
+	}
+	if s.keyring == nil && schema.HasSecretFields() {
+		return nil, fmt.Errorf("an encryption key is required as the schema has Secret fields, set with %s or the %s secret", encryption.EnvKey, encryption.SecretKey)
+	}
+
+	if node.HasFunctions(schema, cfg) {
+		if options.FunctionsUrl == "" {
+			return nil, fmt.Errorf("this project has functions, and so the url of the functions server is required, set with --functions-url or %s", EnvFunctionsUrl)
+		}
+		s.transport = functions.NewHttpTransport(options.FunctionsUrl)
+	}
+
+	// Environment variables from the project config are defaults, and so anything set on the host takes precedence
+	for k, v := range cfg.GetEnvVars() {
+		if _, ok := os.LookupEnv(k); !ok {
+			os.Setenv(k, v)
+		}
+	}
+	if _, ok := os.LookupEnv(EnvApiUrl); !ok {
+		os.Setenv(EnvApiUrl, fmt.Sprintf("http://localhost:%s", options.Port))
+	}
+
+	s.database, err = db.New(ctx, options.DbConn)
+	if err != nil {
+		return nil, err
+	}
+
+	s.storage, err = storage.NewStorer(ctx, cfg, s.secrets, s.database)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mailClient, err = mail.NewClient(cfg, s.secrets, options.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	s.templates, err = mail.LoadTemplates(cfg.Email.TemplatesDir(options.ProjectDir))
+	if err != nil {
+		return nil, err
+	}
+
+	corsHandler := cors.New(cors.Options{
+		AllowOriginFunc: func(origin string) bool {
+			return true
+		},
+		AllowedMethods: []string{
+			http.MethodHead,
+			http.MethodGet,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+		AllowedHeaders:   []string{"*"},
+		AllowCredentials: true,
+	})
+
+	s.handler = s.withHealth(corsHandler.Handler(s.withRuntimeContext(s.withJobs(runtime.NewHttpHandler(schema)))))
+
+	return s, nil
+}
+
+// Handler returns the handler for all requests to the server, including the health and job endpoints.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Run serves requests, delivers events and runs scheduled jobs until the context is cancelled, and then shuts down gracefully
+// by failing readiness checks, waiting for in-flight requests to complete, and closing the database.
+func (s *Server) Run(ctx context.Context) error {
+	defer s.database.Close()
+
+	// In-flight requests are not cancelled when shutdown begins, and so they are given a context of their own
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%s", s.options.Port),
+		Handler: s.handler,
+		BaseContext: func(_ net.Listener) context.Context {
+			return context.Background()
+		},
+	}
+
+	workerCtx, stopWorker := context.WithCancel(s.runtimeContext(context.Background()))
+	defer stopWorker()
+
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		s.runWorker(workerCtx)
+	}()
+
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		s.runScheduler(workerCtx)
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.WithField("port", s.options.Port).Info("keel server is listening")
+		serveErr <- server.ListenAndServe()
+	}()
+
+	s.ready.Store(true)
+
+	select {
+	case err := <-serveErr:
+		s.ready.Store(false)
+		stopWorker()
+		<-workerDone
+		<-schedulerDone
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("keel server is shutting down")
+	s.ready.Store(false)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.options.ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+
+	stopWorker()
+	<-workerDone
+	<-schedulerDone
+
+	if err != nil {
+		return fmt.Errorf("shutting down the server: %w", err)
+	}
+
+	return nil
+}
+
+// runWorker delivers pending events until the context is cancelled. If the database is unavailable then
+// the worker stops, and so it is restarted after a delay.
+func (s *Server) runWorker(ctx context.Context) {
+	if len(s.schema.Events) == 0 {
+		return
+	}
+
+	worker := events.NewWorker(s.subscriberHandler())
+	for {
+		err := worker.Run(ctx)
+		if err != nil {
+			log.WithError(err).Error("delivering events")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(worker.Interval):
+		}
+	}
+}
+
+// runScheduler runs scheduled jobs as they become due until the context is cancelled. If the database
+// is unavailable then the scheduler stops, and so it is restarted after a delay.
+func (s *Server) runScheduler(ctx context.Context) {
+	if !scheduler.HasScheduledJobs(s.schema) {
+		return
+	}
+
+	sched := scheduler.New(s.schema, func(ctx context.Context, job string) error {
+		return s.RunJob(ctx, job, map[string]any{}, functions.ScheduledTrigger)
+	})
+	for {
+		err := sched.Run(ctx)
+		if err != nil {
+			log.WithError(err).Error("running scheduled jobs")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sched.Interval):
+		}
+	}
+}
+
+// runtimeContext adds the services needed by the runtime to the context
+func (s *Server) runtimeContext(ctx context.Context) context.Context {
+	ctx = runtimectx.WithPrivateKeys(ctx, s.privateKeys)
+	ctx = db.WithDatabase(ctx, s.database)
+	ctx = runtimectx.WithSecrets(ctx, s.secrets)
+	ctx = runtimectx.WithOAuthConfig(ctx, &s.config.Auth)
+	ctx = events.WithConfig(ctx, &s.config.Events)
+	ctx = runtimectx.WithActionsConfig(ctx, &s.config.Actions)
+	ctx = runtimectx.WithStorage(ctx, s.storage)
+	ctx = runtimectx.WithMailClient(ctx, s.mailClient)
+	ctx = runtimectx.WithMailTemplates(ctx, s.templates)
+	ctx = encryption.WithKeyring(ctx, s.keyring)
+
+	if s.transport != nil {
+		ctx = functions.WithFunctionsTransport(ctx, s.transport)
+	}
+
+	return ctx
+}
+
+// subscriberHandler runs subscribers in this process
+func (s *Server) subscriberHandler() events.EventHandler {
+	return func(ctx context.Context, subscriber string, event *events.Event, traceparent string) error {
+		return runtime.NewSubscriberHandler(s.schema).RunSubscriber(ctx, subscriber, event)
+	}
+}
+
+// RunJob runs a job, such as when it is triggered by an external scheduler.
+func (s *Server) RunJob(ctx context.Context, jobName string, inputs map[string]any, trigger functions.TriggerType) error {
+	ctx, err := events.WithEventHandler(s.runtimeContext(ctx), s.subscriberHandler())
+	if err != nil {
+		return err
+	}
+
+	return runtime.NewJobHandler(s.schema).RunJob(ctx, jobName, inputs, trigger)
+}
+
+func (s *Server) withRuntimeContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := s.runtimeContext(r.Context())
+
+		// Events are delivered straight after the request, and any failed deliveries are retried by the worker
+		ctx, err := events.WithEventHandler(ctx, s.subscriberHandler())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(err.Error()))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (s *Server) withHealth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case HealthPath:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("ok"))
+		case ReadyPath:
+			if !s.ready.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte("not ready"))
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+			defer cancel()
+
+			_, err := s.database.ExecuteQuery(ctx, "SELECT 1")
+			if err != nil {
+				log.WithError(err).Warn("readiness check could not reach the database")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte("database unavailable"))
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("ok"))
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// SecretsFromEnv returns the secrets set in the environment with the KEEL_SECRET_ prefix, keyed by the secret name.
+func SecretsFromEnv(environ []string) map[string]string {
+	secrets := map[string]string{}
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(k, SecretEnvPrefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(k, SecretEnvPrefix)
+		if name != "" {
+			secrets[name] = v
+		}
+	}
+	return secrets
+}
+
+func withEnvDefaults(options Options) Options {
+	if options.Port == "" {
+		options.Port = os.Getenv(EnvPort)
+	}
+	if options.Port == "" {
+		options.Port = defaultPort
+	}
+	if options.DbConn == "" {
+		options.DbConn = os.Getenv(EnvDbConn)
+	}
+	if options.PrivateKeyPath == "" {
+		options.PrivateKeyPath = os.Getenv(EnvPrivateKeyPath)
+	}
+	if options.FunctionsUrl == "" {
+		options.FunctionsUrl = os.Getenv(EnvFunctionsUrl)
+	}
+	if options.ApiUrl != "" {
+		os.Setenv(EnvApiUrl, options.ApiUrl)
+	}
+	if options.ShutdownTimeout <= 0 {
+		if d, err := time.ParseDuration(os.Getenv(EnvShutdownTimeout)); err == nil && d > 0 {
+			options.ShutdownTimeout = d
+		} else {
+			options.ShutdownTimeout = defaultShutdownTimeout
+		}
+	}
+	return options
+}
+
+// loadPrivateKeys parses the PEM encoded RSA private keys used to sign access tokens, either from the file
+// at path or from the KEEL_PRIVATE_KEY environment variable. The first key signs new tokens, and any which
+// follow are still accepted, so that the signing key can be rotated.
+func loadPrivateKeys(path string) ([]*rsa.PrivateKey, error) {
+	var privateKeyPem []byte
+
+	switch {
+	case path != "":
+		b, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cannot locate private key file at: %s", path)
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot read private key file: %s", err.Error())
+		}
+		privateKeyPem = b
+	case os.Getenv(EnvPrivateKey) != "":
+		privateKeyPem = []byte(os.Getenv(EnvPrivateKey))
+	default:
+		return nil, fmt.Errorf("a private key is required to sign access tokens, set with --private-key-path, %s or %s", EnvPrivateKeyPath, EnvPrivateKey)
+	}
+
+	return oauth.ParsePrivateKeys(privateKeyPem)
+}
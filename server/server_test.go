@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"gorm.io/gorm"
+)
+
+type stubDatabase struct {
+	err error
+}
+
+func (s *stubDatabase) ExecuteQuery(ctx context.Context, sql string, args ...any) (*db.ExecuteQueryResult, error) {
+	return &db.ExecuteQueryResult{}, s.err
+}
+
+func (s *stubDatabase) ExecuteStatement(ctx context.Context, sql string, args ...any) (*db.ExecuteStatementResult, error) {
+	return &db.ExecuteStatementResult{}, s.err
+}
+
+func (s *stubDatabase) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (s *stubDatabase) Close() error { return nil }
+
+func (s *stubDatabase) GetDB() *gorm.DB { return nil }
+
+func serve(t *testing.T, handler http.Handler, method string, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+func TestSecretsFromEnv(t *testing.T) {
+	secrets := SecretsFromEnv([]string{
+		"KEEL_SECRET_STRIPE_API_KEY=sk_test=123",
+		"KEEL_SECRET_=ignored",
+		"KEEL_DB_CONN=postgres://localhost",
+		"PATH=/usr/bin",
+	})
+
+	require.Equal(t, map[string]string{"STRIPE_API_KEY": "sk_test=123"}, secrets)
+}
+
+func TestHealth(t *testing.T) {
+	s := &Server{database: &stubDatabase{}}
+	handler := s.withHealth(http.NotFoundHandler())
+
+	res := serve(t, handler, http.MethodGet, HealthPath)
+	require.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestReadiness(t *testing.T) {
+	database := &stubDatabase{}
+	s := &Server{database: database}
+	handler := s.withHealth(http.NotFoundHandler())
+
+	res := serve(t, handler, http.MethodGet, ReadyPath)
+	require.Equal(t, http.StatusServiceUnavailable, res.Code, "not ready until the server is running")
+
+	s.ready.Store(true)
+	res = serve(t, handler, http.MethodGet, ReadyPath)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	database.err = errors.New("connection refused")
+	res = serve(t, handler, http.MethodGet, ReadyPath)
+	require.Equal(t, http.StatusServiceUnavailable, res.Code)
+
+	res = serve(t, handler, http.MethodGet, "/api/json/listPosts")
+	require.Equal(t, http.StatusNotFound, res.Code, "other requests are passed through")
+}
+
+func TestJobs(t *testing.T) {
+	s := &Server{schema: &proto.Schema{}}
+	handler := s.withJobs(http.NotFoundHandler())
+
+	res := serve(t, handler, http.MethodGet, JobsPath+"sendReminders")
+	require.Equal(t, http.StatusMethodNotAllowed, res.Code)
+
+	res = serve(t, handler, http.MethodPost, JobsPath+"sendReminders")
+	require.Equal(t, http.StatusNotFound, res.Code)
+}
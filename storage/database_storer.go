@@ -2,21 +2,36 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"os"
+	"time"
 
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/config"
 	"github.com/teamkeel/keel/db"
 	"github.com/vincent-petithory/dataurl"
 )
 
 type DbStore struct {
-	db db.Database
+	db            db.Database
+	urlExpiry     time.Duration
+	maxUploadSize int64
 }
 
-// Make sure DbStore implements the storage.Storer interface. Any missing methods will cause a compile error
+// Make sure DbStore implements the storage.Storer and storage.UploadReceiver interfaces. Any missing methods will cause a compile error
 var _ Storer = &DbStore{}
+var _ UploadReceiver = &DbStore{}
 
-const dbTable = "keel_storage" // table where files will be stored
+const (
+	dbTable = "keel_storage" // table where files will be stored
+)
+
+// UploadPath is the path of the runtime endpoint which receives direct uploads for the database storage
+const UploadPath = "/files/upload/"
 
 type fileData struct {
 	Filename    string
@@ -24,10 +39,33 @@ type fileData struct {
 	Data        []byte
 }
 
+// DbConfig is the configuration for direct uploads to the database storage
+type DbConfig struct {
+	// UrlExpiry is how long upload tickets are valid for
+	UrlExpiry time.Duration
+
+	// MaxUploadSize is the largest file in bytes which can be uploaded directly
+	MaxUploadSize int64
+}
+
 // NewDbStore will return a Storage service for files that is db based
 func NewDbStore(ctx context.Context, db db.Database) (*DbStore, error) {
+	return NewDbStoreWithConfig(ctx, db, DbConfig{})
+}
+
+// NewDbStoreWithConfig will return a Storage service for files that is db based, using the given
+// configuration for direct uploads
+func NewDbStoreWithConfig(ctx context.Context, db db.Database, cfg DbConfig) (*DbStore, error) {
 	svc := &DbStore{
-		db: db,
+		db:            db,
+		urlExpiry:     cfg.UrlExpiry,
+		maxUploadSize: cfg.MaxUploadSize,
+	}
+	if svc.urlExpiry <= 0 {
+		svc.urlExpiry = config.DefaultStorageUrlExpiry
+	}
+	if svc.maxUploadSize <= 0 {
+		svc.maxUploadSize = config.DefaultStorageMaxUploadSize
 	}
 	if err := svc.setupDB(ctx); err != nil {
 		return nil, err
@@ -142,3 +180,94 @@ func (s *DbStore) HydrateFileInfo(fi *FileResponse) (FileResponse, error) {
 		URL:         &dataURL,
 	}, nil
 }
+
+func (s *DbStore) GenerateUploadTicket(filename string, contentType string, size int64) (UploadTicket, error) {
+	if size > s.maxUploadSize {
+		return UploadTicket{}, ErrUploadTooLarge
+	}
+
+	key := ksuid.New().String()
+	expiresAt := time.Now().UTC().Add(s.urlExpiry)
+
+	uploadUrl, err := directUploadUrl(key)
+	if err != nil {
+		return UploadTicket{}, err
+	}
+
+	return UploadTicket{
+		Key:    key,
+		Url:    uploadUrl,
+		Method: http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type": contentType,
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *DbStore) ReceiveUpload(key string, body io.Reader) (FileResponse, error) {
+	var ticket struct {
+		Size int64
+	}
+
+	sql := `SELECT size FROM ` + UploadTable + ` WHERE id = ? AND expires_at > now() AND uploaded_at IS NULL`
+
+	db := s.db.GetDB().Raw(sql, key).Scan(&ticket)
+	if db.Error != nil {
+		return FileResponse{}, fmt.Errorf("retrieving upload ticket: %w", db.Error)
+	}
+	if db.RowsAffected == 0 {
+		return FileResponse{}, ErrUploadNotFound
+	}
+
+	// The file can be no larger than the size it was issued a ticket for, and reading stops
+	// as soon as it exceeds it
+	limit := ticket.Size
+	if limit <= 0 || limit > s.maxUploadSize {
+		limit = s.maxUploadSize
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return FileResponse{}, fmt.Errorf("reading upload: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return FileResponse{}, ErrUploadTooLarge
+	}
+
+	// The upload ticket is marked as uploaded as the file is saved, so that a ticket can only be uploaded to once
+	sql = `WITH upload AS (
+			UPDATE ` + UploadTable + ` SET uploaded_at = now()
+			WHERE id = ? AND expires_at > now() AND uploaded_at IS NULL
+			RETURNING id, filename, content_type
+		)
+		INSERT INTO ` + dbTable + ` (id, filename, content_type, data)
+		SELECT id, filename, content_type, ? FROM upload
+		RETURNING
+			id AS key,
+			filename,
+			content_type,
+			octet_length(data) AS size`
+
+	var fi FileResponse
+	db = s.db.GetDB().Raw(sql, key, data).Scan(&fi)
+	if db.Error != nil {
+		return FileResponse{}, fmt.Errorf("saving file in db: %w", db.Error)
+	}
+
+	if fi.Key == "" {
+		return FileResponse{}, ErrUploadNotFound
+	}
+
+	return fi, nil
+}
+
+// directUploadUrl is the URL of the runtime endpoint where the file for the given key can be uploaded
+func directUploadUrl(key string) (string, error) {
+	apiUrl, err := url.ParseRequestURI(os.Getenv("KEEL_API_URL"))
+	if err != nil {
+		return "", errors.New("the KEEL_API_URL environment variable must be set to a valid url for direct uploads")
+	}
+
+	return apiUrl.JoinPath(UploadPath, key).String(), nil
+}
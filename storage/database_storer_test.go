@@ -0,0 +1,139 @@
+package storage_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/storage"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+func newDbStore(t *testing.T) (context.Context, db.Database, *storage.DbStore) {
+	t.Setenv("KEEL_API_URL", "http://localhost:8000")
+
+	ctx, database, _ := keeltesting.MakeContext(t, context.TODO(), `model Photo { fields { image File } }`, true)
+	t.Cleanup(func() {
+		database.Close()
+	})
+
+	store, err := storage.NewDbStoreWithConfig(ctx, database, storage.DbConfig{
+		UrlExpiry:     time.Minute,
+		MaxUploadSize: 16,
+	})
+	require.NoError(t, err)
+
+	return ctx, database, store
+}
+
+func issueTicket(t *testing.T, ctx context.Context, database db.Database, store storage.Storer, identityId string, size int64) storage.UploadTicket {
+	ticket, err := storage.IssueUploadTicket(ctx, database, store, storage.UploadRequest{
+		ModelName:   "Photo",
+		FieldName:   "image",
+		IdentityId:  identityId,
+		Filename:    "cat.png",
+		ContentType: "image/png",
+		Size:        size,
+	})
+	require.NoError(t, err)
+	return ticket
+}
+
+func TestDbStoreReceiveUpload(t *testing.T) {
+	ctx, database, store := newDbStore(t)
+
+	ticket := issueTicket(t, ctx, database, store, "", 3)
+	assert.Equal(t, "http://localhost:8000/files/upload/"+ticket.Key, ticket.Url)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), ticket.ExpiresAt, 5*time.Second)
+
+	fi, err := store.ReceiveUpload(ticket.Key, strings.NewReader("cat"))
+	require.NoError(t, err)
+	assert.Equal(t, ticket.Key, fi.Key)
+	assert.Equal(t, "cat.png", fi.Filename)
+	assert.Equal(t, "image/png", fi.ContentType)
+	assert.Equal(t, 3, fi.Size)
+
+	// A ticket can only be uploaded to once
+	_, err = store.ReceiveUpload(ticket.Key, strings.NewReader("dog"))
+	assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+}
+
+func TestDbStoreReceiveUploadUnknownKey(t *testing.T) {
+	_, _, store := newDbStore(t)
+
+	_, err := store.ReceiveUpload("unknown", strings.NewReader("cat"))
+	assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+}
+
+func TestDbStoreReceiveUploadExpired(t *testing.T) {
+	ctx, database, store := newDbStore(t)
+
+	ticket := issueTicket(t, ctx, database, store, "", 3)
+	require.NoError(t, database.GetDB().Exec("UPDATE keel_storage_upload SET expires_at = now() - interval '1 second' WHERE id = ?", ticket.Key).Error)
+
+	_, err := store.ReceiveUpload(ticket.Key, strings.NewReader("cat"))
+	assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+}
+
+func TestDbStoreReceiveUploadTooLarge(t *testing.T) {
+	ctx, database, store := newDbStore(t)
+
+	_, err := store.GenerateUploadTicket("cat.png", "image/png", 17)
+	assert.ErrorIs(t, err, storage.ErrUploadTooLarge)
+
+	// The body can be no larger than the size given for the ticket
+	ticket := issueTicket(t, ctx, database, store, "", 3)
+	_, err = store.ReceiveUpload(ticket.Key, strings.NewReader("kitten"))
+	assert.ErrorIs(t, err, storage.ErrUploadTooLarge)
+
+	// ... and the ticket can still be used
+	_, err = store.ReceiveUpload(ticket.Key, strings.NewReader("cat"))
+	require.NoError(t, err)
+}
+
+func TestConsumeUploadTicket(t *testing.T) {
+	ctx, database, store := newDbStore(t)
+
+	ticket := issueTicket(t, ctx, database, store, "identity_1", 3)
+
+	consumed, err := storage.ConsumeUploadTicket(ctx, database, ticket.Key, "Photo", "image", "identity_2")
+	require.NoError(t, err)
+	assert.False(t, consumed)
+
+	consumed, err = storage.ConsumeUploadTicket(ctx, database, ticket.Key, "Photo", "thumbnail", "identity_1")
+	require.NoError(t, err)
+	assert.False(t, consumed)
+
+	consumed, err = storage.ConsumeUploadTicket(ctx, database, ticket.Key, "Photo", "image", "")
+	require.NoError(t, err)
+	assert.False(t, consumed)
+
+	consumed, err = storage.ConsumeUploadTicket(ctx, database, ticket.Key, "Photo", "image", "identity_1")
+	require.NoError(t, err)
+	assert.True(t, consumed)
+
+	consumed, err = storage.ConsumeUploadTicket(ctx, database, ticket.Key, "Photo", "image", "identity_1")
+	require.NoError(t, err)
+	assert.False(t, consumed)
+}
+
+func TestExpiredUploadTicketsDeleted(t *testing.T) {
+	ctx, database, store := newDbStore(t)
+
+	expired := issueTicket(t, ctx, database, store, "", 3)
+	require.NoError(t, database.GetDB().Exec("UPDATE keel_storage_upload SET expires_at = now() - interval '2 hours' WHERE id = ?", expired.Key).Error)
+
+	// Expired tickets are cleaned up as new tickets are issued
+	issueTicket(t, ctx, database, store, "", 3)
+
+	var count int64
+	require.NoError(t, database.GetDB().Table("keel_storage_upload").Where("id = ?", expired.Key).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	require.NoError(t, database.GetDB().Table("keel_storage_upload").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/config"
+)
+
+type S3Store struct {
+	client    *s3.Client
+	presigner *s3.PresignClient
+	bucket    string
+	urlExpiry time.Duration
+	maxUpload int64
+}
+
+// Make sure S3Store implements the storage.Storer interface. Any missing methods will cause a compile error
+var _ Storer = &S3Store{}
+
+const (
+	defaultS3Region = "us-east-1"
+	// the object metadata key where the original filename is kept
+	filenameMetadataKey = "filename"
+)
+
+// S3Config is the configuration needed to store files in an S3-compatible bucket
+type S3Config struct {
+	Bucket          string
+	Region          string
+	AccessKeyId     string
+	SecretAccessKey string
+
+	// Endpoint is only needed for S3-compatible services other than AWS, such as MinIO
+	Endpoint string
+
+	// UsePathStyle addresses the bucket in the path of the URL rather than in the host, which
+	// is required by most S3-compatible services
+	UsePathStyle bool
+
+	// UrlExpiry is how long presigned download URLs are valid for
+	UrlExpiry time.Duration
+
+	// MaxUploadSize is the largest file in bytes which can be uploaded directly
+	MaxUploadSize int64
+}
+
+// NewS3Store will return a Storage service for files that is S3 bucket based
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("a bucket is required for S3 file storage")
+	}
+	if cfg.AccessKeyId == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("credentials are required for S3 file storage")
+	}
+
+	options := s3.Options{
+		Region:       cfg.Region,
+		Credentials:  credentials.NewStaticCredentialsProvider(cfg.AccessKeyId, cfg.SecretAccessKey, ""),
+		UsePathStyle: cfg.UsePathStyle,
+	}
+	if options.Region == "" {
+		options.Region = defaultS3Region
+	}
+	if cfg.Endpoint != "" {
+		options.BaseEndpoint = aws.String(cfg.Endpoint)
+	}
+
+	urlExpiry := cfg.UrlExpiry
+	if urlExpiry <= 0 {
+		urlExpiry = config.DefaultStorageUrlExpiry
+	}
+
+	maxUpload := cfg.MaxUploadSize
+	if maxUpload <= 0 {
+		maxUpload = config.DefaultStorageMaxUploadSize
+	}
+
+	client := s3.New(options)
+
+	return &S3Store{
+		client:    client,
+		presigner: s3.NewPresignClient(client),
+		bucket:    cfg.Bucket,
+		urlExpiry: urlExpiry,
+		maxUpload: maxUpload,
+	}, nil
+}
+
+func (s *S3Store) Store(url string) (FileResponse, error) {
+	fd, err := decodeDataURL(url)
+	if err != nil {
+		return FileResponse{}, fmt.Errorf("decoding data URL: %w", err)
+	}
+
+	key := ksuid.New().String()
+
+	_, err = s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:             aws.String(s.bucket),
+		Key:                aws.String(key),
+		Body:               bytes.NewReader(fd.Data),
+		ContentLength:      aws.Int64(int64(len(fd.Data))),
+		ContentType:        aws.String(fd.ContentType),
+		ContentDisposition: aws.String(contentDisposition(fd.Filename)),
+		Metadata: map[string]string{
+			filenameMetadataKey: escapeMetadata(fd.Filename),
+		},
+	})
+	if err != nil {
+		return FileResponse{}, fmt.Errorf("saving file in bucket: %w", err)
+	}
+
+	return FileResponse{
+		Key:         key,
+		Filename:    fd.Filename,
+		ContentType: fd.ContentType,
+		Size:        len(fd.Data),
+	}, nil
+}
+
+func (s *S3Store) GetFileInfo(key string) (FileResponse, error) {
+	head, err := s.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return FileResponse{}, fmt.Errorf("retrieving file info: %w", err)
+	}
+
+	filename, err := url.QueryUnescape(head.Metadata[filenameMetadataKey])
+	if err != nil {
+		return FileResponse{}, fmt.Errorf("decoding filename: %w", err)
+	}
+
+	return FileResponse{
+		Key:         key,
+		Filename:    filename,
+		ContentType: aws.ToString(head.ContentType),
+		Size:        int(aws.ToInt64(head.ContentLength)),
+	}, nil
+}
+
+func (s *S3Store) HydrateFileInfo(fi *FileResponse) (FileResponse, error) {
+	req, err := s.presigner.PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(fi.Key),
+	}, s3.WithPresignExpires(s.urlExpiry))
+	if err != nil {
+		return FileResponse{}, fmt.Errorf("generating file url: %w", err)
+	}
+
+	return FileResponse{
+		Key:         fi.Key,
+		Filename:    fi.Filename,
+		ContentType: fi.ContentType,
+		Size:        fi.Size,
+		URL:         &req.URL,
+	}, nil
+}
+
+func (s *S3Store) GenerateUploadTicket(filename string, contentType string, size int64) (UploadTicket, error) {
+	if size > s.maxUpload {
+		return UploadTicket{}, ErrUploadTooLarge
+	}
+
+	key := ksuid.New().String()
+	expiresAt := time.Now().UTC().Add(s.urlExpiry)
+
+	req, err := s.presigner.PresignPutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:             aws.String(s.bucket),
+		Key:                aws.String(key),
+		ContentType:        aws.String(contentType),
+		ContentLength:      aws.Int64(size),
+		ContentDisposition: aws.String(contentDisposition(filename)),
+		Metadata: map[string]string{
+			filenameMetadataKey: escapeMetadata(filename),
+		},
+	}, s3.WithPresignExpires(s.urlExpiry))
+	if err != nil {
+		return UploadTicket{}, fmt.Errorf("generating upload url: %w", err)
+	}
+
+	// The upload must be made with the same headers which were signed, as well as the content type
+	// which is stored with the file. The content length is signed so that only a file of the size
+	// given for the ticket can be uploaded.
+	headers := map[string]string{
+		"Content-Type": contentType,
+	}
+	for name := range req.SignedHeader {
+		if strings.EqualFold(name, "Host") {
+			continue
+		}
+		headers[name] = req.SignedHeader.Get(name)
+	}
+
+	return UploadTicket{
+		Key:       key,
+		Url:       req.URL,
+		Method:    req.Method,
+		Headers:   headers,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// contentDisposition makes sure the file is downloaded with its original filename
+func contentDisposition(filename string) string {
+	if filename == "" {
+		return "inline"
+	}
+	return mime.FormatMediaType("inline", map[string]string{"filename": filename})
+}
+
+// escapeMetadata escapes the value as S3 object metadata can only contain ASCII characters
+func escapeMetadata(value string) string {
+	return url.QueryEscape(value)
+}
@@ -0,0 +1,182 @@
+package storage_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/storage"
+)
+
+type object struct {
+	data    []byte
+	headers http.Header
+}
+
+// newBucketServer is a stand-in for an S3-compatible service such as MinIO. It only supports
+// path-style requests to put, head and get objects.
+func newBucketServer(t *testing.T, bucket string) *httptest.Server {
+	var mu sync.Mutex
+	objects := map[string]*object{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, found := strings.CutPrefix(r.URL.Path, "/"+bucket+"/")
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		signed := strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") || r.URL.Query().Get("X-Amz-Signature") != ""
+		if !signed {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			objects[key] = &object{data: data, headers: r.Header.Clone()}
+			w.WriteHeader(http.StatusOK)
+		case http.MethodHead, http.MethodGet:
+			obj, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", obj.headers.Get("Content-Type"))
+			w.Header().Set("Content-Length", obj.headers.Get("Content-Length"))
+			w.Header().Set("X-Amz-Meta-Filename", obj.headers.Get("X-Amz-Meta-Filename"))
+			w.WriteHeader(http.StatusOK)
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(obj.data)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+func newS3Store(t *testing.T, endpoint string) *storage.S3Store {
+	store, err := storage.NewS3Store(storage.S3Config{
+		Bucket:          "files",
+		Region:          "eu-west-2",
+		Endpoint:        endpoint,
+		UsePathStyle:    true,
+		AccessKeyId:     "minioadmin",
+		SecretAccessKey: "minioadmin",
+		UrlExpiry:       time.Minute,
+		MaxUploadSize:   1024,
+	})
+	require.NoError(t, err)
+	return store
+}
+
+func TestS3StoreRoundTrip(t *testing.T) {
+	server := newBucketServer(t, "files")
+	defer server.Close()
+
+	store := newS3Store(t, server.URL)
+
+	fi, err := store.Store("data:text/plain;name=my%20notes.txt;base64,aGVsbG8gd29ybGQ=")
+	require.NoError(t, err)
+	assert.NotEmpty(t, fi.Key)
+	assert.Equal(t, "my notes.txt", fi.Filename)
+	assert.Equal(t, "text/plain", fi.ContentType)
+	assert.Equal(t, 11, fi.Size)
+	assert.Nil(t, fi.URL)
+
+	info, err := store.GetFileInfo(fi.Key)
+	require.NoError(t, err)
+	assert.Equal(t, fi.Key, info.Key)
+	assert.Equal(t, "my notes.txt", info.Filename)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, 11, info.Size)
+
+	hydrated, err := store.HydrateFileInfo(&info)
+	require.NoError(t, err)
+	require.NotNil(t, hydrated.URL)
+	assert.True(t, strings.HasPrefix(*hydrated.URL, server.URL+"/files/"+fi.Key+"?"))
+	assert.Contains(t, *hydrated.URL, "X-Amz-Expires=60")
+	assert.Contains(t, *hydrated.URL, "X-Amz-Signature=")
+
+	res, err := http.Get(*hydrated.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "hello world", string(body))
+}
+
+func TestS3StoreFileNotFound(t *testing.T) {
+	server := newBucketServer(t, "files")
+	defer server.Close()
+
+	store := newS3Store(t, server.URL)
+
+	_, err := store.GetFileInfo("missing")
+	assert.ErrorContains(t, err, "retrieving file info")
+}
+
+func TestS3StoreMissingCredentials(t *testing.T) {
+	_, err := storage.NewS3Store(storage.S3Config{
+		Bucket: "files",
+	})
+	assert.ErrorContains(t, err, "credentials are required for S3 file storage")
+}
+
+func TestS3StoreUploadTicket(t *testing.T) {
+	server := newBucketServer(t, "files")
+	defer server.Close()
+
+	store := newS3Store(t, server.URL)
+
+	ticket, err := store.GenerateUploadTicket("report.pdf", "application/pdf", 8)
+	require.NoError(t, err)
+	assert.NotEmpty(t, ticket.Key)
+	assert.Equal(t, http.MethodPut, ticket.Method)
+	assert.True(t, strings.HasPrefix(ticket.Url, server.URL+"/files/"+ticket.Key+"?"))
+	assert.Contains(t, ticket.Url, "X-Amz-Signature=")
+	assert.Equal(t, "application/pdf", ticket.Headers["Content-Type"])
+	assert.Equal(t, "report.pdf", ticket.Headers["X-Amz-Meta-Filename"])
+	assert.WithinDuration(t, time.Now().Add(time.Minute), ticket.ExpiresAt, 5*time.Second)
+
+	req, err := http.NewRequest(ticket.Method, ticket.Url, strings.NewReader("%PDF-1.4"))
+	require.NoError(t, err)
+	for k, v := range ticket.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	info, err := store.GetFileInfo(ticket.Key)
+	require.NoError(t, err)
+	assert.Equal(t, ticket.Key, info.Key)
+	assert.Equal(t, "report.pdf", info.Filename)
+	assert.Equal(t, "application/pdf", info.ContentType)
+	assert.Equal(t, 8, info.Size)
+}
+
+func TestS3StoreUploadTicketTooLarge(t *testing.T) {
+	server := newBucketServer(t, "files")
+	defer server.Close()
+
+	store := newS3Store(t, server.URL)
+
+	_, err := store.GenerateUploadTicket("report.pdf", "application/pdf", 1025)
+	assert.ErrorIs(t, err, storage.ErrUploadTooLarge)
+}
@@ -1,8 +1,15 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"time"
+
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
 )
 
 // Storer represents the interface for a file storing service that is used by the Keel runtime
@@ -24,6 +31,62 @@ type Storer interface {
 	//
 	// The use of this function is to generate any signed URLs for file downloads.
 	HydrateFileInfo(fi *FileResponse) (FileResponse, error)
+
+	// GenerateUploadTicket will return a new file key along with a URL where the file, of the given size in bytes,
+	// can be uploaded to directly. ErrUploadTooLarge is returned if the file is larger than the maximum upload size.
+	//
+	// Tickets should be issued with IssueUploadTicket, so that once the file has been uploaded the key can be given as
+	// the input to the File field it was issued for instead of a dataURL.
+	GenerateUploadTicket(filename string, contentType string, size int64) (UploadTicket, error)
+}
+
+// UploadReceiver is implemented by a Storer which receives direct uploads through the Keel runtime rather than at
+// a URL of the storage service itself.
+type UploadReceiver interface {
+	// ReceiveUpload will save the contents of the file for the given key, which must have been issued by an upload ticket.
+	ReceiveUpload(key string, body io.Reader) (FileResponse, error)
+}
+
+// ErrUploadNotFound is returned when receiving an upload for a key which doesn't have a valid upload ticket
+var ErrUploadNotFound = errors.New("upload ticket not found or has expired")
+
+// UploadTicket is what is returned from the upload ticket endpoint, and describes how the file should be uploaded
+type UploadTicket struct {
+	Key       string            `json:"key"`
+	Url       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// NewStorer returns the Storer for the project's storage config. Files are stored in an
+// S3-compatible bucket if configured, otherwise they are stored in the database.
+func NewStorer(ctx context.Context, cfg *config.ProjectConfig, secrets map[string]string, database db.Database) (Storer, error) {
+	if cfg == nil {
+		return NewDbStore(ctx, database)
+	}
+
+	if !cfg.Storage.UsesS3() {
+		return NewDbStoreWithConfig(ctx, database, DbConfig{
+			UrlExpiry:     cfg.Storage.PresignedUrlExpiry(),
+			MaxUploadSize: cfg.Storage.UploadSizeLimit(),
+		})
+	}
+
+	if secrets[config.StorageAccessKeyIdSecret] == "" || secrets[config.StorageSecretAccessKeySecret] == "" {
+		return nil, fmt.Errorf("the %s and %s secrets must be set to store files in a bucket", config.StorageAccessKeyIdSecret, config.StorageSecretAccessKeySecret)
+	}
+
+	return NewS3Store(S3Config{
+		Bucket:          cfg.Storage.Bucket,
+		Region:          cfg.Storage.Region,
+		Endpoint:        cfg.Storage.Endpoint,
+		UsePathStyle:    cfg.Storage.UsePathStyle,
+		UrlExpiry:       cfg.Storage.PresignedUrlExpiry(),
+		MaxUploadSize:   cfg.Storage.UploadSizeLimit(),
+		AccessKeyId:     secrets[config.StorageAccessKeyIdSecret],
+		SecretAccessKey: secrets[config.StorageSecretAccessKeySecret],
+	})
 }
 
 // FileResponse is what is returned from our APIs
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/teamkeel/keel/db"
+)
+
+const (
+	// UploadTable is where upload tickets are kept so that an uploaded file can only be used by the field,
+	// and identity, which the ticket was issued for
+	UploadTable = "keel_storage_upload"
+
+	// UploadConsumePeriod is how long after a ticket's upload URL has expired that its file can still be
+	// given as an input, after which the ticket is deleted
+	UploadConsumePeriod = time.Hour
+)
+
+// ErrUploadTooLarge is returned when a file is larger than the maximum upload size
+var ErrUploadTooLarge = errors.New("the file is larger than the maximum upload size")
+
+// UploadRequest describes the file which an upload ticket is being issued for
+type UploadRequest struct {
+	ModelName   string
+	FieldName   string
+	IdentityId  string
+	Filename    string
+	ContentType string
+	Size        int64
+}
+
+// IssueUploadTicket generates an upload ticket using the storer and records what it has been issued for,
+// so that the file can only be used as the input to the same field by the same identity.
+func IssueUploadTicket(ctx context.Context, database db.Database, storer Storer, request UploadRequest) (UploadTicket, error) {
+	if err := deleteExpiredUploadTickets(ctx, database); err != nil {
+		return UploadTicket{}, err
+	}
+
+	ticket, err := storer.GenerateUploadTicket(request.Filename, request.ContentType, request.Size)
+	if err != nil {
+		return UploadTicket{}, err
+	}
+
+	sql := `INSERT INTO ` + UploadTable + ` (id, model_name, field_name, identity_id, filename, content_type, size, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	err = database.GetDB().WithContext(ctx).Exec(sql,
+		ticket.Key,
+		request.ModelName,
+		request.FieldName,
+		nullableString(request.IdentityId),
+		request.Filename,
+		request.ContentType,
+		request.Size,
+		ticket.ExpiresAt,
+	).Error
+	if err != nil {
+		return UploadTicket{}, fmt.Errorf("saving upload ticket in db: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// ConsumeUploadTicket marks the upload ticket for the key as used, returning false if there is no unused ticket
+// for the key which was issued for the given field and identity.
+func ConsumeUploadTicket(ctx context.Context, database db.Database, key string, modelName string, fieldName string, identityId string) (bool, error) {
+	sql := `UPDATE ` + UploadTable + ` SET consumed_at = now()
+		WHERE id = ?
+			AND model_name = ?
+			AND field_name = ?
+			AND identity_id IS NOT DISTINCT FROM ?
+			AND consumed_at IS NULL
+			AND expires_at > ?
+		RETURNING id`
+
+	rows := []map[string]any{}
+	err := database.GetDB().WithContext(ctx).Raw(sql,
+		key,
+		modelName,
+		fieldName,
+		nullableString(identityId),
+		time.Now().UTC().Add(-UploadConsumePeriod),
+	).Scan(&rows).Error
+	if err != nil {
+		return false, fmt.Errorf("consuming upload ticket: %w", err)
+	}
+
+	return len(rows) == 1, nil
+}
+
+// deleteExpiredUploadTickets deletes the tickets which can no longer be used
+func deleteExpiredUploadTickets(ctx context.Context, database db.Database) error {
+	sql := `DELETE FROM ` + UploadTable + ` WHERE expires_at < ?`
+
+	err := database.GetDB().WithContext(ctx).Exec(sql, time.Now().UTC().Add(-UploadConsumePeriod)).Error
+	if err != nil {
+		return fmt.Errorf("deleting expired upload tickets: %w", err)
+	}
+
+	return nil
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -93,8 +93,13 @@ func SetupDatabaseForTestCase(ctx context.Context, dbConnInfo *db.ConnectionInfo
 		return nil, err
 	}
 
-	// Migrate the database to this test case's schema.
+	// Migrate the database to this test case's schema. Test databases are disposable, so
+	// any renames inferred from the previous test case's schema are treated as a drop and an add.
 	m, err := migrations.New(ctx, schema, database)
+	var renamesErr *migrations.UnconfirmedRenamesError
+	if errors.As(err, &renamesErr) {
+		m, err = migrations.NewWithRenames(ctx, schema, database, &migrations.Renames{Rejected: renamesErr.Renames})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -16,8 +16,10 @@ import (
 	"strings"
 
 	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/encryption"
 	"github.com/teamkeel/keel/events"
 	"github.com/teamkeel/keel/functions"
+	"github.com/teamkeel/keel/mail"
 	"github.com/teamkeel/keel/node"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime"
@@ -170,6 +172,16 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 		return err
 	}
 
+	mailClient, err := mail.NewClient(builder.Config, opts.Secrets, opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	mailTemplates, err := mail.LoadTemplates(builder.Config.Email.TemplatesDir(opts.Dir))
+	if err != nil {
+		return err
+	}
+
 	for key, value := range envVars {
 		os.Setenv(key, value)
 	}
@@ -181,7 +193,7 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 			ctx, span := tracer.Start(r.Context(), strings.Trim(r.URL.Path, "/"))
 			defer span.End()
 
-			storer, err := storage.NewDbStore(context.Background(), database)
+			storer, err := storage.NewStorer(context.Background(), builder.Config, opts.Secrets, database)
 			if err != nil {
 				panic(err)
 			}
@@ -190,7 +202,12 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 			ctx = db.WithDatabase(ctx, database)
 			ctx = runtimectx.WithSecrets(ctx, opts.Secrets)
 			ctx = runtimectx.WithOAuthConfig(ctx, &builder.Config.Auth)
+			ctx = events.WithConfig(ctx, &builder.Config.Events)
+			ctx = runtimectx.WithActionsConfig(ctx, &builder.Config.Actions)
 			ctx = runtimectx.WithStorage(ctx, storer)
+			ctx = runtimectx.WithMailClient(ctx, mailClient)
+			ctx = runtimectx.WithMailTemplates(ctx, mailTemplates)
+			ctx = encryption.WithKeyring(ctx, encryption.DevelopmentKeyring())
 
 			span.SetAttributes(attribute.String("request.url", r.URL.String()))
 
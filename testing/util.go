@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/teamkeel/keel/config"
 	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/encryption"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/runtimectx"
 	"github.com/teamkeel/keel/schema"
@@ -49,6 +50,9 @@ func MakeContext(t *testing.T, ctx context.Context, keelSchema string, resetData
 	require.NoError(t, err)
 	ctx = runtimectx.WithPrivateKey(ctx, pk)
 
+	// Add encryption keyring for Secret fields to context
+	ctx = encryption.WithKeyring(ctx, encryption.DevelopmentKeyring())
+
 	ctx, err = testhelpers.WithTracing(ctx)
 	require.NoError(t, err)
 